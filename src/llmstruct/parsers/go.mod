@@ -0,0 +1,10 @@
+module github.com/kpblcaoo/llmstruct/src/llmstruct/parsers
+
+go 1.24
+
+require (
+	golang.org/x/mod v0.22.0
+	golang.org/x/tools v0.27.0
+)
+
+require golang.org/x/sync v0.9.0 // indirect
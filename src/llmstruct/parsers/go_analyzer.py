@@ -11,7 +11,7 @@ import os
 import subprocess
 import tempfile
 from pathlib import Path
-from typing import Dict, Any
+from typing import Dict, Any, List, Optional
 
 logging.basicConfig(level=logging.INFO, format="%(asctime)s - %(levelname)s - %(message)s")
 
@@ -27,32 +27,18 @@ class GoAnalyzer:
         self.temp_dir = tempfile.mkdtemp()
         temp_path = Path(self.temp_dir)
         
-        # Копируем готовый Go анализатор
-        analyzer_source = Path(__file__).parent / "analyzer.go"
-        analyzer_file = temp_path / "analyzer.go"
-        analyzer_file.write_text(analyzer_source.read_text())
-        
-        # Создаем go.mod для анализатора
-        go_mod_content = '''module go-analyzer
-
-go 1.24
-
-require (
-    golang.org/x/tools v0.27.0
-)
-
-require (
-    golang.org/x/mod v0.22.0 // indirect
-    golang.org/x/sync v0.10.0 // indirect
-)
-'''
-        
-        # Также создаем go.mod для анализатора
-        (temp_path / "go.mod").write_text(go_mod_content)
-        
-        # Загружаем зависимости заранее с отключенной проверкой контрольных сумм
+        # Копируем модуль Go анализатора: go.mod, go.sum, main.go и пакет
+        # pkg/analyzer
+        source_root = Path(__file__).parent
+        sources = list(source_root.rglob("*.go")) + [source_root / "go.mod", source_root / "go.sum"]
+        for go_source in sources:
+            target = temp_path / go_source.relative_to(source_root)
+            target.parent.mkdir(parents=True, exist_ok=True)
+            target.write_text(go_source.read_text())
+        analyzer_file = temp_path / "main.go"
+        
+        # Загружаем зависимости заранее; версии и контрольные суммы - из go.sum
         env = os.environ.copy()
-        env['GOSUMDB'] = 'off'  # Отключаем проверку контрольных сумм
         env['GOPROXY'] = 'https://proxy.golang.org,direct'
         
         try:
@@ -68,8 +54,8 @@ require (
             import shutil
             shutil.rmtree(self.temp_dir, ignore_errors=True)
     
-    def analyze_project(self, project_path: str) -> Dict[str, Any]:
-        """Анализирует Go проект"""
+    def analyze_project(self, project_path: str, extra_args: Optional[List[str]] = None) -> Dict[str, Any]:
+        """Анализирует Go проект; extra_args передаются анализатору как флаги"""
         try:
             self._setup_analyzer()
             
@@ -89,7 +75,7 @@ require (
             
             # Запускаем анализатор
             result = subprocess.run(
-                ['go', 'run', 'analyzer.go', project_path],
+                ['go', 'run', '.', *(extra_args or []), project_path],
                 cwd=self.temp_dir,
                 capture_output=True,
                 text=True,
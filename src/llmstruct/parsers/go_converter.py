@@ -13,6 +13,62 @@ from typing import Dict, Any, List
 from llmstruct.core.tag_inference import infer_tags
 
 
+# Проектные секции анализатора, переносимые в struct.json без изменений
+PROJECT_SECTIONS = (
+    "feature_flags",
+    "config_surface",
+    "resilience",
+    "transactions",
+    "channel_graph",
+    "global_state",
+    "implementations",
+    "call_graph",
+    "reflection",
+    "plugins",
+    "wire_schemas",
+    "validations",
+    "routes",
+    "auth",
+    "lifecycle",
+    "background_jobs",
+    "services",
+    "module_graph",
+    "dependency_api",
+    "package_advice",
+    "import_cycles",
+    "components",
+    "binary_size",
+    "benchmarks",
+    "coverage",
+    "perf_hints",
+    "api_conventions",
+    "error_handling",
+    "panics",
+    "time_rand",
+    "hard_coded",
+    "i18n_strings",
+    "field_usage",
+    "any_usage",
+    "linkname",
+    "templates",
+    "house_style",
+    "test_mapping",
+    "go_upgrade",
+    "shell_scripts",
+    "doc_examples",
+    "profile",
+    "terraform",
+    "unified",
+    "diagnostics",
+    "diff",
+    "annotations",
+    "scope",
+    "complexity",
+    "enums",
+    "provenance",
+)
+
+
 def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool = False, goals: List[str] = None) -> Dict[str, Any]:
     """Конвертирует результат анализа в формат llmstruct"""
     
@@ -33,6 +89,12 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
                 "receiver": fn.get("receiver", ""),
                 "is_exported": fn.get("is_exported", False),
                 "is_method": fn.get("is_method", False),
+                "tokens": fn.get("tokens", 0),
+                "complexity": fn.get("complexity", 1),
+                "coverage": fn.get("coverage"),
+                "test_kind": fn.get("test_kind"),
+                "example_output": fn.get("example_output"),
+                "anchor": fn.get("anchor"),
                 "tags": func_tags,
             })
         
@@ -47,6 +109,8 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
                 "fields": struct.get("fields", []),
                 "methods": struct.get("methods", []),
                 "is_exported": struct.get("is_exported", False),
+                "tokens": struct.get("tokens", 0),
+                "anchor": struct.get("anchor"),
                 "tags": class_tags,
             })
         
@@ -61,6 +125,8 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
                 "methods": iface.get("methods", []),
                 "is_exported": iface.get("is_exported", False),
                 "is_interface": True,
+                "tokens": iface.get("tokens", 0),
+                "anchor": iface.get("anchor"),
                 "tags": iface_tags,
             })
         
@@ -99,6 +165,11 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
             "artifact_id": str(uuid.uuid4()),
             "line_count": file_data.get("line_count", 0),
             "has_tests": file_data.get("has_tests", False),
+            "tokens": file_data.get("tokens", 0),
+            "complexity": file_data.get("complexity"),
+            "build": file_data.get("build"),
+            "is_generated": file_data.get("is_generated", False),
+            "coverage": file_data.get("coverage"),
             "tags": module_tags,
         }
         
@@ -120,14 +191,14 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
         "functions_count": sum(len(m["functions"]) for m in modules),
         "structs_count": sum(len(m["classes"]) for m in modules),
         "packages_count": len(analysis.get("all_packages", [])),
-        "call_edges_count": 0,
+        "call_edges_count": sum(len(node["calls"]) for node in analysis.get("call_graph") or []),
         "total_lines": analysis.get("total_lines", 0),
         "test_files_count": len(analysis.get("test_files", [])),
     }
     
     project_name = analysis.get("module_name", "go-project")
     
-    return {
+    result = {
         "metadata": {
             "project_name": project_name,
             "description": f"Go project analysis for {project_name}",
@@ -147,6 +218,9 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
                 "go_version": analysis.get("go_version", ""),
                 "has_go_mod": analysis.get("has_go_mod", False),
                 "dependencies": analysis.get("dependencies", []),
+                # Модули go.work (ключ modules в struct.json занят файлами)
+                "workspace": analysis.get("workspace", ""),
+                "workspace_modules": analysis.get("modules", []),
             },
             "artifact_id": str(uuid.uuid4()),
             "summary": f"Structured JSON for Go project {project_name}",
@@ -157,6 +231,12 @@ def convert_to_llmstruct_format(analysis: Dict[str, Any], include_ranges: bool =
         "modules": modules,
     }
 
+    for section in PROJECT_SECTIONS:
+        if analysis.get(section):
+            result[section] = analysis[section]
+
+    return result
+
 
 def compute_file_hash(file_path: str) -> str:
     """Вычисляет SHA-256 хэш файла"""
@@ -0,0 +1,11 @@
+package main
+
+import (
+    _ "net/http/pprof"
+
+    "github.com/kpblcaoo/llmstruct/src/llmstruct/parsers/pkg/analyzer"
+)
+
+func main() {
+    analyzer.Main()
+}
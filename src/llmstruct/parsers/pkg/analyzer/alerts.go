@@ -0,0 +1,255 @@
+package analyzer
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log/slog"
+    "math"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+)
+
+const defaultAlertsFile = ".llmstruct/alerts.json"
+
+// AlertRule - порог метрики. Package - путь пакета (с /... - вместе с
+// подпакетами); пустой - весь проект. Изменения считаются от предыдущего
+// снимка, Max/Min - по значению нового. Severity: error (по умолчанию,
+// команда завершается с ошибкой) или warning
+type AlertRule struct {
+    Metric         string   `json:"metric"`
+    Package        string   `json:"package,omitempty"`
+    MaxIncrease    *float64 `json:"max_increase,omitempty"`
+    MaxIncreasePct *float64 `json:"max_increase_pct,omitempty"`
+    MaxDecrease    *float64 `json:"max_decrease,omitempty"`
+    MaxDecreasePct *float64 `json:"max_decrease_pct,omitempty"`
+    Max            *float64 `json:"max,omitempty"`
+    Min            *float64 `json:"min,omitempty"`
+    Severity       string   `json:"severity,omitempty"`
+}
+
+type alertConfig struct {
+    Rules        []AlertRule `json:"rules"`
+}
+
+// Alert - сработавший порог
+type Alert struct {
+    Metric       string   `json:"metric"`
+    Package      string   `json:"package,omitempty"`
+    Severity     string   `json:"severity"`
+    Before       *float64 `json:"before,omitempty"`
+    After        float64  `json:"after"`
+    Message      string   `json:"message"`
+}
+
+// Метрики пакета; coverage есть только у проекта
+var packageMetrics = []string{"files", "lines", "functions", "complexity", "avg_complexity", "max_complexity", "api_size", "tokens"}
+
+// loadAlertRules читает правила; отсутствие файла по умолчанию - не ошибка
+func loadAlertRules(path string) ([]AlertRule, error) {
+    data, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) && strings.HasSuffix(filepath.ToSlash(path), defaultAlertsFile) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    var config alertConfig
+    if err := json.Unmarshal(data, &config); err != nil {
+        return nil, fmt.Errorf("%s: %v", path, err)
+    }
+    for i, rule := range config.Rules {
+        metrics := historyMetrics
+        if rule.Package != "" {
+            metrics = packageMetrics
+        }
+        if !slices.Contains(metrics, rule.Metric) {
+            return nil, fmt.Errorf("%s: rule %d: unknown metric %q (supported: %s)", path, i+1, rule.Metric, strings.Join(metrics, ", "))
+        }
+        if rule.Severity != "" && rule.Severity != "error" && rule.Severity != "warning" {
+            return nil, fmt.Errorf("%s: rule %d: unknown severity %q (supported: error, warning)", path, i+1, rule.Severity)
+        }
+    }
+    return config.Rules, nil
+}
+
+// packageMetricValues сводит метрики пакетов, подходящих под шаблон;
+// ok=false - таких пакетов в анализе нет
+func packageMetricValues(analysis *ProjectAnalysis, pattern string) (map[string]float64, bool) {
+    prefix, recursive := strings.CutSuffix(pattern, "/...")
+    match := func(pkg string) bool {
+        return pkg == prefix || recursive && hasPathPrefix(pkg, prefix)
+    }
+    values := make(map[string]float64)
+    found := false
+    for _, file := range analysis.Files {
+        if file.HasTests || !match(packagePathOf(analysis, file.Path)) {
+            continue
+        }
+        found = true
+        values["files"]++
+        values["lines"] += float64(file.LineCount)
+        values["tokens"] += float64(file.Tokens)
+        if c := file.Complexity; c != nil {
+            values["functions"] += float64(c.Functions)
+            values["complexity"] += float64(c.Total)
+            values["max_complexity"] = math.Max(values["max_complexity"], float64(c.Max))
+        }
+    }
+    if values["functions"] > 0 {
+        values["avg_complexity"] = roundMetric(values["complexity"] / values["functions"])
+    }
+    for id := range goSymbols(analysis, true) {
+        if match(symbolIDPackage(id)) {
+            values["api_size"]++
+        }
+    }
+    return values, found
+}
+
+func formatMetric(v float64) string {
+    return fmt.Sprintf("%g", roundMetric(v))
+}
+
+// check проверяет правило; before - nil, если предыдущего значения нет
+func (rule AlertRule) check(before *float64, after float64) []string {
+    var violations []string
+    if rule.Max != nil && after > *rule.Max {
+        violations = append(violations, fmt.Sprintf("%s above maximum %s", formatMetric(after), formatMetric(*rule.Max)))
+    }
+    if rule.Min != nil && after < *rule.Min {
+        violations = append(violations, fmt.Sprintf("%s below minimum %s", formatMetric(after), formatMetric(*rule.Min)))
+    }
+    if before == nil {
+        return violations
+    }
+    delta := after - *before
+    pct := 0.0
+    if *before != 0 {
+        pct = delta / math.Abs(*before) * 100
+    } else if delta != 0 {
+        pct = math.Inf(int(math.Copysign(1, delta)))
+    }
+    change := fmt.Sprintf("from %s to %s", formatMetric(*before), formatMetric(after))
+    switch {
+    case rule.MaxIncrease != nil && delta > *rule.MaxIncrease:
+        violations = append(violations, fmt.Sprintf("rose by %s (%s), more than %s", formatMetric(delta), change, formatMetric(*rule.MaxIncrease)))
+    case rule.MaxIncreasePct != nil && pct > *rule.MaxIncreasePct:
+        violations = append(violations, fmt.Sprintf("rose by %s%% (%s), more than %s%%", formatMetric(pct), change, formatMetric(*rule.MaxIncreasePct)))
+    }
+    switch {
+    case rule.MaxDecrease != nil && -delta > *rule.MaxDecrease:
+        violations = append(violations, fmt.Sprintf("fell by %s (%s), more than %s", formatMetric(-delta), change, formatMetric(*rule.MaxDecrease)))
+    case rule.MaxDecreasePct != nil && -pct > *rule.MaxDecreasePct:
+        violations = append(violations, fmt.Sprintf("fell by %s%% (%s), more than %s%%", formatMetric(-pct), change, formatMetric(*rule.MaxDecreasePct)))
+    }
+    return violations
+}
+
+// evaluateAlerts сравнивает снимок to с предыдущим from (nil - первый
+// снимок) по правилам
+func (h *historyStore) evaluateAlerts(rules []AlertRule, from *Snapshot, to Snapshot) ([]Alert, error) {
+    alerts := []Alert{}
+    var before, after *ProjectAnalysis
+    for _, rule := range rules {
+        var prev map[string]float64
+        var next map[string]float64
+        if rule.Package == "" {
+            next = to.Metrics.values()
+            if from != nil {
+                prev = from.Metrics.values()
+            }
+        } else {
+            // Анализы загружаются, только если есть правила для пакетов
+            var err error
+            if after == nil {
+                if after, err = h.load(to); err != nil {
+                    return nil, err
+                }
+            }
+            if before == nil && from != nil {
+                if before, err = h.load(*from); err != nil {
+                    return nil, err
+                }
+            }
+            var found bool
+            if next, found = packageMetricValues(after, rule.Package); !found {
+                alerts = append(alerts, Alert{Metric: rule.Metric, Package: rule.Package, Severity: "warning", Message: fmt.Sprintf("package %s not found in the snapshot", rule.Package)})
+                continue
+            }
+            if before != nil {
+                if values, ok := packageMetricValues(before, rule.Package); ok {
+                    prev = values
+                }
+            }
+        }
+        value, ok := next[rule.Metric]
+        if !ok {
+            // Покрытие не записано
+            continue
+        }
+        var old *float64
+        if v, ok := prev[rule.Metric]; ok {
+            old = &v
+        }
+        severity := rule.Severity
+        if severity == "" {
+            severity = "error"
+        }
+        for _, violation := range rule.check(old, value) {
+            subject := rule.Metric
+            if rule.Package != "" {
+                subject += " of " + rule.Package
+            }
+            alerts = append(alerts, Alert{
+                Metric:   rule.Metric,
+                Package:  rule.Package,
+                Severity: severity,
+                Before:   old,
+                After:    value,
+                Message:  subject + " " + violation,
+            })
+        }
+    }
+    return alerts, nil
+}
+
+// predecessor - снимок, записанный перед snap; nil для первого
+func (h *historyStore) predecessor(snap Snapshot) *Snapshot {
+    for i, s := range h.Snapshots {
+        if s.ID == snap.ID && i > 0 {
+            return &h.Snapshots[i-1]
+        }
+    }
+    return nil
+}
+
+func loadProjectAlertRules(projectPath, path string) []AlertRule {
+    if !filepath.IsAbs(path) {
+        path = filepath.Join(projectPath, path)
+    }
+    rules, err := loadAlertRules(path)
+    if err != nil {
+        fatal("failed to read metric thresholds", "error", err)
+    }
+    return rules
+}
+
+// exitOnAlerts завершает команду с кодом 1, если сработал порог уровня
+// error; предупреждения только выводятся в журнал
+func exitOnAlerts(alerts []Alert) {
+    failed := 0
+    for _, alert := range alerts {
+        if alert.Severity == "error" {
+            slog.Error("metric threshold exceeded", "alert", alert.Message)
+            failed++
+        } else {
+            slog.Warn("metric threshold exceeded", "alert", alert.Message)
+        }
+    }
+    if failed > 0 {
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,109 @@
+// Package analyzer строит структурное описание Go-проекта для LLM: файлы,
+// символы, связи между ними и отчёты поверх них. Команда analyzer - тонкая
+// обёртка над Main; другие инструменты встраивают анализ через Analyze.
+package analyzer
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+)
+
+// Значения по умолчанию флагов analyzer
+const (
+    defaultMaxFileSize = 4 << 20
+    defaultMaxSymbols  = 5000
+    defaultSampleRate  = 0.2
+    defaultTokenizer   = "cl100k"
+)
+
+// defaultCacheDir - кэш анализа в пользовательском каталоге кэша; пустая
+// строка (кэш отключён), если каталога нет
+func defaultCacheDir() string {
+    if dir, err := os.UserCacheDir(); err == nil {
+        return filepath.Join(dir, "llmstruct", "go")
+    }
+    return ""
+}
+
+// defaultOptions - опции команды analyzer без флагов: лимиты размера
+// файлов и числа символов, токенизатор и кэш. С них начинают подкоманды,
+// чтобы анализ огромных файлов не обходил лимиты
+func defaultOptions() Options {
+    return Options{
+        Limits: Limits{
+            MaxFileSize: defaultMaxFileSize,
+            MaxSymbols:  defaultMaxSymbols,
+        },
+        SampleRate: defaultSampleRate,
+        Tokenizer:  defaultTokenizer,
+        Vendor:     vendorSkip,
+        DocsSite:   defaultDocsSite,
+        CacheDir:   defaultCacheDir(),
+    }
+}
+
+// validate проверяет значения режимов, которые задаются строками
+func (o Options) validate() error {
+    if o.IncludeDeps != "" && o.IncludeDeps != "direct" {
+        return fmt.Errorf("unknown include-deps mode %q (supported: direct)", o.IncludeDeps)
+    }
+    if err := validVendorMode(o.Vendor); err != nil {
+        return err
+    }
+    if o.DepDocs && o.IncludeDeps == "" {
+        return fmt.Errorf("dependency docs require include-deps")
+    }
+    if o.Sample != "" && o.Sample != "representative" {
+        return fmt.Errorf("unknown sample mode %q (supported: representative)", o.Sample)
+    }
+    if o.Scope != "" {
+        if _, _, err := parseScope(o.Scope); err != nil {
+            return err
+        }
+    }
+    if _, err := newTokenCounter(o.Tokenizer); err != nil {
+        return err
+    }
+    if o.GoUpgrade != "" {
+        if err := validGoVersion(o.GoUpgrade); err != nil {
+            return err
+        }
+    }
+    if _, err := newPathFilter(o.Include, o.Exclude); err != nil {
+        return err
+    }
+    // Эти режимы дополняют функции или читают файлы анализа уже после
+    // того, как файл отдан в FileSink
+    if o.FileSink != nil && (o.CompilerFeedback || o.BenchResults != "" || o.CoverProfile != "" || o.Anchors || o.TestMapping) {
+        return fmt.Errorf("streaming output cannot be combined with compiler feedback, benchmark results, coverage, anchors or test mapping")
+    }
+    return nil
+}
+
+// Analyze анализирует проект в каталоге path так же, как команда analyzer
+// без флагов вывода. Нефатальные проблемы (ошибки загрузки пакетов,
+// недоступные go-команды) попадают в ProjectAnalysis.Errors; ошибка
+// возвращается для неверного пути или опций и при отмене ctx
+func Analyze(ctx context.Context, path string, opts Options) (*ProjectAnalysis, error) {
+    if err := opts.validate(); err != nil {
+        return nil, err
+    }
+    projectPath, err := filepath.Abs(path)
+    if err != nil {
+        return nil, err
+    }
+    info, err := os.Stat(projectPath)
+    if err != nil {
+        return nil, err
+    }
+    if !info.IsDir() {
+        return nil, fmt.Errorf("%s is not a directory", path)
+    }
+    analysis := analyzeProject(ctx, projectPath, opts)
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+    return analysis, nil
+}
@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "go/ast"
+    "go/doc"
+    "go/format"
+    "go/parser"
+    "go/token"
+    "io/fs"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+)
+
+// ModuleExample - пример Example* из тестов модуля, как его показывает
+// pkg.go.dev
+type ModuleExample struct {
+    Name         string   `json:"name"`
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Doc          string   `json:"doc,omitempty"`
+    Code         string   `json:"code"`
+    Output       string   `json:"output,omitempty"`
+}
+
+// PublishedModule - анализ опубликованного модуля из кэша модулей
+type PublishedModule struct {
+    Path         string           `json:"path"`
+    Version      string           `json:"version"`
+    Time         time.Time        `json:"time"`
+    Dir          string           `json:"dir"`
+    Examples     []ModuleExample  `json:"examples"`
+    Analysis     *ProjectAnalysis `json:"analysis"`
+}
+
+// goModDownload - вывод go mod download -json
+type goModDownload struct {
+    Path    string
+    Version string
+    Info    string
+    Dir     string
+    Error   string
+}
+
+// downloadModule скачивает модуль в кэш (или находит его там) и
+// возвращает его каталог; version может быть latest или запросом вроде
+// v1.2
+func downloadModule(ctx context.Context, path, version string) (*goModDownload, error) {
+    cmd := exec.CommandContext(ctx, "go", "mod", "download", "-json", path+"@"+version)
+    // Запуск вне проекта: go.mod и go.work текущего каталога не влияют
+    cmd.Dir = os.TempDir()
+    cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    var m goModDownload
+    if jsonErr := json.Unmarshal(out, &m); jsonErr == nil && m.Error != "" {
+        return nil, fmt.Errorf("%s", m.Error)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("go mod download: %v: %s", err, strings.TrimSpace(stderr.String()))
+    }
+    if m.Dir == "" {
+        return nil, fmt.Errorf("go mod download: no directory for %s@%s", path, version)
+    }
+    return &m, nil
+}
+
+// moduleExamples собирает примеры из _test.go всех пакетов модуля
+func moduleExamples(dir, modulePath string) ([]ModuleExample, error) {
+    examples := []ModuleExample{}
+    err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if !d.IsDir() {
+            return nil
+        }
+        if path != dir && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_") || fileExists(filepath.Join(path, "go.mod"))) {
+            // Вложенный go.mod - другой модуль
+            return filepath.SkipDir
+        }
+        matches, _ := filepath.Glob(filepath.Join(path, "*_test.go"))
+        if len(matches) == 0 {
+            return nil
+        }
+        fset := token.NewFileSet()
+        rel, _ := filepath.Rel(dir, path)
+        pkgPath := modulePath
+        if rel != "." {
+            pkgPath += "/" + filepath.ToSlash(rel)
+        }
+        for _, name := range matches {
+            file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+            if err != nil {
+                continue
+            }
+            fileRel, _ := filepath.Rel(dir, name)
+            for _, ex := range doc.Examples(file) {
+                var code bytes.Buffer
+                var node any = ex.Code
+                if ex.Play != nil {
+                    // Полная программа для примеров с собственными объявлениями
+                    node = ex.Play
+                }
+                if err := format.Node(&code, fset, node); err != nil {
+                    continue
+                }
+                text := code.String()
+                if _, ok := ex.Code.(*ast.BlockStmt); ok && ex.Play == nil {
+                    // Тело функции примера без скобок и отступа
+                    text = strings.TrimSuffix(strings.TrimPrefix(text, "{"), "}")
+                    text = strings.TrimSpace(strings.ReplaceAll(text, "\n\t", "\n"))
+                }
+                examples = append(examples, ModuleExample{
+                    Name:    "Example" + ex.Name,
+                    Package: pkgPath,
+                    File:    filepath.ToSlash(fileRel),
+                    Doc:     strings.TrimSpace(ex.Doc),
+                    Code:    text,
+                    Output:  strings.TrimSpace(ex.Output),
+                })
+            }
+        }
+        return nil
+    })
+    sort.SliceStable(examples, func(i, j int) bool {
+        if examples[i].Package != examples[j].Package {
+            return examples[i].Package < examples[j].Package
+        }
+        return examples[i].Name < examples[j].Name
+    })
+    return examples, err
+}
+
+// runAnalyzeModule - analyzer analyze-module <path>@<version>: API,
+// документация и примеры опубликованного модуля без проекта, который от
+// него зависит. Каталог кэша модулей только читается
+func runAnalyzeModule(args []string) {
+    fs := newCommandFlags("analyze-module", "[flags] <module>@<version>")
+    tokenizer := fs.String("tokenizer", "cl100k", "token estimate: cl100k (BPE approximation) or chars (4 bytes per token)")
+    fs.parse(args)
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+    path, version, ok := strings.Cut(fs.Arg(0), "@")
+    if !ok {
+        version = "latest"
+    }
+    opts := defaultOptions()
+    opts.Tokenizer = *tokenizer
+    if err := opts.validate(); err != nil {
+        fatal("invalid options", "error", err)
+    }
+
+    ctx := context.Background()
+    m, err := downloadModule(ctx, path, version)
+    if err != nil {
+        fatal("failed to download module", "module", fs.Arg(0), "error", err)
+    }
+    // go.sum в кэше не меняется: недостающие записи - ошибка загрузки,
+    // а не запись в каталог модуля
+    os.Setenv("GOWORK", "off")
+    os.Setenv("GOFLAGS", "-mod=readonly")
+    result := &PublishedModule{Path: m.Path, Version: m.Version, Dir: m.Dir}
+    if data, err := os.ReadFile(m.Info); err == nil {
+        var info struct{ Time time.Time }
+        if json.Unmarshal(data, &info) == nil {
+            result.Time = info.Time
+        }
+    }
+    result.Analysis = analyzeProject(ctx, m.Dir, opts)
+    if result.Examples, err = moduleExamples(m.Dir, m.Path); err != nil {
+        result.Analysis.Errors = append(result.Analysis.Errors, fmt.Sprintf("Examples: %v", err))
+    }
+    fs.write(result)
+}
@@ -0,0 +1,1591 @@
+package analyzer
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "go/ast"
+    "go/constant"
+    "go/doc"
+    "go/token"
+    "go/types"
+    "log/slog"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "runtime/trace"
+    "sort"
+    "strconv"
+    "strings"
+    
+    "golang.org/x/tools/go/packages"
+    "golang.org/x/tools/go/types/typeutil"
+)
+
+type Options struct {
+    FlagPatterns []*regexp.Regexp
+    Include      []string
+    Exclude      []string
+    // ExcludeGenerated - не включать в Files файлы с заголовком
+    // "Code generated ... DO NOT EDIT."
+    ExcludeGenerated bool
+    // Scope - team:<имя> или owner:<владелец> из CODEOWNERS
+    Scope        string
+    AuthPatterns []*regexp.Regexp
+    Diagnostics  bool
+    Platforms    []string
+    BuildTags    []string
+    IncludeDeps  string
+    // Vendor - режим каталога vendor: skip (по умолчанию), deps-only или
+    // include
+    Vendor       string
+    // DepDocs - дописать к используемым символам зависимостей
+    // документацию с DocsSite (pkg.go.dev по умолчанию)
+    DepDocs      bool
+    DocsSite     string
+    StdlibCalls  bool
+    FuncDeps     bool
+    BinaryTarget string
+    CompilerFeedback bool
+    BenchResults string
+    // CoverProfile - профиль go test -coverprofile для покрытия функций
+    // и файлов
+    CoverProfile string
+    PerfHints    bool
+    APIAudit     bool
+    ErrorReport  bool
+    Panics       bool
+    TimeRand     bool
+    HardCoded    bool
+    I18n         bool
+    FieldUsage   bool
+    AnyUsage     bool
+    Linkname     bool
+    Templates    bool
+    HouseStyle   bool
+    TestMapping  bool
+    // Tests - загружать и _test.go: тестовые варианты пакетов вместо
+    // обычных
+    Tests        bool
+    // Anchors - стабильные якоря функций и типов для analyzer edits
+    Anchors      bool
+    // GoUpgrade - целевая версия Go для отчёта о влиянии обновления
+    GoUpgrade    string
+    ResolvedTypes bool
+    Limits       Limits
+    Sample       string
+    SampleRate   float64
+    Unified      bool
+    // Tokenizer - оценка токенов для функций, типов и файлов: cl100k
+    // (по умолчанию) или chars
+    Tokenizer    string
+    // CacheDir - каталог кэша анализа файлов по хэшу содержимого;
+    // пустая строка - без кэша
+    CacheDir     string
+    // FileSink получает каждый файл сразу после разбора (потоковый
+    // вывод); в ProjectAnalysis.Files файлы тогда не попадают, и секции,
+    // которые строятся по ним (complexity, call_graph, package_advice,
+    // import_cycles, profile), не заполняются
+    FileSink     func(FileAnalysis) error
+}
+
+// stringList - повторяемый строковый флаг командной строки
+type stringList []string
+
+func (l *stringList) String() string {
+    return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+    *l = append(*l, value)
+    return nil
+}
+
+type Function struct {
+    Name         string   `json:"name"`
+    Params       []string `json:"params"`
+    Returns      []string `json:"returns"`
+    Line         int      `json:"line"`
+    EndLine      int      `json:"end_line"`
+    Docstring    string   `json:"docstring"`
+    Receiver     string   `json:"receiver,omitempty"`
+    IsExported   bool     `json:"is_exported"`
+    IsMethod     bool     `json:"is_method"`
+    TypeParams   []string `json:"type_params,omitempty"`
+    StdlibCalls  []StdlibCall `json:"stdlib_calls,omitempty"`
+    Deps         *FunctionDeps `json:"deps,omitempty"`
+    Compiler     *CompilerFeedback `json:"compiler,omitempty"`
+    Benchmarks   []BenchmarkStat `json:"benchmarks,omitempty"`
+    // Coverage - доля покрытых операторов (%) по -coverprofile
+    Coverage     *float64 `json:"coverage,omitempty"`
+    Tokens       int      `json:"tokens"`
+    Complexity   int      `json:"complexity,omitempty"`
+    // TestKind - test, benchmark, fuzz или example для функций _test.go;
+    // ExampleOutput - ожидаемый вывод из комментария "// Output:"
+    TestKind     string   `json:"test_kind,omitempty"`
+    ExampleOutput string  `json:"example_output,omitempty"`
+    ExampleUnordered bool `json:"example_unordered,omitempty"`
+    // Anchor - хэш стабильного ID (-anchors) для правок через analyzer edits
+    Anchor       string   `json:"anchor,omitempty"`
+}
+
+type Struct struct {
+    Name         string   `json:"name"`
+    Fields       []Field  `json:"fields"`
+    Line         int      `json:"line"`
+    EndLine      int      `json:"end_line"`
+    Docstring    string   `json:"docstring"`
+    IsExported   bool     `json:"is_exported"`
+    TypeParams   []string `json:"type_params,omitempty"`
+    Methods      []Function `json:"methods"`
+    Tokens       int      `json:"tokens"`
+    Anchor       string   `json:"anchor,omitempty"`
+}
+
+// Field - поле структуры или встроенный в интерфейс тип. Tags - ключи
+// тега (json, db, yaml...) со значениями, как их возвращает
+// reflect.StructTag.Get; Name встроенного поля - имя его типа
+type Field struct {
+    Name         string            `json:"name"`
+    Type         string            `json:"type"`
+    Tags         map[string]string `json:"tags,omitempty"`
+    Docstring    string            `json:"docstring,omitempty"`
+    Embedded     bool              `json:"embedded,omitempty"`
+}
+
+// Variable - переменная или константа. У констант Value - значение,
+// вычисленное go/types (в виде Go-литерала), ValueKind - bool, string,
+// int, float или complex
+type Variable struct {
+    Name         string   `json:"name"`
+    Type         string   `json:"type"`
+    Line         int      `json:"line"`
+    IsExported   bool     `json:"is_exported"`
+    IsConstant   bool     `json:"is_constant"`
+    Value        string   `json:"value,omitempty"`
+    ValueKind    string   `json:"value_kind,omitempty"`
+}
+
+type Import struct {
+    Path         string   `json:"path"`
+    Alias        string   `json:"alias"`
+    Line         int      `json:"line"`
+}
+
+type FileAnalysis struct {
+    Path         string     `json:"path"`
+    Package      string     `json:"package"`
+    Imports      []Import   `json:"imports"`
+    Functions    []Function `json:"functions"`
+    Structs      []Struct   `json:"structs"`
+    Variables    []Variable `json:"variables"`
+    Constants    []Variable `json:"constants"`
+    Interfaces   []Struct   `json:"interfaces"`
+    LineCount    int        `json:"line_count"`
+    HasTests     bool       `json:"has_tests"`
+    Truncated    []string   `json:"truncated,omitempty"`
+    Tokens       int        `json:"tokens"`
+    Complexity   *ComplexityStats `json:"complexity,omitempty"`
+    Build        *FileBuild `json:"build,omitempty"`
+    IsGenerated  bool       `json:"is_generated,omitempty"`
+    Coverage     *float64   `json:"coverage,omitempty"`
+}
+
+type ProjectAnalysis struct {
+    ModuleName     string         `json:"module_name"`
+    GoVersion      string         `json:"go_version"`
+    // Workspace - файл go.work, по которому загружены модули Modules
+    // (GoVersion тогда - версия из go.work); без него Modules - вложенные
+    // go.mod проекта
+    Workspace      string         `json:"workspace,omitempty"`
+    Modules        []WorkspaceModule `json:"modules,omitempty"`
+    Files          []FileAnalysis `json:"files"`
+    Dependencies   []string       `json:"dependencies"`
+    AllPackages    []string       `json:"all_packages"`
+    TestFiles      []string       `json:"test_files"`
+    TotalLines     int            `json:"total_lines"`
+    HasGoMod       bool           `json:"has_go_mod"`
+    Environment    *BuildEnvironment `json:"environment,omitempty"`
+    SkippedInputs  []SkippedInput `json:"skipped_inputs,omitempty"`
+    Scope          *ScopeInfo     `json:"scope,omitempty"`
+    Complexity     []PackageComplexity `json:"complexity,omitempty"`
+    Enums          []Enum         `json:"enums,omitempty"`
+    Sampling       *SamplingInfo  `json:"sampling,omitempty"`
+    Meta           *AnalysisMeta  `json:"meta,omitempty"`
+    Provenance     *Provenance    `json:"provenance,omitempty"`
+    FeatureFlags   []FeatureFlag  `json:"feature_flags,omitempty"`
+    ConfigSurface  *ConfigSurface `json:"config_surface,omitempty"`
+    Resilience     []ResiliencePattern `json:"resilience,omitempty"`
+    Transactions   []Transaction  `json:"transactions,omitempty"`
+    ChannelGraph   *ChannelGraph  `json:"channel_graph,omitempty"`
+    GlobalState    []GlobalVar    `json:"global_state,omitempty"`
+    Implementations []TypeImplementations `json:"implementations,omitempty"`
+    CallGraph      []CallGraphNode `json:"call_graph,omitempty"`
+    Reflection     []ReflectionSite `json:"reflection,omitempty"`
+    Plugins        *PluginReport  `json:"plugins,omitempty"`
+    WireSchemas    []JSONSchema   `json:"wire_schemas,omitempty"`
+    Validations    []FieldValidation `json:"validations,omitempty"`
+    Routes         []Route        `json:"routes,omitempty"`
+    Auth           *AuthReport    `json:"auth,omitempty"`
+    Lifecycle      *Lifecycle     `json:"lifecycle,omitempty"`
+    BackgroundJobs []BackgroundJob `json:"background_jobs,omitempty"`
+    Services       *ServiceEndpoints `json:"services,omitempty"`
+    ModuleGraph    *ModuleGraph   `json:"module_graph,omitempty"`
+    PackageAdvice  *PackageAdvice `json:"package_advice,omitempty"`
+    ImportCycles   []ImportCycle  `json:"import_cycles,omitempty"`
+    Components     *ComponentGraph `json:"components,omitempty"`
+    BinarySize     *BinarySize    `json:"binary_size,omitempty"`
+    Benchmarks     []BenchmarkResult `json:"benchmarks,omitempty"`
+    Coverage       *CoverageReport `json:"coverage,omitempty"`
+    PerfHints      []FunctionPerfHints `json:"perf_hints,omitempty"`
+    APIConventions []APIFinding   `json:"api_conventions,omitempty"`
+    ErrorHandling  *ErrorHandlingReport `json:"error_handling,omitempty"`
+    Panics         []PanicSite    `json:"panics,omitempty"`
+    TimeRand       []FunctionTimeRand `json:"time_rand,omitempty"`
+    HardCoded      []HardCodedValue `json:"hard_coded,omitempty"`
+    I18nStrings    []UserMessage  `json:"i18n_strings,omitempty"`
+    FieldUsage     []StructFieldUsage `json:"field_usage,omitempty"`
+    AnyUsage       []AnyUse       `json:"any_usage,omitempty"`
+    Linkname       *LinknameReport `json:"linkname,omitempty"`
+    Templates      []FunctionTemplate `json:"templates,omitempty"`
+    HouseStyle     *HouseStyle    `json:"house_style,omitempty"`
+    TestMapping    []TestMapping  `json:"test_mapping,omitempty"`
+    GoUpgrade      *UpgradeReport `json:"go_upgrade,omitempty"`
+    DependencyAPI  []DependencyAPI `json:"dependency_api,omitempty"`
+    ShellScripts   []ShellScript  `json:"shell_scripts,omitempty"`
+    DocExamples    []DocCodeBlock `json:"doc_examples,omitempty"`
+    Profile        *ProjectProfile `json:"profile,omitempty"`
+    Terraform      *TerraformProvider `json:"terraform,omitempty"`
+    Diagnostics    *Diagnostics   `json:"diagnostics,omitempty"`
+    Diff           *AnalysisDiff  `json:"diff,omitempty"`
+    Annotations    []AttachedAnnotation `json:"annotations,omitempty"`
+    Unified        *UnifiedSchema `json:"unified,omitempty"`
+    Errors         []string       `json:"errors"`
+    
+    // Ссылки между символами для команд review-pack, impact и др.
+    refs           *refIndex
+}
+
+// BatchAnalysis - результат пакетного режима по нескольким проектам
+type BatchAnalysis struct {
+    Projects       []*ProjectAnalysis `json:"projects"`
+    ServiceLinks   []ServiceLink      `json:"service_links"`
+}
+
+func extractTypeString(expr ast.Expr) string {
+    if expr == nil {
+        return ""
+    }
+    
+    switch t := expr.(type) {
+    case *ast.Ident:
+        return t.Name
+    case *ast.StarExpr:
+        return "*" + extractTypeString(t.X)
+    case *ast.ArrayType:
+        return "[]" + extractTypeString(t.Elt)
+    case *ast.SelectorExpr:
+        return extractTypeString(t.X) + "." + t.Sel.Name
+    case *ast.MapType:
+        return "map[" + extractTypeString(t.Key) + "]" + extractTypeString(t.Value)
+    case *ast.ChanType:
+        dir := ""
+        if t.Dir == ast.SEND {
+            dir = "chan<- "
+        } else if t.Dir == ast.RECV {
+            dir = "<-chan "
+        } else {
+            dir = "chan "
+        }
+        return dir + extractTypeString(t.Value)
+    case *ast.InterfaceType:
+        return "interface{}"
+    case *ast.StructType:
+        return "struct{}"
+    case *ast.FuncType:
+        sig := "func(" + strings.Join(fieldTypes(t.Params), ", ") + ")"
+        results := fieldTypes(t.Results)
+        switch len(results) {
+        case 0:
+        case 1:
+            sig += " " + results[0]
+        default:
+            sig += " (" + strings.Join(results, ", ") + ")"
+        }
+        return sig
+    case *ast.Ellipsis:
+        return "..." + extractTypeString(t.Elt)
+    case *ast.IndexExpr:
+        // Инстанцированный дженерик: List[int]
+        return extractTypeString(t.X) + "[" + extractTypeString(t.Index) + "]"
+    case *ast.IndexListExpr:
+        args := make([]string, len(t.Indices))
+        for i, index := range t.Indices {
+            args[i] = extractTypeString(index)
+        }
+        return extractTypeString(t.X) + "[" + strings.Join(args, ", ") + "]"
+    case *ast.UnaryExpr:
+        // Элемент ограничения: ~int
+        return t.Op.String() + extractTypeString(t.X)
+    case *ast.BinaryExpr:
+        // Объединение в ограничении: ~int | ~string
+        return extractTypeString(t.X) + " " + t.Op.String() + " " + extractTypeString(t.Y)
+    case *ast.ParenExpr:
+        return "(" + extractTypeString(t.X) + ")"
+    default:
+        return fmt.Sprintf("%T", t)
+    }
+}
+
+// fieldTypes возвращает типы параметров или результатов, по одному на имя
+func fieldTypes(list *ast.FieldList) []string {
+    var result []string
+    if list == nil {
+        return result
+    }
+    for _, field := range list.List {
+        typ := extractTypeString(field.Type)
+        for range max(len(field.Names), 1) {
+            result = append(result, typ)
+        }
+    }
+    return result
+}
+
+// extractFields возвращает параметры или результаты в виде "имя тип"
+// (или только тип для безымянных)
+func extractFields(list *ast.FieldList, typeOf func(ast.Expr) string) []string {
+    fields := []string{}
+    if list == nil {
+        return fields
+    }
+    for _, field := range list.List {
+        typ := typeOf(field.Type)
+        if len(field.Names) == 0 {
+            fields = append(fields, typ)
+        }
+        for _, name := range field.Names {
+            fields = append(fields, name.Name+" "+typ)
+        }
+    }
+    return fields
+}
+
+// extractTypeParams возвращает параметры типа с ограничениями: "K comparable", "V any"
+func extractTypeParams(list *ast.FieldList) []string {
+    if list == nil {
+        return nil
+    }
+    var params []string
+    for _, field := range list.List {
+        constraint := extractTypeString(field.Type)
+        for _, name := range field.Names {
+            params = append(params, name.Name+" "+constraint)
+        }
+    }
+    return params
+}
+
+// extractStructFields разворачивает объявление "A, B int `tag`" в поля;
+// без doc-комментария над полем берётся комментарий в конце строки
+func extractStructFields(field *ast.Field, typeOf func(ast.Expr) string) []Field {
+    f := Field{Type: typeOf(field.Type), Docstring: extractDocstring(field.Doc)}
+    if f.Docstring == "" {
+        f.Docstring = extractDocstring(field.Comment)
+    }
+    if field.Tag != nil {
+        if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+            for _, key := range tagKeys(raw) {
+                if f.Tags == nil {
+                    f.Tags = make(map[string]string)
+                }
+                f.Tags[key] = reflect.StructTag(raw).Get(key)
+            }
+        }
+    }
+    if len(field.Names) == 0 {
+        f.Name = embeddedFieldName(f.Type)
+        f.Embedded = true
+        return []Field{f}
+    }
+    fields := make([]Field, len(field.Names))
+    for i, name := range field.Names {
+        fields[i] = f
+        fields[i].Name = name.Name
+    }
+    return fields
+}
+
+// embeddedFieldName - имя встроенного поля: тип без указателя, пакета и
+// аргументов типа; у ограничений вида ~int | string имени нет
+func embeddedFieldName(typ string) string {
+    name := strings.TrimPrefix(typ, "*")
+    if i := strings.Index(name, "["); i >= 0 {
+        name = name[:i]
+    }
+    name = name[strings.LastIndex(name, ".")+1:]
+    if !token.IsIdentifier(name) {
+        return ""
+    }
+    return name
+}
+
+// String - поле в виде Go-объявления: "Name Type" или тип встроенного поля
+func (f Field) String() string {
+    if f.Embedded {
+        return f.Type
+    }
+    return f.Name + " " + f.Type
+}
+
+func extractDocstring(doc *ast.CommentGroup) string {
+    if doc == nil {
+        return ""
+    }
+    
+    var lines []string
+    for _, comment := range doc.List {
+        text := comment.Text
+        if strings.HasPrefix(text, "//") {
+            text = strings.TrimPrefix(text, "//")
+        } else if strings.HasPrefix(text, "/*") && strings.HasSuffix(text, "*/") {
+            text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+        }
+        text = strings.TrimSpace(text)
+        if text != "" {
+            lines = append(lines, text)
+        }
+    }
+    return strings.Join(lines, " ")
+}
+
+func relativePath(projectPath, filename string) string {
+    if rel, err := filepath.Rel(projectPath, filename); err == nil {
+        return rel
+    }
+    return filename
+}
+
+// funcID возвращает полное имя функции в формате go/types, например
+// "(*example.com/pkg.Server).Handle"
+func funcID(pkg *packages.Package, decl *ast.FuncDecl) string {
+    if decl == nil {
+        return ""
+    }
+    if pkg.TypesInfo != nil {
+        if fn, ok := pkg.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+            return fn.FullName()
+        }
+    }
+    if decl.Recv != nil && len(decl.Recv.List) > 0 {
+        return "(" + pkg.PkgPath + "." + extractTypeString(decl.Recv.List[0].Type) + ")." + decl.Name.Name
+    }
+    return pkg.PkgPath + "." + decl.Name.Name
+}
+
+// inspectCode обходит тела всех функций файла и инициализаторы
+// переменных уровня пакета, передавая в visit объявление функции (nil для
+// уровня пакета) и каждый узел
+func inspectCode(file *ast.File, visit func(decl *ast.FuncDecl, n ast.Node) bool) {
+    for _, decl := range file.Decls {
+        var fd *ast.FuncDecl
+        var root ast.Node
+        switch d := decl.(type) {
+        case *ast.FuncDecl:
+            if d.Body == nil {
+                continue
+            }
+            fd, root = d, d.Body
+        case *ast.GenDecl:
+            if d.Tok != token.VAR {
+                continue
+            }
+            root = d
+        default:
+            continue
+        }
+        ast.Inspect(root, func(n ast.Node) bool {
+            if n == nil {
+                return false
+            }
+            return visit(fd, n)
+        })
+    }
+}
+
+// calleeFunc возвращает вызываемую функцию или метод, если её удаётся
+// определить статически
+func calleeFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+    fn, _ := typeutil.Callee(info, call).(*types.Func)
+    return fn
+}
+
+func isContextType(t types.Type) bool {
+    named, ok := t.(*types.Named)
+    if !ok || named.Obj().Pkg() == nil {
+        return false
+    }
+    return named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// constString возвращает значение выражения, если это строковая константа
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+    tv, ok := info.Types[expr]
+    if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+        return "", false
+    }
+    return constant.StringVal(tv.Value), true
+}
+
+func countLines(filename string) int {
+    content, err := os.ReadFile(filename)
+    if err != nil {
+        return 0
+    }
+    return strings.Count(string(content), "\n") + 1
+}
+
+// constantValue - значение константы в виде Go-литерала. Дроби go/constant
+// (1/3) выводятся десятичной записью, как у strconv
+func constantValue(v constant.Value) (string, string) {
+    switch v.Kind() {
+    case constant.Bool, constant.String, constant.Int:
+        return v.ExactString(), strings.ToLower(v.Kind().String())
+    case constant.Float:
+        f, _ := constant.Float64Val(v)
+        return strconv.FormatFloat(f, 'g', -1, 64), "float"
+    case constant.Complex:
+        re, _ := constant.Float64Val(constant.Real(v))
+        im, _ := constant.Float64Val(constant.Imag(v))
+        return strconv.FormatComplex(complex(re, im), 'g', -1, 128), "complex"
+    }
+    return "", ""
+}
+
+// resolvedTypeString выводит тип по go/types с полными путями пакетов
+// (*github.com/foo/bar.Client вместо *bar.Client); без информации о
+// типах - синтаксическая строка
+func resolvedTypeString(info *types.Info, expr ast.Expr) string {
+    if e, ok := expr.(*ast.Ellipsis); ok {
+        return "..." + resolvedTypeString(info, e.Elt)
+    }
+    if t := info.TypeOf(expr); t != nil && t != types.Typ[types.Invalid] {
+        return types.TypeString(t, nil)
+    }
+    return extractTypeString(expr)
+}
+
+func analyzeFile(pkg *packages.Package, file *ast.File, fset *token.FileSet, resolved bool) FileAnalysis {
+    filename := fset.Position(file.Pos()).Filename
+    typeOf := extractTypeString
+    if resolved && pkg.TypesInfo != nil {
+        typeOf = func(expr ast.Expr) string { return resolvedTypeString(pkg.TypesInfo, expr) }
+    }
+    
+    analysis := FileAnalysis{
+        Path:      filename,
+        Package:   file.Name.Name,
+        Imports:   []Import{},
+        Functions: []Function{},
+        Structs:   []Struct{},
+        Variables: []Variable{},
+        Constants: []Variable{},
+        Interfaces: []Struct{},
+        LineCount: countLines(filename),
+        HasTests:  strings.HasSuffix(filename, "_test.go"),
+    }
+    examples := make(map[string]*doc.Example)
+    if analysis.HasTests {
+        for _, ex := range doc.Examples(file) {
+            examples["Example"+ex.Name] = ex
+        }
+    }
+    
+    // Анализируем импорты
+    for _, imp := range file.Imports {
+        importPath := strings.Trim(imp.Path.Value, "\"")
+        alias := ""
+        if imp.Name != nil {
+            alias = imp.Name.Name
+        }
+        
+        analysis.Imports = append(analysis.Imports, Import{
+            Path:  importPath,
+            Alias: alias,
+            Line:  fset.Position(imp.Pos()).Line,
+        })
+    }
+    
+    // Анализируем декларации
+    for _, decl := range file.Decls {
+        switch d := decl.(type) {
+        case *ast.FuncDecl:
+            // Анализируем функции и методы
+            fn := Function{
+                Name:       d.Name.Name,
+                Line:       fset.Position(d.Pos()).Line,
+                EndLine:    fset.Position(d.End()).Line,
+                IsExported: d.Name.IsExported(),
+                IsMethod:   d.Recv != nil,
+                Docstring:  extractDocstring(d.Doc),
+                TypeParams: extractTypeParams(d.Type.TypeParams),
+                Params:     extractFields(d.Type.Params, typeOf),
+                Returns:    extractFields(d.Type.Results, typeOf),
+                Complexity: cyclomaticComplexity(d.Body),
+            }
+            
+            // Receiver для методов
+            if d.Recv != nil && len(d.Recv.List) > 0 {
+                fn.Receiver = extractTypeString(d.Recv.List[0].Type)
+            }
+            if analysis.HasTests {
+                fn.TestKind = testKind(d)
+                if ex := examples[d.Name.Name]; ex != nil && fn.TestKind == "example" {
+                    fn.ExampleOutput, fn.ExampleUnordered = strings.TrimSpace(ex.Output), ex.Unordered
+                }
+            }
+            
+            analysis.Functions = append(analysis.Functions, fn)
+            
+        case *ast.GenDecl:
+            // Анализируем типы, переменные, константы
+            for _, spec := range d.Specs {
+                switch s := spec.(type) {
+                case *ast.TypeSpec:
+                    switch t := s.Type.(type) {
+                    case *ast.StructType:
+                        // Структуры
+                        st := Struct{
+                            Name:       s.Name.Name,
+                            Line:       fset.Position(s.Pos()).Line,
+                            EndLine:    fset.Position(s.End()).Line,
+                            IsExported: s.Name.IsExported(),
+                            Docstring:  extractDocstring(s.Doc),
+                            TypeParams: extractTypeParams(s.TypeParams),
+                            Fields:     []Field{},
+                            Methods:    []Function{},
+                        }
+                        
+                        if t.Fields != nil {
+                            for _, field := range t.Fields.List {
+                                st.Fields = append(st.Fields, extractStructFields(field, typeOf)...)
+                            }
+                        }
+                        
+                        analysis.Structs = append(analysis.Structs, st)
+                        
+                    case *ast.InterfaceType:
+                        // Интерфейсы
+                        iface := Struct{
+                            Name:       s.Name.Name,
+                            Line:       fset.Position(s.Pos()).Line,
+                            EndLine:    fset.Position(s.End()).Line,
+                            IsExported: s.Name.IsExported(),
+                            Docstring:  extractDocstring(s.Doc),
+                            TypeParams: extractTypeParams(s.TypeParams),
+                            Fields:     []Field{},
+                            Methods:    []Function{},
+                        }
+                        
+                        if t.Methods != nil {
+                            for _, method := range t.Methods.List {
+                                if ft, ok := method.Type.(*ast.FuncType); ok && len(method.Names) > 0 {
+                                    for _, name := range method.Names {
+                                        iface.Methods = append(iface.Methods, Function{
+                                            Name:       name.Name,
+                                            Line:       fset.Position(method.Pos()).Line,
+                                            EndLine:    fset.Position(method.End()).Line,
+                                            IsExported: name.IsExported(),
+                                            IsMethod:   true,
+                                            Receiver:   s.Name.Name,
+                                            Docstring:  extractDocstring(method.Doc),
+                                            Params:     extractFields(ft.Params, typeOf),
+                                            Returns:    extractFields(ft.Results, typeOf),
+                                        })
+                                    }
+                                } else {
+                                    // Встроенный интерфейс или набор типов ограничения
+                                    typ := extractTypeString(method.Type)
+                                    iface.Fields = append(iface.Fields, Field{
+                                        Name:      embeddedFieldName(typ),
+                                        Type:      typ,
+                                        Docstring: extractDocstring(method.Doc),
+                                        Embedded:  true,
+                                    })
+                                }
+                            }
+                        }
+                        
+                        analysis.Interfaces = append(analysis.Interfaces, iface)
+                    }
+                    
+                case *ast.ValueSpec:
+                    // Переменные и константы
+                    for _, name := range s.Names {
+                        typ := extractTypeString(s.Type)
+                        if resolved && pkg.TypesInfo != nil {
+                            // Тип выводится и для объявлений без явного типа
+                            if obj := pkg.TypesInfo.Defs[name]; obj != nil {
+                                typ = types.TypeString(obj.Type(), nil)
+                            }
+                        }
+                        variable := Variable{
+                            Name:       name.Name,
+                            Type:       typ,
+                            Line:       fset.Position(s.Pos()).Line,
+                            IsExported: name.IsExported(),
+                            IsConstant: d.Tok == token.CONST,
+                        }
+                        
+                        if d.Tok == token.CONST {
+                            if pkg.TypesInfo != nil {
+                                if c, ok := pkg.TypesInfo.Defs[name].(*types.Const); ok {
+                                    variable.Value, variable.ValueKind = constantValue(c.Val())
+                                }
+                            }
+                            analysis.Constants = append(analysis.Constants, variable)
+                        } else {
+                            analysis.Variables = append(analysis.Variables, variable)
+                        }
+                    }
+                }
+            }
+        }
+    }
+    analysis.Complexity = fileComplexity(analysis.Functions)
+    
+    return analysis
+}
+
+// analyzeProject загружает пакеты проекта и собирает все разделы анализа
+func analyzeProject(ctx context.Context, projectPath string, opts Options) *ProjectAnalysis {
+    // Конфигурация загрузки пакетов
+    cfg := &packages.Config{
+        Context: ctx,
+        Mode: packages.NeedName |
+              packages.NeedFiles |
+              packages.NeedCompiledGoFiles |
+              packages.NeedImports |
+              packages.NeedDeps |
+              packages.NeedTypes |
+              packages.NeedSyntax |
+              packages.NeedTypesInfo,
+        Dir: projectPath,
+        Env: append(os.Environ(), "CGO_ENABLED=0"),
+        Tests: opts.Tests,
+    }
+    // Вендоренные пакеты нужны из vendor, даже если GOFLAGS требует
+    // -mod=mod
+    useVendor := opts.Vendor != "" && opts.Vendor != vendorSkip && hasVendor(projectPath)
+    if useVendor {
+        cfg.BuildFlags = []string{"-mod=vendor"}
+    }
+    parsed := newFileParser(projectPath, opts)
+    cfg.ParseFile = parsed.parseFile
+    timer := newStageTimer()
+    
+    // В рабочем пространстве go.work пакеты загружаются по модулям;
+    // без него вложенные go.mod загружаются каждый из своего каталога
+    patterns := []string{"./..."}
+    var workGo string
+    var modules []WorkspaceModule
+    var workErr error
+    var discovered bool
+    if fileExists(filepath.Join(projectPath, "go.work")) {
+        if workGo, modules, workErr = readGoWork(projectPath); workErr == nil && len(modules) > 0 {
+            patterns = modulePatterns(modules)
+        }
+    } else if modules = discoverModules(projectPath); len(modules) > 0 {
+        discovered = true
+    }
+    // С include загружаются только его каталоги: пакеты вне include не
+    // проходят проверку типов
+    filter, filterErr := newPathFilter(opts.Include, opts.Exclude)
+    if filterErr == nil && !discovered {
+        if include := filter.loadPatterns(projectPath); len(include) > 0 {
+            patterns = include
+        }
+    }
+    
+    // Загружаем все пакеты
+    var pkgs []*packages.Package
+    var err error
+    timer.track("load", func() {
+        if discovered {
+            pkgs, err = loadModules(cfg, projectPath, modules)
+        } else {
+            pkgs, err = packages.Load(cfg, patterns...)
+        }
+    })
+    if err != nil {
+        slog.Warn("package loading reported an error", "error", err)
+    }
+    if opts.Tests {
+        pkgs = testVariants(pkgs)
+    }
+    if useVendor && opts.Vendor == vendorInclude {
+        pkgs = withVendored(projectPath, pkgs)
+    }
+    
+    slog.Info("loaded packages", "project", projectPath, "count", len(pkgs))
+    
+    result := &ProjectAnalysis{
+        Files:        []FileAnalysis{},
+        Dependencies: []string{},
+        AllPackages:  []string{},
+        TestFiles:    []string{},
+        Errors:       []string{},
+    }
+    if workErr != nil {
+        result.Errors = append(result.Errors, fmt.Sprintf("go.work: %v", workErr))
+    }
+    if len(modules) > 0 {
+        result.Modules = modules
+        if !discovered {
+            result.Workspace = "go.work"
+            result.GoVersion = workGo
+        }
+    }
+    if filterErr != nil {
+        result.Errors = append(result.Errors, fmt.Sprintf("Filters: %v", filterErr))
+    } else {
+        pkgs, result.SkippedInputs = filter.apply(projectPath, pkgs)
+    }
+    if opts.Scope != "" {
+        scoped, scope, skipped, err := applyScope(projectPath, opts.Scope, pkgs)
+        if err != nil {
+            // Весь проект вместо области владельца ввёл бы в заблуждение
+            result.Errors = append(result.Errors, fmt.Sprintf("Scope: %v", err))
+        }
+        pkgs, result.Scope = scoped, scope
+        result.SkippedInputs = append(result.SkippedInputs, skipped...)
+    }
+    
+    // Получаем информацию о модуле
+    var modInfo *GoModInfo
+    if goMod := filepath.Join(projectPath, "go.mod"); fileExists(goMod) {
+        result.HasGoMod = true
+        if modInfo = parseGoMod(goMod); modInfo != nil {
+            result.ModuleName = modInfo.Module
+            if result.Workspace == "" {
+                result.GoVersion = modInfo.Go
+            }
+        }
+        timer.track("module_graph", func() {
+            if graph, err := buildModuleGraph(projectPath); err != nil {
+                result.Errors = append(result.Errors, fmt.Sprintf("Module graph: %v", err))
+            } else {
+                result.ModuleGraph = graph
+            }
+        })
+    }
+    timer.track("environment", func() {
+        env, err := captureEnvironment(projectPath, modInfo)
+        if err != nil {
+            result.Errors = append(result.Errors, fmt.Sprintf("Environment: %v", err))
+        }
+        result.Environment = env
+    })
+    result.Sampling = parsed.sampling
+    timer.track("shell_scripts", func() {
+        scripts, err := extractShellScripts(projectPath)
+        if err != nil {
+            result.Errors = append(result.Errors, fmt.Sprintf("Shell scripts: %v", err))
+        }
+        result.ShellScripts = scripts
+    })
+    
+    allPackages := make(map[string]bool)
+    allDeps := make(map[string]bool)
+    var flagSites []flagSite
+    surface := newConfigSurface()
+    channels := newChannelGraphBuilder(projectPath)
+    sharedState := newSharedStateBuilder(projectPath)
+    wireSchemas := newWireSchemaBuilder()
+    routes := newRouteBuilder(projectPath)
+    auth := newAuthBuilder(projectPath, opts)
+    lifecycle := newLifecycleBuilder(projectPath)
+    services := newServiceBuilder(projectPath)
+    terraform := newTerraformBuilder(projectPath)
+    refs := newRefIndexBuilder(projectPath, pkgs)
+    importCycles := newImportCycleBuilder(projectPath, pkgs)
+    var unified *unifiedBuilder
+    if opts.Unified {
+        unified = newUnifiedBuilder()
+    }
+    var stdlib *stdlibIndex
+    if opts.StdlibCalls {
+        stdlib = newStdlibIndex()
+    }
+    var fieldUsage *fieldUsageBuilder
+    if opts.FieldUsage {
+        fieldUsage = newFieldUsageBuilder(projectPath, pkgs)
+    }
+    cache, err := newAnalysisCache(opts.CacheDir, projectPath, opts, result.Environment)
+    if err != nil {
+        result.Errors = append(result.Errors, fmt.Sprintf("Analysis cache: %v", err))
+    }
+    var funcDeps *funcDepsBuilder
+    if opts.FuncDeps {
+        funcDeps = newFuncDepsBuilder(pkgs)
+    }
+    // Опции уже проверены validate
+    countTokens, _ := newTokenCounter(opts.Tokenizer)
+    if opts.Diagnostics {
+        result.Diagnostics = newDiagnostics()
+    }
+    fileCount := 0
+    
+    for _, pkg := range pkgs {
+        slog.Debug("processing package", "name", pkg.Name, "path", pkg.PkgPath, "files", len(pkg.Syntax))
+        
+        if pkg.Errors != nil {
+            for _, err := range pkg.Errors {
+                if parsed.spurious(err) {
+                    continue
+                }
+                slog.Warn("package error", "package", pkg.PkgPath, "error", err.Msg)
+                result.Errors = append(result.Errors, fmt.Sprintf("Package %s: %s", pkg.PkgPath, err.Msg))
+            }
+        }
+        
+        allPackages[pkg.Name] = true
+        
+        // Собираем зависимости
+        for _, imp := range pkg.Imports {
+            allDeps[imp.PkgPath] = true
+        }
+        
+        // Анализируем файлы
+        timer.track("files", func() {
+            for i, file := range pkg.Syntax {
+                if i < len(pkg.CompiledGoFiles) {
+                    relPath, _ := filepath.Rel(projectPath, pkg.CompiledGoFiles[i])
+                    generated := parsed.isGenerated(pkg.CompiledGoFiles[i])
+                    if generated && opts.ExcludeGenerated {
+                        result.SkippedInputs = append(result.SkippedInputs, SkippedInput{Path: relPath, Reason: "generated file"})
+                        continue
+                    }
+                    if opts.Limits.MaxFiles > 0 && fileCount >= opts.Limits.MaxFiles {
+                        result.SkippedInputs = append(result.SkippedInputs, SkippedInput{
+                            Path:   relPath,
+                            Reason: fmt.Sprintf("max files limit (%d) reached", opts.Limits.MaxFiles),
+                        })
+                        continue
+                    }
+                    
+                    size, oversized := parsed.size(pkg.CompiledGoFiles[i])
+                    reason := fmt.Sprintf("function bodies skipped: file exceeds max size (%d bytes)", opts.Limits.MaxFileSize)
+                    key := cache.key(pkg, pkg.CompiledGoFiles[i])
+                    analysis, cached := cache.load(key)
+                    if !cached {
+                        analysis = analyzeFile(pkg, file, pkg.Fset, opts.ResolvedTypes)
+                        if oversized {
+                            analysis.Truncated = append(analysis.Truncated, reason)
+                        }
+                        if stdlib != nil {
+                            stdlib.annotate(pkg, file, &analysis)
+                        }
+                        if funcDeps != nil {
+                            funcDeps.annotate(pkg, file, &analysis)
+                        }
+                        if src, err := os.ReadFile(pkg.CompiledGoFiles[i]); err == nil {
+                            annotateTokens(pkg.Fset, file, src, &analysis, countTokens)
+                            analysis.Build = fileBuild(filepath.Base(pkg.CompiledGoFiles[i]), src)
+                        }
+                        truncateSymbols(&analysis, opts.Limits.MaxSymbols)
+                        cache.store(key, analysis)
+                    } else {
+                        fillConstants(pkg, file, &analysis)
+                    }
+                    analysis.Path = relPath
+                    analysis.IsGenerated = generated
+                    if oversized {
+                        result.SkippedInputs = append(result.SkippedInputs, SkippedInput{Path: relPath, Reason: reason, Size: size})
+                    }
+                    if unified != nil {
+                        unified.addFile(pkg, &analysis)
+                    }
+                    
+                    fileCount++
+                    result.TotalLines += analysis.LineCount
+                    result.addModuleFile(relPath)
+                    // Отданный в FileSink файл не хранится: потоковый вывод
+                    // не держит весь проект в памяти
+                    if opts.FileSink != nil {
+                        if err := opts.FileSink(analysis); err != nil {
+                            result.Errors = append(result.Errors, fmt.Sprintf("File sink: %v", err))
+                        }
+                    } else {
+                        result.Files = append(result.Files, analysis)
+                    }
+                    
+                    if analysis.HasTests {
+                        result.TestFiles = append(result.TestFiles, relPath)
+                    }
+                }
+            }
+        })
+        
+        timer.track("feature_flags", func() { flagSites = append(flagSites, extractFeatureFlags(pkg, projectPath, opts)...) })
+        timer.track("config_surface", func() { extractConfigSurface(pkg, projectPath, surface) })
+        timer.track("resilience", func() { result.Resilience = append(result.Resilience, extractResilience(pkg, projectPath)...) })
+        timer.track("transactions", func() { result.Transactions = append(result.Transactions, extractTransactions(pkg, projectPath)...) })
+        timer.track("channel_graph", func() { channels.addPackage(pkg) })
+        timer.track("global_state", func() { sharedState.addPackage(pkg) })
+        timer.track("implementations", func() { result.Implementations = append(result.Implementations, extractImplementations(pkg, projectPath)...) })
+        timer.track("wire_schemas", func() { wireSchemas.addPackage(pkg) })
+        timer.track("validations", func() { result.Validations = append(result.Validations, extractValidations(pkg, projectPath)...) })
+        timer.track("routes", func() { routes.addPackage(pkg) })
+        timer.track("auth", func() { auth.addPackage(pkg) })
+        timer.track("lifecycle", func() { lifecycle.addPackage(pkg) })
+        timer.track("services", func() { services.addPackage(pkg) })
+        timer.track("terraform", func() { terraform.addPackage(pkg) })
+        timer.track("refs", func() { refs.addPackage(pkg) })
+        timer.track("import_cycles", func() { importCycles.addPackage(pkg) })
+        if fieldUsage != nil {
+            timer.track("field_usage", func() { fieldUsage.addPackage(pkg) })
+        }
+        timer.track("background_jobs", func() { result.BackgroundJobs = append(result.BackgroundJobs, extractBackgroundJobs(pkg, projectPath)...) })
+        
+        if result.Diagnostics != nil {
+            timer.track("goroutine_leaks", func() {
+                result.Diagnostics.GoroutineLeaks = append(result.Diagnostics.GoroutineLeaks, extractGoroutineLeaks(pkg, projectPath)...)
+            })
+            timer.track("resource_leaks", func() {
+                result.Diagnostics.ResourceLeaks = append(result.Diagnostics.ResourceLeaks, extractResourceLeaks(pkg, projectPath, result.ModuleName)...)
+            })
+        }
+    }
+    
+    timer.track("feature_flags", func() { result.FeatureFlags = groupFeatureFlags(flagSites) })
+    if !surface.empty() {
+        result.ConfigSurface = surface
+    }
+    timer.track("channel_graph", func() { result.ChannelGraph = channels.build() })
+    timer.track("global_state", func() { result.GlobalState = sharedState.globalState() })
+    timer.track("wire_schemas", func() { result.WireSchemas = wireSchemas.build() })
+    timer.track("routes", func() { result.Routes = routes.build() })
+    timer.track("auth", func() { result.Auth = auth.build(result.Routes) })
+    timer.track("lifecycle", func() { result.Lifecycle = lifecycle.build() })
+    timer.track("services", func() { result.Services = services.build() })
+    timer.track("terraform", func() { result.Terraform = terraform.build() })
+    timer.track("refs", func() { result.refs = refs.build() })
+    timer.track("enums", func() { result.Enums = extractEnums(pkgs, projectPath) })
+    timer.track("reflection", func() {
+        sites, reflectRefs := extractReflection(pkgs, projectPath)
+        result.Reflection = sites
+        result.refs.add(reflectRefs)
+    })
+    timer.track("plugins", func() {
+        plugins, err := extractPlugins(projectPath, pkgs)
+        if err != nil {
+            result.Errors = append(result.Errors, fmt.Sprintf("Plugins: %v", err))
+        }
+        result.Plugins = plugins
+    })
+    if opts.FileSink == nil {
+        timer.track("complexity", func() { result.Complexity = packageComplexity(result) })
+        timer.track("call_graph", func() { result.CallGraph = buildCallGraph(result) })
+        timer.track("package_advice", func() { result.PackageAdvice = buildPackageAdvice(result) })
+        timer.track("import_cycles", func() { result.ImportCycles = importCycles.build(goSymbols(result, false)) })
+    }
+    timer.track("components", func() { result.Components = buildComponentGraph(result.ModuleName, importCycles) })
+    timer.track("doc_examples", func() {
+        examples, err := extractDocExamples(projectPath, pkgs)
+        if err != nil {
+            result.Errors = append(result.Errors, fmt.Sprintf("Doc examples: %v", err))
+        }
+        result.DocExamples = examples
+    })
+    if unified != nil {
+        timer.track("unified", func() { result.Unified = unified.build(result.Implementations) })
+    }
+    if opts.PerfHints {
+        timer.track("perf_hints", func() { result.PerfHints = extractPerfHints(pkgs, projectPath) })
+    }
+    if opts.APIAudit {
+        timer.track("api_conventions", func() { result.APIConventions = extractAPIConventions(pkgs, projectPath) })
+    }
+    if opts.ErrorReport {
+        timer.track("error_handling", func() { result.ErrorHandling = extractErrorHandling(pkgs, projectPath) })
+    }
+    if opts.Panics {
+        timer.track("panics", func() { result.Panics = extractPanics(pkgs, projectPath) })
+    }
+    if opts.TimeRand {
+        timer.track("time_rand", func() { result.TimeRand = extractTimeRand(pkgs, projectPath) })
+    }
+    if opts.HardCoded {
+        timer.track("hard_coded", func() { result.HardCoded = extractHardCoded(pkgs, projectPath) })
+    }
+    if fieldUsage != nil {
+        timer.track("field_usage", func() { result.FieldUsage = fieldUsage.build() })
+    }
+    if opts.I18n {
+        timer.track("i18n_strings", func() { result.I18nStrings = extractUserMessages(pkgs, projectPath) })
+    }
+    if opts.AnyUsage {
+        timer.track("any_usage", func() { result.AnyUsage = extractAnyUsage(pkgs, projectPath) })
+    }
+    if opts.Linkname {
+        timer.track("linkname", func() { result.Linkname = extractLinknames(pkgs, projectPath) })
+    }
+    if opts.Templates {
+        timer.track("templates", func() { result.Templates = extractTemplates(pkgs, projectPath) })
+    }
+    if opts.HouseStyle {
+        timer.track("house_style", func() { result.HouseStyle = extractHouseStyle(pkgs, projectPath) })
+    }
+    if opts.TestMapping {
+        timer.track("test_mapping", func() { result.TestMapping = buildTestMapping(projectPath, result) })
+    }
+    if opts.Anchors {
+        timer.track("anchors", func() { assignAnchors(result) })
+    }
+    if opts.GoUpgrade != "" {
+        timer.track("go_upgrade", func() { result.GoUpgrade = extractUpgradeIssues(pkgs, projectPath, result.GoVersion, opts.GoUpgrade) })
+    }
+    if result.Diagnostics != nil {
+        timer.track("race_candidates", func() {
+            result.Diagnostics.RaceCandidates = append(result.Diagnostics.RaceCandidates, sharedState.raceCandidates()...)
+        })
+        timer.track("dead_branches", func() {
+            result.Diagnostics.DeadBranches = append(result.Diagnostics.DeadBranches, extractDeadBranches(pkgs, projectPath)...)
+        })
+        timer.track("dead_files", func() {
+            result.Diagnostics.DeadFiles = append(result.Diagnostics.DeadFiles, extractDeadFiles(projectPath, opts.Platforms, opts.BuildTags)...)
+        })
+    }
+    
+    // Преобразуем мапы в слайсы
+    for pkg := range allPackages {
+        result.AllPackages = append(result.AllPackages, pkg)
+    }
+    sort.Strings(result.AllPackages)
+    
+    if opts.Vendor == vendorDepsOnly && hasVendor(projectPath) {
+        vendored, err := vendoredPackages(projectPath)
+        if err != nil {
+            result.Errors = append(result.Errors, fmt.Sprintf("Vendor: %v", err))
+        }
+        for _, dep := range vendored {
+            allDeps[dep] = true
+        }
+    }
+    for dep := range allDeps {
+        if result.Modules != nil && !result.inProject(dep) || result.Modules == nil && !strings.Contains(dep, result.ModuleName) {
+            result.Dependencies = append(result.Dependencies, dep)
+        }
+    }
+    sort.Strings(result.Dependencies)
+    
+    if result.ModuleGraph != nil {
+        timer.track("checksums", func() {
+            if err := verifyChecksums(projectPath, result.ModuleGraph, result.Dependencies); err != nil {
+                result.Errors = append(result.Errors, fmt.Sprintf("go.sum: %v", err))
+            }
+        })
+    }
+    if opts.CompilerFeedback {
+        timer.track("compiler_feedback", func() {
+            if err := annotateCompilerFeedback(projectPath, result.Files); err != nil {
+                result.Errors = append(result.Errors, fmt.Sprintf("Compiler feedback: %v", err))
+            }
+        })
+    }
+    if opts.BinaryTarget != "" {
+        timer.track("binary_size", func() {
+            size, err := buildBinarySize(projectPath, opts.BinaryTarget, result.ModuleName, result.ModuleGraph)
+            if err != nil {
+                result.Errors = append(result.Errors, fmt.Sprintf("Binary size: %v", err))
+                return
+            }
+            result.BinarySize = size
+        })
+    }
+    if opts.BenchResults != "" {
+        timer.track("benchmarks", func() {
+            benchmarks, err := attachBenchmarks(projectPath, opts.BenchResults, result)
+            if err != nil {
+                result.Errors = append(result.Errors, fmt.Sprintf("Benchmarks: %v", err))
+                return
+            }
+            result.Benchmarks = benchmarks
+        })
+    }
+    if opts.CoverProfile != "" {
+        timer.track("coverage", func() {
+            coverage, err := attachCoverage(projectPath, opts.CoverProfile, result)
+            if err != nil {
+                result.Errors = append(result.Errors, fmt.Sprintf("Coverage: %v", err))
+                return
+            }
+            result.Coverage = coverage
+        })
+    }
+    if opts.IncludeDeps == "direct" && result.HasGoMod {
+        timer.track("dependency_api", func() { result.DependencyAPI = extractDependencyAPI(projectPath, pkgs, result.ModuleGraph) })
+        if opts.DepDocs {
+            timer.track("dependency_docs", func() {
+                site := opts.DocsSite
+                if site == "" {
+                    site = defaultDocsSite
+                }
+                if err := attachDependencyDocs(ctx, result.DependencyAPI, site, opts.CacheDir); err != nil {
+                    result.Errors = append(result.Errors, fmt.Sprintf("Dependency docs: %v", err))
+                }
+            })
+        }
+    }
+    
+    if opts.FileSink == nil {
+        timer.track("profile", func() { result.Profile = buildProjectProfile(result) })
+    }
+    
+    result.Meta = timer.meta()
+    result.Meta.Cache = cache.result()
+    return result
+}
+
+// Main - точка входа командной строки; package main только вызывает её
+func Main() {
+    if len(os.Args) > 1 {
+        if run, ok := commands[os.Args[1]]; ok {
+            run(os.Args[2:])
+            return
+        }
+    }
+    
+    var flagPatterns stringList
+    flag.Var(&flagPatterns, "flag-pattern", "regexp matching internal feature-flag calls (repeatable)")
+    var includes, excludes stringList
+    flag.Var(&includes, "include", "analyze only files matching this glob relative to the project, e.g. internal/... or cmd/**/main.go (repeatable)")
+    flag.Var(&excludes, "exclude", "skip files matching this glob relative to the project, e.g. third_party/** or **/*_gen.go (repeatable)")
+    excludeGenerated := flag.Bool("exclude-generated", false, "skip files with a \"Code generated ... DO NOT EDIT.\" header (protobuf, mocks); they are still type-checked")
+    var authPatterns stringList
+    flag.Var(&authPatterns, "auth-pattern", "regexp matching internal auth/permission check functions (repeatable)")
+    diagnostics := flag.Bool("diagnostics", false, "emit opt-in diagnostic heuristics (goroutine leaks, ...)")
+    platforms := flag.String("platforms", "", "comma-separated GOOS/GOARCH list for dead-file diagnostics (empty = every known platform)")
+    buildTags := flag.String("build-tags", "", "comma-separated custom build tags that are ever set (empty = any tag may be set)")
+    scope := flag.String("scope", "", "restrict the analysis to packages owned by team:<name> or owner:<owner> in CODEOWNERS plus the project packages they import")
+    vendor := flag.String("vendor", vendorSkip, "vendor directory handling: skip, deps-only (vendored packages only extend dependencies) or include (analyze vendored packages in full)")
+    includeDeps := flag.String("include-deps", "", "also emit the exported API of dependencies: direct (from the module cache or vendor)")
+    depDocs := flag.Bool("dep-docs", false, "with -include-deps: attach pkg.go.dev documentation to the dependency symbols the project uses (network; cached in -cache-dir)")
+    docsSite := flag.String("docs-site", defaultDocsSite, "pkgsite instance for -dep-docs")
+    stdlibCalls := flag.Bool("stdlib-calls", false, "attach signature and one-line doc of called standard library functions to each function")
+    funcDeps := flag.Bool("func-deps", false, "list packages, project types and package-local symbols each function depends on")
+    perfHints := flag.Bool("perf-hints", false, "flag allocation-heavy patterns in loops (append without prealloc, fmt.Sprintf, []byte/string conversions, map churn)")
+    apiAudit := flag.Bool("api-audit", false, "check exported API against Go conventions: Get prefixes, error-last results, context-first params, names stuttering the package")
+    errorReport := flag.Bool("error-handling", false, "classify how functions handle errors (returned, wrapped, logged, panicked, ignored) and list ignored errors")
+    panics := flag.Bool("panics", false, "list Must* wrappers and places where library packages panic or exit on errors")
+    timeRand := flag.Bool("time-rand", false, "list time.Now, time.Sleep, math/rand and crypto/rand usage per function; flag constant sleeps and math/rand in security code")
+    hardCoded := flag.Bool("hard-coded", false, "list hard-coded time zones, locales, host:port literals and magic numbers in business logic")
+    i18n := flag.Bool("i18n", false, "list user-facing strings: API error texts, response bodies and template data")
+    i18nCatalog := flag.String("i18n-catalog", "", "also write user-facing strings as a gettext catalog template (.pot) to `file`")
+    fieldUsage := flag.Bool("field-usage", false, "count reads and writes of every struct field; flag never-read and never-written fields")
+    anyUsage := flag.Bool("any-usage", false, "list any/interface{} in exported signatures and fields and suggest concrete or generic types from how they are used")
+    linkname := flag.Bool("linkname", false, "list go:linkname directives, assembly references to runtime and std internal imports in the project and its dependencies")
+    templates := flag.Bool("templates", false, "cluster functions that differ only in names and literals (repeated handlers, wrappers) into templates with per-instance values")
+    houseStyle := flag.Bool("house-style", false, "derive the project's conventions (error wrapping, logging calls, constructors, test and receiver names) for code generators")
+    tests := flag.Bool("tests", false, "also analyze _test.go files; test, benchmark, fuzz and example functions get test_kind and example output")
+    testMapping := flag.Bool("test-mapping", false, "relate test, benchmark, fuzz and example functions to the functions and types they exercise (by name, references and call graph)")
+    anchors := flag.Bool("anchors", false, "give functions, methods and types a stable hash anchor for analyzer edits")
+    goUpgrade := flag.String("go-upgrade", "", "report deprecated APIs and behavior changes the project hits when moving from its go.mod version to this Go version (e.g. 1.24)")
+    resolvedTypes := flag.Bool("resolved-types", false, "report params, returns, fields and variables as fully qualified go/types names")
+    coverProfile := flag.String("coverprofile", "", "attach statement coverage from a go test -coverprofile `file` to functions and files")
+    benchResults := flag.String("bench-results", "", "attach ns/op and allocs/op from saved go test -bench output (text or -json) to benchmarks and the functions they call")
+    binarySize := flag.String("binary-size", "", "build this main package (e.g. ./cmd/server) and attribute binary size to packages and modules")
+    compilerFeedback := flag.Bool("compiler-feedback", false, "build with -gcflags=-m and attach inlining and heap-escape decisions to functions")
+    batch := flag.Bool("batch", false, "analyze several projects and link their service clients and servers")
+    moduleDot := flag.String("module-dot", "", "write the module dependency graph in DOT format to `file`")
+    componentDot := flag.String("component-dot", "", "write the inferred component graph in DOT format to `file`")
+    maxFileSize := flag.Int64("max-file-size", defaultMaxFileSize, "skip function bodies of files larger than this many bytes (0 = no limit)")
+    maxFiles := flag.Int("max-files", 0, "analyze at most this many files (0 = no limit)")
+    maxSymbols := flag.Int("max-symbols", defaultMaxSymbols, "truncate per-file symbol lists to this many entries (0 = no limit)")
+    sample := flag.String("sample", "", "sampling mode for huge repos: representative (all APIs, weighted sample of bodies)")
+    sampleRate := flag.Float64("sample-rate", defaultSampleRate, "base share of unexported function bodies analyzed in sampling mode")
+    pprofAddr := flag.String("pprof", "", "serve net/http/pprof on this address (e.g. 127.0.0.1:6060; an address without a host listens on all interfaces) while the analyzer runs")
+    traceOut := flag.String("trace-out", "", "write a runtime execution trace to `file`")
+    verbose := flag.Bool("v", false, "verbose logging (per-package progress)")
+    quiet := flag.Bool("q", false, "log warnings and errors only")
+    logFormat := flag.String("log-format", "text", "log format: text or json")
+    outputPath := flag.String("o", "", "write the result to `file` atomically instead of stdout")
+    format := flag.String("format", "json", "output format: json, yaml (diff-friendly, without run timings, for checking the analysis into a repository), ndjson (one file record per line as files are analyzed, then a summary record without the sections built from all files) or pr-comment (Markdown summary of -diff-base for a pull request comment)")
+    compact := flag.Bool("compact", false, "emit compact JSON instead of indented")
+    tokenizer := flag.String("tokenizer", defaultTokenizer, "token estimate for functions, types and files: cl100k (BPE approximation) or chars (4 bytes per token)")
+    unified := flag.Bool("unified", false, "also emit the language-agnostic unified schema (symbols and relations)")
+    annotations := flag.String("annotations", defaultAnnotationsFile, "annotation store keyed by stable symbol ID, relative to the project (empty = disabled)")
+    cacheDir := flag.String("cache-dir", "", "directory of the per-file analysis cache keyed by content hash (default: llmstruct/go in the user cache directory)")
+    noCache := flag.Bool("no-cache", false, "analyze every file from scratch without reading or writing the cache")
+    provenance := flag.Bool("provenance", false, "embed provenance: repository, commit, source digest, analyzer version and flags")
+    signKey := flag.String("sign-key", "", "sign the -o file with this Ed25519 private key (from analyzer keygen), writing <file>.sig; implies -provenance")
+    diffBase := flag.String("diff-base", "", "compare with this git revision: Go API breaks and wire-format (JSON/DB) changes")
+    flag.Parse()
+    
+    if err := setupLogger(*verbose, *quiet, *logFormat); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+    
+    if flag.NArg() < 1 {
+        fmt.Fprintln(os.Stderr, "Usage: analyzer [flags] <project_path>\n       analyzer -batch [flags] <project_path>...")
+        flag.PrintDefaults()
+        os.Exit(2)
+    }
+    
+    opts := Options{
+        Diagnostics: *diagnostics,
+        Limits: Limits{
+            MaxFileSize: *maxFileSize,
+            MaxFiles:    *maxFiles,
+            MaxSymbols:  *maxSymbols,
+        },
+        Sample:           *sample,
+        SampleRate:       *sampleRate,
+        Unified:          *unified,
+        Tokenizer:        *tokenizer,
+        IncludeDeps:      *includeDeps,
+        Vendor:           *vendor,
+        DepDocs:          *depDocs,
+        DocsSite:         *docsSite,
+        StdlibCalls:      *stdlibCalls,
+        FuncDeps:         *funcDeps,
+        BinaryTarget:     *binarySize,
+        CompilerFeedback: *compilerFeedback,
+        BenchResults:     *benchResults,
+        CoverProfile:     *coverProfile,
+        PerfHints:        *perfHints,
+        APIAudit:         *apiAudit,
+        ErrorReport:      *errorReport,
+        Panics:           *panics,
+        TimeRand:         *timeRand,
+        HardCoded:        *hardCoded,
+        I18n:             *i18n || *i18nCatalog != "",
+        FieldUsage:       *fieldUsage,
+        AnyUsage:         *anyUsage,
+        Linkname:         *linkname,
+        Templates:        *templates,
+        HouseStyle:       *houseStyle,
+        TestMapping:      *testMapping,
+        Tests:            *tests,
+        Anchors:          *anchors,
+        GoUpgrade:        *goUpgrade,
+        ResolvedTypes:    *resolvedTypes,
+        Include:          includes,
+        Exclude:          excludes,
+        ExcludeGenerated: *excludeGenerated,
+        Scope:            *scope,
+    }
+    // Поток открывается после проверки опций, чтобы не оставлять
+    // временный файл при ошибке
+    var stream *ndjsonWriter
+    if *format == "ndjson" {
+        if *batch {
+            fatal("invalid options", "error", "-format ndjson does not support -batch")
+        }
+        // Файлы в потоке не хранятся, а сравнение и аннотации работают
+        // по файлам анализа: хранилище аннотаций по умолчанию пропускается,
+        // заданное явно - ошибка
+        explicit := false
+        flag.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "annotations" })
+        if *diffBase != "" || explicit && *annotations != "" {
+            fatal("invalid options", "error", "-format ndjson does not support -diff-base or -annotations")
+        }
+        *annotations = ""
+        opts.FileSink = func(file FileAnalysis) error { return stream.file(file) }
+    } else if err := validFormat(*format); err != nil {
+        fatal("invalid options", "error", err)
+    }
+    if err := opts.validate(); err != nil {
+        fatal("invalid options", "error", err)
+    }
+    if *signKey != "" && *outputPath == "" {
+        fatal("invalid options", "error", "-sign-key requires -o")
+    }
+    withProvenance := func(analysis *ProjectAnalysis, projectPath string) {
+        if !*provenance && *signKey == "" {
+            return
+        }
+        p, err := buildProvenance(projectPath, analysis, setFlags(flag.CommandLine))
+        if err != nil {
+            fatal("failed to record provenance", "error", err)
+        }
+        analysis.Provenance = p
+    }
+    if !*noCache {
+        opts.CacheDir = *cacheDir
+        if opts.CacheDir == "" {
+            opts.CacheDir = defaultCacheDir()
+        }
+    }
+    if *platforms != "" {
+        opts.Platforms = strings.Split(*platforms, ",")
+    }
+    if *buildTags != "" {
+        opts.BuildTags = strings.Split(*buildTags, ",")
+    }
+    for _, pattern := range flagPatterns {
+        re, err := regexp.Compile(pattern)
+        if err != nil {
+            fatal("invalid -flag-pattern", "pattern", pattern, "error", err)
+        }
+        opts.FlagPatterns = append(opts.FlagPatterns, re)
+    }
+    for _, pattern := range authPatterns {
+        re, err := regexp.Compile(pattern)
+        if err != nil {
+            fatal("invalid -auth-pattern", "pattern", pattern, "error", err)
+        }
+        opts.AuthPatterns = append(opts.AuthPatterns, re)
+    }
+    
+    if *pprofAddr != "" {
+        go func() {
+            slog.Info("pprof listening", "addr", *pprofAddr)
+            if err := http.ListenAndServe(*pprofAddr, nil); err != nil {
+                slog.Error("pprof server failed", "error", err)
+            }
+        }()
+    }
+    if *traceOut != "" {
+        f, err := os.Create(*traceOut)
+        if err != nil {
+            fatal("failed to create trace file", "error", err)
+        }
+        if err := trace.Start(f); err != nil {
+            fatal("failed to start trace", "error", err)
+        }
+        // fatal выходит мимо defer: трассировку нужно дописать и там
+        stop := func() {
+            trace.Stop()
+            f.Close()
+        }
+        onFatal(stop)
+        defer stop()
+    }
+    
+    var projectPaths []string
+    for _, arg := range flag.Args() {
+        projectPath, err := filepath.Abs(arg)
+        if err != nil {
+            fatal("invalid project path", "path", arg, "error", err)
+        }
+        projectPaths = append(projectPaths, projectPath)
+    }
+    
+    var result any
+    if *batch {
+        // Пакетный режим: анализ каждого проекта и межсервисные связи
+        batchResult := BatchAnalysis{Projects: []*ProjectAnalysis{}}
+        var names []string
+        for _, projectPath := range projectPaths {
+            analysis := analyzeProject(context.Background(), projectPath, opts)
+            if *annotations != "" {
+                if err := attachAnnotations(analysis, projectPath, *annotations); err != nil {
+                    slog.Warn("failed to attach annotations", "project", projectPath, "error", err)
+                }
+            }
+            withProvenance(analysis, projectPath)
+            batchResult.Projects = append(batchResult.Projects, analysis)
+            names = append(names, projectName(analysis, projectPath))
+        }
+        batchResult.ServiceLinks = linkServices(names, batchResult.Projects)
+        result = batchResult
+    } else {
+        if opts.FileSink != nil {
+            var err error
+            if stream, err = newNDJSONWriter(*outputPath); err != nil {
+                fatal("failed to write result", "path", *outputPath, "error", err)
+            }
+            onFatal(stream.abort)
+        }
+        analysis := analyzeProject(context.Background(), projectPaths[0], opts)
+        if *diffBase != "" {
+            base, commit, basePath, cleanup, err := analyzeRevision(projectPaths[0], *diffBase, opts)
+            if err != nil {
+                cleanup()
+                fatal("failed to analyze base revision", "rev", *diffBase, "error", err)
+            }
+            analysis.Diff = diffAnalyses(*diffBase, commit, base, basePath, analysis, projectPaths[0])
+            cleanup()
+            if err := attachBlame(projectPaths[0], analysis.Diff, base, analysis); err != nil {
+                slog.Warn("failed to attribute changes to commits", "error", err)
+            }
+        }
+        if *annotations != "" {
+            if err := attachAnnotations(analysis, projectPaths[0], *annotations); err != nil {
+                slog.Warn("failed to attach annotations", "error", err)
+            }
+        }
+        if *moduleDot != "" && analysis.ModuleGraph != nil {
+            if err := writeAtomic(*moduleDot, []byte(analysis.ModuleGraph.dot())); err != nil {
+                fatal("failed to write module graph", "error", err)
+            }
+        }
+        if *i18nCatalog != "" {
+            if err := writeAtomic(*i18nCatalog, []byte(gettextCatalog(analysis.I18nStrings))); err != nil {
+                fatal("failed to write i18n catalog", "error", err)
+            }
+        }
+        if *componentDot != "" && analysis.Components != nil {
+            if err := writeAtomic(*componentDot, []byte(analysis.Components.dot())); err != nil {
+                fatal("failed to write component graph", "error", err)
+            }
+        }
+        withProvenance(analysis, projectPaths[0])
+        if stream != nil {
+            if err := stream.finish(analysis); err != nil {
+                fatal("failed to write result", "path", *outputPath, "error", err)
+            }
+        }
+        result = analysis
+    }
+    
+    if stream == nil {
+        if err := writeResult(result, *outputPath, *format, *compact); err != nil {
+            fatal("failed to write result", "path", *outputPath, "error", err)
+        }
+    }
+    if *signKey != "" {
+        if err := signFile(*outputPath, *signKey); err != nil {
+            fatal("failed to sign result", "path", *outputPath, "error", err)
+        }
+    }
+}
+
+type GoModInfo struct {
+    Module    string
+    Go        string
+    Toolchain string
+}
+
+func parseGoMod(path string) *GoModInfo {
+    content, err := os.ReadFile(path)
+    if err != nil {
+        return nil
+    }
+    
+    lines := strings.Split(string(content), "\n")
+    info := &GoModInfo{}
+    
+    for _, line := range lines {
+        line = strings.TrimSpace(line)
+        if strings.HasPrefix(line, "module ") {
+            info.Module = strings.TrimSpace(strings.TrimPrefix(line, "module"))
+        } else if strings.HasPrefix(line, "go ") {
+            info.Go = strings.TrimSpace(strings.TrimPrefix(line, "go"))
+        } else if strings.HasPrefix(line, "toolchain ") {
+            info.Toolchain = strings.TrimSpace(strings.TrimPrefix(line, "toolchain"))
+        }
+    }
+    
+    return info
+}
+
+func fileExists(path string) bool {
+    _, err := os.Stat(path)
+    return err == nil
+} 
\ No newline at end of file
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// writeTestProject создаёт проект из файлов (путь через "/" -> текст)
+func writeTestProject(t *testing.T, files map[string]string) string {
+    t.Helper()
+    dir := t.TempDir()
+    for name, text := range files {
+        path := filepath.Join(dir, filepath.FromSlash(name))
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return dir
+}
+
+// testOptions - параметры по умолчанию без пользовательского кэша
+func testOptions(t *testing.T) Options {
+    t.Helper()
+    opts := defaultOptions()
+    opts.CacheDir = ""
+    return opts
+}
@@ -0,0 +1,341 @@
+package analyzer
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// anchorOf - якорь символа: короткий хэш стабильного ID, поэтому он не
+// меняется при правках тела и сдвиге строк
+func anchorOf(id string) string {
+    sum := sha256.Sum256([]byte(id))
+    return "a" + hex.EncodeToString(sum[:6])
+}
+
+// anchorTarget - символ, на который указывает якорь; ordinal - номер
+// среди одноимённых объявлений файла (несколько init или _)
+type anchorTarget struct {
+    id      string
+    file    string
+    kind    string
+    name    string
+    recv    string
+    ordinal int
+}
+
+// assignAnchors проставляет якоря функциям, методам и типам анализа и
+// возвращает их индекс. ID неуникален у init и _: якорь таких символов -
+// хэш ID, файла и номера объявления в файле
+func assignAnchors(analysis *ProjectAnalysis) map[string]*anchorTarget {
+    var all []*anchorTarget
+    var anchors []*string
+    ids := make(map[string]int)
+    for i := range analysis.Files {
+        file := &analysis.Files[i]
+        pkgPath := packagePathOf(analysis, file.Path)
+        seen := make(map[string]int)
+        add := func(t *anchorTarget, anchor *string) {
+            key := t.kind + " " + t.recv + "." + t.name
+            t.ordinal = seen[key]
+            seen[key]++
+            ids[t.id]++
+            all = append(all, t)
+            anchors = append(anchors, anchor)
+        }
+        for j := range file.Functions {
+            fn := &file.Functions[j]
+            t := &anchorTarget{file: file.Path, kind: "function", name: fn.Name}
+            if fn.IsMethod {
+                t.kind, t.recv = "method", receiverTypeName(fn.Receiver)
+                t.id = goSymbolID(pkgPath, t.recv+"."+fn.Name)
+            } else {
+                t.id = goSymbolID(pkgPath, fn.Name)
+            }
+            add(t, &fn.Anchor)
+        }
+        for _, types := range [][]Struct{file.Structs, file.Interfaces} {
+            for j := range types {
+                st := &types[j]
+                add(&anchorTarget{id: goSymbolID(pkgPath, st.Name), file: file.Path, kind: "type", name: st.Name}, &st.Anchor)
+            }
+        }
+    }
+    targets := make(map[string]*anchorTarget)
+    for i, t := range all {
+        key := t.id
+        if ids[t.id] > 1 {
+            key = fmt.Sprintf("%s@%s#%d", t.id, filepath.ToSlash(t.file), t.ordinal)
+        }
+        *anchors[i] = anchorOf(key)
+        targets[*anchors[i]] = t
+    }
+    return targets
+}
+
+// EditInstruction - правка символа. Символ задаётся якорем (-anchors) или
+// стабильным ID; Op: replace_body - заменить тело функции или поля
+// структуры, replace - всё объявление вместе с doc-комментарием, delete -
+// удалить объявление, insert_after - вставить Content после объявления
+type EditInstruction struct {
+    Anchor       string   `json:"anchor,omitempty"`
+    Symbol       string   `json:"symbol,omitempty"`
+    Op           string   `json:"op"`
+    Content      string   `json:"content,omitempty"`
+}
+
+// EditPatch - точная замена байтов [Start, End) файла на Replacement.
+// OriginalSHA256 - хэш заменяемых байтов: патч применим, только пока
+// файл не изменился
+type EditPatch struct {
+    Anchor         string `json:"anchor"`
+    Symbol         string `json:"symbol"`
+    Op             string `json:"op"`
+    File           string `json:"file"`
+    Start          int    `json:"start"`
+    End            int    `json:"end"`
+    Line           int    `json:"line"`
+    EndLine        int    `json:"end_line"`
+    OriginalSHA256 string `json:"original_sha256"`
+    Replacement    string `json:"replacement"`
+}
+
+// EditsResult - результат analyzer edits; с ошибками патчи не применяются
+type EditsResult struct {
+    Patches      []EditPatch `json:"patches"`
+    Errors       []string    `json:"errors,omitempty"`
+    Written      bool        `json:"written"`
+}
+
+var editOps = map[string]bool{"replace_body": true, "replace": true, "delete": true, "insert_after": true}
+
+// findDecl ищет объявление символа в файле (ordinal-е из одноимённых);
+// для типа из группы type (...) возвращается спецификация
+func findDecl(file *ast.File, t *anchorTarget) (decl ast.Node, doc *ast.CommentGroup, body [2]token.Pos) {
+    skip := t.ordinal
+    for _, d := range file.Decls {
+        switch d := d.(type) {
+        case *ast.FuncDecl:
+            if t.kind == "type" || d.Name.Name != t.name {
+                continue
+            }
+            recv := ""
+            if d.Recv != nil && len(d.Recv.List) > 0 {
+                recv = receiverTypeName(extractTypeString(d.Recv.List[0].Type))
+            }
+            if recv != t.recv {
+                continue
+            }
+            if skip > 0 {
+                skip--
+                continue
+            }
+            if d.Body != nil {
+                body = [2]token.Pos{d.Body.Lbrace + 1, d.Body.Rbrace}
+            }
+            return d, d.Doc, body
+        case *ast.GenDecl:
+            if t.kind != "type" || d.Tok != token.TYPE {
+                continue
+            }
+            for _, spec := range d.Specs {
+                ts := spec.(*ast.TypeSpec)
+                if ts.Name.Name != t.name {
+                    continue
+                }
+                if skip > 0 {
+                    skip--
+                    continue
+                }
+                switch st := ts.Type.(type) {
+                case *ast.StructType:
+                    body = [2]token.Pos{st.Fields.Opening + 1, st.Fields.Closing}
+                case *ast.InterfaceType:
+                    body = [2]token.Pos{st.Methods.Opening + 1, st.Methods.Closing}
+                }
+                if d.Lparen.IsValid() {
+                    return ts, ts.Doc, body
+                }
+                return d, d.Doc, body
+            }
+        }
+    }
+    return nil, nil, body
+}
+
+// buildEdits переводит инструкции в патчи по текущему тексту файлов
+func buildEdits(projectPath string, analysis *ProjectAnalysis, edits []EditInstruction) *EditsResult {
+    anchors := assignAnchors(analysis)
+    bySymbol := make(map[string]string)
+    declared := make(map[string]int)
+    for anchor, t := range anchors {
+        bySymbol[t.id] = anchor
+        declared[t.id]++
+    }
+    result := &EditsResult{Patches: []EditPatch{}}
+    fail := func(i int, format string, args ...any) {
+        result.Errors = append(result.Errors, fmt.Sprintf("edit %d: ", i)+fmt.Sprintf(format, args...))
+    }
+    for i, e := range edits {
+        anchor := e.Anchor
+        if anchor == "" {
+            if n := declared[e.Symbol]; n > 1 {
+                fail(i, "symbol %q is declared %d times; use an anchor", e.Symbol, n)
+                continue
+            }
+            anchor = bySymbol[e.Symbol]
+        }
+        t := anchors[anchor]
+        switch {
+        case !editOps[e.Op]:
+            fail(i, "unknown op %q", e.Op)
+            continue
+        case t == nil && e.Anchor != "":
+            fail(i, "unknown anchor %s", e.Anchor)
+            continue
+        case t == nil:
+            fail(i, "unknown symbol %q", e.Symbol)
+            continue
+        }
+        content, err := os.ReadFile(filepath.Join(projectPath, t.file))
+        if err != nil {
+            fail(i, "%v", err)
+            continue
+        }
+        fset := token.NewFileSet()
+        file, err := parser.ParseFile(fset, t.file, content, parser.ParseComments)
+        if err != nil {
+            fail(i, "%v", err)
+            continue
+        }
+        decl, doc, body := findDecl(file, t)
+        if decl == nil {
+            fail(i, "%s not found in %s", t.id, t.file)
+            continue
+        }
+        start, end := decl.Pos(), decl.End()
+        if doc != nil {
+            start = doc.Pos()
+        }
+        text := strings.TrimRight(e.Content, "\n")
+        var replacement string
+        switch e.Op {
+        case "replace_body":
+            if !body[0].IsValid() {
+                fail(i, "%s has no body", t.id)
+                continue
+            }
+            start, end = body[0], body[1]
+            replacement = "\n" + text + "\n"
+        case "replace":
+            replacement = text
+        case "delete":
+            // Вместе с переводом строки, чтобы не оставлять пустую строку
+            if off := fset.Position(end).Offset; off < len(content) && content[off] == '\n' {
+                end++
+            }
+        case "insert_after":
+            start = end
+            replacement = "\n\n" + text
+        }
+        from, to := fset.Position(start), fset.Position(end)
+        sum := sha256.Sum256(content[from.Offset:to.Offset])
+        result.Patches = append(result.Patches, EditPatch{
+            Anchor:         anchor,
+            Symbol:         t.id,
+            Op:             e.Op,
+            File:           t.file,
+            Start:          from.Offset,
+            End:            to.Offset,
+            Line:           from.Line,
+            EndLine:        to.Line,
+            OriginalSHA256: hex.EncodeToString(sum[:]),
+            Replacement:    replacement,
+        })
+    }
+    return result
+}
+
+// applyEdits применяет патчи с конца файла к началу; пересекающиеся
+// патчи и файлы, изменённые после построения патчей, отвергаются
+func applyEdits(projectPath string, patches []EditPatch) error {
+    byFile := make(map[string][]EditPatch)
+    for _, p := range patches {
+        byFile[p.File] = append(byFile[p.File], p)
+    }
+    contents := make(map[string][]byte)
+    for file, ps := range byFile {
+        content, err := os.ReadFile(filepath.Join(projectPath, file))
+        if err != nil {
+            return err
+        }
+        sort.SliceStable(ps, func(i, j int) bool { return ps[i].Start > ps[j].Start })
+        for i, p := range ps {
+            if i > 0 && p.End > ps[i-1].Start {
+                return fmt.Errorf("%s: overlapping edits of %s and %s", file, p.Symbol, ps[i-1].Symbol)
+            }
+            if p.End > len(content) {
+                return fmt.Errorf("%s: changed since the edits were built", file)
+            }
+            sum := sha256.Sum256(content[p.Start:p.End])
+            if hex.EncodeToString(sum[:]) != p.OriginalSHA256 {
+                return fmt.Errorf("%s: changed since the edits were built", file)
+            }
+        }
+        for _, p := range ps {
+            content = append(content[:p.Start:p.Start], append([]byte(p.Replacement), content[p.End:]...)...)
+        }
+        contents[file] = content
+    }
+    for file, content := range contents {
+        if err := writeAtomic(filepath.Join(projectPath, file), content); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// runEdits - analyzer edits -edits <file>: инструкции правки символов
+// (JSON-массив EditInstruction, "-" - stdin) в патчи по байтовым
+// диапазонам; с -write патчи применяются
+func runEdits(args []string) {
+    fs := newCommandFlags("edits", "-edits <file> [-write] [project_path]")
+    editsPath := fs.String("edits", "-", "JSON array of edit instructions ({anchor|symbol, op, content}); - reads stdin")
+    write := fs.Bool("write", false, "apply the patches to the files instead of only printing them")
+    projectPath := fs.parse(args)
+
+    var data []byte
+    var err error
+    if *editsPath == "-" {
+        data, err = io.ReadAll(os.Stdin)
+    } else {
+        data, err = os.ReadFile(*editsPath)
+    }
+    if err != nil {
+        fatal("failed to read edits", "error", err)
+    }
+    var edits []EditInstruction
+    if err := json.Unmarshal(data, &edits); err != nil {
+        fatal("invalid edits", "error", err)
+    }
+
+    analysis := analyzeProject(context.Background(), projectPath, defaultOptions())
+    result := buildEdits(projectPath, analysis, edits)
+    if *write && len(result.Errors) == 0 {
+        if err := applyEdits(projectPath, result.Patches); err != nil {
+            fatal("failed to apply edits", "error", err)
+        }
+        result.Written = true
+    }
+    fs.write(result)
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "log/slog"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// Путь хранилища аннотаций по умолчанию, относительно корня проекта
+const defaultAnnotationsFile = ".llmstruct/annotations.json"
+
+// Annotation - внешняя аннотация символа (заметка, описание от LLM,
+// вердикт ревью), хранится отдельно от кода по стабильному ID символа.
+// Fingerprint - отпечаток символа, по которому аннотация находит символ
+// после переименования или переноса
+type Annotation struct {
+    Symbol       string   `json:"symbol"`
+    Kind         string   `json:"kind"`
+    Text         string   `json:"text"`
+    Author       string   `json:"author,omitempty"`
+    Created      string   `json:"created,omitempty"`
+    Fingerprint  string   `json:"fingerprint,omitempty"`
+}
+
+type AnnotationStore struct {
+    Version      int          `json:"version"`
+    Annotations  []Annotation `json:"annotations"`
+}
+
+// AttachedAnnotation - аннотация в результате анализа: attached - символ
+// найден по ID, relocated - перенесена на переименованный символ,
+// orphaned - символ не найден
+type AttachedAnnotation struct {
+    Annotation
+    Status         string `json:"status"`
+    PreviousSymbol string `json:"previous_symbol,omitempty"`
+}
+
+// symbolFingerprint не зависит от имени и пакета символа: вид, сигнатура и
+// токены тела для функций, набор полей/методов для типов
+func symbolFingerprint(sym *goSymbol, src *sourceLines) string {
+    parts := []string{sym.kind, sym.signature}
+    switch sym.kind {
+    case "function", "method":
+        toks := bodyTokens(src.span(sym.file, sym.line, sym.endLine), shortName(sym.name))
+        if len(toks) < minRenameTokens {
+            // Короткие тела не отличают символы друг от друга
+            return ""
+        }
+        parts = append(parts, toks...)
+    case "struct", "interface":
+        if len(sym.members) < 2 {
+            return ""
+        }
+        for name, typ := range sym.members {
+            parts = append(parts, name+" "+typ)
+        }
+        sort.Strings(parts[2:])
+    default:
+        return ""
+    }
+    sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+    return hex.EncodeToString(sum[:8])
+}
+
+func loadAnnotations(path string) (*AnnotationStore, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var store AnnotationStore
+    if err := json.Unmarshal(data, &store); err != nil {
+        return nil, err
+    }
+    return &store, nil
+}
+
+// attachAnnotations привязывает аннотации из хранилища к символам текущего
+// анализа. Аннотация, символ которой исчез, переносится по переименованию
+// из режима diff или по совпадению отпечатка с единственным новым
+// символом; хранилище перезаписывается с обновлёнными ID и отпечатками
+func attachAnnotations(analysis *ProjectAnalysis, projectPath, storePath string) error {
+    if !filepath.IsAbs(storePath) {
+        storePath = filepath.Join(projectPath, storePath)
+    }
+    store, err := loadAnnotations(storePath)
+    if os.IsNotExist(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    symbols := goSymbols(analysis, false)
+    src := &sourceLines{root: projectPath, files: make(map[string][]string)}
+    fingerprints := make(map[string]string, len(symbols))
+    byFingerprint := make(map[string][]string)
+    for id, sym := range symbols {
+        if fp := symbolFingerprint(sym, src); fp != "" {
+            fingerprints[id] = fp
+            byFingerprint[fp] = append(byFingerprint[fp], id)
+        }
+    }
+    renamed := make(map[string]string)
+    if analysis.Diff != nil {
+        for _, r := range analysis.Diff.Renames {
+            renamed[r.From] = r.To
+        }
+    }
+
+    changed := false
+    attached := []AttachedAnnotation{}
+    for i := range store.Annotations {
+        a := &store.Annotations[i]
+        result := AttachedAnnotation{Status: "attached"}
+        if symbols[a.Symbol] == nil {
+            target := renamed[a.Symbol]
+            if target == "" && a.Fingerprint != "" && len(byFingerprint[a.Fingerprint]) == 1 {
+                target = byFingerprint[a.Fingerprint][0]
+            }
+            if target != "" && symbols[target] != nil {
+                result.Status, result.PreviousSymbol = "relocated", a.Symbol
+                a.Symbol = target
+                changed = true
+            } else {
+                result.Status = "orphaned"
+            }
+        }
+        if fp := fingerprints[a.Symbol]; symbols[a.Symbol] != nil && fp != a.Fingerprint {
+            a.Fingerprint = fp
+            changed = true
+        }
+        result.Annotation = *a
+        attached = append(attached, result)
+    }
+    analysis.Annotations = attached
+
+    if !changed {
+        return nil
+    }
+    data, err := json.MarshalIndent(store, "", "  ")
+    if err != nil {
+        return err
+    }
+    slog.Info("updated annotation store", "path", storePath)
+    return writeAtomic(storePath, append(data, '\n'))
+}
@@ -0,0 +1,374 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+    "strconv"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// AnyUse - any/interface{} в экспортируемой сигнатуре или поле. Observed -
+// конкретные типы, которые туда попадают (аргументы вызовов, присваивания,
+// возвраты) или извлекаются проверками типа
+type AnyUse struct {
+    Symbol       string   `json:"symbol"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Position     string   `json:"position"`
+    Type         string   `json:"type"`
+    Observed     []string `json:"observed,omitempty"`
+    Suggestion   string   `json:"suggestion,omitempty"`
+}
+
+// containsAny: пустой интерфейс сам по себе или внутри составного типа;
+// ограничения параметров типа не в счёт
+func containsAny(t types.Type) bool {
+    switch x := types.Unalias(t).(type) {
+    case *types.Interface:
+        return x.Empty()
+    case *types.Named, *types.TypeParam, *types.Basic:
+        return false
+    case *types.Pointer:
+        return containsAny(x.Elem())
+    case *types.Slice:
+        return containsAny(x.Elem())
+    case *types.Array:
+        return containsAny(x.Elem())
+    case *types.Chan:
+        return containsAny(x.Elem())
+    case *types.Map:
+        return containsAny(x.Key()) || containsAny(x.Elem())
+    }
+    return false
+}
+
+// anySlot - место с any, к которому привязываются наблюдения
+type anySlot struct {
+    use      *AnyUse
+    typ      types.Type
+    observed map[string]bool
+    // Вариадический ...any: разные типы аргументов - норма (Printf)
+    variadic bool
+    // Результат возвращает параметр any как есть
+    passes   string
+}
+
+func (s *anySlot) observe(t types.Type) {
+    if t == nil {
+        return
+    }
+    t = types.Default(t)
+    // Интерфейсы и тот же тип (передали []any в []any) ничего не уточняют
+    if types.IsInterface(t) || types.Identical(t, s.typ) {
+        return
+    }
+    if b, ok := t.(*types.Basic); ok && b.Kind() == types.UntypedNil {
+        return
+    }
+    s.observed[types.TypeString(t, nil)] = true
+}
+
+type anyAuditor struct {
+    projectPath string
+    params      map[*types.Func]map[int]*anySlot
+    results     map[*types.Func]map[int]*anySlot
+    fields      map[*types.Var]*anySlot
+    byParam     map[*types.Var]*anySlot
+    slots       []*anySlot
+}
+
+func (a *anyAuditor) slot(pkg *packages.Package, symbol string, pos token.Pos, position string, t types.Type) *anySlot {
+    p := pkg.Fset.Position(pos)
+    s := &anySlot{
+        use: &AnyUse{
+            Symbol:   symbol,
+            File:     relativePath(a.projectPath, p.Filename),
+            Line:     p.Line,
+            Position: position,
+            Type:     types.TypeString(t, nil),
+        },
+        typ:      t,
+        observed: make(map[string]bool),
+    }
+    a.slots = append(a.slots, s)
+    return s
+}
+
+// collect находит any в экспортируемых функциях, методах экспортируемых
+// типов и экспортируемых полях экспортируемых структур
+func (a *anyAuditor) collect(pkg *packages.Package) {
+    scope := pkg.Types.Scope()
+    for _, name := range scope.Names() {
+        obj := scope.Lookup(name)
+        if !obj.Exported() {
+            continue
+        }
+        switch o := obj.(type) {
+        case *types.Func:
+            a.collectFunc(pkg, o)
+        case *types.TypeName:
+            if o.IsAlias() {
+                continue
+            }
+            if st, ok := o.Type().Underlying().(*types.Struct); ok {
+                for i := 0; i < st.NumFields(); i++ {
+                    field := st.Field(i)
+                    if field.Exported() && containsAny(field.Type()) {
+                        a.fields[field] = a.slot(pkg, objectSymbolID(o), field.Pos(), "field "+field.Name(), field.Type())
+                    }
+                }
+            }
+            if named, ok := o.Type().(*types.Named); ok {
+                for i := 0; i < named.NumMethods(); i++ {
+                    if m := named.Method(i); m.Exported() {
+                        a.collectFunc(pkg, m)
+                    }
+                }
+            }
+            if iface, ok := o.Type().Underlying().(*types.Interface); ok {
+                for i := 0; i < iface.NumExplicitMethods(); i++ {
+                    if m := iface.ExplicitMethod(i); m.Exported() {
+                        a.collectFunc(pkg, m)
+                    }
+                }
+            }
+        }
+    }
+}
+
+func (a *anyAuditor) collectFunc(pkg *packages.Package, fn *types.Func) {
+    sig := fn.Type().(*types.Signature)
+    id := objectSymbolID(fn)
+    for i := 0; i < sig.Params().Len(); i++ {
+        p := sig.Params().At(i)
+        if !containsAny(p.Type()) {
+            continue
+        }
+        name := p.Name()
+        if name == "" || name == "_" {
+            name = "#" + strconv.Itoa(i)
+        }
+        s := a.slot(pkg, id, p.Pos(), "param "+name, p.Type())
+        s.variadic = sig.Variadic() && i == sig.Params().Len()-1
+        if a.params[fn] == nil {
+            a.params[fn] = make(map[int]*anySlot)
+        }
+        a.params[fn][i] = s
+        a.byParam[p] = s
+    }
+    for i := 0; i < sig.Results().Len(); i++ {
+        r := sig.Results().At(i)
+        if !containsAny(r.Type()) {
+            continue
+        }
+        if a.results[fn] == nil {
+            a.results[fn] = make(map[int]*anySlot)
+        }
+        a.results[fn][i] = a.slot(pkg, id, fn.Pos(), "result "+strconv.Itoa(i), r.Type())
+    }
+}
+
+// observe обходит код проекта и собирает конкретные типы для мест с any
+func (a *anyAuditor) observe(pkg *packages.Package) {
+    info := pkg.TypesInfo
+    // Проверка типа параметра или поля: v.(T), switch v.(type)
+    asserted := func(expr ast.Expr) *anySlot {
+        switch e := ast.Unparen(expr).(type) {
+        case *ast.Ident:
+            if v, ok := info.Uses[e].(*types.Var); ok {
+                return a.byParam[v]
+            }
+        case *ast.SelectorExpr:
+            if sel, ok := info.Selections[e]; ok && sel.Kind() == types.FieldVal {
+                return a.fields[sel.Obj().(*types.Var).Origin()]
+            }
+        }
+        return nil
+    }
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            switch x := n.(type) {
+            case *ast.CallExpr:
+                a.observeCall(info, x)
+            case *ast.TypeAssertExpr:
+                if x.Type != nil {
+                    if s := asserted(x.X); s != nil {
+                        s.observe(info.TypeOf(x.Type))
+                    }
+                }
+            case *ast.TypeSwitchStmt:
+                var subject ast.Expr
+                switch assign := x.Assign.(type) {
+                case *ast.AssignStmt:
+                    subject = assign.Rhs[0].(*ast.TypeAssertExpr).X
+                case *ast.ExprStmt:
+                    subject = assign.X.(*ast.TypeAssertExpr).X
+                }
+                if s := asserted(subject); s != nil {
+                    for _, clause := range x.Body.List {
+                        for _, t := range clause.(*ast.CaseClause).List {
+                            s.observe(info.TypeOf(t))
+                        }
+                    }
+                }
+            case *ast.AssignStmt:
+                if len(x.Lhs) != len(x.Rhs) {
+                    break
+                }
+                for i, lhs := range x.Lhs {
+                    if sel, ok := lhs.(*ast.SelectorExpr); ok {
+                        if s := asserted(sel); s != nil {
+                            s.observe(info.TypeOf(x.Rhs[i]))
+                        }
+                    }
+                }
+            case *ast.KeyValueExpr:
+                if key, ok := x.Key.(*ast.Ident); ok {
+                    if v, ok := info.Uses[key].(*types.Var); ok && v.IsField() {
+                        if s := a.fields[v.Origin()]; s != nil {
+                            s.observe(info.TypeOf(x.Value))
+                        }
+                    }
+                }
+            }
+            return true
+        })
+        for _, decl := range file.Decls {
+            if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+                if fn, ok := info.Defs[fd.Name].(*types.Func); ok && a.results[fn] != nil {
+                    a.observeReturns(info, fn, fd.Body)
+                }
+            }
+        }
+    }
+}
+
+// observeCall сопоставляет аргументы вызова с параметрами any
+func (a *anyAuditor) observeCall(info *types.Info, call *ast.CallExpr) {
+    fn := calleeFunc(info, call)
+    if fn == nil {
+        return
+    }
+    slots := a.params[fn.Origin()]
+    if slots == nil {
+        return
+    }
+    sig := fn.Type().(*types.Signature)
+    last := sig.Params().Len() - 1
+    for i, arg := range call.Args {
+        pi := i
+        if sig.Variadic() && i > last {
+            pi = last
+        }
+        s := slots[pi]
+        if s == nil {
+            continue
+        }
+        // f(xs...) передаёт срез целиком, иначе - отдельный элемент
+        if sig.Variadic() && pi == last && !call.Ellipsis.IsValid() {
+            s.observeElem(info.TypeOf(arg))
+            continue
+        }
+        s.observe(info.TypeOf(arg))
+    }
+}
+
+// observeReturns собирает типы возвращаемых значений; return во
+// вложенных литералах функций относится к ним
+func (a *anyAuditor) observeReturns(info *types.Info, fn *types.Func, body *ast.BlockStmt) {
+    results := a.results[fn]
+    count := fn.Type().(*types.Signature).Results().Len()
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.FuncLit:
+            return false
+        case *ast.ReturnStmt:
+            if len(x.Results) != count {
+                break
+            }
+            for i, r := range x.Results {
+                s := results[i]
+                if s == nil {
+                    continue
+                }
+                if id, ok := ast.Unparen(r).(*ast.Ident); ok {
+                    if v, ok := info.Uses[id].(*types.Var); ok && a.byParam[v] != nil && types.Identical(v.Type(), s.typ) {
+                        s.passes = v.Name()
+                    }
+                }
+                s.observe(info.TypeOf(r))
+            }
+        }
+        return true
+    })
+}
+
+// observeElem - аргумент вариадического ...any: наблюдается тип элемента
+func (s *anySlot) observeElem(t types.Type) {
+    if slice, ok := s.typ.(*types.Slice); ok {
+        elem := &anySlot{typ: slice.Elem(), observed: s.observed}
+        elem.observe(t)
+    }
+}
+
+func (s *anySlot) suggest() {
+    for t := range s.observed {
+        s.use.Observed = append(s.use.Observed, t)
+    }
+    sort.Strings(s.use.Observed)
+    switch len(s.use.Observed) {
+    case 0:
+        if s.passes != "" {
+            s.use.Suggestion = "returns param " + s.passes + " unchanged; a type parameter keeps the caller's type"
+        }
+    case 1:
+        s.use.Suggestion = "only " + s.use.Observed[0] + " is used here; use the concrete type"
+    default:
+        if s.variadic {
+            break
+        }
+        if len(s.use.Observed) <= 4 {
+            s.use.Suggestion = "used with " + strings.Join(s.use.Observed, ", ") + "; consider a type parameter constrained to these types"
+        } else {
+            s.use.Suggestion = "used with many types; consider an interface with the methods the code relies on"
+        }
+    }
+}
+
+// extractAnyUsage перечисляет any/interface{} в экспортируемом API
+// проекта и предлагает замену по фактическому использованию
+func extractAnyUsage(pkgs []*packages.Package, projectPath string) []AnyUse {
+    a := &anyAuditor{
+        projectPath: projectPath,
+        params:      make(map[*types.Func]map[int]*anySlot),
+        results:     make(map[*types.Func]map[int]*anySlot),
+        fields:      make(map[*types.Var]*anySlot),
+        byParam:     make(map[*types.Var]*anySlot),
+    }
+    for _, pkg := range pkgs {
+        if pkg.Types != nil && pkg.TypesInfo != nil && pkg.Name != "main" {
+            a.collect(pkg)
+        }
+    }
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo != nil {
+            a.observe(pkg)
+        }
+    }
+    uses := []AnyUse{}
+    for _, s := range a.slots {
+        s.suggest()
+        uses = append(uses, *s.use)
+    }
+    sort.SliceStable(uses, func(i, j int) bool {
+        if uses[i].File != uses[j].File {
+            return uses[i].File < uses[j].File
+        }
+        return uses[i].Line < uses[j].Line
+    })
+    return uses
+}
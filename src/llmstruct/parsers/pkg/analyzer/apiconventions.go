@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+    "go/token"
+    "go/types"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// APIFinding - нарушение соглашений Go в экспортируемом API
+type APIFinding struct {
+    Symbol       string   `json:"symbol"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Rule         string   `json:"rule"`
+    Message      string   `json:"message"`
+    Suggestion   string   `json:"suggestion,omitempty"`
+}
+
+type apiAuditor struct {
+    projectPath string
+    fset        *token.FileSet
+    findings    []APIFinding
+}
+
+func (a *apiAuditor) report(obj types.Object, symbol, rule, message, suggestion string) {
+    pos := a.fset.Position(obj.Pos())
+    a.findings = append(a.findings, APIFinding{
+        Symbol:     symbol,
+        File:       relativePath(a.projectPath, pos.Filename),
+        Line:       pos.Line,
+        Rule:       rule,
+        Message:    message,
+        Suggestion: suggestion,
+    })
+}
+
+func isErrorType(t types.Type) bool {
+    return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// checkSignature: error - последний результат, context.Context - первый параметр
+func (a *apiAuditor) checkSignature(fn *types.Func) {
+    sig := fn.Type().(*types.Signature)
+    results := sig.Results()
+    for i := 0; i < results.Len()-1; i++ {
+        if isErrorType(results.At(i).Type()) {
+            a.report(fn, fn.FullName(), "error_not_last", "error is returned at position "+strconv.Itoa(i+1)+" of "+strconv.Itoa(results.Len()), "return error as the last result")
+            break
+        }
+    }
+    params := sig.Params()
+    for i := 1; i < params.Len(); i++ {
+        if isContextType(params.At(i).Type()) {
+            a.report(fn, fn.FullName(), "context_not_first", "context.Context is parameter "+strconv.Itoa(i+1), "take ctx context.Context as the first parameter")
+            break
+        }
+    }
+}
+
+// checkGetter: метод без параметров, возвращающий значение, называется
+// по полю, а не GetField
+func (a *apiAuditor) checkGetter(fn *types.Func) {
+    sig := fn.Type().(*types.Signature)
+    rest, ok := strings.CutPrefix(fn.Name(), "Get")
+    if !ok || rest == "" || !unicode.IsUpper(rune(rest[0])) || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+        return
+    }
+    a.report(fn, fn.FullName(), "getter_prefix", "getter "+fn.Name()+" uses the Get prefix", "rename to "+rest)
+}
+
+// checkStutter: имя повторяет имя пакета (user.UserService вместо
+// user.Service)
+func (a *apiAuditor) checkStutter(pkgName string, obj types.Object) {
+    name := obj.Name()
+    if len(name) <= len(pkgName) || !strings.EqualFold(name[:len(pkgName)], pkgName) {
+        return
+    }
+    rest := name[len(pkgName):]
+    if !unicode.IsUpper(rune(rest[0])) {
+        return
+    }
+    a.report(obj, obj.Pkg().Path()+"."+name, "stutter", pkgName+"."+name+" repeats the package name", "rename to "+pkgName+"."+rest)
+}
+
+// extractAPIConventions проверяет экспортируемый API пакетов проекта
+// (кроме main): префикс Get у геттеров, error не последним результатом,
+// context.Context не первым параметром и повтор имени пакета в именах
+func extractAPIConventions(pkgs []*packages.Package, projectPath string) []APIFinding {
+    a := &apiAuditor{projectPath: projectPath}
+    for _, pkg := range pkgs {
+        if pkg.Types == nil || pkg.Name == "main" {
+            continue
+        }
+        a.fset = pkg.Fset
+        scope := pkg.Types.Scope()
+        for _, name := range scope.Names() {
+            obj := scope.Lookup(name)
+            if !obj.Exported() {
+                continue
+            }
+            a.checkStutter(pkg.Name, obj)
+            switch o := obj.(type) {
+            case *types.Func:
+                a.checkSignature(o)
+            case *types.TypeName:
+                named, ok := o.Type().(*types.Named)
+                if !ok || o.IsAlias() {
+                    continue
+                }
+                for i := 0; i < named.NumMethods(); i++ {
+                    if m := named.Method(i); m.Exported() {
+                        a.checkSignature(m)
+                        a.checkGetter(m)
+                    }
+                }
+                if iface, ok := named.Underlying().(*types.Interface); ok {
+                    for i := 0; i < iface.NumExplicitMethods(); i++ {
+                        if m := iface.ExplicitMethod(i); m.Exported() {
+                            a.checkSignature(m)
+                            a.checkGetter(m)
+                        }
+                    }
+                }
+            }
+        }
+    }
+    sort.Slice(a.findings, func(i, j int) bool {
+        if a.findings[i].File != a.findings[j].File {
+            return a.findings[i].File < a.findings[j].File
+        }
+        return a.findings[i].Line < a.findings[j].Line
+    })
+    if a.findings == nil {
+        return []APIFinding{}
+    }
+    return a.findings
+}
@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type AuthCheck struct {
+    Kind         string   `json:"kind"`
+    Call         string   `json:"call"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+}
+
+type RouteAuth struct {
+    Method       string   `json:"method"`
+    Path         string   `json:"path"`
+    Handler      string   `json:"handler"`
+    ProtectedBy  []string `json:"protected_by"`
+    Protected    bool     `json:"protected"`
+}
+
+type AuthReport struct {
+    Checks           []AuthCheck `json:"checks"`
+    AuthFunctions    []string    `json:"auth_functions"`
+    Routes           []RouteAuth `json:"routes"`
+}
+
+type authAPI struct {
+    kind    string
+    pkgPath string
+    names   []string
+}
+
+// Известные API аутентификации (authn) и авторизации (authz)
+var authAPIs = []authAPI{
+    {kind: "authn", pkgPath: "github.com/golang-jwt/jwt", names: []string{"Parse", "ParseWithClaims"}},
+    {kind: "authn", pkgPath: "github.com/dgrijalva/jwt-go", names: []string{"Parse", "ParseWithClaims"}},
+    {kind: "authn", pkgPath: "github.com/lestrrat-go/jwx", names: []string{"Parse", "ParseRequest", "ParseString", "Verify"}},
+    {kind: "authn", pkgPath: "github.com/coreos/go-oidc", names: []string{"Verify"}},
+    {kind: "authn", pkgPath: "github.com/gorilla/sessions", names: []string{"Get"}},
+    {kind: "authn", pkgPath: "github.com/alexedwards/scs", names: []string{"Get", "GetString", "GetInt", "GetBool", "Exists"}},
+    {kind: "authn", pkgPath: "net/http", names: []string{"BasicAuth"}},
+    {kind: "authn", pkgPath: "golang.org/x/crypto/bcrypt", names: []string{"CompareHashAndPassword"}},
+    {kind: "authz", pkgPath: "github.com/casbin/casbin", names: []string{"Enforce", "EnforceWithMatcher", "BatchEnforce"}},
+    {kind: "authz", pkgPath: "github.com/openfga/go-sdk", names: []string{"Check"}},
+    {kind: "authz", pkgPath: "github.com/ory/keto-client-go", names: []string{"CheckPermission"}},
+}
+
+// authBuilder собирает прямые проверки auth и статические вызовы между
+// функциями проекта, чтобы найти проверки, выполняемые через помощники
+type authBuilder struct {
+    projectPath string
+    opts        Options
+    checks      []AuthCheck
+    direct      map[string]bool
+    callees     map[string][]string
+}
+
+func newAuthBuilder(projectPath string, opts Options) *authBuilder {
+    return &authBuilder{
+        projectPath: projectPath,
+        opts:        opts,
+        direct:      make(map[string]bool),
+        callees:     make(map[string][]string),
+    }
+}
+
+func (b *authBuilder) authKind(name, pkgPath, funcName string) (string, bool) {
+    for _, api := range authAPIs {
+        if !hasPathPrefix(pkgPath, api.pkgPath) {
+            continue
+        }
+        for _, n := range api.names {
+            if n == funcName {
+                return api.kind, true
+            }
+        }
+    }
+    for _, re := range b.opts.AuthPatterns {
+        if re.MatchString(name) {
+            return "custom", true
+        }
+    }
+    return "", false
+}
+
+func (b *authBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok || decl == nil {
+                return true
+            }
+            fn := calleeFunc(pkg.TypesInfo, call)
+            if fn == nil || fn.Pkg() == nil {
+                return true
+            }
+
+            caller := funcID(pkg, decl)
+            if kind, ok := b.authKind(fn.FullName(), fn.Pkg().Path(), fn.Name()); ok {
+                pos := pkg.Fset.Position(call.Pos())
+                b.checks = append(b.checks, AuthCheck{
+                    Kind:     kind,
+                    Call:     fn.FullName(),
+                    File:     relativePath(b.projectPath, pos.Filename),
+                    Line:     pos.Line,
+                    Function: caller,
+                })
+                b.direct[caller] = true
+            }
+            // Вызов фабрики middleware только строит цепочку, а не проверяет
+            if results := fn.Type().(*types.Signature).Results(); results.Len() == 1 && isHandlerType(results.At(0).Type()) {
+                return true
+            }
+            b.callees[caller] = appendUnique(b.callees[caller], fn.FullName())
+            return true
+        })
+    }
+}
+
+// performsAuth: функция выполняет проверку сама или через вызываемые функции
+func (b *authBuilder) performsAuth(function string, visiting map[string]bool) bool {
+    if b.direct[function] {
+        return true
+    }
+    if visiting[function] {
+        return false
+    }
+    visiting[function] = true
+    for _, callee := range b.callees[function] {
+        if b.performsAuth(callee, visiting) {
+            return true
+        }
+    }
+    return false
+}
+
+// isAuthMiddleware распознаёт проверки в middleware: по телу функции проекта,
+// по пользовательским шаблонам или по имени библиотечного middleware
+func (b *authBuilder) isAuthMiddleware(name string) bool {
+    if b.performsAuth(name, make(map[string]bool)) {
+        return true
+    }
+    for _, re := range b.opts.AuthPatterns {
+        if re.MatchString(name) {
+            return true
+        }
+    }
+    short := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+    return strings.Contains(short, "auth") || strings.Contains(short, "jwt") || strings.Contains(short, "session")
+}
+
+func (b *authBuilder) build(routes []Route) *AuthReport {
+    if len(b.checks) == 0 && len(routes) == 0 {
+        return nil
+    }
+
+    report := &AuthReport{
+        Checks:        b.checks,
+        AuthFunctions: []string{},
+        Routes:        []RouteAuth{},
+    }
+    if report.Checks == nil {
+        report.Checks = []AuthCheck{}
+    }
+
+    for function := range b.callees {
+        if b.performsAuth(function, make(map[string]bool)) {
+            report.AuthFunctions = append(report.AuthFunctions, function)
+        }
+    }
+    sort.Strings(report.AuthFunctions)
+
+    for _, route := range routes {
+        ra := RouteAuth{
+            Method:      route.Method,
+            Path:        route.Path,
+            Handler:     route.Handler,
+            ProtectedBy: []string{},
+        }
+        for _, mw := range route.Middleware {
+            if b.isAuthMiddleware(mw.Name) {
+                ra.ProtectedBy = appendUnique(ra.ProtectedBy, mw.Name)
+            }
+        }
+        if b.performsAuth(route.Handler, make(map[string]bool)) {
+            ra.ProtectedBy = appendUnique(ra.ProtectedBy, route.Handler)
+        }
+        ra.Protected = len(ra.ProtectedBy) > 0
+        report.Routes = append(report.Routes, ra)
+    }
+
+    // Незащищённые маршруты первыми - это главный предмет ревью
+    sort.SliceStable(report.Routes, func(i, j int) bool {
+        return !report.Routes[i].Protected && report.Routes[j].Protected
+    })
+    return report
+}
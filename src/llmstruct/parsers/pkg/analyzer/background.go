@@ -0,0 +1,292 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type BackgroundJob struct {
+    Kind         string   `json:"kind"`
+    Library      string   `json:"library,omitempty"`
+    Schedule     string   `json:"schedule,omitempty"`
+    Trigger      string   `json:"trigger,omitempty"`
+    Concurrency  string   `json:"concurrency,omitempty"`
+    Entry        string   `json:"entry"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+}
+
+// isTimeChan: канал тикера или таймера (<-chan time.Time)
+func isTimeChan(t types.Type) bool {
+    if t == nil {
+        return false
+    }
+    ch, ok := t.Underlying().(*types.Chan)
+    return ok && types.TypeString(ch.Elem(), nil) == "time.Time"
+}
+
+// chainSchedule восстанавливает расписание gocron из цепочки вызовов:
+// s.Every(5).Minutes().Do(job) -> "Every(5).Minutes()"
+func chainSchedule(expr ast.Expr) string {
+    schedule := types.ExprString(expr)
+    if root := rootIdent(expr); root != nil {
+        schedule = strings.TrimPrefix(schedule, root.Name+".")
+    }
+    return schedule
+}
+
+// rangedChannel возвращает канал, который тело обходит через range
+func rangedChannel(info *types.Info, body ast.Node) string {
+    channel := ""
+    ast.Inspect(body, func(n ast.Node) bool {
+        if rs, ok := n.(*ast.RangeStmt); ok && channel == "" {
+            if tv, ok := info.Types[rs.X]; ok && isChanType(tv.Type) && !isTimeChan(tv.Type) {
+                channel = types.ExprString(rs.X)
+            }
+        }
+        return channel == ""
+    })
+    return channel
+}
+
+// loopBound извлекает число итераций цикла: i < n или range n
+func loopBound(info *types.Info, loop ast.Node) string {
+    switch l := loop.(type) {
+    case *ast.ForStmt:
+        if cond, ok := l.Cond.(*ast.BinaryExpr); ok && (cond.Op == token.LSS || cond.Op == token.LEQ) {
+            return types.ExprString(cond.Y)
+        }
+    case *ast.RangeStmt:
+        if tv, ok := info.Types[l.X]; ok {
+            if basic, ok := tv.Type.Underlying().(*types.Basic); ok && basic.Info()&types.IsInteger != 0 {
+                return types.ExprString(l.X)
+            }
+        }
+    }
+    return ""
+}
+
+func extractBackgroundJobs(pkg *packages.Package, projectPath string) []BackgroundJob {
+    if pkg.TypesInfo == nil {
+        return nil
+    }
+    info := pkg.TypesInfo
+    decls := funcDecls(pkg)
+
+    // Интервалы тикеров: t := time.NewTicker(d)
+    intervals := make(map[types.Object]string)
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            assign, ok := n.(*ast.AssignStmt)
+            if !ok || len(assign.Lhs) != len(assign.Rhs) {
+                return true
+            }
+            for i, rhs := range assign.Rhs {
+                call, ok := rhs.(*ast.CallExpr)
+                if !ok || len(call.Args) == 0 {
+                    continue
+                }
+                fn := calleeFunc(info, call)
+                if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "time" || (fn.Name() != "NewTicker" && fn.Name() != "NewTimer") {
+                    continue
+                }
+                if obj := varObject(info, assign.Lhs[i]); obj != nil {
+                    intervals[obj] = types.ExprString(call.Args[0])
+                }
+            }
+            return true
+        })
+    }
+
+    // Тело функции, запускаемой в горутине
+    goBody := func(call *ast.CallExpr) ast.Node {
+        if lit, ok := call.Fun.(*ast.FuncLit); ok {
+            return lit.Body
+        }
+        if fn := calleeFunc(info, call); fn != nil {
+            if fd, ok := decls[fn]; ok {
+                return fd.Body
+            }
+        }
+        return nil
+    }
+
+    var jobs []BackgroundJob
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            fd, ok := decl.(*ast.FuncDecl)
+            if !ok || fd.Body == nil {
+                continue
+            }
+            function := funcID(pkg, fd)
+            entry := function
+
+            add := func(job BackgroundJob, pos token.Pos) {
+                p := pkg.Fset.Position(pos)
+                job.File = relativePath(projectPath, p.Filename)
+                job.Line = p.Line
+                job.Function = function
+                if job.Entry == "" {
+                    job.Entry = entry
+                }
+                jobs = append(jobs, job)
+            }
+
+            // Стек узлов нужен, чтобы знать охватывающие циклы и замыкания
+            var stack []ast.Node
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                if n == nil {
+                    stack = stack[:len(stack)-1]
+                    return false
+                }
+                parents := stack
+                stack = append(stack, n)
+
+                // Ближайший цикл в пределах текущей функции или замыкания
+                enclosingLoop := func() ast.Node {
+                    for i := len(parents) - 1; i >= 0; i-- {
+                        switch p := parents[i].(type) {
+                        case *ast.ForStmt, *ast.RangeStmt:
+                            return p
+                        case *ast.FuncLit:
+                            return nil
+                        }
+                    }
+                    return nil
+                }
+                enclosingEntry := func() string {
+                    for i := len(parents) - 1; i >= 0; i-- {
+                        if lit, ok := parents[i].(*ast.FuncLit); ok {
+                            return handlerName(pkg, lit)
+                        }
+                    }
+                    return entry
+                }
+
+                switch node := n.(type) {
+                case *ast.RangeStmt:
+                    // for range ticker.C / for range time.Tick(d)
+                    if tv, ok := info.Types[node.X]; ok && isTimeChan(tv.Type) {
+                        add(BackgroundJob{Kind: "interval", Schedule: tickerInterval(info, node.X, intervals), Trigger: types.ExprString(node.X), Entry: enclosingEntry()}, node.Pos())
+                    }
+
+                case *ast.UnaryExpr:
+                    // for { select { case <-ticker.C: ... } }
+                    if node.Op != token.ARROW || enclosingLoop() == nil {
+                        return true
+                    }
+                    // <-time.After(d) внутри select - таймаут, а не расписание
+                    if call, ok := node.X.(*ast.CallExpr); ok {
+                        if fn := calleeFunc(info, call); fn != nil && fn.FullName() == "time.After" {
+                            return true
+                        }
+                    }
+                    if tv, ok := info.Types[node.X]; ok && isTimeChan(tv.Type) {
+                        add(BackgroundJob{Kind: "interval", Schedule: tickerInterval(info, node.X, intervals), Trigger: types.ExprString(node.X), Entry: enclosingEntry()}, node.Pos())
+                    }
+
+                case *ast.GoStmt:
+                    body := goBody(node.Call)
+                    job := BackgroundJob{Entry: handlerName(pkg, node.Call.Fun)}
+                    if body != nil {
+                        job.Trigger = rangedChannel(info, body)
+                        if job.Trigger != "" {
+                            job.Trigger = "channel " + job.Trigger
+                        }
+                    }
+                    if loop := enclosingLoop(); loop != nil {
+                        job.Kind = "worker-pool"
+                        job.Concurrency = loopBound(info, loop)
+                        add(job, node.Pos())
+                    } else if job.Trigger != "" {
+                        job.Kind = "queue-consumer"
+                        add(job, node.Pos())
+                    }
+
+                case *ast.CallExpr:
+                    fn := calleeFunc(info, node)
+                    if fn == nil || fn.Pkg() == nil {
+                        return true
+                    }
+                    path := fn.Pkg().Path()
+                    arg := func(i int) ast.Expr {
+                        if i < len(node.Args) {
+                            return node.Args[i]
+                        }
+                        return nil
+                    }
+                    exprString := func(e ast.Expr) string {
+                        if e == nil {
+                            return ""
+                        }
+                        if s, ok := constString(info, e); ok {
+                            return s
+                        }
+                        return types.ExprString(e)
+                    }
+
+                    switch {
+                    case hasPathPrefix(path, "github.com/robfig/cron") && (fn.Name() == "AddFunc" || fn.Name() == "AddJob" || fn.Name() == "Schedule"):
+                        job := BackgroundJob{Kind: "cron", Library: "robfig/cron", Schedule: exprString(arg(0))}
+                        if target := arg(1); target != nil {
+                            job.Entry = handlerName(pkg, target)
+                        }
+                        add(job, node.Pos())
+
+                    case hasPathPrefix(path, "github.com/go-co-op/gocron") && fn.Name() == "Do":
+                        sel, ok := node.Fun.(*ast.SelectorExpr)
+                        if !ok || arg(0) == nil {
+                            return true
+                        }
+                        add(BackgroundJob{Kind: "cron", Library: "gocron", Schedule: chainSchedule(sel.X), Entry: handlerName(pkg, arg(0))}, node.Pos())
+
+                    case hasPathPrefix(path, "github.com/go-co-op/gocron") && fn.Name() == "NewJob" && len(node.Args) >= 2:
+                        // gocron v2: s.NewJob(gocron.DurationJob(d), gocron.NewTask(fn))
+                        job := BackgroundJob{Kind: "cron", Library: "gocron", Schedule: types.ExprString(arg(0))}
+                        if task, ok := arg(1).(*ast.CallExpr); ok && len(task.Args) > 0 {
+                            job.Entry = handlerName(pkg, task.Args[0])
+                        }
+                        add(job, node.Pos())
+
+                    case path == "time" && fn.Name() == "AfterFunc" && len(node.Args) == 2:
+                        add(BackgroundJob{Kind: "timer", Schedule: "after " + types.ExprString(arg(0)), Entry: handlerName(pkg, arg(1))}, node.Pos())
+
+                    case hasPathPrefix(path, "github.com/panjf2000/ants") && (fn.Name() == "NewPool" || fn.Name() == "NewPoolWithFunc"):
+                        job := BackgroundJob{Kind: "worker-pool", Library: "ants", Concurrency: exprString(arg(0))}
+                        if fn.Name() == "NewPoolWithFunc" && arg(1) != nil {
+                            job.Entry = handlerName(pkg, arg(1))
+                        }
+                        add(job, node.Pos())
+
+                    case hasPathPrefix(path, "github.com/alitto/pond") && (fn.Name() == "New" || fn.Name() == "NewPool"):
+                        add(BackgroundJob{Kind: "worker-pool", Library: "pond", Concurrency: exprString(arg(0))}, node.Pos())
+
+                    case path == "golang.org/x/sync/errgroup" && fn.Name() == "SetLimit":
+                        add(BackgroundJob{Kind: "worker-pool", Library: "errgroup", Concurrency: exprString(arg(0)), Entry: enclosingEntry()}, node.Pos())
+                    }
+                }
+                return true
+            })
+        }
+    }
+    return jobs
+}
+
+// tickerInterval находит период тикера по его объявлению
+func tickerInterval(info *types.Info, expr ast.Expr, intervals map[types.Object]string) string {
+    if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) > 0 {
+        // time.Tick(d)
+        return types.ExprString(call.Args[0])
+    }
+    if sel, ok := expr.(*ast.SelectorExpr); ok && sel.Sel.Name == "C" {
+        if obj := varObject(info, sel.X); obj != nil {
+            return intervals[obj]
+        }
+    }
+    return ""
+}
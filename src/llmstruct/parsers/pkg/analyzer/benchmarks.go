@@ -0,0 +1,244 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "math"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// BenchmarkResult - результат бенчмарка из вывода `go test -bench`;
+// при нескольких запусках (-count) значения усредняются
+type BenchmarkResult struct {
+    Name         string             `json:"name"`
+    Package      string             `json:"package"`
+    File         string             `json:"file,omitempty"`
+    Line         int                `json:"line,omitempty"`
+    Procs        int                `json:"procs,omitempty"`
+    Runs         int                `json:"runs"`
+    Iterations   int64              `json:"iterations"`
+    NsPerOp      float64            `json:"ns_per_op"`
+    BytesPerOp   *float64           `json:"bytes_per_op,omitempty"`
+    AllocsPerOp  *float64           `json:"allocs_per_op,omitempty"`
+    Metrics      map[string]float64 `json:"metrics,omitempty"`
+    Targets      []string           `json:"targets"`
+}
+
+// BenchmarkStat - замер бенчмарка, который вызывает функцию
+type BenchmarkStat struct {
+    Name         string   `json:"name"`
+    NsPerOp      float64  `json:"ns_per_op"`
+    AllocsPerOp  *float64 `json:"allocs_per_op,omitempty"`
+}
+
+// BenchmarkFoo/case-8   1000   1234 ns/op   56 B/op   2 allocs/op
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-(\d+))?\s+(\d+)\s+(.+)$`)
+
+// benchOutput возвращает текст вывода тестов по пакетам: поток
+// `go test -json` собирается из событий output, обычный вывод делится по
+// строкам "pkg: "
+func benchOutput(data []byte) map[string][]string {
+    lines := make(map[string][]string)
+    if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+        // Строка бенчмарка может прийти несколькими событиями
+        texts := make(map[string]*strings.Builder)
+        var order []string
+        dec := json.NewDecoder(bytes.NewReader(data))
+        for {
+            var ev struct {
+                Action  string
+                Package string
+                Output  string
+            }
+            if err := dec.Decode(&ev); err != nil {
+                break
+            }
+            if ev.Action != "output" {
+                continue
+            }
+            if texts[ev.Package] == nil {
+                texts[ev.Package] = &strings.Builder{}
+                order = append(order, ev.Package)
+            }
+            texts[ev.Package].WriteString(ev.Output)
+        }
+        for _, pkg := range order {
+            lines[pkg] = strings.Split(texts[pkg].String(), "\n")
+        }
+        return lines
+    }
+
+    pkg := ""
+    sc := bufio.NewScanner(bytes.NewReader(data))
+    sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    for sc.Scan() {
+        line := sc.Text()
+        if rest, ok := strings.CutPrefix(line, "pkg: "); ok {
+            pkg = strings.TrimSpace(rest)
+            continue
+        }
+        lines[pkg] = append(lines[pkg], line)
+    }
+    return lines
+}
+
+// parseBenchmarks разбирает строки результатов и усредняет повторы
+func parseBenchmarks(data []byte) []*BenchmarkResult {
+    type key struct{ pkg, name string }
+    byKey := make(map[key]*BenchmarkResult)
+    var results []*BenchmarkResult
+    for pkg, lines := range benchOutput(data) {
+        for _, line := range lines {
+            m := benchLine.FindStringSubmatch(strings.TrimSpace(line))
+            if m == nil {
+                continue
+            }
+            iterations, _ := strconv.ParseInt(m[3], 10, 64)
+            fields := strings.Fields(m[4])
+            values := make(map[string]float64)
+            for i := 0; i+1 < len(fields); i += 2 {
+                if v, err := strconv.ParseFloat(fields[i], 64); err == nil {
+                    values[fields[i+1]] = v
+                }
+            }
+            ns, ok := values["ns/op"]
+            if !ok {
+                continue
+            }
+
+            k := key{pkg, m[1]}
+            r := byKey[k]
+            if r == nil {
+                r = &BenchmarkResult{Name: m[1], Package: pkg, Targets: []string{}}
+                r.Procs, _ = strconv.Atoi(m[2])
+                byKey[k] = r
+                results = append(results, r)
+            }
+            // Накопление сумм; деление на Runs - после разбора
+            r.Runs++
+            r.Iterations += iterations
+            r.NsPerOp += ns
+            for unit, v := range values {
+                switch unit {
+                case "ns/op":
+                case "B/op":
+                    r.BytesPerOp = addMetric(r.BytesPerOp, v)
+                case "allocs/op":
+                    r.AllocsPerOp = addMetric(r.AllocsPerOp, v)
+                default:
+                    if r.Metrics == nil {
+                        r.Metrics = make(map[string]float64)
+                    }
+                    r.Metrics[unit] += v
+                }
+            }
+        }
+    }
+    for _, r := range results {
+        n := float64(r.Runs)
+        r.Iterations /= int64(r.Runs)
+        r.NsPerOp = roundMetric(r.NsPerOp / n)
+        if r.BytesPerOp != nil {
+            *r.BytesPerOp = roundMetric(*r.BytesPerOp / n)
+        }
+        if r.AllocsPerOp != nil {
+            *r.AllocsPerOp = roundMetric(*r.AllocsPerOp / n)
+        }
+        for unit, v := range r.Metrics {
+            r.Metrics[unit] = roundMetric(v / n)
+        }
+    }
+    sort.Slice(results, func(i, j int) bool {
+        if results[i].Package != results[j].Package {
+            return results[i].Package < results[j].Package
+        }
+        return results[i].Name < results[j].Name
+    })
+    return results
+}
+
+func addMetric(sum *float64, v float64) *float64 {
+    if sum == nil {
+        return &v
+    }
+    *sum += v
+    return sum
+}
+
+func roundMetric(v float64) float64 {
+    return math.Round(v*100) / 100
+}
+
+// attachBenchmarks читает результаты бенчмарков из path, находит функции
+// Benchmark* в _test.go и привязывает замеры к функциям проекта, которые
+// эти бенчмарки вызывают
+func attachBenchmarks(projectPath, path string, analysis *ProjectAnalysis) ([]BenchmarkResult, error) {
+    if !filepath.IsAbs(path) {
+        path = filepath.Join(projectPath, path)
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    results := parseBenchmarks(data)
+
+    tests := make(map[string][]*testFunc)
+    for _, test := range collectTestFuncs(projectPath) {
+        if test.kind == "benchmark" {
+            tests[test.name] = append(tests[test.name], test)
+        }
+    }
+    symbols := goSymbols(analysis, false)
+    stats := make(map[string][]BenchmarkStat)
+
+    out := make([]BenchmarkResult, 0, len(results))
+    for _, r := range results {
+        name, _, _ := strings.Cut(r.Name, "/")
+        dir := strings.TrimPrefix(strings.TrimPrefix(r.Package, analysis.ModuleName), "/")
+        if dir == "" {
+            dir = "."
+        }
+        var test *testFunc
+        for _, t := range tests[name] {
+            // Без строки "pkg: " пакет неизвестен - годится единственный кандидат
+            if t.dir == dir || r.Package == "" && len(tests[name]) == 1 {
+                test = t
+                break
+            }
+        }
+        if test != nil {
+            r.File, r.Line = test.file, test.line
+            for id, sym := range symbols {
+                if (sym.kind == "function" || sym.kind == "method") && test.references(sym) {
+                    r.Targets = append(r.Targets, id)
+                    stats[id] = append(stats[id], BenchmarkStat{Name: r.Name, NsPerOp: r.NsPerOp, AllocsPerOp: r.AllocsPerOp})
+                }
+            }
+            sort.Strings(r.Targets)
+        }
+        out = append(out, *r)
+    }
+
+    for i := range analysis.Files {
+        file := &analysis.Files[i]
+        pkgPath := packagePathOf(analysis, file.Path)
+        for j := range file.Functions {
+            fn := &file.Functions[j]
+            name := fn.Name
+            if fn.IsMethod {
+                name = receiverTypeName(fn.Receiver) + "." + fn.Name
+            }
+            if s := stats[goSymbolID(pkgPath, name)]; len(s) > 0 {
+                sort.Slice(s, func(a, b int) bool { return s[a].Name < s[b].Name })
+                fn.Benchmarks = s
+            }
+        }
+    }
+    return out, nil
+}
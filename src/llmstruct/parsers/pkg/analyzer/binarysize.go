@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "math"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+type SizeEntry struct {
+    Name         string   `json:"name"`
+    Size         int64    `json:"size"`
+    Symbols      int      `json:"symbols"`
+    Share        float64  `json:"share"`
+}
+
+// BinarySize - вклад модулей и пакетов в размер собранного бинарника по
+// таблице символов (`go tool nm -size`)
+type BinarySize struct {
+    Target       string      `json:"target"`
+    FileSize     int64       `json:"file_size"`
+    SymbolSize   int64       `json:"symbol_size"`
+    Modules      []SizeEntry `json:"modules"`
+    Packages     []SizeEntry `json:"packages"`
+}
+
+// Сколько крупнейших пакетов выводить
+const maxSizePackages = 50
+
+// symbolPackage извлекает путь пакета из имени символа Go:
+// "net/http.(*Server).Serve", "type:*example.com/x.T", "pkg.F[...]"
+func symbolPackage(name string) string {
+    name = strings.TrimPrefix(name, "type:")
+    name = strings.TrimLeft(name, "*")
+    if strings.HasPrefix(name, "go:") {
+        return ""
+    }
+    if i := strings.IndexAny(name, "[("); i >= 0 {
+        name = name[:i]
+    }
+    slash := strings.LastIndex(name, "/")
+    dot := strings.Index(name[slash+1:], ".")
+    if dot <= 0 {
+        return ""
+    }
+    return name[:slash+1+dot]
+}
+
+// sizeModule относит пакет к модулю: стандартная библиотека, проект или
+// зависимость из графа модулей
+func sizeModule(pkg, moduleName string, modules []ModuleInfo) string {
+    first, _, _ := strings.Cut(pkg, "/")
+    switch {
+    case pkg == "main" || moduleName != "" && hasPathPrefix(pkg, moduleName):
+        return moduleName
+    case !strings.Contains(first, "."):
+        return "std"
+    }
+    if m := moduleOf(modules, pkg); m != nil {
+        return m.Path
+    }
+    return pkg
+}
+
+func sizeEntries(sizes map[string]int64, counts map[string]int, total int64, limit int) []SizeEntry {
+    entries := []SizeEntry{}
+    for name, size := range sizes {
+        share := 0.0
+        if total > 0 {
+            share = math.Round(float64(size)/float64(total)*1000) / 1000
+        }
+        entries = append(entries, SizeEntry{Name: name, Size: size, Symbols: counts[name], Share: share})
+    }
+    sort.Slice(entries, func(i, j int) bool {
+        if entries[i].Size != entries[j].Size {
+            return entries[i].Size > entries[j].Size
+        }
+        return entries[i].Name < entries[j].Name
+    })
+    if limit > 0 && len(entries) > limit {
+        entries = entries[:limit]
+    }
+    return entries
+}
+
+// buildBinarySize собирает target во временный каталог и распределяет
+// размеры символов по пакетам и модулям; символы без пакета (служебные
+// таблицы, itab) попадают в "other"
+func buildBinarySize(projectPath, target, moduleName string, graph *ModuleGraph) (*BinarySize, error) {
+    dir, err := os.MkdirTemp("", "llmstruct-size-")
+    if err != nil {
+        return nil, err
+    }
+    defer os.RemoveAll(dir)
+    binary := filepath.Join(dir, "bin")
+    if _, err := runGo(projectPath, "build", "-o", binary, target); err != nil {
+        return nil, err
+    }
+    info, err := os.Stat(binary)
+    if err != nil {
+        return nil, err
+    }
+    out, err := runGo(projectPath, "tool", "nm", "-size", binary)
+    if err != nil {
+        return nil, err
+    }
+
+    var modules []ModuleInfo
+    if graph != nil {
+        modules = graph.Modules
+    }
+    report := &BinarySize{Target: target, FileSize: info.Size()}
+    pkgSizes, modSizes := make(map[string]int64), make(map[string]int64)
+    pkgCounts, modCounts := make(map[string]int), make(map[string]int)
+    sc := bufio.NewScanner(bytes.NewReader(out))
+    sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    for sc.Scan() {
+        // адрес размер тип имя
+        fields := strings.Fields(sc.Text())
+        if len(fields) < 4 {
+            continue
+        }
+        size, err := strconv.ParseInt(fields[1], 10, 64)
+        // U - внешние ссылки, B/b - неинициализированные данные (BSS),
+        // которые не занимают места в файле
+        if err != nil || size == 0 || fields[2] == "U" || fields[2] == "B" || fields[2] == "b" {
+            continue
+        }
+        pkg := symbolPackage(strings.Join(fields[3:], " "))
+        mod := "other"
+        if pkg == "" {
+            pkg = "other"
+        } else {
+            mod = sizeModule(pkg, moduleName, modules)
+        }
+        report.SymbolSize += size
+        pkgSizes[pkg] += size
+        pkgCounts[pkg]++
+        modSizes[mod] += size
+        modCounts[mod]++
+    }
+    report.Modules = sizeEntries(modSizes, modCounts, report.SymbolSize, 0)
+    report.Packages = sizeEntries(pkgSizes, pkgCounts, report.SymbolSize, maxSizePackages)
+    return report, sc.Err()
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+    "fmt"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+)
+
+// ChangeCommit - коммит между базовой ревизией и HEAD, менявший символы
+// из diff
+type ChangeCommit struct {
+    SHA          string    `json:"sha"`
+    Author       string    `json:"author"`
+    Date         time.Time `json:"date"`
+    Message      string    `json:"message"`
+}
+
+// symbolCommits возвращает коммиты base..HEAD, менявшие строки from-to
+// файла (git log -L). Если диапазон не совпадает с HEAD (рабочее дерево
+// изменено) или символ удалён, берутся коммиты, менявшие файл целиком
+func symbolCommits(projectPath, base, file string, from, to int) ([]ChangeCommit, error) {
+    format := "--format=%H%x00%an%x00%aI%x00%s"
+    var out []byte
+    var err error
+    if from > 0 {
+        out, err = runGit(projectPath, "log", "-s", format, fmt.Sprintf("-L%d,%d:%s", from, to, filepath.ToSlash(file)), base+"..HEAD")
+    }
+    if from == 0 || err != nil {
+        out, err = runGit(projectPath, "log", format, base+"..HEAD", "--", filepath.ToSlash(file))
+        if err != nil {
+            return nil, err
+        }
+    }
+    var commits []ChangeCommit
+    for _, line := range strings.Split(string(out), "\n") {
+        parts := strings.SplitN(line, "\x00", 4)
+        if len(parts) != 4 {
+            continue
+        }
+        date, _ := time.Parse(time.RFC3339, parts[2])
+        commits = append(commits, ChangeCommit{SHA: parts[0], Author: parts[1], Date: date, Message: parts[3]})
+    }
+    return commits, nil
+}
+
+// attachBlame проставляет изменениям API коммиты, которые их внесли, и
+// собирает эти коммиты в diff.Commits. Изменения, не попавшие ни в один
+// коммит, сделаны в рабочем дереве
+func attachBlame(projectPath string, diff *AnalysisDiff, base, head *ProjectAnalysis) error {
+    before, after := goSymbols(base, true), goSymbols(head, true)
+    byKey := make(map[string][]string)
+    seen := make(map[string]ChangeCommit)
+    for i := range diff.APIChanges {
+        change := &diff.APIChanges[i]
+        sym := after[change.Symbol]
+        switch {
+        case sym == nil && change.Change != "removed" && change.After != "" && after[change.After] != nil:
+            // Переименование: символ под новым именем
+            sym = after[change.After]
+        case sym == nil && strings.HasSuffix(change.Kind, " member"):
+            // Член типа отслеживается по объявлению типа
+            sym = after[change.Symbol[:strings.LastIndex(change.Symbol, ".")]]
+        }
+        file, from, to := change.File, 0, 0
+        if sym != nil {
+            file, from, to = sym.file, sym.line, sym.endLine
+        } else if old := before[change.Symbol]; old != nil {
+            // Удалённый символ: только история файла
+            file = old.file
+        }
+        if file == "" {
+            continue
+        }
+        key := fmt.Sprintf("%s:%d-%d", file, from, to)
+        shas, ok := byKey[key]
+        if !ok {
+            commits, err := symbolCommits(projectPath, diff.BaseCommit, file, from, to)
+            if err != nil {
+                return err
+            }
+            for _, c := range commits {
+                shas = append(shas, c.SHA)
+                seen[c.SHA] = c
+            }
+            byKey[key] = shas
+        }
+        change.Commits = shas
+    }
+    for _, c := range seen {
+        diff.Commits = append(diff.Commits, c)
+    }
+    sort.Slice(diff.Commits, func(i, j int) bool {
+        a, b := diff.Commits[i], diff.Commits[j]
+        if !a.Date.Equal(b.Date) {
+            return a.Date.Before(b.Date)
+        }
+        return a.SHA < b.SHA
+    })
+    return nil
+}
@@ -0,0 +1,262 @@
+package analyzer
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "go/ast"
+    "go/token"
+    "go/types"
+    "log/slog"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// Версия формата записей кэша; меняется вместе с FileAnalysis
+const cacheFormat = "8"
+
+// CacheStats - попадания в кэш анализа файлов за запуск
+type CacheStats struct {
+    Dir          string   `json:"dir"`
+    Hits         int      `json:"hits"`
+    Misses       int      `json:"misses"`
+}
+
+// analysisCache хранит результат анализа файла (FileAnalysis до
+// проставления пути) по хэшу содержимого. Ключ включает версию Go,
+// сборку анализатора и опции, влияющие на разбор файла. Значения
+// констант в запись не входят: go/types вычисляет их и по другим файлам
+// (const Timeout = BaseTimeout * 2), они заполняются после чтения. Если включены
+// режимы, зависящие от типов из других файлов (-resolved-types,
+// -stdlib-calls, -func-deps), - ещё и отпечаток пакета со всеми его
+// зависимостями внутри проекта. Проверка типов при этом выполняется
+// заново: межфайловым разделам нужен весь проект
+type analysisCache struct {
+    dir         string
+    project     string
+    base        string
+    typed       bool
+    hashes      map[string]string
+    fingerprint map[string]string
+    stats       CacheStats
+    warned      bool
+}
+
+func hashBytes(data ...[]byte) string {
+    h := sha256.New()
+    for _, d := range data {
+        h.Write(d)
+        h.Write([]byte{0})
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// newAnalysisCache создаёт кэш в dir; nil - кэш отключён
+func newAnalysisCache(dir, projectPath string, opts Options, env *BuildEnvironment) (*analysisCache, error) {
+    if dir == "" {
+        return nil, nil
+    }
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return nil, err
+    }
+    c := &analysisCache{
+        dir:         dir,
+        project:     projectPath,
+        typed:       opts.ResolvedTypes || opts.StdlibCalls || opts.FuncDeps,
+        hashes:      make(map[string]string),
+        fingerprint: make(map[string]string),
+        stats:       CacheStats{Dir: dir},
+    }
+    parts := []string{cacheFormat}
+    if env != nil {
+        parts = append(parts, env.GoVersion, env.AnalyzerRuntime, env.GOOS, env.GOARCH)
+    }
+    // Пересобранный анализатор может выдавать другой результат
+    if exe, err := os.Executable(); err == nil {
+        if info, err := os.Stat(exe); err == nil {
+            parts = append(parts, fmt.Sprint(info.Size(), info.ModTime().UnixNano()))
+        }
+    }
+    parts = append(parts, fmt.Sprint(opts.ResolvedTypes, opts.StdlibCalls, opts.FuncDeps,
+        opts.Limits.MaxFileSize, opts.Limits.MaxSymbols, opts.Sample, opts.SampleRate, opts.Tokenizer))
+    if c.typed {
+        // Версии внешних зависимостей влияют на выведенные типы
+        for _, name := range []string{"go.mod", "go.sum"} {
+            data, _ := os.ReadFile(filepath.Join(projectPath, name))
+            parts = append(parts, hashBytes(data))
+        }
+    }
+    c.base = hashBytes([]byte(strings.Join(parts, "\n")))
+    return c, nil
+}
+
+// fileHash читает файл ещё раз: загрузчик не отдаёт исходный текст, а
+// чтение много дешевле разбора
+func (c *analysisCache) fileHash(filename string) string {
+    if sum, ok := c.hashes[filename]; ok {
+        return sum
+    }
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return ""
+    }
+    sum := hashBytes(data)
+    c.hashes[filename] = sum
+    return sum
+}
+
+// packageFingerprint - хэш файлов пакета и пакетов проекта, которые он
+// импортирует (транзитивно); внешние пакеты учитываются по go.sum
+func (c *analysisCache) packageFingerprint(pkg *packages.Package) string {
+    if fp, ok := c.fingerprint[pkg.PkgPath]; ok {
+        return fp
+    }
+    // Защита от циклов импорта в некомпилируемом коде
+    c.fingerprint[pkg.PkgPath] = ""
+    var parts []string
+    parts = append(parts, pkg.PkgPath)
+    files := append([]string(nil), pkg.CompiledGoFiles...)
+    sort.Strings(files)
+    for _, filename := range files {
+        parts = append(parts, c.fileHash(filename))
+    }
+    var imports []string
+    for path := range pkg.Imports {
+        imports = append(imports, path)
+    }
+    sort.Strings(imports)
+    for _, path := range imports {
+        imp := pkg.Imports[path]
+        if c.inProject(imp) {
+            parts = append(parts, path+"="+c.packageFingerprint(imp))
+        } else {
+            parts = append(parts, path)
+        }
+    }
+    fp := hashBytes([]byte(strings.Join(parts, "\n")))
+    c.fingerprint[pkg.PkgPath] = fp
+    return fp
+}
+
+func (c *analysisCache) inProject(pkg *packages.Package) bool {
+    return len(pkg.CompiledGoFiles) > 0 && hasPathPrefix(pkg.CompiledGoFiles[0], c.project)
+}
+
+// key - ключ записи файла; пустая строка - файл не кэшируется
+func (c *analysisCache) key(pkg *packages.Package, filename string) string {
+    if c == nil {
+        return ""
+    }
+    sum := c.fileHash(filename)
+    if sum == "" {
+        return ""
+    }
+    rel := relativePath(c.project, filename)
+    parts := []string{c.base, pkg.PkgPath, filepath.ToSlash(rel), sum}
+    if c.typed {
+        parts = append(parts, c.packageFingerprint(pkg))
+    }
+    return hashBytes([]byte(strings.Join(parts, "\n")))
+}
+
+func (c *analysisCache) path(key string) string {
+    return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// load возвращает сохранённый анализ файла; повреждённая запись - промах
+func (c *analysisCache) load(key string) (FileAnalysis, bool) {
+    var analysis FileAnalysis
+    if key == "" {
+        return analysis, false
+    }
+    data, err := os.ReadFile(c.path(key))
+    if err == nil && json.Unmarshal(data, &analysis) == nil {
+        c.stats.Hits++
+        return analysis, true
+    }
+    c.stats.Misses++
+    return FileAnalysis{}, false
+}
+
+// store сохраняет анализ файла. Запись не синхронизируется на диск:
+// оборванная запись при следующем запуске окажется промахом
+func (c *analysisCache) store(key string, analysis FileAnalysis) {
+    if key == "" {
+        return
+    }
+    analysis.Path = ""
+    // Копия, а не append к nil: пустой список должен остаться [], как без кэша
+    constants := make([]Variable, len(analysis.Constants))
+    copy(constants, analysis.Constants)
+    analysis.Constants = constants
+    for i := range analysis.Constants {
+        analysis.Constants[i].Value, analysis.Constants[i].ValueKind = "", ""
+    }
+    data, err := json.Marshal(analysis)
+    if err == nil {
+        err = c.write(c.path(key), data)
+    }
+    if err != nil && !c.warned {
+        c.warned = true
+        slog.Warn("analysis cache write failed", "dir", c.dir, "error", err)
+    }
+}
+
+// fillConstants заполняет значения констант записи из кэша по текущей
+// проверке типов; константы идут в порядке объявлений файла
+func fillConstants(pkg *packages.Package, file *ast.File, analysis *FileAnalysis) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    i := 0
+    for _, decl := range file.Decls {
+        gd, ok := decl.(*ast.GenDecl)
+        if !ok || gd.Tok != token.CONST {
+            continue
+        }
+        for _, spec := range gd.Specs {
+            for _, name := range spec.(*ast.ValueSpec).Names {
+                if i >= len(analysis.Constants) {
+                    return
+                }
+                if c, ok := pkg.TypesInfo.Defs[name].(*types.Const); ok && analysis.Constants[i].Name == name.Name {
+                    analysis.Constants[i].Value, analysis.Constants[i].ValueKind = constantValue(c.Val())
+                }
+                i++
+            }
+        }
+    }
+}
+
+func (c *analysisCache) write(path string, data []byte) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    tmp, err := createAtomic(path)
+    if err != nil {
+        return err
+    }
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        os.Remove(tmp.Name())
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        os.Remove(tmp.Name())
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
+
+func (c *analysisCache) result() *CacheStats {
+    if c == nil {
+        return nil
+    }
+    stats := c.stats
+    return &stats
+}
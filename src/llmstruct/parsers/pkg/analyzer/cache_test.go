@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+    "bytes"
+    "context"
+    "testing"
+)
+
+func TestCacheWarmRunMatchesColdRun(t *testing.T) {
+    project := writeTestProject(t, map[string]string{
+        "go.mod": "module example.com/cached\n\ngo 1.21\n",
+        "plain/plain.go": `package plain
+
+// Hello says hello.
+func Hello() string { return "hello" }
+`,
+        "consts/consts.go": `package consts
+
+const (
+    Base    = 3
+    Timeout = Base * 2
+    Name    = "svc"
+)
+`,
+    })
+    opts := testOptions(t)
+    opts.CacheDir = t.TempDir()
+
+    run := func() ([]byte, *ProjectAnalysis) {
+        analysis := analyzeProject(context.Background(), project, opts)
+        meta := analysis.Meta
+        analysis.Meta = nil
+        data, err := renderResult(analysis, "yaml", false)
+        if err != nil {
+            t.Fatal(err)
+        }
+        analysis.Meta = meta
+        return data, analysis
+    }
+    cold, _ := run()
+    warm, analysis := run()
+    if analysis.Meta == nil || analysis.Meta.Cache == nil || analysis.Meta.Cache.Hits != 2 {
+        t.Fatalf("warm run cache stats = %+v, want 2 hits", analysis.Meta)
+    }
+    if !bytes.Equal(cold, warm) {
+        t.Errorf("warm run differs from cold run\ncold:\n%s\nwarm:\n%s", cold, warm)
+    }
+    // Значения констант берутся из текущей проверки типов, а не из кэша
+    for _, file := range analysis.Files {
+        for _, c := range file.Constants {
+            if c.Name == "Timeout" && c.Value != "6" {
+                t.Errorf("Timeout value = %q, want 6", c.Value)
+            }
+        }
+    }
+}
+
+func TestCacheStoreLoadRoundTrip(t *testing.T) {
+    cache, err := newAnalysisCache(t.TempDir(), t.TempDir(), testOptions(t), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    analysis := FileAnalysis{
+        Path:      "svc/svc.go",
+        Package:   "svc",
+        Functions: []Function{{Name: "Run", Line: 3, EndLine: 5}},
+        Constants: []Variable{{Name: "Timeout", Type: "int", Line: 1, IsConstant: true, Value: "6", ValueKind: "int"}},
+    }
+    cache.store("abcd", analysis)
+    // store работает с копией: значения констант у вызывающего остаются
+    if analysis.Constants[0].Value != "6" || analysis.Path != "svc/svc.go" {
+        t.Errorf("store modified its argument: %+v", analysis)
+    }
+
+    got, ok := cache.load("abcd")
+    if !ok {
+        t.Fatal("stored entry is a miss")
+    }
+    if got.Path != "" || got.Package != "svc" || len(got.Functions) != 1 || got.Functions[0].Name != "Run" {
+        t.Errorf("loaded %+v", got)
+    }
+    // Значения констант в кэш не попадают: их заполняет fillConstants
+    if len(got.Constants) != 1 || got.Constants[0].Value != "" || got.Constants[0].ValueKind != "" {
+        t.Errorf("loaded constants %+v", got.Constants)
+    }
+
+    cache.store("abce", FileAnalysis{Constants: []Variable{}})
+    if got, _ := cache.load("abce"); got.Constants == nil {
+        t.Error("empty constants load as nil")
+    }
+    if _, ok := cache.load("ffff"); ok {
+        t.Error("missing entry is a hit")
+    }
+    if stats := cache.result(); stats.Hits != 2 || stats.Misses != 1 {
+        t.Errorf("stats = %+v, want 2 hits and 1 miss", stats)
+    }
+}
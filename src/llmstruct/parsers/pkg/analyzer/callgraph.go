@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+    "sort"
+    "strings"
+)
+
+// CallEdge - вызов функции проекта; для метода интерфейса Dynamic = true,
+// а Targets - методы типов проекта, реализующих интерфейс
+type CallEdge struct {
+    Callee       string   `json:"callee"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Dynamic      bool     `json:"dynamic,omitempty"`
+    Targets      []string `json:"targets,omitempty"`
+}
+
+// CallGraphNode - функция и её исходящие вызовы; место вызова - первое
+// в теле функции
+type CallGraphNode struct {
+    Function     string     `json:"function"`
+    File         string     `json:"file"`
+    Line         int        `json:"line"`
+    Calls        []CallEdge `json:"calls"`
+}
+
+// buildCallGraph собирает рёбра caller -> callee из индекса ссылок,
+// построенного по информации о типах
+func buildCallGraph(analysis *ProjectAnalysis) []CallGraphNode {
+    refs := analysis.refs
+    if refs == nil {
+        return nil
+    }
+    symbols := goSymbols(analysis, false)
+    var nodes []CallGraphNode
+    byFunction := make(map[string]int)
+    // refs отсортированы по from, поэтому рёбра одной функции идут подряд
+    for _, ref := range refs.refs {
+        if ref.kind != "call" {
+            continue
+        }
+        i, ok := byFunction[ref.from]
+        if !ok {
+            node := CallGraphNode{Function: ref.from, Calls: []CallEdge{}}
+            if sym := symbols[ref.from]; sym != nil {
+                node.File, node.Line = sym.file, sym.line
+            }
+            i = len(nodes)
+            byFunction[ref.from] = i
+            nodes = append(nodes, node)
+        }
+        edge := CallEdge{Callee: ref.to, File: ref.file, Line: ref.line}
+        if sym := symbols[ref.to]; sym == nil {
+            // Метода интерфейса нет среди символов верхнего уровня
+            dot := strings.LastIndex(ref.to, ".")
+            ifaceID, method := ref.to[:dot], ref.to[dot+1:]
+            if iface := symbols[ifaceID]; iface != nil && iface.kind == "interface" {
+                edge.Dynamic = true
+                for _, typeID := range refs.implementedBy[ifaceID] {
+                    if symbols[typeID+"."+method] != nil {
+                        edge.Targets = append(edge.Targets, typeID+"."+method)
+                    }
+                }
+                sort.Strings(edge.Targets)
+            }
+        }
+        nodes[i].Calls = append(nodes[i].Calls, edge)
+    }
+    return nodes
+}
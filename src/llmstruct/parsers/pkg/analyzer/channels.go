@@ -0,0 +1,276 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type ChannelNode struct {
+    ID           string   `json:"id"`
+    Type         string   `json:"type"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Producers    []string `json:"producers"`
+    Consumers    []string `json:"consumers"`
+    Closers      []string `json:"closers"`
+}
+
+type ChannelOp struct {
+    Function     string   `json:"function"`
+    Channel      string   `json:"channel"`
+    Op           string   `json:"op"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+}
+
+type ChannelGraph struct {
+    Channels     []ChannelNode `json:"channels"`
+    Ops          []ChannelOp   `json:"ops"`
+}
+
+type channelOp struct {
+    obj      types.Object
+    op       ChannelOp
+}
+
+// channelGraphBuilder объединяет переменные-каналы, связанные
+// присваиваниями и передачей в параметры (flow-insensitive)
+type channelGraphBuilder struct {
+    projectPath string
+    fset        *token.FileSet
+    parent      map[types.Object]types.Object
+    ops         []channelOp
+}
+
+func newChannelGraphBuilder(projectPath string) *channelGraphBuilder {
+    return &channelGraphBuilder{
+        projectPath: projectPath,
+        parent:      make(map[types.Object]types.Object),
+    }
+}
+
+// channelRank: глобальные переменные и поля именуют канал лучше локальных
+func channelRank(obj types.Object) int {
+    v, ok := obj.(*types.Var)
+    switch {
+    case ok && v.IsField():
+        return 1
+    case obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope():
+        return 2
+    default:
+        return 0
+    }
+}
+
+func (b *channelGraphBuilder) find(obj types.Object) types.Object {
+    for {
+        p, ok := b.parent[obj]
+        if !ok || p == obj {
+            return obj
+        }
+        obj = p
+    }
+}
+
+func (b *channelGraphBuilder) union(a, c types.Object) {
+    ra, rc := b.find(a), b.find(c)
+    if ra == rc {
+        return
+    }
+    if channelRank(rc) > channelRank(ra) {
+        ra, rc = rc, ra
+    }
+    b.parent[ra] = ra
+    b.parent[rc] = ra
+}
+
+func isChanType(t types.Type) bool {
+    if t == nil {
+        return false
+    }
+    _, ok := t.Underlying().(*types.Chan)
+    return ok
+}
+
+// varObject возвращает переменную или поле, которое обозначает выражение
+func varObject(info *types.Info, expr ast.Expr) types.Object {
+    switch e := expr.(type) {
+    case *ast.ParenExpr:
+        return varObject(info, e.X)
+    case *ast.Ident:
+        if obj, ok := info.ObjectOf(e).(*types.Var); ok {
+            return obj
+        }
+    case *ast.SelectorExpr:
+        if sel, ok := info.Selections[e]; ok {
+            return sel.Obj()
+        }
+        if obj, ok := info.Uses[e.Sel].(*types.Var); ok {
+            return obj
+        }
+    }
+    return nil
+}
+
+func (b *channelGraphBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    info := pkg.TypesInfo
+    b.fset = pkg.Fset
+
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            record := func(expr ast.Expr, op string, pos token.Pos) {
+                obj := varObject(info, expr)
+                if obj == nil {
+                    return
+                }
+                p := pkg.Fset.Position(pos)
+                b.ops = append(b.ops, channelOp{
+                    obj: obj,
+                    op: ChannelOp{
+                        Function: funcID(pkg, decl),
+                        Op:       op,
+                        File:     relativePath(b.projectPath, p.Filename),
+                        Line:     p.Line,
+                    },
+                })
+            }
+
+            switch node := n.(type) {
+            case *ast.SendStmt:
+                record(node.Chan, "send", node.Pos())
+            case *ast.UnaryExpr:
+                if node.Op == token.ARROW {
+                    record(node.X, "receive", node.Pos())
+                }
+            case *ast.RangeStmt:
+                if tv, ok := info.Types[node.X]; ok && isChanType(tv.Type) {
+                    record(node.X, "receive", node.Pos())
+                }
+            case *ast.AssignStmt:
+                // a := b для каналов связывает переменные
+                if len(node.Lhs) == len(node.Rhs) {
+                    for i, lhs := range node.Lhs {
+                        if tv, ok := info.Types[node.Rhs[i]]; !ok || !isChanType(tv.Type) {
+                            continue
+                        }
+                        l, r := varObject(info, lhs), varObject(info, node.Rhs[i])
+                        if l != nil && r != nil {
+                            b.union(l, r)
+                        }
+                    }
+                }
+            case *ast.CallExpr:
+                if id, ok := node.Fun.(*ast.Ident); ok && id.Name == "close" && len(node.Args) == 1 {
+                    if _, ok := info.Uses[id].(*types.Builtin); ok {
+                        record(node.Args[0], "close", node.Pos())
+                        return true
+                    }
+                }
+                // Передача канала в параметр функции
+                fn := calleeFunc(info, node)
+                if fn == nil {
+                    return true
+                }
+                sig := fn.Type().(*types.Signature)
+                for i, arg := range node.Args {
+                    if i >= sig.Params().Len() || (sig.Variadic() && i >= sig.Params().Len()-1) {
+                        break
+                    }
+                    if tv, ok := info.Types[arg]; !ok || !isChanType(tv.Type) {
+                        continue
+                    }
+                    if obj := varObject(info, arg); obj != nil {
+                        b.union(sig.Params().At(i), obj)
+                    }
+                }
+            case *ast.KeyValueExpr:
+                // Worker{jobs: ch} связывает поле и переменную
+                key, ok := node.Key.(*ast.Ident)
+                if !ok {
+                    return true
+                }
+                if tv, ok := info.Types[node.Value]; !ok || !isChanType(tv.Type) {
+                    return true
+                }
+                field, _ := info.Uses[key].(*types.Var)
+                if obj := varObject(info, node.Value); field != nil && obj != nil {
+                    b.union(field, obj)
+                }
+            }
+            return true
+        })
+    }
+}
+
+func (b *channelGraphBuilder) channelID(obj types.Object) string {
+    if channelRank(obj) == 2 {
+        return obj.Pkg().Path() + "." + obj.Name()
+    }
+    p := b.fset.Position(obj.Pos())
+    return fmt.Sprintf("%s (%s:%d)", obj.Name(), relativePath(b.projectPath, p.Filename), p.Line)
+}
+
+func appendUnique(list []string, value string) []string {
+    for _, v := range list {
+        if v == value {
+            return list
+        }
+    }
+    return append(list, value)
+}
+
+func (b *channelGraphBuilder) build() *ChannelGraph {
+    if len(b.ops) == 0 {
+        return nil
+    }
+
+    graph := &ChannelGraph{Channels: []ChannelNode{}, Ops: []ChannelOp{}}
+    nodes := make(map[types.Object]*ChannelNode)
+    var order []types.Object
+
+    for _, op := range b.ops {
+        root := b.find(op.obj)
+        node, ok := nodes[root]
+        if !ok {
+            p := b.fset.Position(root.Pos())
+            node = &ChannelNode{
+                ID:        b.channelID(root),
+                Type:      types.TypeString(root.Type(), nil),
+                File:      relativePath(b.projectPath, p.Filename),
+                Line:      p.Line,
+                Producers: []string{},
+                Consumers: []string{},
+                Closers:   []string{},
+            }
+            nodes[root] = node
+            order = append(order, root)
+        }
+
+        op.op.Channel = node.ID
+        graph.Ops = append(graph.Ops, op.op)
+        switch op.op.Op {
+        case "send":
+            node.Producers = appendUnique(node.Producers, op.op.Function)
+        case "receive":
+            node.Consumers = appendUnique(node.Consumers, op.op.Function)
+        case "close":
+            node.Closers = appendUnique(node.Closers, op.op.Function)
+        }
+    }
+
+    for _, root := range order {
+        graph.Channels = append(graph.Channels, *nodes[root])
+    }
+    sort.SliceStable(graph.Channels, func(i, j int) bool {
+        return graph.Channels[i].ID < graph.Channels[j].ID
+    })
+    return graph
+}
@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// ScopeInfo - ограничение анализа владельцем из CODEOWNERS: пакеты
+// владельца и пакеты проекта, которые они импортируют напрямую
+type ScopeInfo struct {
+    Scope        string   `json:"scope"`
+    CodeOwners   string   `json:"codeowners"`
+    Owned        []string `json:"owned"`
+    Dependencies []string `json:"dependencies"`
+}
+
+type codeOwnersRule struct {
+    pattern []string
+    owners  []string
+}
+
+// Расположения CODEOWNERS в порядке поиска GitHub и GitLab
+var codeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// parseScope разбирает "team:payments" или "owner:@alice"
+func parseScope(scope string) (kind, name string, err error) {
+    kind, name, ok := strings.Cut(scope, ":")
+    if !ok || name == "" || kind != "team" && kind != "owner" {
+        return "", "", fmt.Errorf("invalid scope %q (expected team:<name> or owner:<owner>)", scope)
+    }
+    return kind, name, nil
+}
+
+// codeOwnersPattern переводит шаблон CODEOWNERS (синтаксис gitignore) в
+// шаблон globMatch: без "/" в начале или середине он действует на любой
+// глубине, каталог захватывает всё содержимое
+func codeOwnersPattern(pattern string) []string {
+    anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+    pattern = strings.Trim(pattern, "/")
+    if !anchored {
+        pattern = "**/" + pattern
+    }
+    return strings.Split(pattern, "/")
+}
+
+func readCodeOwners(projectPath string) (string, []codeOwnersRule, error) {
+    for _, name := range codeOwnersFiles {
+        f, err := os.Open(filepath.Join(projectPath, name))
+        if err != nil {
+            continue
+        }
+        defer f.Close()
+        var rules []codeOwnersRule
+        scanner := bufio.NewScanner(f)
+        for scanner.Scan() {
+            line := strings.TrimSpace(scanner.Text())
+            // Секции GitLab ([Section]) задают владельцев по умолчанию,
+            // которые здесь не нужны
+            if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+                continue
+            }
+            fields := strings.Fields(line)
+            var owners []string
+            for _, owner := range fields[1:] {
+                if strings.HasPrefix(owner, "#") {
+                    break
+                }
+                owners = append(owners, owner)
+            }
+            rules = append(rules, codeOwnersRule{pattern: codeOwnersPattern(fields[0]), owners: owners})
+        }
+        return name, rules, scanner.Err()
+    }
+    return "", nil, fmt.Errorf("no CODEOWNERS file (looked in %s)", strings.Join(codeOwnersFiles, ", "))
+}
+
+// fileOwners - владельцы файла: действует последнее совпавшее правило
+func fileOwners(rules []codeOwnersRule, rel string) []string {
+    name := strings.Split(filepath.ToSlash(rel), "/")
+    for i := len(rules) - 1; i >= 0; i-- {
+        if globMatch(rules[i].pattern, name, true) {
+            return rules[i].owners
+        }
+    }
+    return nil
+}
+
+// ownerMatches: team:payments совпадает с @org/payments и @payments,
+// owner: - с владельцем, записанным в CODEOWNERS буквально
+func ownerMatches(kind, name, owner string) bool {
+    if kind == "owner" {
+        return strings.EqualFold(owner, name)
+    }
+    owner = strings.TrimPrefix(owner, "@")
+    return strings.EqualFold(owner, name) || strings.EqualFold(owner[strings.LastIndex(owner, "/")+1:], name)
+}
+
+// applyScope оставляет пакеты, хотя бы одним файлом принадлежащие
+// владельцу, и пакеты проекта, которые они импортируют
+func applyScope(projectPath, scope string, pkgs []*packages.Package) ([]*packages.Package, *ScopeInfo, []SkippedInput, error) {
+    kind, name, err := parseScope(scope)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+    file, rules, err := readCodeOwners(projectPath)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+    info := &ScopeInfo{Scope: scope, CodeOwners: file, Owned: []string{}, Dependencies: []string{}}
+    owned := make(map[string]bool)
+    for _, pkg := range pkgs {
+        for _, filename := range pkg.CompiledGoFiles {
+            rel, err := filepath.Rel(projectPath, filename)
+            if err != nil {
+                continue
+            }
+            for _, owner := range fileOwners(rules, rel) {
+                if ownerMatches(kind, name, owner) {
+                    owned[pkg.PkgPath] = true
+                }
+            }
+        }
+    }
+    ring := make(map[string]bool)
+    for _, pkg := range pkgs {
+        if !owned[pkg.PkgPath] {
+            continue
+        }
+        for path := range pkg.Imports {
+            if !owned[path] {
+                ring[path] = true
+            }
+        }
+    }
+    var kept []*packages.Package
+    var skipped []SkippedInput
+    for _, pkg := range pkgs {
+        switch {
+        case owned[pkg.PkgPath]:
+            info.Owned = appendUnique(info.Owned, pkg.PkgPath)
+        case ring[pkg.PkgPath]:
+            info.Dependencies = appendUnique(info.Dependencies, pkg.PkgPath)
+        default:
+            skipped = append(skipped, SkippedInput{Path: pkg.PkgPath, Reason: "outside scope " + scope})
+            continue
+        }
+        kept = append(kept, pkg)
+    }
+    sort.Strings(info.Owned)
+    sort.Strings(info.Dependencies)
+    return kept, info, skipped, nil
+}
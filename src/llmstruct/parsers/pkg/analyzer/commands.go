@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+)
+
+// commands - подкоманды анализатора: analyzer <команда> [флаги] <путь>
+var commands = map[string]func(args []string){
+    "impact":          runImpact,
+    "review-pack":     runReviewPack,
+    "mcp":             runMCP,
+    "pack":            runPack,
+    "extract-module":  runExtractModule,
+    "analyze-module":  runAnalyzeModule,
+    "history":         runHistory,
+    "diff":            runDiff,
+    "serve":           runServe,
+    "testgen-targets": runTestgenTargets,
+    "scaffold":        runScaffold,
+    "edits":           runEdits,
+    "keygen":          runKeygen,
+    "verify":          runVerify,
+}
+
+// commandFlags - общие флаги подкоманд: логирование и вывод результата
+type commandFlags struct {
+    *flag.FlagSet
+    verbose    *bool
+    quiet      *bool
+    logFormat  *string
+    output     *string
+    format     *string
+    compact    *bool
+}
+
+func newCommandFlags(name, usage string) *commandFlags {
+    fs := flag.NewFlagSet(name, flag.ExitOnError)
+    fs.Usage = func() {
+        fmt.Fprintf(fs.Output(), "Usage: analyzer %s %s\n", name, usage)
+        fs.PrintDefaults()
+    }
+    return &commandFlags{
+        FlagSet:   fs,
+        verbose:   fs.Bool("v", false, "verbose logging (per-package progress)"),
+        quiet:     fs.Bool("q", false, "log warnings and errors only"),
+        logFormat: fs.String("log-format", "text", "log format: text or json"),
+        output:    fs.String("o", "", "write the result to `file` atomically instead of stdout"),
+        format:    fs.String("format", "json", "output format: json, yaml or pr-comment (Markdown, diffs only)"),
+        compact:   fs.Bool("compact", false, "emit compact JSON instead of indented"),
+    }
+}
+
+// parse разбирает аргументы, настраивает логирование и возвращает
+// абсолютный путь проекта (по умолчанию текущий каталог)
+func (c *commandFlags) parse(args []string) string {
+    c.Parse(args)
+    if err := setupLogger(*c.verbose, *c.quiet, *c.logFormat); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+    if err := validFormat(*c.format); err != nil {
+        fatal("invalid options", "error", err)
+    }
+    arg := "."
+    if c.NArg() > 0 {
+        arg = c.Arg(0)
+    }
+    projectPath, err := filepath.Abs(arg)
+    if err != nil {
+        fatal("invalid project path", "path", arg, "error", err)
+    }
+    return projectPath
+}
+
+func (c *commandFlags) write(result any) {
+    if err := writeResult(result, *c.output, *c.format, *c.compact); err != nil {
+        fatal("failed to write result", "path", *c.output, "error", err)
+    }
+}
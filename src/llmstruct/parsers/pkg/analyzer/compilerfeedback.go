@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "os/exec"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// HeapEscape - значение, которое по решению компилятора уходит в кучу
+type HeapEscape struct {
+    Line         int      `json:"line"`
+    Name         string   `json:"name"`
+    Kind         string   `json:"kind"`
+}
+
+// CompilerFeedback - решения компилятора по функции из `go build -gcflags=-m`
+type CompilerFeedback struct {
+    Inlinable    bool         `json:"inlinable"`
+    InlinedCalls []string     `json:"inlined_calls"`
+    Escapes      []HeapEscape `json:"escapes"`
+}
+
+var gcflagsLine = regexp.MustCompile(`^(.+\.go):(\d+):\d+: (.+)$`)
+
+type gcDecision struct {
+    line    int
+    message string
+}
+
+// runGcflags собирает пакеты проекта с -gcflags=-m и группирует сообщения
+// компилятора по файлам (пути относительно проекта). Ошибка сборки
+// возвращается вместе с тем, что удалось разобрать
+func runGcflags(projectPath string) (map[string][]gcDecision, error) {
+    cmd := exec.Command("go", "build", "-gcflags=-m", "./...")
+    // Для пакетов из кэша сборки go повторяет сохранённый вывод компилятора
+    cmd.Dir = projectPath
+    out, runErr := cmd.CombinedOutput()
+
+    decisions := make(map[string][]gcDecision)
+    sc := bufio.NewScanner(bytes.NewReader(out))
+    sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    for sc.Scan() {
+        m := gcflagsLine.FindStringSubmatch(sc.Text())
+        if m == nil {
+            continue
+        }
+        path := m[1]
+        if !filepath.IsAbs(path) {
+            path = filepath.Join(projectPath, path)
+        }
+        line, _ := strconv.Atoi(m[2])
+        file := relativePath(projectPath, path)
+        decisions[file] = append(decisions[file], gcDecision{line: line, message: m[3]})
+    }
+    if runErr != nil {
+        lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+        return decisions, fmt.Errorf("go build -gcflags=-m: %v: %s", runErr, lines[len(lines)-1])
+    }
+    return decisions, nil
+}
+
+// apply переносит сообщение компилятора в описание функции
+func (f *CompilerFeedback) apply(d gcDecision) {
+    msg := d.message
+    switch {
+    case strings.HasPrefix(msg, "can inline "):
+        f.Inlinable = true
+    case strings.HasPrefix(msg, "inlining call to "):
+        f.InlinedCalls = appendUnique(f.InlinedCalls, strings.TrimPrefix(msg, "inlining call to "))
+    case strings.HasPrefix(msg, "moved to heap: "):
+        f.Escapes = append(f.Escapes, HeapEscape{Line: d.line, Name: strings.TrimPrefix(msg, "moved to heap: "), Kind: "moved_to_heap"})
+    case strings.HasSuffix(msg, " escapes to heap"):
+        f.Escapes = append(f.Escapes, HeapEscape{Line: d.line, Name: strings.TrimSuffix(msg, " escapes to heap"), Kind: "escapes"})
+    case strings.HasPrefix(msg, "leaking param content: "):
+        f.Escapes = append(f.Escapes, HeapEscape{Line: d.line, Name: leakedParam(strings.TrimPrefix(msg, "leaking param content: ")), Kind: "leaking_param_content"})
+    case strings.HasPrefix(msg, "leaking param: "):
+        f.Escapes = append(f.Escapes, HeapEscape{Line: d.line, Name: leakedParam(strings.TrimPrefix(msg, "leaking param: ")), Kind: "leaking_param"})
+    }
+}
+
+// leakedParam отбрасывает уточнение "next to result ~r0 level=0"
+func leakedParam(s string) string {
+    name, _, _ := strings.Cut(s, " to ")
+    return name
+}
+
+// annotateCompilerFeedback добавляет функциям проекта решения об
+// инлайнинге и утечках в кучу по строкам их тел
+func annotateCompilerFeedback(projectPath string, files []FileAnalysis) error {
+    decisions, err := runGcflags(projectPath)
+    for i := range files {
+        file := &files[i]
+        for _, d := range decisions[filepath.ToSlash(file.Path)] {
+            for j := range file.Functions {
+                fn := &file.Functions[j]
+                if d.line < fn.Line || d.line > fn.EndLine {
+                    continue
+                }
+                if fn.Compiler == nil {
+                    fn.Compiler = &CompilerFeedback{InlinedCalls: []string{}, Escapes: []HeapEscape{}}
+                }
+                fn.Compiler.apply(d)
+                break
+            }
+        }
+    }
+    return err
+}
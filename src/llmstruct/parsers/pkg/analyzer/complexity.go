@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "sort"
+)
+
+// ComplexityStats - сводка цикломатической сложности функций файла или
+// пакета; MaxFunction - самая сложная функция (Type.Method для методов)
+type ComplexityStats struct {
+    Functions    int      `json:"functions"`
+    Total        int      `json:"total"`
+    Max          int      `json:"max"`
+    Average      float64  `json:"average"`
+    MaxFunction  string   `json:"max_function,omitempty"`
+}
+
+type PackageComplexity struct {
+    Package      string   `json:"package"`
+    ComplexityStats
+}
+
+// cyclomaticComplexity считает цикломатическую сложность по McCabe: 1 +
+// ветвления (if, for, range, case, select-case кроме default) + && и ||.
+// Вложенные функциональные литералы учитываются вместе с функцией
+func cyclomaticComplexity(body *ast.BlockStmt) int {
+    if body == nil {
+        return 1
+    }
+    complexity := 1
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+            complexity++
+        case *ast.CaseClause:
+            if x.List != nil {
+                complexity++
+            }
+        case *ast.CommClause:
+            if x.Comm != nil {
+                complexity++
+            }
+        case *ast.BinaryExpr:
+            if x.Op == token.LAND || x.Op == token.LOR {
+                complexity++
+            }
+        }
+        return true
+    })
+    return complexity
+}
+
+func (s *ComplexityStats) add(name string, complexity int) {
+    s.Functions++
+    s.Total += complexity
+    if complexity > s.Max {
+        s.Max, s.MaxFunction = complexity, name
+    }
+    s.Average = roundMetric(float64(s.Total) / float64(s.Functions))
+}
+
+// complexityName - имя функции в сводке
+func complexityName(fn Function) string {
+    if fn.IsMethod {
+        return receiverTypeName(fn.Receiver) + "." + fn.Name
+    }
+    return fn.Name
+}
+
+// fileComplexity сводит сложность функций файла; nil - функций нет
+func fileComplexity(functions []Function) *ComplexityStats {
+    if len(functions) == 0 {
+        return nil
+    }
+    stats := &ComplexityStats{}
+    for _, fn := range functions {
+        stats.add(complexityName(fn), fn.Complexity)
+    }
+    return stats
+}
+
+// packageComplexity сводит сложность по пакетам без учёта тестов; сводки
+// файлов уже посчитаны до усечения списков символов
+func packageComplexity(analysis *ProjectAnalysis) []PackageComplexity {
+    byPackage := make(map[string]*PackageComplexity)
+    for _, file := range analysis.Files {
+        if file.HasTests || file.Complexity == nil {
+            continue
+        }
+        pkg := packagePathOf(analysis, file.Path)
+        p := byPackage[pkg]
+        if p == nil {
+            p = &PackageComplexity{Package: pkg}
+            byPackage[pkg] = p
+        }
+        fc := file.Complexity
+        p.Functions += fc.Functions
+        p.Total += fc.Total
+        if fc.Max > p.Max {
+            p.Max, p.MaxFunction = fc.Max, fc.MaxFunction
+        }
+        p.Average = roundMetric(float64(p.Total) / float64(p.Functions))
+    }
+    result := make([]PackageComplexity, 0, len(byPackage))
+    for _, p := range byPackage {
+        result = append(result, *p)
+    }
+    sort.Slice(result, func(i, j int) bool { return result[i].Package < result[j].Package })
+    return result
+}
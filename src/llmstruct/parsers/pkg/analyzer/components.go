@@ -0,0 +1,209 @@
+package analyzer
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+)
+
+// Component - группа пакетов; Internal - вес связей между её пакетами,
+// Cohesion - доля внутренних связей среди всех связей компонента
+type Component struct {
+    Name         string   `json:"name"`
+    Packages     []string `json:"packages"`
+    Internal     int      `json:"internal"`
+    Cohesion     float64  `json:"cohesion"`
+}
+
+// ComponentEdge - зависимость между компонентами; Weight - число обращений
+// к символам, Imports - число импортирующих пар пакетов
+type ComponentEdge struct {
+    From         string   `json:"from"`
+    To           string   `json:"to"`
+    Weight       int      `json:"weight"`
+    Imports      int      `json:"imports"`
+}
+
+// ComponentGraph - предполагаемые границы сервисов/компонентов: карта
+// архитектуры крупного репозитория
+type ComponentGraph struct {
+    Components   []Component     `json:"components"`
+    Edges        []ComponentEdge `json:"edges"`
+}
+
+// Каталоги-контейнеры: компонент - следующий уровень под ними
+var containerDirs = map[string]bool{
+    "cmd": true, "internal": true, "pkg": true, "services": true, "apps": true,
+    "components": true, "modules": true, "libs": true,
+}
+
+// Компоненты одного контейнера сливаются, если доля связей между ними
+// не меньше этого порога
+const componentMergeCoupling = 0.5
+
+// componentRoot - начальный компонент пакета по структуре каталогов
+func componentRoot(moduleName, pkgPath string) string {
+    rel := strings.TrimPrefix(strings.TrimPrefix(pkgPath, moduleName), "/")
+    if rel == "" {
+        return "."
+    }
+    parts := strings.Split(rel, "/")
+    n := 1
+    for n < len(parts) && containerDirs[parts[n-1]] {
+        n++
+    }
+    return strings.Join(parts[:n], "/")
+}
+
+func componentParent(name string) string {
+    if i := strings.LastIndex(name, "/"); i >= 0 {
+        return name[:i]
+    }
+    return ""
+}
+
+// buildComponentGraph группирует пакеты по каталогам, затем сливает
+// компоненты одного контейнера с плотными взаимными импортами и считает
+// веса рёбер между итоговыми компонентами
+func buildComponentGraph(moduleName string, imports *importCycleBuilder) *ComponentGraph {
+    // Импорт без обращений к символам (побочный эффект) весит 1
+    weight := func(from, to string) int {
+        total := 0
+        for _, u := range imports.edges[from][to] {
+            total += u.uses
+        }
+        return max(total, 1)
+    }
+
+    members := make(map[string][]string)
+    of := make(map[string]string)
+    for pkg := range imports.project {
+        root := componentRoot(moduleName, pkg)
+        members[root] = append(members[root], pkg)
+        of[pkg] = root
+    }
+
+    // pairs[a][b] - суммарный вес рёбер из a в b (a == b - внутренние)
+    pairs := func() map[string]map[string]int {
+        w := make(map[string]map[string]int)
+        for from, targets := range imports.edges {
+            for to := range targets {
+                a, b := of[from], of[to]
+                if a == "" || b == "" {
+                    continue
+                }
+                if w[a] == nil {
+                    w[a] = make(map[string]int)
+                }
+                w[a][b] += weight(from, to)
+            }
+        }
+        return w
+    }
+
+    // Обход по именам: при равной связности сливается первая по имени
+    // пара, и результат не зависит от порядка обхода map
+    names := func() []string {
+        list := make([]string, 0, len(members))
+        for name := range members {
+            list = append(list, name)
+        }
+        sort.Strings(list)
+        return list
+    }
+
+    for {
+        w := pairs()
+        var bestA, bestB string
+        best := 0.0
+        sorted := names()
+        for _, a := range sorted {
+            for _, b := range sorted {
+                if a >= b || componentParent(a) != componentParent(b) {
+                    continue
+                }
+                between := w[a][b] + w[b][a]
+                if between == 0 {
+                    continue
+                }
+                coupling := float64(between) / float64(between+w[a][a]+w[b][b])
+                if coupling >= componentMergeCoupling && coupling > best {
+                    best, bestA, bestB = coupling, a, b
+                }
+            }
+        }
+        if bestA == "" {
+            break
+        }
+        // Имя остаётся за большим компонентом
+        keep, drop := bestA, bestB
+        if len(members[drop]) > len(members[keep]) {
+            keep, drop = drop, keep
+        }
+        for _, pkg := range members[drop] {
+            of[pkg] = keep
+        }
+        members[keep] = append(members[keep], members[drop]...)
+        delete(members, drop)
+    }
+
+    graph := &ComponentGraph{Components: []Component{}, Edges: []ComponentEdge{}}
+    w := pairs()
+    sorted := names()
+    for _, name := range sorted {
+        pkgs := members[name]
+        sort.Strings(pkgs)
+        c := Component{Name: name, Packages: pkgs, Internal: w[name][name]}
+        external := 0
+        for _, other := range sorted {
+            if other != name {
+                external += w[name][other] + w[other][name]
+            }
+        }
+        // Изолированный компонент связен по определению
+        c.Cohesion = 1
+        if total := c.Internal + external; total > 0 {
+            c.Cohesion = math.Round(float64(c.Internal)/float64(total)*100) / 100
+        }
+        graph.Components = append(graph.Components, c)
+    }
+    sort.Slice(graph.Components, func(i, j int) bool { return graph.Components[i].Name < graph.Components[j].Name })
+
+    counts := make(map[[2]string]int)
+    for from, targets := range imports.edges {
+        for to := range targets {
+            if a, b := of[from], of[to]; a != "" && b != "" && a != b {
+                counts[[2]string{a, b}]++
+            }
+        }
+    }
+    for edge, n := range counts {
+        graph.Edges = append(graph.Edges, ComponentEdge{From: edge[0], To: edge[1], Weight: w[edge[0]][edge[1]], Imports: n})
+    }
+    sort.Slice(graph.Edges, func(i, j int) bool {
+        if graph.Edges[i].From != graph.Edges[j].From {
+            return graph.Edges[i].From < graph.Edges[j].From
+        }
+        return graph.Edges[i].To < graph.Edges[j].To
+    })
+    return graph
+}
+
+// dot выводит граф компонентов в формате Graphviz; толщина ребра растёт
+// с его весом
+func (g *ComponentGraph) dot() string {
+    var sb strings.Builder
+    sb.WriteString("digraph components {\n")
+    sb.WriteString("    rankdir=LR;\n")
+    sb.WriteString("    node [shape=box];\n")
+    for _, c := range g.Components {
+        fmt.Fprintf(&sb, "    %q [label=%q];\n", c.Name, fmt.Sprintf("%s\n%d packages", c.Name, len(c.Packages)))
+    }
+    for _, e := range g.Edges {
+        width := 1 + math.Log2(float64(e.Weight))
+        fmt.Fprintf(&sb, "    %q -> %q [label=\"%d\", penwidth=%.1f];\n", e.From, e.To, e.Weight, width)
+    }
+    sb.WriteString("}\n")
+    return sb.String()
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+    "reflect"
+    "testing"
+)
+
+// chainImports - internal/a <-> b <-> c <-> d: все пары одинаково связаны
+func chainImports() *importCycleBuilder {
+    b := newImportCycleBuilder("", nil)
+    link := func(from, to string) {
+        from, to = "example.com/m/internal/"+from, "example.com/m/internal/"+to
+        b.project[from], b.project[to] = true, true
+        if b.edges[from] == nil {
+            b.edges[from] = make(map[string]map[string]*importUse)
+        }
+        b.edges[from][to] = map[string]*importUse{"F": {uses: 1}}
+    }
+    for _, pair := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}} {
+        link(pair[0], pair[1])
+        link(pair[1], pair[0])
+    }
+    return b
+}
+
+func TestComponentGraphMerge(t *testing.T) {
+    graph := buildComponentGraph("example.com/m", chainImports())
+
+    // При равной связности первой сливается пара a+b, затем c+d
+    want := []Component{
+        {Name: "internal/a", Packages: []string{"example.com/m/internal/a", "example.com/m/internal/b"}, Internal: 2, Cohesion: 0.5},
+        {Name: "internal/c", Packages: []string{"example.com/m/internal/c", "example.com/m/internal/d"}, Internal: 2, Cohesion: 0.5},
+    }
+    if !reflect.DeepEqual(graph.Components, want) {
+        t.Errorf("components = %+v, want %+v", graph.Components, want)
+    }
+    wantEdges := []ComponentEdge{
+        {From: "internal/a", To: "internal/c", Weight: 1, Imports: 1},
+        {From: "internal/c", To: "internal/a", Weight: 1, Imports: 1},
+    }
+    if !reflect.DeepEqual(graph.Edges, wantEdges) {
+        t.Errorf("edges = %+v, want %+v", graph.Edges, wantEdges)
+    }
+}
+
+func TestComponentGraphStable(t *testing.T) {
+    first := buildComponentGraph("example.com/m", chainImports())
+    for i := 0; i < 50; i++ {
+        if graph := buildComponentGraph("example.com/m", chainImports()); !reflect.DeepEqual(graph, first) {
+            t.Fatalf("run %d: %+v, first run: %+v", i, graph, first)
+        }
+    }
+}
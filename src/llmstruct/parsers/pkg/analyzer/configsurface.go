@@ -0,0 +1,261 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type ConfigFlag struct {
+    Name         string   `json:"name"`
+    Shorthand    string   `json:"shorthand,omitempty"`
+    Type         string   `json:"type"`
+    Default      string   `json:"default,omitempty"`
+    Usage        string   `json:"usage,omitempty"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+}
+
+type ConfigKey struct {
+    Key          string   `json:"key"`
+    Access       string   `json:"access"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+}
+
+type ConfigField struct {
+    Struct       string            `json:"struct"`
+    Field        string            `json:"field"`
+    Type         string            `json:"type"`
+    Tags         map[string]string `json:"tags"`
+    File         string            `json:"file"`
+    Line         int               `json:"line"`
+}
+
+type ConfigSurface struct {
+    Flags        []ConfigFlag  `json:"flags"`
+    EnvVars      []ConfigKey   `json:"env_vars"`
+    ViperKeys    []ConfigKey   `json:"viper_keys"`
+    StructFields []ConfigField `json:"struct_fields"`
+}
+
+// Теги, по которым поле структуры считается частью конфигурации
+var configTagKeys = []string{"mapstructure", "yaml", "toml", "env", "envconfig", "envDefault", "koanf", "default"}
+
+func newConfigSurface() *ConfigSurface {
+    return &ConfigSurface{
+        Flags:        []ConfigFlag{},
+        EnvVars:      []ConfigKey{},
+        ViperKeys:    []ConfigKey{},
+        StructFields: []ConfigField{},
+    }
+}
+
+func (s *ConfigSurface) empty() bool {
+    return len(s.Flags) == 0 && len(s.EnvVars) == 0 && len(s.ViperKeys) == 0 && len(s.StructFields) == 0
+}
+
+// flagDefinition разбирает вызов определения флага пакетов flag/pflag по
+// именам параметров сигнатуры: name, shorthand, value, usage
+func flagDefinition(info *types.Info, fn *types.Func, call *ast.CallExpr) (ConfigFlag, bool) {
+    sig, ok := fn.Type().(*types.Signature)
+    if !ok || sig.Params().Len() == 0 || sig.Params().Len() != len(call.Args) {
+        return ConfigFlag{}, false
+    }
+    if sig.Params().At(sig.Params().Len()-1).Name() != "usage" {
+        return ConfigFlag{}, false
+    }
+
+    def := ConfigFlag{}
+    hasName := false
+    for i := 0; i < sig.Params().Len(); i++ {
+        arg := call.Args[i]
+        switch sig.Params().At(i).Name() {
+        case "name":
+            name, ok := constString(info, arg)
+            if !ok {
+                name = types.ExprString(arg)
+            }
+            def.Name = name
+            hasName = true
+        case "shorthand":
+            def.Shorthand, _ = constString(info, arg)
+        case "value":
+            def.Default = types.ExprString(arg)
+            if tv, ok := info.Types[arg]; ok && tv.Value != nil {
+                def.Default = tv.Value.ExactString()
+            }
+        case "usage":
+            def.Usage, _ = constString(info, arg)
+        }
+    }
+
+    def.Type = flagType(fn.Name())
+    return def, hasName
+}
+
+// flagTypes - тип значения по имени функции определения флага без
+// суффиксов Var и P
+var flagTypes = map[string]string{
+    "Bool": "bool", "BoolSlice": "boolslice", "BoolFunc": "func", "Func": "func",
+    "BytesBase64": "bytesbase64", "BytesHex": "byteshex", "Count": "count",
+    "Duration": "duration", "DurationSlice": "durationslice",
+    "Float32": "float32", "Float32Slice": "float32slice", "Float64": "float64", "Float64Slice": "float64slice",
+    "Int": "int", "Int8": "int8", "Int16": "int16", "Int32": "int32", "Int64": "int64",
+    "IntSlice": "intslice", "Int32Slice": "int32slice", "Int64Slice": "int64slice",
+    "IP": "ip", "IPMask": "ipmask", "IPNet": "ipnet", "IPSlice": "ipslice",
+    "String": "string", "StringArray": "stringarray", "StringSlice": "stringslice",
+    "StringToInt": "stringtoint", "StringToInt64": "stringtoint64", "StringToString": "stringtostring",
+    "Text": "text",
+    "Uint": "uint", "Uint8": "uint8", "Uint16": "uint16", "Uint32": "uint32", "Uint64": "uint64", "UintSlice": "uintslice",
+}
+
+// flagType: IPVarP -> ip, StringVar -> string; Var и неизвестные функции -
+// value. Суффиксы снимаются по одному, чтобы IP не превратился в I
+func flagType(name string) string {
+    for _, candidate := range []string{name, strings.TrimSuffix(name, "P"), strings.TrimSuffix(strings.TrimSuffix(name, "P"), "Var")} {
+        if t, ok := flagTypes[candidate]; ok {
+            return t
+        }
+    }
+    return "value"
+}
+
+func viperAccess(fn *types.Func) bool {
+    if fn.Pkg() == nil || fn.Pkg().Path() != "github.com/spf13/viper" {
+        return false
+    }
+    name := fn.Name()
+    return strings.HasPrefix(name, "Get") || name == "IsSet" || name == "SetDefault" ||
+        name == "Set" || name == "BindEnv" || name == "BindPFlag" || name == "Sub" || name == "UnmarshalKey"
+}
+
+func extractConfigSurface(pkg *packages.Package, projectPath string, surface *ConfigSurface) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok {
+                return true
+            }
+
+            fn := calleeFunc(pkg.TypesInfo, call)
+            if fn == nil || fn.Pkg() == nil {
+                return true
+            }
+
+            pos := pkg.Fset.Position(call.Pos())
+            path := relativePath(projectPath, pos.Filename)
+
+            switch pkgPath := fn.Pkg().Path(); {
+            case pkgPath == "flag" || pkgPath == "github.com/spf13/pflag":
+                if def, ok := flagDefinition(pkg.TypesInfo, fn, call); ok {
+                    def.File = path
+                    def.Line = pos.Line
+                    def.Function = funcID(pkg, decl)
+                    surface.Flags = append(surface.Flags, def)
+                }
+            case (pkgPath == "os" || pkgPath == "syscall") && (fn.Name() == "Getenv" || fn.Name() == "LookupEnv"):
+                if len(call.Args) == 0 {
+                    return true
+                }
+                key, ok := constString(pkg.TypesInfo, call.Args[0])
+                if !ok {
+                    key = types.ExprString(call.Args[0])
+                }
+                surface.EnvVars = append(surface.EnvVars, ConfigKey{
+                    Key:      key,
+                    Access:   pkgPath + "." + fn.Name(),
+                    File:     path,
+                    Line:     pos.Line,
+                    Function: funcID(pkg, decl),
+                })
+            case viperAccess(fn):
+                if len(call.Args) == 0 {
+                    return true
+                }
+                key, ok := constString(pkg.TypesInfo, call.Args[0])
+                if !ok {
+                    return true
+                }
+                surface.ViperKeys = append(surface.ViperKeys, ConfigKey{
+                    Key:      key,
+                    Access:   fn.Name(),
+                    File:     path,
+                    Line:     pos.Line,
+                    Function: funcID(pkg, decl),
+                })
+            }
+            return true
+        })
+
+        // Поля конфигурационных структур с тегами mapstructure/yaml/env/...
+        ast.Inspect(file, func(n ast.Node) bool {
+            spec, ok := n.(*ast.TypeSpec)
+            if !ok {
+                return true
+            }
+            st, ok := spec.Type.(*ast.StructType)
+            if !ok || st.Fields == nil {
+                return true
+            }
+
+            for _, field := range st.Fields.List {
+                if field.Tag == nil {
+                    continue
+                }
+                raw, err := strconv.Unquote(field.Tag.Value)
+                if err != nil {
+                    continue
+                }
+
+                tags := make(map[string]string)
+                for _, key := range configTagKeys {
+                    if value, ok := reflect.StructTag(raw).Lookup(key); ok {
+                        tags[key] = value
+                    }
+                }
+                if len(tags) == 0 {
+                    continue
+                }
+
+                pos := pkg.Fset.Position(field.Pos())
+                names := []string{extractTypeString(field.Type)}
+                if len(field.Names) > 0 {
+                    names = names[:0]
+                    for _, name := range field.Names {
+                        names = append(names, name.Name)
+                    }
+                }
+                for _, name := range names {
+                    surface.StructFields = append(surface.StructFields, ConfigField{
+                        Struct: pkg.PkgPath + "." + spec.Name.Name,
+                        Field:  name,
+                        Type:   extractTypeString(field.Type),
+                        Tags:   tags,
+                        File:   relativePath(projectPath, pos.Filename),
+                        Line:   pos.Line,
+                    })
+                }
+            }
+            return true
+        })
+    }
+
+    sort.SliceStable(surface.EnvVars, func(i, j int) bool {
+        return surface.EnvVars[i].Key < surface.EnvVars[j].Key
+    })
+    sort.SliceStable(surface.ViperKeys, func(i, j int) bool {
+        return surface.ViperKeys[i].Key < surface.ViperKeys[j].Key
+    })
+}
@@ -0,0 +1,27 @@
+package analyzer
+
+import "testing"
+
+func TestFlagType(t *testing.T) {
+    for name, want := range map[string]string{
+        "String":      "string",
+        "StringVarP":  "string",
+        "IP":          "ip",
+        "IPP":         "ip",
+        "IPVar":       "ip",
+        "IPVarP":      "ip",
+        "IPNet":       "ipnet",
+        "IPNetVarP":   "ipnet",
+        "Duration":    "duration",
+        "DurationVar": "duration",
+        "Int64P":      "int64",
+        "TextVar":     "text",
+        "Var":         "value",
+        "VarP":        "value",
+        "Custom":      "value",
+    } {
+        if got := flagType(name); got != want {
+            t.Errorf("flagType(%q) = %q, want %q", name, got, want)
+        }
+    }
+}
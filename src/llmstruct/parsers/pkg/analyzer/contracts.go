@@ -0,0 +1,294 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// OperationContract - типы запроса и ответа операции gRPC/OpenAPI по
+// сигнатуре метода интерфейса сервера
+type OperationContract struct {
+    Operation    string   `json:"operation"`
+    Request      string   `json:"request,omitempty"`
+    Response     string   `json:"response,omitempty"`
+    Streaming    bool     `json:"streaming,omitempty"`
+}
+
+// Вызовы, которые декодируют запрос или кодируют ответ: роль и индекс
+// аргумента со значением (-1 - последний)
+var contractCalls = map[string]struct {
+    role string
+    arg  int
+}{
+    "(*encoding/json.Decoder).Decode":                         {"request", 0},
+    "encoding/json.Unmarshal":                                 {"request", 1},
+    "(*encoding/json.Encoder).Encode":                         {"response", 0},
+    "encoding/json.Marshal":                                   {"response", 0},
+    "encoding/json.MarshalIndent":                             {"response", 0},
+    "(*encoding/xml.Decoder).Decode":                          {"request", 0},
+    "encoding/xml.Unmarshal":                                  {"request", 1},
+    "(*encoding/xml.Encoder).Encode":                          {"response", 0},
+    "encoding/xml.Marshal":                                    {"response", 0},
+    "google.golang.org/protobuf/proto.Unmarshal":              {"request", 1},
+    "google.golang.org/protobuf/proto.Marshal":                {"response", 0},
+    "google.golang.org/protobuf/encoding/protojson.Unmarshal":  {"request", 1},
+    "google.golang.org/protobuf/encoding/protojson.Marshal":    {"response", 0},
+    "(*github.com/gin-gonic/gin.Context).Bind":                {"request", 0},
+    "(*github.com/gin-gonic/gin.Context).BindJSON":            {"request", 0},
+    "(*github.com/gin-gonic/gin.Context).ShouldBind":          {"request", 0},
+    "(*github.com/gin-gonic/gin.Context).ShouldBindJSON":      {"request", 0},
+    "(*github.com/gin-gonic/gin.Context).ShouldBindWith":      {"request", 0},
+    "(*github.com/gin-gonic/gin.Context).JSON":                {"response", -1},
+    "(*github.com/gin-gonic/gin.Context).IndentedJSON":        {"response", -1},
+    "(*github.com/gin-gonic/gin.Context).PureJSON":            {"response", -1},
+    "(*github.com/gin-gonic/gin.Context).AbortWithStatusJSON": {"response", -1},
+    "(*github.com/gin-gonic/gin.Context).XML":                 {"response", -1},
+    "(github.com/labstack/echo/v4.Context).Bind":              {"request", 0},
+    "(github.com/labstack/echo/v4.Context).JSON":              {"response", -1},
+    "(github.com/labstack/echo/v4.Context).JSONPretty":        {"response", 1},
+    "(*github.com/gofiber/fiber/v2.Ctx).BodyParser":           {"request", 0},
+    "(*github.com/gofiber/fiber/v2.Ctx).JSON":                 {"response", 0},
+    "github.com/go-chi/render.DecodeJSON":                     {"request", 1},
+    "github.com/go-chi/render.Bind":                           {"request", 1},
+    "github.com/go-chi/render.JSON":                           {"response", 2},
+    "github.com/go-chi/render.Render":                         {"response", 2},
+}
+
+// funcBody - тело функции проекта с информацией о типах её пакета
+type funcBody struct {
+    info *types.Info
+    body *ast.BlockStmt
+}
+
+// handlerRef - обработчик маршрута: функция или метод проекта, литерал
+// функции или вызов фабрики, возвращающей обработчик
+type handlerRef struct {
+    info *types.Info
+    expr ast.Expr
+}
+
+// contractResolver находит типы, которые функции проекта декодируют из
+// запроса и кодируют в ответ, включая вспомогательные функции вида
+// writeJSON(w, status, v)
+type contractResolver struct {
+    bodies  map[*types.Func]funcBody
+    // Роли параметров вспомогательных функций: индекс -> request/response
+    helpers map[*types.Func]map[int]string
+}
+
+func newContractResolver() *contractResolver {
+    return &contractResolver{
+        bodies:  make(map[*types.Func]funcBody),
+        helpers: make(map[*types.Func]map[int]string),
+    }
+}
+
+func (r *contractResolver) addPackage(pkg *packages.Package) {
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+                if fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+                    r.bodies[fn] = funcBody{info: pkg.TypesInfo, body: fd.Body}
+                }
+            }
+        }
+    }
+}
+
+// contractType - тип значения без указателя и &; интерфейсы (any) ничего
+// не говорят о контракте
+func contractType(info *types.Info, expr ast.Expr) types.Type {
+    if u, ok := ast.Unparen(expr).(*ast.UnaryExpr); ok {
+        expr = u.X
+    }
+    t := info.TypeOf(expr)
+    if ptr, ok := t.(*types.Pointer); ok {
+        t = ptr.Elem()
+    }
+    if t == nil || types.IsInterface(t) {
+        return nil
+    }
+    return t
+}
+
+// visit обходит тело и сообщает о каждом значении, которое декодируется
+// или кодируется напрямую либо через вспомогательную функцию проекта
+func (r *contractResolver) visit(info *types.Info, body ast.Node, found func(role string, expr ast.Expr)) {
+    ast.Inspect(body, func(n ast.Node) bool {
+        call, ok := n.(*ast.CallExpr)
+        if !ok {
+            return true
+        }
+        fn := calleeFunc(info, call)
+        if fn == nil {
+            return true
+        }
+        if c, ok := contractCalls[fn.Origin().FullName()]; ok {
+            i := c.arg
+            if i < 0 {
+                i = len(call.Args) - 1
+            }
+            if i >= 0 && i < len(call.Args) {
+                found(c.role, call.Args[i])
+            }
+            return true
+        }
+        for i, role := range r.helperRoles(fn.Origin()) {
+            if i < len(call.Args) {
+                found(role, call.Args[i])
+            }
+        }
+        return true
+    })
+}
+
+// helperRoles определяет, какие параметры функции проекта она декодирует
+// или кодирует
+func (r *contractResolver) helperRoles(fn *types.Func) map[int]string {
+    if roles, ok := r.helpers[fn]; ok {
+        return roles
+    }
+    // Рекурсивные вызовы видят пустой результат
+    r.helpers[fn] = nil
+    fb, ok := r.bodies[fn]
+    if !ok {
+        return nil
+    }
+    sig := fn.Type().(*types.Signature)
+    params := make(map[types.Object]int)
+    for i := 0; i < sig.Params().Len(); i++ {
+        params[sig.Params().At(i)] = i
+    }
+    roles := make(map[int]string)
+    r.visit(fb.info, fb.body, func(role string, expr ast.Expr) {
+        if u, ok := ast.Unparen(expr).(*ast.UnaryExpr); ok {
+            expr = u.X
+        }
+        if id, ok := ast.Unparen(expr).(*ast.Ident); ok {
+            if i, ok := params[fb.info.Uses[id]]; ok {
+                roles[i] = role
+            }
+        }
+    })
+    r.helpers[fn] = roles
+    return roles
+}
+
+// handlerBody находит тело обработчика: функцию или метод проекта,
+// литерал или литерал, возвращаемый фабрикой h.users()
+func (r *contractResolver) handlerBody(h handlerRef) (*types.Info, ast.Node) {
+    switch e := ast.Unparen(h.expr).(type) {
+    case *ast.FuncLit:
+        return h.info, e.Body
+    case *ast.Ident, *ast.SelectorExpr:
+        id, _ := e.(*ast.Ident)
+        if sel, ok := e.(*ast.SelectorExpr); ok {
+            id = sel.Sel
+        }
+        if fn, ok := h.info.Uses[id].(*types.Func); ok {
+            if fb, ok := r.bodies[fn.Origin()]; ok {
+                return fb.info, fb.body
+            }
+        }
+    case *ast.CallExpr:
+        fn := calleeFunc(h.info, e)
+        if fn == nil {
+            return nil, nil
+        }
+        fb, ok := r.bodies[fn.Origin()]
+        if !ok {
+            return nil, nil
+        }
+        var lit *ast.FuncLit
+        ast.Inspect(fb.body, func(n ast.Node) bool {
+            if ret, ok := n.(*ast.ReturnStmt); ok && lit == nil && len(ret.Results) == 1 {
+                lit, _ = ast.Unparen(ret.Results[0]).(*ast.FuncLit)
+            }
+            return lit == nil
+        })
+        if lit != nil {
+            return fb.info, lit.Body
+        }
+    }
+    return nil, nil
+}
+
+// resolve возвращает типы запроса и ответа обработчика маршрута
+func (r *contractResolver) resolve(h handlerRef) (request, response []string) {
+    info, body := r.handlerBody(h)
+    if body == nil {
+        return nil, nil
+    }
+    r.visit(info, body, func(role string, expr ast.Expr) {
+        t := contractType(info, expr)
+        if t == nil {
+            return
+        }
+        if role == "request" {
+            request = appendUnique(request, types.TypeString(t, nil))
+        } else {
+            response = appendUnique(response, types.TypeString(t, nil))
+        }
+    })
+    return request, response
+}
+
+// operationContracts выводит типы операций из методов интерфейса сервера:
+// Get(ctx, *GetRequest) (*GetResponse, error); у потоковых методов gRPC
+// сообщения берутся из методов Send/Recv/SendAndClose потока
+func operationContracts(iface *types.Interface) []OperationContract {
+    var contracts []OperationContract
+    typeName := func(t types.Type) string {
+        if ptr, ok := t.(*types.Pointer); ok {
+            t = ptr.Elem()
+        }
+        return types.TypeString(t, nil)
+    }
+    for _, name := range interfaceMethods(iface) {
+        obj, _, _ := types.LookupFieldOrMethod(iface, false, nil, name)
+        fn, ok := obj.(*types.Func)
+        if !ok {
+            continue
+        }
+        sig := fn.Type().(*types.Signature)
+        contract := OperationContract{Operation: name}
+        for i := 0; i < sig.Params().Len(); i++ {
+            t := sig.Params().At(i).Type()
+            if isContextType(t) {
+                continue
+            }
+            stream := false
+            for _, method := range []string{"Send", "SendAndClose", "Recv"} {
+                m, _, _ := types.LookupFieldOrMethod(t, true, nil, method)
+                mfn, ok := m.(*types.Func)
+                if !ok {
+                    continue
+                }
+                stream = true
+                msig := mfn.Type().(*types.Signature)
+                switch {
+                case method == "Recv" && msig.Results().Len() > 0:
+                    contract.Request = typeName(msig.Results().At(0).Type())
+                case method != "Recv" && msig.Params().Len() == 1:
+                    contract.Response = typeName(msig.Params().At(0).Type())
+                }
+            }
+            if stream {
+                contract.Streaming = true
+            } else if contract.Request == "" {
+                contract.Request = typeName(t)
+            }
+        }
+        for i := 0; i < sig.Results().Len(); i++ {
+            if t := sig.Results().At(i).Type(); !isErrorType(t) && contract.Response == "" {
+                contract.Response = typeName(t)
+            }
+        }
+        contracts = append(contracts, contract)
+    }
+    sort.Slice(contracts, func(i, j int) bool { return contracts[i].Operation < contracts[j].Operation })
+    return contracts
+}
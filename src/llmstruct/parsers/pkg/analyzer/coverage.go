@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// CoverageReport - сводка профиля go test -coverprofile. Uncovered -
+// функции, ни один оператор которых не выполнялся; Unmatched - файлы
+// профиля, которых нет в анализе (устаревший профиль или другой модуль)
+type CoverageReport struct {
+    Profile      string   `json:"profile"`
+    Statements   int      `json:"statements"`
+    Covered      int      `json:"covered"`
+    Percent      float64  `json:"percent"`
+    Uncovered    []string `json:"uncovered"`
+    Unmatched    []string `json:"unmatched,omitempty"`
+}
+
+// coverBlock - блок профиля: строки начала и конца и число операторов
+type coverBlock struct {
+    startLine int
+    endLine   int
+    stmts     int
+    covered   bool
+}
+
+// readCoverProfile разбирает профиль go test -coverprofile по файлам
+// (ключ - путь импорта пакета и имя файла); блоки, повторённые в профиле
+// (несколько пакетов тестов), объединяются
+func readCoverProfile(path string) (map[string][]*coverBlock, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    seen := make(map[string]*coverBlock)
+    files := make(map[string][]*coverBlock)
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        line := scanner.Text()
+        if strings.HasPrefix(line, "mode:") || line == "" {
+            continue
+        }
+        // file.go:10.2,12.3 2 1
+        fields := strings.Fields(line)
+        if len(fields) != 3 {
+            return nil, fmt.Errorf("%s: malformed line %q", path, line)
+        }
+        name, span, ok := strings.Cut(fields[0], ":")
+        start, end, ok2 := strings.Cut(span, ",")
+        startLine, _, _ := strings.Cut(start, ".")
+        endLine, _, _ := strings.Cut(end, ".")
+        l1, err1 := strconv.Atoi(startLine)
+        l2, err2 := strconv.Atoi(endLine)
+        stmts, err3 := strconv.Atoi(fields[1])
+        count, err4 := strconv.Atoi(fields[2])
+        if !ok || !ok2 || err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+            return nil, fmt.Errorf("%s: malformed line %q", path, line)
+        }
+        b := seen[fields[0]]
+        if b == nil {
+            b = &coverBlock{startLine: l1, endLine: l2, stmts: stmts}
+            seen[fields[0]] = b
+            files[name] = append(files[name], b)
+        }
+        b.covered = b.covered || count > 0
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    return files, nil
+}
+
+// coverPercent - доля покрытых операторов блоков, попадающих в строки
+// [line, endLine]; nil, если операторов нет
+func coverPercent(blocks []*coverBlock, line, endLine int) (*float64, int, int) {
+    total, covered := 0, 0
+    for _, b := range blocks {
+        if b.startLine < line || b.startLine > endLine {
+            continue
+        }
+        total += b.stmts
+        if b.covered {
+            covered += b.stmts
+        }
+    }
+    if total == 0 {
+        return nil, 0, 0
+    }
+    v := roundMetric(float64(covered) / float64(total) * 100)
+    return &v, total, covered
+}
+
+// attachCoverage читает профиль покрытия и проставляет процент покрытых
+// операторов функциям и файлам анализа
+func attachCoverage(projectPath, profile string, analysis *ProjectAnalysis) (*CoverageReport, error) {
+    if !filepath.IsAbs(profile) {
+        profile = filepath.Join(projectPath, profile)
+    }
+    blocks, err := readCoverProfile(profile)
+    if err != nil {
+        return nil, err
+    }
+    report := &CoverageReport{Profile: relativePath(projectPath, profile), Uncovered: []string{}}
+    matched := make(map[string]bool)
+    for i := range analysis.Files {
+        file := &analysis.Files[i]
+        pkgPath := packagePathOf(analysis, file.Path)
+        key := pkgPath + "/" + path.Base(filepath.ToSlash(file.Path))
+        fileBlocks, ok := blocks[key]
+        if !ok {
+            continue
+        }
+        matched[key] = true
+        var total, covered int
+        file.Coverage, total, covered = coverPercent(fileBlocks, 1, file.LineCount)
+        report.Statements += total
+        report.Covered += covered
+        for j := range file.Functions {
+            fn := &file.Functions[j]
+            fn.Coverage, _, _ = coverPercent(fileBlocks, fn.Line, fn.EndLine)
+            if fn.Coverage != nil && *fn.Coverage == 0 {
+                name := fn.Name
+                if fn.IsMethod {
+                    name = receiverTypeName(fn.Receiver) + "." + fn.Name
+                }
+                report.Uncovered = append(report.Uncovered, goSymbolID(pkgPath, name))
+            }
+        }
+    }
+    for key := range blocks {
+        if !matched[key] {
+            report.Unmatched = append(report.Unmatched, key)
+        }
+    }
+    sort.Strings(report.Uncovered)
+    sort.Strings(report.Unmatched)
+    if report.Statements > 0 {
+        report.Percent = roundMetric(float64(report.Covered) / float64(report.Statements) * 100)
+    }
+    return report, nil
+}
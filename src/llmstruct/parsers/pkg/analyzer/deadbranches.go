@@ -0,0 +1,268 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/constant"
+    "go/token"
+    "go/types"
+    "sort"
+    "strconv"
+
+    "golang.org/x/tools/go/packages"
+    "golang.org/x/tools/go/ssa"
+    "golang.org/x/tools/go/ssa/ssautil"
+)
+
+// DeadBranch - условие с известным при компиляции значением или код,
+// который никогда не выполняется; часто это следы устаревших флагов
+type DeadBranch struct {
+    File            string   `json:"file"`
+    Line            int      `json:"line"`
+    Function        string   `json:"function"`
+    Kind            string   `json:"kind"`
+    Condition       string   `json:"condition,omitempty"`
+    Value           string   `json:"value,omitempty"`
+    UnreachableFrom int      `json:"unreachable_from,omitempty"`
+    UnreachableTo   int      `json:"unreachable_to,omitempty"`
+    Reason          string   `json:"reason"`
+}
+
+// foldBool вычисляет логическое SSA-значение, если оно не зависит от
+// входных данных: константы, отрицание, сравнение двух констант и phi, у
+// которого все входы дают одно значение
+func foldBool(v ssa.Value, visiting map[ssa.Value]bool) (bool, bool) {
+    if visiting[v] {
+        return false, false
+    }
+    visiting[v] = true
+    defer delete(visiting, v)
+
+    switch x := v.(type) {
+    case *ssa.Const:
+        if x.Value != nil && x.Value.Kind() == constant.Bool {
+            return constant.BoolVal(x.Value), true
+        }
+    case *ssa.UnOp:
+        if x.Op == token.NOT {
+            if b, ok := foldBool(x.X, visiting); ok {
+                return !b, true
+            }
+        }
+    case *ssa.BinOp:
+        left, lok := x.X.(*ssa.Const)
+        right, rok := x.Y.(*ssa.Const)
+        if !lok || !rok {
+            return false, false
+        }
+        // Сравнение с nil: nil == nil для одинаковых типов
+        if left.Value == nil || right.Value == nil {
+            if x.Op != token.EQL && x.Op != token.NEQ {
+                return false, false
+            }
+            equal := left.Value == nil && right.Value == nil
+            return equal == (x.Op == token.EQL), true
+        }
+        switch x.Op {
+        case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+            return constant.Compare(left.Value, x.Op, right.Value), true
+        }
+    case *ssa.Phi:
+        var result, known bool
+        for _, edge := range x.Edges {
+            b, ok := foldBool(edge, visiting)
+            if !ok || known && b != result {
+                return false, false
+            }
+            result, known = b, true
+        }
+        return result, known
+    }
+    return false, false
+}
+
+// isTerminating сообщает, что после оператора управление не продолжается
+func isTerminating(info *types.Info, stmt ast.Stmt) bool {
+    switch s := stmt.(type) {
+    case *ast.ReturnStmt:
+        return true
+    case *ast.BranchStmt:
+        return s.Tok == token.GOTO || s.Tok == token.BREAK || s.Tok == token.CONTINUE
+    case *ast.ExprStmt:
+        call, ok := s.X.(*ast.CallExpr)
+        if !ok {
+            return false
+        }
+        if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+            _, builtin := info.Uses[id].(*types.Builtin)
+            return builtin && id.Name == "panic"
+        }
+        if fn := calleeFunc(info, call); fn != nil && fn.Pkg() != nil {
+            return fn.Pkg().Path() == "os" && fn.Name() == "Exit" || fn.Pkg().Path() == "log" && (fn.Name() == "Fatal" || fn.Name() == "Fatalf" || fn.Name() == "Fatalln")
+        }
+    }
+    return false
+}
+
+type deadBranchFinder struct {
+    projectPath string
+    fset        *token.FileSet
+    info        *types.Info
+    fn          *ssa.Function
+    function    string
+    found       []DeadBranch
+}
+
+func (f *deadBranchFinder) lines(node ast.Node) (int, int) {
+    return f.fset.Position(node.Pos()).Line, f.fset.Position(node.End()).Line
+}
+
+func (f *deadBranchFinder) report(node ast.Node, branch DeadBranch) {
+    pos := f.fset.Position(node.Pos())
+    branch.File = relativePath(f.projectPath, pos.Filename)
+    branch.Line = pos.Line
+    branch.Function = f.function
+    f.found = append(f.found, branch)
+}
+
+// condition проверяет условие if/for: сначала свёртку констант go/types,
+// затем значение условия в SSA
+func (f *deadBranchFinder) condition(cond ast.Expr) (value bool, reason string, ok bool) {
+    if tv, found := f.info.Types[cond]; found && tv.Value != nil && tv.Value.Kind() == constant.Bool {
+        return constant.BoolVal(tv.Value), "constant expression", true
+    }
+    // Отрицание SSA строит как переход с переставленными ветками
+    if not, isNot := ast.Unparen(cond).(*ast.UnaryExpr); isNot && not.Op == token.NOT {
+        value, reason, ok := f.condition(not.X)
+        return !value, reason, ok
+    }
+    v, _ := f.fn.ValueForExpr(ast.Unparen(cond))
+    if v == nil {
+        return false, "", false
+    }
+    if b, ok := foldBool(v, make(map[ssa.Value]bool)); ok {
+        return b, "operands are constant on every path (SSA)", true
+    }
+    return false, "", false
+}
+
+func (f *deadBranchFinder) inspect(body *ast.BlockStmt) {
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.FuncLit:
+            // Литералы - отдельные SSA-функции
+            return false
+        case *ast.IfStmt:
+            value, reason, ok := f.condition(x.Cond)
+            if !ok {
+                break
+            }
+            branch := DeadBranch{Kind: "constant_condition", Condition: types.ExprString(x.Cond), Value: strconv.FormatBool(value), Reason: reason}
+            var dead ast.Node = x.Body
+            if value {
+                dead = x.Else
+            }
+            if dead != nil {
+                branch.UnreachableFrom, branch.UnreachableTo = f.lines(dead)
+            }
+            f.report(x.Cond, branch)
+        case *ast.ForStmt:
+            // for true {...} - обычный бесконечный цикл
+            if x.Cond == nil {
+                break
+            }
+            if value, reason, ok := f.condition(x.Cond); ok && !value {
+                branch := DeadBranch{Kind: "constant_condition", Condition: types.ExprString(x.Cond), Value: "false", Reason: reason}
+                branch.UnreachableFrom, branch.UnreachableTo = f.lines(x.Body)
+                f.report(x.Cond, branch)
+            }
+        case *ast.BlockStmt:
+            f.unreachableTail(x.List)
+        case *ast.CaseClause:
+            f.unreachableTail(x.Body)
+        case *ast.CommClause:
+            f.unreachableTail(x.Body)
+        }
+        return true
+    })
+}
+
+// unreachableTail отмечает операторы после return, panic, goto и т.п.
+func (f *deadBranchFinder) unreachableTail(list []ast.Stmt) {
+    for i, stmt := range list[:max(len(list)-1, 0)] {
+        if !isTerminating(f.info, stmt) {
+            continue
+        }
+        next := list[i+1]
+        // На метку можно перейти по goto
+        if _, labeled := next.(*ast.LabeledStmt); labeled {
+            return
+        }
+        from, _ := f.lines(next)
+        _, to := f.lines(list[len(list)-1])
+        f.report(next, DeadBranch{Kind: "unreachable", UnreachableFrom: from, UnreachableTo: to, Reason: "follows " + types.ExprString(stmtExpr(stmt))})
+        return
+    }
+}
+
+// stmtExpr - краткое описание завершающего оператора для причины
+func stmtExpr(stmt ast.Stmt) ast.Expr {
+    switch s := stmt.(type) {
+    case *ast.ExprStmt:
+        if call, ok := s.X.(*ast.CallExpr); ok {
+            return call.Fun
+        }
+        return s.X
+    case *ast.BranchStmt:
+        return ast.NewIdent(s.Tok.String())
+    }
+    return ast.NewIdent("return")
+}
+
+// extractDeadBranches строит SSA проекта (с отладочной информацией для
+// сопоставления выражений) и проверяет условия всех функций
+func extractDeadBranches(pkgs []*packages.Package, projectPath string) []DeadBranch {
+    prog, ssaPkgs := ssautil.Packages(pkgs, ssa.GlobalDebug)
+    prog.Build()
+
+    project := make(map[*ssa.Package]*packages.Package)
+    for i, p := range ssaPkgs {
+        if p != nil {
+            project[p] = pkgs[i]
+        }
+    }
+    var branches []DeadBranch
+    for fn := range ssautil.AllFunctions(prog) {
+        pkg, ok := project[fn.Pkg]
+        if !ok || fn.Synthetic != "" {
+            continue
+        }
+        var body *ast.BlockStmt
+        switch syntax := fn.Syntax().(type) {
+        case *ast.FuncDecl:
+            body = syntax.Body
+        case *ast.FuncLit:
+            body = syntax.Body
+        }
+        if body == nil {
+            continue
+        }
+        top := fn
+        for top.Parent() != nil {
+            top = top.Parent()
+        }
+        function := top.String()
+        if obj, ok := top.Object().(*types.Func); ok {
+            function = obj.FullName()
+        }
+        finder := &deadBranchFinder{projectPath: projectPath, fset: pkg.Fset, info: pkg.TypesInfo, fn: fn, function: function}
+        finder.inspect(body)
+        branches = append(branches, finder.found...)
+    }
+    sort.Slice(branches, func(i, j int) bool {
+        if branches[i].File != branches[j].File {
+            return branches[i].File < branches[j].File
+        }
+        return branches[i].Line < branches[j].Line
+    })
+    return branches
+}
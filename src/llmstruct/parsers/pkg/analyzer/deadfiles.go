@@ -0,0 +1,320 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "go/build"
+    "go/build/constraint"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// DeadFile - файл, который не собирается ни на одной настроенной
+// платформе ни с одним сочетанием тегов
+type DeadFile struct {
+    File         string   `json:"file"`
+    Constraint   string   `json:"constraint,omitempty"`
+    Kind         string   `json:"kind"`
+    BuildsOn     []string `json:"builds_on,omitempty"`
+    Reason       string   `json:"reason"`
+}
+
+// Известные GOOS и GOARCH (как в go/build/syslist.go)
+var knownOS = map[string]bool{
+    "aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+    "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true,
+    "openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+}
+
+var unixOS = map[string]bool{
+    "aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+    "illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+var knownArch = map[string]bool{
+    "386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+    "arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+    "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true,
+    "riscv64": true, "s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+// Больше свободных тегов не перебираем: такой файл считаем живым
+const maxFreeBuildTags = 8
+
+type buildPlatform struct {
+    goos, goarch string
+}
+
+func (p buildPlatform) String() string {
+    return p.goos + "/" + p.goarch
+}
+
+// allPlatforms - все пары известных GOOS/GOARCH
+func allPlatforms() []buildPlatform {
+    var platforms []buildPlatform
+    for goos := range knownOS {
+        for goarch := range knownArch {
+            platforms = append(platforms, buildPlatform{goos, goarch})
+        }
+    }
+    sort.Slice(platforms, func(i, j int) bool { return platforms[i].String() < platforms[j].String() })
+    return platforms
+}
+
+// parsePlatforms разбирает список "linux/amd64,darwin/arm64"; пустой
+// список - все известные платформы
+func parsePlatforms(list []string) []buildPlatform {
+    if len(list) == 0 {
+        return allPlatforms()
+    }
+    var platforms []buildPlatform
+    for _, item := range list {
+        goos, goarch, _ := strings.Cut(strings.TrimSpace(item), "/")
+        platforms = append(platforms, buildPlatform{goos, goarch})
+    }
+    return platforms
+}
+
+// summarizePlatforms сворачивает платформы: GOOS целиком, если подходят
+// все его архитектуры, иначе пары GOOS/GOARCH
+func summarizePlatforms(platforms []buildPlatform) []string {
+    arches := make(map[string]int)
+    for _, p := range platforms {
+        arches[p.goos]++
+    }
+    var out []string
+    for _, p := range platforms {
+        if arches[p.goos] < len(knownArch) {
+            out = append(out, p.String())
+        } else {
+            out = appendUnique(out, p.goos)
+        }
+    }
+    return out
+}
+
+// FileBuild - ограничения сборки файла: выражение //go:build (или
+// собранное из // +build) и GOOS/GOARCH из суффикса имени файла
+type FileBuild struct {
+    Constraint   string   `json:"constraint,omitempty"`
+    GOOS         string   `json:"goos,omitempty"`
+    GOARCH       string   `json:"goarch,omitempty"`
+}
+
+// fileBuild - ограничения файла для FileAnalysis; nil - файл собирается
+// на любой платформе с любыми тегами
+func fileBuild(name string, src []byte) *FileBuild {
+    b := &FileBuild{}
+    if expr, err := srcConstraint(src); err == nil && expr != nil {
+        b.Constraint = expr.String()
+    }
+    b.GOOS, b.GOARCH = fileNamePlatform(name)
+    if *b == (FileBuild{}) {
+        return nil
+    }
+    return b
+}
+
+// fileConstraint читает //go:build (или устаревшие // +build) из заголовка
+// файла до объявления package
+func fileConstraint(path string) (constraint.Expr, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    return srcConstraint(data)
+}
+
+func srcConstraint(data []byte) (constraint.Expr, error) {
+    var plusBuild constraint.Expr
+    sc := bufio.NewScanner(bytes.NewReader(data))
+    sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    for sc.Scan() {
+        line := strings.TrimSpace(sc.Text())
+        if line == "" {
+            continue
+        }
+        if !strings.HasPrefix(line, "//") {
+            break
+        }
+        if constraint.IsGoBuild(line) {
+            return constraint.Parse(line)
+        }
+        if constraint.IsPlusBuild(line) {
+            expr, err := constraint.Parse(line)
+            if err != nil {
+                return nil, err
+            }
+            if plusBuild == nil {
+                plusBuild = expr
+            } else {
+                plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+            }
+        }
+    }
+    return plusBuild, nil
+}
+
+// fileNamePlatform возвращает GOOS/GOARCH из суффикса имени файла
+// (name_GOOS_GOARCH.go, name_GOOS.go, name_GOARCH.go)
+func fileNamePlatform(name string) (goos, goarch string) {
+    name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), "_test")
+    parts := strings.Split(name, "_")
+    // Файл из одного суффикса (linux.go) ограничением не считается
+    if n := len(parts); n >= 3 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+        return parts[n-2], parts[n-1]
+    } else if n >= 2 && knownOS[parts[n-1]] {
+        return parts[n-1], ""
+    } else if n >= 2 && knownArch[parts[n-1]] {
+        return "", parts[n-1]
+    }
+    return "", ""
+}
+
+// matchOS учитывает подразумеваемые GOOS: android - это linux, ios -
+// darwin, illumos - solaris
+func matchOS(goos, tag string) bool {
+    return goos == tag || goos == "android" && tag == "linux" || goos == "ios" && tag == "darwin" || goos == "illumos" && tag == "solaris"
+}
+
+// isPlatformTag - тег, значение которого определяется платформой и
+// тулчейном, а не выбором пользователя
+func isPlatformTag(tag string) bool {
+    return knownOS[tag] || knownArch[tag] || tag == "unix" || tag == "gc" || tag == "gccgo" || strings.HasPrefix(tag, "go1.")
+}
+
+type buildConstraintChecker struct {
+    releaseTags map[string]bool
+    // nil - пользовательские теги не заданы и могут быть любыми
+    buildTags   map[string]bool
+}
+
+func newBuildConstraintChecker(buildTags []string) *buildConstraintChecker {
+    c := &buildConstraintChecker{releaseTags: make(map[string]bool)}
+    for _, tag := range build.Default.ReleaseTags {
+        c.releaseTags[tag] = true
+    }
+    if len(buildTags) > 0 {
+        c.buildTags = make(map[string]bool)
+        for _, tag := range buildTags {
+            c.buildTags[strings.TrimSpace(tag)] = true
+        }
+    }
+    return c
+}
+
+// satisfiable перебирает значения свободных тегов (cgo и, если теги не
+// заданы, пользовательских) на платформе p
+func (c *buildConstraintChecker) satisfiable(expr constraint.Expr, name string, p buildPlatform, fixed bool) bool {
+    goos, goarch := fileNamePlatform(name)
+    if goos != "" && !matchOS(p.goos, goos) || goarch != "" && p.goarch != goarch {
+        return false
+    }
+    if expr == nil {
+        return true
+    }
+    free := []string{"cgo"}
+    seen := map[string]bool{"cgo": true}
+    expr.Eval(func(tag string) bool {
+        if !seen[tag] && !isPlatformTag(tag) && (c.buildTags == nil || !fixed) {
+            seen[tag] = true
+            free = append(free, tag)
+        }
+        return false
+    })
+    if len(free) > maxFreeBuildTags {
+        return true
+    }
+    for mask := 0; mask < 1<<len(free); mask++ {
+        set := make(map[string]bool, len(free))
+        for i, tag := range free {
+            set[tag] = mask&(1<<i) != 0
+        }
+        ok := expr.Eval(func(tag string) bool {
+            switch {
+            case seen[tag]:
+                return set[tag]
+            case knownOS[tag]:
+                return matchOS(p.goos, tag)
+            case knownArch[tag]:
+                return p.goarch == tag
+            case tag == "unix":
+                return unixOS[p.goos]
+            case tag == "gc":
+                return true
+            case tag == "gccgo":
+                return false
+            case strings.HasPrefix(tag, "go1."):
+                return c.releaseTags[tag]
+            }
+            return c.buildTags[tag]
+        })
+        if ok {
+            return true
+        }
+    }
+    return false
+}
+
+// extractDeadFiles проверяет ограничения сборки всех .go файлов проекта:
+// unsatisfiable - файл не собирается нигде (противоречивые теги или
+// отсутствующая версия Go), unconfigured - только вне заданных платформ
+// и тегов
+func extractDeadFiles(projectPath string, platforms, buildTags []string) []DeadFile {
+    checker := newBuildConstraintChecker(buildTags)
+    configured := parsePlatforms(platforms)
+    every := allPlatforms()
+    dead := []DeadFile{}
+
+    filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if d.IsDir() {
+            if path != projectPath && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        name := d.Name()
+        if !strings.HasSuffix(name, ".go") || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+            return nil
+        }
+        expr, err := fileConstraint(path)
+        if err != nil {
+            return nil
+        }
+        for _, p := range configured {
+            if checker.satisfiable(expr, name, p, true) {
+                return nil
+            }
+        }
+        file := DeadFile{File: relativePath(projectPath, path), Kind: "unsatisfiable"}
+        if expr != nil {
+            file.Constraint = expr.String()
+        }
+        var buildsOn []buildPlatform
+        for _, p := range every {
+            if checker.satisfiable(expr, name, p, false) {
+                buildsOn = append(buildsOn, p)
+            }
+        }
+        switch {
+        case len(buildsOn) == 0:
+            file.Reason = "no GOOS/GOARCH and tag combination satisfies the constraint"
+        case len(buildsOn) == len(every):
+            file.Kind = "unconfigured"
+            file.Reason = "requires build tags outside the configured set"
+        default:
+            file.Kind = "unconfigured"
+            file.BuildsOn = summarizePlatforms(buildsOn)
+            file.Reason = "builds only on platforms outside the configured set"
+        }
+        dead = append(dead, file)
+        return nil
+    })
+    return dead
+}
@@ -0,0 +1,218 @@
+package analyzer
+
+import (
+    "go/types"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "golang.org/x/mod/modfile"
+    "golang.org/x/tools/go/packages"
+)
+
+type DependencySymbol struct {
+    Name         string   `json:"name"`
+    Kind         string   `json:"kind"`
+    Signature    string   `json:"signature"`
+    Used         bool     `json:"used"`
+    // Doc - документация символа с сайта документации (-dep-docs)
+    Doc          string   `json:"doc,omitempty"`
+}
+
+type DependencyPackage struct {
+    Path         string             `json:"path"`
+    Name         string             `json:"name"`
+    Symbols      []DependencySymbol `json:"symbols"`
+}
+
+// DependencyAPI - экспортированный API прямой зависимости; Used отмечает
+// символы, на которые ссылается проект
+type DependencyAPI struct {
+    Module       string              `json:"module"`
+    Version      string              `json:"version,omitempty"`
+    Source       string              `json:"source"`
+    Packages     []DependencyPackage `json:"packages"`
+}
+
+// moduleOf находит модуль с самым длинным префиксом пути пакета
+func moduleOf(modules []ModuleInfo, pkgPath string) *ModuleInfo {
+    var best *ModuleInfo
+    for i := range modules {
+        m := &modules[i]
+        if hasPathPrefix(pkgPath, m.Path) && (best == nil || len(m.Path) > len(best.Path)) {
+            best = m
+        }
+    }
+    return best
+}
+
+// requiredModules - требования go.mod; нужен, когда граф модулей не
+// построен (например, `go list -m all` недоступен в режиме vendor)
+func requiredModules(projectPath string) []ModuleInfo {
+    data, err := os.ReadFile(filepath.Join(projectPath, "go.mod"))
+    if err != nil {
+        return nil
+    }
+    file, err := modfile.Parse("go.mod", data, nil)
+    if err != nil {
+        return nil
+    }
+    var modules []ModuleInfo
+    for _, req := range file.Require {
+        modules = append(modules, ModuleInfo{Path: req.Mod.Path, Version: req.Mod.Version, Direct: !req.Indirect, Indirect: req.Indirect})
+    }
+    return modules
+}
+
+// dependencySource - откуда взяты исходники: vendor, локальная замена
+// или кэш модулей
+func dependencySource(projectPath string, m *ModuleInfo, pkg *packages.Package) string {
+    vendor := filepath.Join(projectPath, "vendor") + string(filepath.Separator)
+    for _, file := range pkg.GoFiles {
+        if strings.HasPrefix(file, vendor) {
+            return "vendor"
+        }
+    }
+    if m.Replace != nil && m.Replace.Version == "" {
+        return "replace"
+    }
+    return "module_cache"
+}
+
+// dependencySymbols перечисляет экспортированные объекты пакета и методы
+// его именованных типов
+func dependencySymbols(pkg *types.Package, used map[types.Object]bool) []DependencySymbol {
+    // Другие пакеты - по имени, как в исходниках
+    qualifier := func(other *types.Package) string {
+        if other == pkg {
+            return ""
+        }
+        return other.Name()
+    }
+    symbols := []DependencySymbol{}
+    scope := pkg.Scope()
+    for _, name := range scope.Names() {
+        obj := scope.Lookup(name)
+        if !obj.Exported() {
+            continue
+        }
+        sym := DependencySymbol{Name: name, Signature: types.ObjectString(obj, qualifier), Used: used[obj]}
+        switch o := obj.(type) {
+        case *types.Func:
+            sym.Kind = "function"
+        case *types.Const:
+            sym.Kind = "const"
+        case *types.Var:
+            sym.Kind = "var"
+        case *types.TypeName:
+            sym.Kind = "type"
+            switch u := o.Type().Underlying().(type) {
+            case *types.Interface:
+                sym.Kind = "interface"
+            case *types.Struct:
+                if !o.IsAlias() {
+                    sym.Signature = "type " + name + " " + exportedStruct(u, qualifier)
+                }
+            }
+        }
+        symbols = append(symbols, sym)
+
+        tn, ok := obj.(*types.TypeName)
+        if !ok || tn.IsAlias() {
+            continue
+        }
+        named, ok := tn.Type().(*types.Named)
+        if !ok {
+            continue
+        }
+        if _, isIface := named.Underlying().(*types.Interface); isIface {
+            continue
+        }
+        for i := 0; i < named.NumMethods(); i++ {
+            m := named.Method(i)
+            if !m.Exported() {
+                continue
+            }
+            symbols = append(symbols, DependencySymbol{
+                Name:      name + "." + m.Name(),
+                Kind:      "method",
+                Signature: types.ObjectString(m, qualifier),
+                Used:      used[m],
+            })
+        }
+    }
+    return symbols
+}
+
+// exportedStruct выводит структуру только с экспортированными полями
+func exportedStruct(st *types.Struct, qualifier types.Qualifier) string {
+    var fields []string
+    for i := 0; i < st.NumFields(); i++ {
+        f := st.Field(i)
+        if !f.Exported() {
+            continue
+        }
+        if f.Embedded() {
+            fields = append(fields, types.TypeString(f.Type(), qualifier))
+        } else {
+            fields = append(fields, f.Name()+" "+types.TypeString(f.Type(), qualifier))
+        }
+    }
+    return "struct{" + strings.Join(fields, "; ") + "}"
+}
+
+// extractDependencyAPI собирает API пакетов прямых зависимостей, которые
+// попали в граф импортов проекта
+func extractDependencyAPI(projectPath string, pkgs []*packages.Package, graph *ModuleGraph) []DependencyAPI {
+    modules := requiredModules(projectPath)
+    if graph != nil {
+        modules = graph.Modules
+    }
+    project := make(map[string]bool)
+    for _, pkg := range pkgs {
+        project[pkg.PkgPath] = true
+    }
+    used := make(map[types.Object]bool)
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        for _, obj := range pkg.TypesInfo.Uses {
+            if obj.Pkg() != nil && !project[obj.Pkg().Path()] {
+                if fn, ok := obj.(*types.Func); ok {
+                    obj = fn.Origin()
+                }
+                used[obj] = true
+            }
+        }
+    }
+
+    byModule := make(map[string]*DependencyAPI)
+    packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+        if project[pkg.PkgPath] || pkg.Types == nil {
+            return
+        }
+        m := moduleOf(modules, pkg.PkgPath)
+        if m == nil || !m.Direct || strings.Contains(pkg.PkgPath, "/internal/") || strings.HasSuffix(pkg.PkgPath, "/internal") {
+            return
+        }
+        api := byModule[m.Path]
+        if api == nil {
+            api = &DependencyAPI{Module: m.Path, Version: m.Version, Source: dependencySource(projectPath, m, pkg), Packages: []DependencyPackage{}}
+            if m.Replace != nil && m.Replace.Version != "" {
+                api.Version = m.Replace.Version
+            }
+            byModule[m.Path] = api
+        }
+        api.Packages = append(api.Packages, DependencyPackage{Path: pkg.PkgPath, Name: pkg.Name, Symbols: dependencySymbols(pkg.Types, used)})
+    })
+
+    var result []DependencyAPI
+    for _, api := range byModule {
+        sort.Slice(api.Packages, func(i, j int) bool { return api.Packages[i].Path < api.Packages[j].Path })
+        result = append(result, *api)
+    }
+    sort.Slice(result, func(i, j int) bool { return result[i].Module < result[j].Module })
+    return result
+}
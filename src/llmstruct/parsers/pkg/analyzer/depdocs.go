@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "html"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// defaultDocsSite - сайт документации по умолчанию; -docs-site позволяет
+// указать собственный экземпляр pkgsite
+const defaultDocsSite = "https://pkg.go.dev"
+
+var (
+    // Заголовки функций, типов и методов (h4) и имена в объявлениях
+    // констант и переменных (span) на странице пакета pkgsite
+    docAnchorRe  = regexp.MustCompile(`<(?:h4|span)\b[^>]*\sid="([^"]+)"[^>]*\sdata-kind="(?:function|type|method|constant|variable)"`)
+    docHeaderRe  = regexp.MustCompile(`<h[234]\b`)
+    docParaRe    = regexp.MustCompile(`(?s)<p\b[^>]*>(.*?)</p>`)
+    docTagRe     = regexp.MustCompile(`<[^>]+>`)
+    docSpaceRe   = regexp.MustCompile(`\s+`)
+)
+
+// parsePackageDocs извлекает документацию символов со страницы пакета:
+// абзацы после заголовка символа до следующего заголовка (без примеров).
+// Константы и переменные группы получают документацию группы
+func parsePackageDocs(page string) map[string]string {
+    docs := make(map[string]string)
+    for _, m := range docAnchorRe.FindAllStringSubmatchIndex(page, -1) {
+        name := html.UnescapeString(page[m[2]:m[3]])
+        if _, ok := docs[name]; ok {
+            continue
+        }
+        section := page[m[1]:]
+        if next := docHeaderRe.FindStringIndex(section); next != nil {
+            section = section[:next[0]]
+        }
+        if i := strings.Index(section, "<details"); i >= 0 {
+            section = section[:i]
+        }
+        var paras []string
+        for _, p := range docParaRe.FindAllStringSubmatch(section, -1) {
+            text := html.UnescapeString(docTagRe.ReplaceAllString(p[1], ""))
+            if text = strings.TrimSpace(docSpaceRe.ReplaceAllString(text, " ")); text != "" {
+                paras = append(paras, text)
+            }
+        }
+        if len(paras) > 0 {
+            docs[name] = strings.Join(paras, "\n\n")
+        }
+    }
+    return docs
+}
+
+// docFetcher загружает страницы пакетов и кэширует разобранную
+// документацию всех символов пакета: страница версии не меняется, так что
+// кэш не устаревает
+type docFetcher struct {
+    site     string
+    cacheDir string
+    client   *http.Client
+}
+
+func (f *docFetcher) cachePath(pkgPath, version string) string {
+    sum := sha256.Sum256([]byte(f.site + "\x00" + pkgPath + "@" + version))
+    return filepath.Join(f.cacheDir, "pkgdocs", hex.EncodeToString(sum[:])+".json")
+}
+
+func (f *docFetcher) packageDocs(ctx context.Context, pkgPath, version string) (map[string]string, error) {
+    var path string
+    if f.cacheDir != "" {
+        path = f.cachePath(pkgPath, version)
+        if data, err := os.ReadFile(path); err == nil {
+            var docs map[string]string
+            if json.Unmarshal(data, &docs) == nil {
+                return docs, nil
+            }
+        }
+    }
+    url := strings.TrimSuffix(f.site, "/") + "/" + pkgPath + "@" + version
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := f.client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("%s: %s", url, resp.Status)
+    }
+    body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+    if err != nil {
+        return nil, err
+    }
+    docs := parsePackageDocs(string(body))
+    if path != "" {
+        // Кэш - оптимизация: ошибка записи не мешает результату
+        if data, err := json.Marshal(docs); err == nil && os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+            writeAtomic(path, data)
+        }
+    }
+    return docs, nil
+}
+
+// attachDependencyDocs дописывает документацию с сайта к символам
+// зависимостей, которые использует проект. Модули без версии (локальные
+// замены) пропускаются: их страниц на сайте нет. Недоступный сайт -
+// ошибка, но уже полученная документация остаётся
+func attachDependencyDocs(ctx context.Context, apis []DependencyAPI, site, cacheDir string) error {
+    f := &docFetcher{site: site, cacheDir: cacheDir, client: &http.Client{Timeout: 30 * time.Second}}
+    var failed, total int
+    var firstErr error
+    for i := range apis {
+        api := &apis[i]
+        if api.Version == "" || api.Source == "replace" {
+            continue
+        }
+        for j := range api.Packages {
+            pkg := &api.Packages[j]
+            used := false
+            for _, sym := range pkg.Symbols {
+                used = used || sym.Used
+            }
+            if !used {
+                continue
+            }
+            total++
+            docs, err := f.packageDocs(ctx, pkg.Path, api.Version)
+            if err != nil {
+                failed++
+                if firstErr == nil {
+                    firstErr = err
+                }
+                continue
+            }
+            for k := range pkg.Symbols {
+                if sym := &pkg.Symbols[k]; sym.Used {
+                    sym.Doc = docs[sym.Name]
+                }
+            }
+        }
+    }
+    if firstErr != nil {
+        return fmt.Errorf("%d of %d packages failed: %v", failed, total, firstErr)
+    }
+    return nil
+}
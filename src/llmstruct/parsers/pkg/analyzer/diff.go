@@ -0,0 +1,336 @@
+package analyzer
+
+import (
+    "archive/tar"
+    "bytes"
+    "context"
+    "fmt"
+    "go/token"
+    "io"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+type APIChange struct {
+    Symbol       string   `json:"symbol"`
+    Kind         string   `json:"kind"`
+    Change       string   `json:"change"`
+    Before       string   `json:"before,omitempty"`
+    After        string   `json:"after,omitempty"`
+    File         string   `json:"file,omitempty"`
+    Breaking     bool     `json:"breaking"`
+    // SHA коммитов из AnalysisDiff.Commits, менявших символ
+    Commits      []string `json:"commits,omitempty"`
+}
+
+// AnalysisDiff - изменения между базовой ревизией и рабочим деревом;
+// изменения формата сериализации отделены от изменений Go API
+type AnalysisDiff struct {
+    Base         string         `json:"base"`
+    BaseCommit   string         `json:"base_commit"`
+    APIChanges   []APIChange    `json:"api_changes"`
+    WireChanges  []WireChange   `json:"wire_changes"`
+    Renames      []SymbolRename `json:"renames"`
+    Hotspots     []DiffHotspot  `json:"hotspots,omitempty"`
+    Commits      []ChangeCommit `json:"commits,omitempty"`
+}
+
+func runGit(dir string, args ...string) ([]byte, error) {
+    cmd := exec.Command("git", args...)
+    cmd.Dir = dir
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    if err != nil {
+        return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+    }
+    return out, nil
+}
+
+// extractTar распаковывает вывод git archive в каталог
+func extractTar(data []byte, dir string) error {
+    tr := tar.NewReader(bytes.NewReader(data))
+    for {
+        hdr, err := tr.Next()
+        if err == io.EOF {
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+        target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+        if !hasPathPrefix(target, dir) {
+            return fmt.Errorf("git archive: entry outside of target: %s", hdr.Name)
+        }
+        switch hdr.Typeflag {
+        case tar.TypeDir:
+            if err := os.MkdirAll(target, 0o755); err != nil {
+                return err
+            }
+        case tar.TypeReg:
+            if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+                return err
+            }
+            f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0o777)
+            if err != nil {
+                return err
+            }
+            _, err = io.Copy(f, tr)
+            f.Close()
+            if err != nil {
+                return err
+            }
+        case tar.TypeSymlink:
+            if err := os.Symlink(hdr.Linkname, target); err != nil {
+                return err
+            }
+        }
+    }
+}
+
+// analyzeRevision анализирует проект в состоянии ревизии rev: дерево
+// репозитория выгружается через git archive во временный каталог, так что
+// рабочее дерево и метаданные репозитория не меняются. Возвращает путь к
+// проекту во временном каталоге; cleanup удаляет каталог
+func analyzeRevision(projectPath, rev string, opts Options) (analysis *ProjectAnalysis, commit, basePath string, cleanup func(), err error) {
+    cleanup = func() {}
+    // Файлы базовой ревизии не выводятся
+    opts.FileSink = nil
+    out, err := runGit(projectPath, "rev-parse", "--show-toplevel")
+    if err != nil {
+        return nil, "", "", cleanup, err
+    }
+    toplevel := strings.TrimSpace(string(out))
+    if resolved, err := filepath.EvalSymlinks(projectPath); err == nil {
+        projectPath = resolved
+    }
+    subdir, err := filepath.Rel(toplevel, projectPath)
+    if err != nil {
+        return nil, "", "", cleanup, err
+    }
+    out, err = runGit(projectPath, "rev-parse", "--verify", rev+"^{commit}")
+    if err != nil {
+        return nil, "", "", cleanup, err
+    }
+    commit = strings.TrimSpace(string(out))
+
+    archive, err := runGit(toplevel, "archive", "--format=tar", commit)
+    if err != nil {
+        return nil, commit, "", cleanup, err
+    }
+    tmp, err := os.MkdirTemp("", "llmstruct-base-")
+    if err != nil {
+        return nil, commit, "", cleanup, err
+    }
+    cleanup = func() { os.RemoveAll(tmp) }
+    if err := extractTar(archive, tmp); err != nil {
+        return nil, commit, "", cleanup, err
+    }
+
+    basePath = filepath.Join(tmp, subdir)
+    if !fileExists(basePath) {
+        return nil, commit, "", cleanup, fmt.Errorf("%s does not exist at %s", subdir, rev)
+    }
+    return analyzeProject(context.Background(), basePath, opts), commit, basePath, cleanup, nil
+}
+
+// goSymbol - символ верхнего уровня для сравнения ревизий
+type goSymbol struct {
+    id        string
+    name      string
+    kind      string
+    exported  bool
+    signature string
+    file      string
+    line      int
+    endLine   int
+    members   map[string]string
+}
+
+// paramTypes отбрасывает имена параметров: "ctx context.Context" -> "context.Context"
+func paramTypes(params []string) string {
+    types := make([]string, len(params))
+    for i, p := range params {
+        // Ключевое слово chan не идентификатор: "chan int" остаётся типом
+        if name, typ, ok := strings.Cut(p, " "); ok && token.IsIdentifier(name) {
+            p = typ
+        }
+        types[i] = p
+    }
+    return strings.Join(types, ", ")
+}
+
+func funcSignature(fn Function) string {
+    sig := "func"
+    if len(fn.TypeParams) > 0 {
+        sig += "[" + strings.Join(fn.TypeParams, ", ") + "]"
+    }
+    sig += "(" + paramTypes(fn.Params) + ")"
+    switch len(fn.Returns) {
+    case 0:
+    case 1:
+        sig += " " + paramTypes(fn.Returns)
+    default:
+        sig += " (" + paramTypes(fn.Returns) + ")"
+    }
+    return sig
+}
+
+// packagePathOf восстанавливает путь пакета по каталогу файла
+func packagePathOf(analysis *ProjectAnalysis, file string) string {
+    dir := filepath.ToSlash(filepath.Dir(file))
+    if m := analysis.moduleForDir(dir); m != nil {
+        if rel := moduleRelDir(m, dir); rel != "" && rel != "." {
+            return m.Path + "/" + rel
+        }
+        return m.Path
+    }
+    if analysis.ModuleName == "" {
+        return dir
+    }
+    if dir == "." {
+        return analysis.ModuleName
+    }
+    return analysis.ModuleName + "/" + dir
+}
+
+// goSymbols собирает функции, методы, типы, переменные и константы с
+// ключом - стабильным ID единой схемы ("go:<пакет>.<имя>", для методов
+// "go:<пакет>.<тип>.<имя>"); apiOnly оставляет только экспортируемый API
+// (без internal, main и тестов)
+func goSymbols(analysis *ProjectAnalysis, apiOnly bool) map[string]*goSymbol {
+    symbols := make(map[string]*goSymbol)
+    for _, file := range analysis.Files {
+        if apiOnly && (file.HasTests || file.Package == "main" || strings.Contains(filepath.ToSlash(file.Path), "internal/")) {
+            continue
+        }
+        pkgPath := packagePathOf(analysis, file.Path)
+        add := func(sym *goSymbol) {
+            if !apiOnly || sym.exported {
+                sym.id = goSymbolID(pkgPath, sym.name)
+                sym.file = file.Path
+                symbols[sym.id] = sym
+            }
+        }
+        for _, fn := range file.Functions {
+            sym := &goSymbol{name: fn.Name, kind: "function", exported: fn.IsExported, signature: funcSignature(fn), line: fn.Line, endLine: fn.EndLine}
+            if fn.IsMethod {
+                recv := receiverTypeName(fn.Receiver)
+                if recv == "" {
+                    continue
+                }
+                sym.name, sym.kind = recv+"."+fn.Name, "method"
+                sym.exported = fn.IsExported && token.IsExported(recv)
+            }
+            add(sym)
+        }
+        for _, st := range file.Structs {
+            sym := &goSymbol{name: st.Name, kind: "struct", exported: st.IsExported, line: st.Line, endLine: st.EndLine, members: make(map[string]string)}
+            for _, field := range st.Fields {
+                if !apiOnly || token.IsExported(field.Name) {
+                    sym.members[field.Name] = field.Type
+                }
+            }
+            add(sym)
+        }
+        for _, iface := range file.Interfaces {
+            sym := &goSymbol{name: iface.Name, kind: "interface", exported: iface.IsExported, line: iface.Line, endLine: iface.EndLine, members: make(map[string]string)}
+            for _, method := range iface.Methods {
+                sym.members[method.Name] = funcSignature(method)
+            }
+            for _, embedded := range iface.Fields {
+                sym.members[embedded.Type] = "embedded"
+            }
+            add(sym)
+        }
+        for _, v := range append(append([]Variable{}, file.Variables...), file.Constants...) {
+            kind := "variable"
+            if v.IsConstant {
+                kind = "constant"
+            }
+            add(&goSymbol{name: v.Name, kind: kind, exported: v.IsExported, signature: v.Type, line: v.Line, endLine: v.Line})
+        }
+    }
+    return symbols
+}
+
+// diffAPI сравнивает экспортируемый API: удаление символа, смена сигнатуры,
+// удаление/смена типа поля структуры и изменение набора методов интерфейса
+// ломают совместимость; добавления - нет (кроме методов интерфейса).
+// Переименованный или перенесённый символ - одно изменение вместо пары
+// removed/added
+func diffAPI(base, head *ProjectAnalysis, renames []SymbolRename) []APIChange {
+    before, after := goSymbols(base, true), goSymbols(head, true)
+    changes := []APIChange{}
+    renamed := make(map[string]bool)
+    for _, r := range renames {
+        old, cur := before[r.From], after[r.To]
+        if old == nil || cur == nil {
+            continue
+        }
+        renamed[r.From], renamed[r.To] = true, true
+        changes = append(changes, APIChange{Symbol: r.From, Kind: cur.kind, Change: r.Change, Before: r.From, After: r.To, File: cur.file, Breaking: true})
+    }
+    for key, old := range before {
+        if renamed[key] {
+            continue
+        }
+        cur, ok := after[key]
+        if !ok {
+            changes = append(changes, APIChange{Symbol: key, Kind: old.kind, Change: "removed", Before: old.signature, File: old.file, Breaking: true})
+            continue
+        }
+        if old.kind != cur.kind {
+            changes = append(changes, APIChange{Symbol: key, Kind: cur.kind, Change: "kind changed", Before: old.kind, After: cur.kind, File: cur.file, Breaking: true})
+            continue
+        }
+        if old.signature != cur.signature {
+            changes = append(changes, APIChange{Symbol: key, Kind: cur.kind, Change: "signature changed", Before: old.signature, After: cur.signature, File: cur.file, Breaking: true})
+        }
+        for name, typ := range old.members {
+            newTyp, ok := cur.members[name]
+            switch {
+            case !ok:
+                changes = append(changes, APIChange{Symbol: key + "." + name, Kind: old.kind + " member", Change: "removed", Before: typ, File: cur.file, Breaking: true})
+            case newTyp != typ:
+                changes = append(changes, APIChange{Symbol: key + "." + name, Kind: old.kind + " member", Change: "type changed", Before: typ, After: newTyp, File: cur.file, Breaking: true})
+            }
+        }
+        for name, typ := range cur.members {
+            if _, ok := old.members[name]; !ok {
+                // Новый метод интерфейса ломает сторонние реализации
+                changes = append(changes, APIChange{Symbol: key + "." + name, Kind: cur.kind + " member", Change: "added", After: typ, File: cur.file, Breaking: cur.kind == "interface"})
+            }
+        }
+    }
+    for key, cur := range after {
+        if _, ok := before[key]; !ok && !renamed[key] {
+            changes = append(changes, APIChange{Symbol: key, Kind: cur.kind, Change: "added", After: cur.signature, File: cur.file})
+        }
+    }
+    sort.Slice(changes, func(i, j int) bool {
+        if changes[i].Breaking != changes[j].Breaking {
+            return changes[i].Breaking
+        }
+        return changes[i].Symbol < changes[j].Symbol
+    })
+    return changes
+}
+
+// diffAnalyses сравнивает анализ базовой ревизии с текущим; пути нужны
+// для сравнения тел функций при поиске переименований
+func diffAnalyses(rev, commit string, base *ProjectAnalysis, basePath string, head *ProjectAnalysis, headPath string) *AnalysisDiff {
+    renames := detectRenames(base, basePath, head, headPath)
+    changes := diffAPI(base, head, renames)
+    return &AnalysisDiff{
+        Base:        rev,
+        BaseCommit:  commit,
+        APIChanges:  changes,
+        WireChanges: diffWireSchemas(base.WireSchemas, head.WireSchemas),
+        Renames:     renames,
+        Hotspots:    diffHotspots(head, changes),
+    }
+}
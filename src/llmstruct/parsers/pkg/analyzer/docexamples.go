@@ -0,0 +1,250 @@
+package analyzer
+
+import (
+    "bufio"
+    "fmt"
+    "go/ast"
+    "go/parser"
+    "go/scanner"
+    "go/token"
+    "io/fs"
+    "os"
+    "path"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type DocCodeBlock struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    EndLine      int      `json:"end_line"`
+    Form         string   `json:"form,omitempty"`
+    Parses       bool     `json:"parses"`
+    Error        string   `json:"error,omitempty"`
+    References   []string `json:"references"`
+    Unresolved   []string `json:"unresolved"`
+}
+
+// Обёртки, в которых пробуется разобрать фрагмент: как файл, как
+// объявления без package и как тело функции
+var docBlockForms = []struct {
+    name    string
+    prefix  string
+    suffix  string
+}{
+    {"file", "", ""},
+    {"declarations", "package example\n", ""},
+    {"statements", "package example\nfunc _() {\n", "\n}\n"},
+}
+
+// fencedGoBlock - блок ```go в Markdown; line - строка первой строки кода
+type fencedGoBlock struct {
+    line    int
+    endLine int
+    code    string
+}
+
+func isGoFence(info string) bool {
+    lang, _, _ := strings.Cut(strings.TrimSpace(info), " ")
+    lang = strings.Trim(lang, "{}.")
+    return lang == "go" || lang == "golang"
+}
+
+// fencedGoBlocks находит блоки кода Go, огороженные ``` или ~~~
+func fencedGoBlocks(content string) []fencedGoBlock {
+    var blocks []fencedGoBlock
+    var current *fencedGoBlock
+    var fence string
+    var code []string
+    sc := bufio.NewScanner(strings.NewReader(content))
+    sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    lineNo := 0
+    for sc.Scan() {
+        lineNo++
+        line := sc.Text()
+        trimmed := strings.TrimSpace(line)
+        if fence == "" {
+            for _, marker := range []string{"```", "~~~"} {
+                if strings.HasPrefix(trimmed, marker) {
+                    n := len(trimmed) - len(strings.TrimLeft(trimmed, marker[:1]))
+                    fence = strings.Repeat(marker[:1], n)
+                    if isGoFence(trimmed[n:]) {
+                        current = &fencedGoBlock{line: lineNo + 1}
+                        code = nil
+                    }
+                    break
+                }
+            }
+            continue
+        }
+        if strings.HasPrefix(trimmed, fence) && strings.TrimLeft(trimmed, fence[:1]) == "" {
+            if current != nil {
+                current.endLine = lineNo - 1
+                current.code = strings.Join(code, "\n")
+                blocks = append(blocks, *current)
+                current = nil
+            }
+            fence = ""
+            continue
+        }
+        if current != nil {
+            code = append(code, line)
+        }
+    }
+    return blocks
+}
+
+// parseDocBlock разбирает фрагмент в первой подходящей обёртке; при
+// неудаче возвращает ошибку для наиболее вероятной формы фрагмента с
+// позицией в документе; firstLine - строка документа, где начинается код
+func parseDocBlock(code string, firstLine int) (*ast.File, string, error) {
+    trimmed := strings.TrimSpace(code)
+    likely := "statements"
+    switch first, _, _ := strings.Cut(trimmed, " "); first {
+    case "package":
+        likely = "file"
+    case "func", "type", "import", "var", "const":
+        likely = "declarations"
+    }
+
+    var likelyErr error
+    for _, form := range docBlockForms {
+        fset := token.NewFileSet()
+        file, err := parser.ParseFile(fset, "", form.prefix+code+form.suffix, parser.SkipObjectResolution)
+        if err == nil {
+            return file, form.name, nil
+        }
+        if form.name == likely {
+            likelyErr = shiftDocError(err, firstLine-1-strings.Count(form.prefix, "\n"))
+        }
+    }
+    return nil, "", likelyErr
+}
+
+// shiftDocError переводит строку ошибки из обёртки в строку документа
+func shiftDocError(err error, shift int) error {
+    list, ok := err.(scanner.ErrorList)
+    if !ok || len(list) == 0 {
+        return err
+    }
+    first := list[0]
+    return fmt.Errorf("%d:%d: %s", first.Pos.Line+shift, first.Pos.Column, first.Msg)
+}
+
+// docSymbolIndex - экспортируемые символы пакетов проекта
+type docSymbolIndex struct {
+    byPath map[string]*packages.Package
+    byName map[string][]string
+}
+
+func newDocSymbolIndex(pkgs []*packages.Package) *docSymbolIndex {
+    index := &docSymbolIndex{byPath: make(map[string]*packages.Package), byName: make(map[string][]string)}
+    for _, pkg := range pkgs {
+        if pkg.Types == nil || index.byPath[pkg.PkgPath] != nil {
+            continue
+        }
+        index.byPath[pkg.PkgPath] = pkg
+        index.byName[pkg.Name] = append(index.byName[pkg.Name], pkg.PkgPath)
+    }
+    return index
+}
+
+// references связывает селекторы pkg.Name фрагмента с символами проекта:
+// пакет ищется по импортам фрагмента, иначе по имени пакета проекта
+func (idx *docSymbolIndex) references(file *ast.File) ([]string, []string) {
+    imports := make(map[string]string)
+    for _, imp := range file.Imports {
+        importPath, _ := strconv.Unquote(imp.Path.Value)
+        name := path.Base(importPath)
+        if pkg := idx.byPath[importPath]; pkg != nil {
+            name = pkg.Name
+        }
+        if imp.Name != nil {
+            name = imp.Name.Name
+        }
+        imports[name] = importPath
+    }
+
+    refs, unresolved := []string{}, []string{}
+    ast.Inspect(file, func(n ast.Node) bool {
+        sel, ok := n.(*ast.SelectorExpr)
+        if !ok {
+            return true
+        }
+        x, ok := sel.X.(*ast.Ident)
+        if !ok || !sel.Sel.IsExported() {
+            return true
+        }
+        pkgPath, imported := imports[x.Name]
+        if !imported {
+            // Без импорта - только однозначное имя пакета проекта
+            if candidates := idx.byName[x.Name]; len(candidates) == 1 {
+                pkgPath = candidates[0]
+            }
+        }
+        pkg := idx.byPath[pkgPath]
+        if pkg == nil {
+            return true
+        }
+        id := pkgPath + "." + sel.Sel.Name
+        if pkg.Types.Scope().Lookup(sel.Sel.Name) != nil {
+            refs = appendUnique(refs, id)
+        } else {
+            unresolved = appendUnique(unresolved, id)
+        }
+        return true
+    })
+    sort.Strings(refs)
+    sort.Strings(unresolved)
+    return refs, unresolved
+}
+
+// extractDocExamples извлекает блоки Go из Markdown-документации, проверяет,
+// что они разбираются, и связывает их с символами проекта; неразрешённые
+// ссылки указывают на устаревшие примеры
+func extractDocExamples(projectPath string, pkgs []*packages.Package) ([]DocCodeBlock, error) {
+    index := newDocSymbolIndex(pkgs)
+    var examples []DocCodeBlock
+    err := filepath.WalkDir(projectPath, func(p string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if d.IsDir() {
+            if p != projectPath && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if ext := strings.ToLower(filepath.Ext(p)); ext != ".md" && ext != ".markdown" {
+            return nil
+        }
+        content, err := os.ReadFile(p)
+        if err != nil {
+            return err
+        }
+        for _, block := range fencedGoBlocks(string(content)) {
+            example := DocCodeBlock{
+                File:       relativePath(projectPath, p),
+                Line:       block.line,
+                EndLine:    block.endLine,
+                References: []string{},
+                Unresolved: []string{},
+            }
+            file, form, err := parseDocBlock(block.code, block.line)
+            if err != nil {
+                example.Error = err.Error()
+            } else {
+                example.Form = form
+                example.Parses = true
+                example.References, example.Unresolved = index.references(file)
+            }
+            examples = append(examples, example)
+        }
+        return nil
+    })
+    return examples, err
+}
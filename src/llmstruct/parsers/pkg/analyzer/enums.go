@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type EnumMember struct {
+    Name         string   `json:"name"`
+    Value        string   `json:"value"`
+    Line         int      `json:"line"`
+}
+
+// Enum - именованный тип, значения которого объявлены блоком const с
+// iota. Members - в порядке объявления, включая константы типа из других
+// блоков с iota; Stringer - у типа есть метод String
+type Enum struct {
+    ID           string       `json:"id"`
+    Underlying   string       `json:"underlying"`
+    File         string       `json:"file"`
+    Line         int          `json:"line"`
+    Stringer     bool         `json:"stringer"`
+    Members      []EnumMember `json:"members"`
+}
+
+// usesIota - встречается ли в выражениях предопределённый iota
+func usesIota(info *types.Info, exprs []ast.Expr) bool {
+    found := false
+    for _, expr := range exprs {
+        ast.Inspect(expr, func(n ast.Node) bool {
+            if id, ok := n.(*ast.Ident); ok && info.Uses[id] == types.Universe.Lookup("iota") {
+                found = true
+            }
+            return !found
+        })
+    }
+    return found
+}
+
+// extractEnums собирает блоки const с iota над именованными типами
+// проекта. Значение неявно повторённого выражения ("Info" после
+// "Debug Level = iota") берётся из проверки типов
+func extractEnums(pkgs []*packages.Package, projectPath string) []Enum {
+    enums := []Enum{}
+    byType := make(map[*types.TypeName]int)
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        for _, file := range pkg.Syntax {
+            for _, decl := range file.Decls {
+                gd, ok := decl.(*ast.GenDecl)
+                if !ok || gd.Tok != token.CONST {
+                    continue
+                }
+                hasIota := false
+                for _, spec := range gd.Specs {
+                    if usesIota(pkg.TypesInfo, spec.(*ast.ValueSpec).Values) {
+                        hasIota = true
+                    }
+                }
+                if !hasIota {
+                    continue
+                }
+                for _, spec := range gd.Specs {
+                    for _, name := range spec.(*ast.ValueSpec).Names {
+                        c, ok := pkg.TypesInfo.Defs[name].(*types.Const)
+                        if !ok || name.Name == "_" {
+                            continue
+                        }
+                        named, ok := c.Type().(*types.Named)
+                        if !ok || named.Obj().Pkg() != pkg.Types {
+                            continue
+                        }
+                        tn := named.Obj()
+                        i, ok := byType[tn]
+                        if !ok {
+                            pos := pkg.Fset.Position(tn.Pos())
+                            stringer := types.NewMethodSet(types.NewPointer(named)).Lookup(tn.Pkg(), "String") != nil
+                            enums = append(enums, Enum{
+                                ID:         goSymbolID(pkg.PkgPath, tn.Name()),
+                                Underlying: named.Underlying().String(),
+                                File:       relativePath(projectPath, pos.Filename),
+                                Line:       pos.Line,
+                                Stringer:   stringer,
+                                Members:    []EnumMember{},
+                            })
+                            i = len(enums) - 1
+                            byType[tn] = i
+                        }
+                        value, _ := constantValue(c.Val())
+                        enums[i].Members = append(enums[i].Members, EnumMember{
+                            Name:  name.Name,
+                            Value: value,
+                            Line:  pkg.Fset.Position(name.Pos()).Line,
+                        })
+                    }
+                }
+            }
+        }
+    }
+    sort.Slice(enums, func(i, j int) bool { return enums[i].ID < enums[j].ID })
+    return enums
+}
@@ -0,0 +1,360 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// FunctionErrorHandling - как функция обходится с ошибками вызываемых ею
+// функций; Styles - число мест каждого вида
+type FunctionErrorHandling struct {
+    Function     string         `json:"function"`
+    File         string         `json:"file"`
+    Line         int            `json:"line"`
+    Styles       map[string]int `json:"styles"`
+    Dominant     string         `json:"dominant"`
+}
+
+type PackageErrorHandling struct {
+    Package      string         `json:"package"`
+    Functions    int            `json:"functions"`
+    Styles       map[string]int `json:"styles"`
+    Dominant     string         `json:"dominant"`
+}
+
+// IgnoredError - ошибка, которая теряется: Via - blank (присвоена _),
+// unchecked (присвоена, но не проверяется), discarded (результат вызова
+// отброшен), defer (отложенный вызов)
+type IgnoredError struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+    Call         string   `json:"call"`
+    Via          string   `json:"via"`
+}
+
+// ErrorHandlingReport - стиль обработки ошибок по функциям и пакетам;
+// Ignored упорядочены по приоритету проверки
+type ErrorHandlingReport struct {
+    Packages     []PackageErrorHandling  `json:"packages"`
+    Functions    []FunctionErrorHandling `json:"functions"`
+    Ignored      []IgnoredError          `json:"ignored"`
+}
+
+// Порядок важности потерянных ошибок
+var ignoredPriority = map[string]int{"blank": 0, "unchecked": 1, "discarded": 2, "defer": 3}
+
+// Вызовы, ошибки которых по соглашению не проверяют
+var neverFailing = map[string]bool{
+    "fmt.Print": true, "fmt.Printf": true, "fmt.Println": true,
+    "(*strings.Builder).Write": true, "(*strings.Builder).WriteString": true, "(*strings.Builder).WriteByte": true, "(*strings.Builder).WriteRune": true,
+    "(*bytes.Buffer).Write": true, "(*bytes.Buffer).WriteString": true, "(*bytes.Buffer).WriteByte": true, "(*bytes.Buffer).WriteRune": true,
+}
+
+// Методы логгеров, вызов которых с ошибкой считается логированием
+var logMethods = map[string]bool{
+    "Print": true, "Printf": true, "Println": true, "Log": true, "Logf": true,
+    "Error": true, "Errorf": true, "Errorw": true, "Warn": true, "Warnf": true, "Warnw": true, "Warning": true,
+    "Info": true, "Infof": true, "Infow": true, "Debug": true, "Debugf": true, "Debugw": true,
+}
+
+type errorHandlingFinder struct {
+    info     *types.Info
+    fset     *token.FileSet
+    body     *ast.BlockStmt
+    styles   map[string]int
+    ignored  []IgnoredError
+    function string
+    file     string
+}
+
+// returnsError сообщает, что последний результат вызова - error
+func returnsError(info *types.Info, call *ast.CallExpr) bool {
+    t := info.TypeOf(call)
+    if tuple, ok := t.(*types.Tuple); ok {
+        return tuple.Len() > 0 && isErrorType(tuple.At(tuple.Len()-1).Type())
+    }
+    return t != nil && isErrorType(t)
+}
+
+// errorSource - вызов, который может вернуть ошибку; конструкторы
+// ошибок (errors.New, fmt.Errorf) источниками не считаются
+func (f *errorHandlingFinder) errorSource(expr ast.Expr) *ast.CallExpr {
+    call, ok := ast.Unparen(expr).(*ast.CallExpr)
+    if !ok || !returnsError(f.info, call) {
+        return nil
+    }
+    if fn := calleeFunc(f.info, call); fn != nil && fn.Pkg() != nil {
+        if fn.Pkg().Path() == "errors" || fn.FullName() == "fmt.Errorf" {
+            return nil
+        }
+    }
+    return call
+}
+
+func (f *errorHandlingFinder) ignore(call *ast.CallExpr, via string) {
+    if fn := calleeFunc(f.info, call); fn != nil && neverFailing[fn.FullName()] {
+        return
+    }
+    f.styles["ignored"]++
+    f.ignored = append(f.ignored, IgnoredError{
+        File:     f.file,
+        Line:     f.fset.Position(call.Pos()).Line,
+        Function: f.function,
+        Call:     types.ExprString(call.Fun),
+        Via:      via,
+    })
+}
+
+// isLogCall - вызов пакетов log/slog, fmt.Fprint* или метода логгера
+func (f *errorHandlingFinder) isLogCall(call *ast.CallExpr) bool {
+    fn := calleeFunc(f.info, call)
+    if fn == nil || fn.Pkg() == nil {
+        return false
+    }
+    switch fn.Pkg().Path() {
+    case "log", "log/slog":
+        return true
+    case "fmt":
+        return fn.Name() != "Errorf" && fn.Name() != "Sprintf" && fn.Name() != "Sprint" && fn.Name() != "Sprintln"
+    }
+    sig, _ := fn.Type().(*types.Signature)
+    return sig != nil && sig.Recv() != nil && logMethods[fn.Name()]
+}
+
+// classifyHandler определяет стиль по телу ветки if err != nil
+func (f *errorHandlingFinder) classifyHandler(body *ast.BlockStmt, obj types.Object) string {
+    objs := map[types.Object]bool{obj: true}
+    style := "handled"
+    logged := false
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.FuncLit:
+            return false
+        case *ast.ReturnStmt:
+            for _, result := range x.Results {
+                if id, ok := ast.Unparen(result).(*ast.Ident); ok && f.info.Uses[id] == obj {
+                    style = "returned"
+                } else if style != "returned" && usesObject(f.info, result, objs) {
+                    style = "wrapped"
+                }
+            }
+        case *ast.ExprStmt:
+            if isTerminating(f.info, x) {
+                style = "panicked"
+                return false
+            }
+            if call, ok := x.X.(*ast.CallExpr); ok && f.isLogCall(call) && usesObject(f.info, call, objs) {
+                logged = true
+            }
+        }
+        return style == "handled"
+    })
+    if style == "handled" && logged {
+        return "logged"
+    }
+    return style
+}
+
+// usedAfter сообщает, читается ли переменная после позиции pos
+func (f *errorHandlingFinder) usedAfter(obj types.Object, pos token.Pos) bool {
+    used := false
+    ast.Inspect(f.body, func(n ast.Node) bool {
+        if id, ok := n.(*ast.Ident); ok && id.Pos() > pos && f.info.Uses[id] == obj {
+            used = true
+        }
+        return !used
+    })
+    return used
+}
+
+// assigned разбирает присваивание результата вызова; next - следующий
+// оператор блока или сам if для if err := f(); err != nil
+func (f *errorHandlingFinder) assigned(lhs []ast.Expr, rhs []ast.Expr, next ast.Stmt) {
+    type site struct {
+        call *ast.CallExpr
+        dst  ast.Expr
+    }
+    var sites []site
+    if len(rhs) == 1 {
+        if call := f.errorSource(rhs[0]); call != nil && len(lhs) > 0 {
+            sites = append(sites, site{call, lhs[len(lhs)-1]})
+        }
+    } else if len(lhs) == len(rhs) {
+        for i := range rhs {
+            if call := f.errorSource(rhs[i]); call != nil {
+                sites = append(sites, site{call, lhs[i]})
+            }
+        }
+    }
+    for _, s := range sites {
+        id, ok := s.dst.(*ast.Ident)
+        if !ok {
+            f.styles["handled"]++
+            continue
+        }
+        if id.Name == "_" {
+            f.ignore(s.call, "blank")
+            continue
+        }
+        obj := f.info.ObjectOf(id)
+        switch n := next.(type) {
+        case *ast.IfStmt:
+            if usesObject(f.info, n.Cond, map[types.Object]bool{obj: true}) {
+                f.styles[f.classifyHandler(n.Body, obj)]++
+                continue
+            }
+        case *ast.ReturnStmt:
+            if usesObject(f.info, n, map[types.Object]bool{obj: true}) {
+                f.styles["returned"]++
+                continue
+            }
+        }
+        if f.usedAfter(obj, s.call.End()) {
+            f.styles["handled"]++
+        } else {
+            f.ignore(s.call, "unchecked")
+        }
+    }
+}
+
+func (f *errorHandlingFinder) stmts(list []ast.Stmt) {
+    for i, stmt := range list {
+        var next ast.Stmt
+        if i+1 < len(list) {
+            next = list[i+1]
+        }
+        switch s := stmt.(type) {
+        case *ast.ExprStmt:
+            if call := f.errorSource(s.X); call != nil {
+                f.ignore(call, "discarded")
+            }
+        case *ast.DeferStmt:
+            if call := f.errorSource(s.Call); call != nil {
+                f.ignore(call, "defer")
+            }
+        case *ast.GoStmt:
+            if call := f.errorSource(s.Call); call != nil {
+                f.ignore(call, "discarded")
+            }
+        case *ast.AssignStmt:
+            f.assigned(s.Lhs, s.Rhs, next)
+        case *ast.DeclStmt:
+            if gen, ok := s.Decl.(*ast.GenDecl); ok {
+                for _, spec := range gen.Specs {
+                    if vs, ok := spec.(*ast.ValueSpec); ok && len(vs.Values) > 0 {
+                        lhs := make([]ast.Expr, len(vs.Names))
+                        for i, name := range vs.Names {
+                            lhs[i] = name
+                        }
+                        f.assigned(lhs, vs.Values, next)
+                    }
+                }
+            }
+        case *ast.IfStmt:
+            if init, ok := s.Init.(*ast.AssignStmt); ok {
+                f.assigned(init.Lhs, init.Rhs, s)
+            }
+        case *ast.ReturnStmt:
+            // return f() - ошибка передаётся вызывающему как есть
+            for _, result := range s.Results {
+                if f.errorSource(result) != nil {
+                    f.styles["returned"]++
+                }
+            }
+        }
+    }
+}
+
+func dominantStyle(styles map[string]int) string {
+    best := ""
+    for style, n := range styles {
+        if best == "" || n > styles[best] || n == styles[best] && style < best {
+            best = style
+        }
+    }
+    return best
+}
+
+// extractErrorHandling классифицирует обработку каждой ошибки, которую
+// функции проекта получают от вызовов: returned (возвращается как есть),
+// wrapped (оборачивается), logged (логируется, выполнение продолжается),
+// panicked (panic/log.Fatal/os.Exit), ignored, handled (прочее)
+func extractErrorHandling(pkgs []*packages.Package, projectPath string) *ErrorHandlingReport {
+    report := &ErrorHandlingReport{Packages: []PackageErrorHandling{}, Functions: []FunctionErrorHandling{}, Ignored: []IgnoredError{}}
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        pkgReport := PackageErrorHandling{Package: pkg.PkgPath, Styles: make(map[string]int)}
+        for _, file := range pkg.Syntax {
+            for _, decl := range file.Decls {
+                fd, ok := decl.(*ast.FuncDecl)
+                if !ok || fd.Body == nil {
+                    continue
+                }
+                pos := pkg.Fset.Position(fd.Pos())
+                f := &errorHandlingFinder{
+                    info:     pkg.TypesInfo,
+                    fset:     pkg.Fset,
+                    body:     fd.Body,
+                    styles:   make(map[string]int),
+                    function: funcID(pkg, fd),
+                    file:     relativePath(projectPath, pos.Filename),
+                }
+                ast.Inspect(fd.Body, func(n ast.Node) bool {
+                    switch b := n.(type) {
+                    case *ast.BlockStmt:
+                        f.stmts(b.List)
+                    case *ast.CaseClause:
+                        f.stmts(b.Body)
+                    case *ast.CommClause:
+                        f.stmts(b.Body)
+                    }
+                    return true
+                })
+                if len(f.styles) == 0 {
+                    continue
+                }
+                report.Functions = append(report.Functions, FunctionErrorHandling{
+                    Function: f.function,
+                    File:     f.file,
+                    Line:     pos.Line,
+                    Styles:   f.styles,
+                    Dominant: dominantStyle(f.styles),
+                })
+                report.Ignored = append(report.Ignored, f.ignored...)
+                pkgReport.Functions++
+                for style, n := range f.styles {
+                    pkgReport.Styles[style] += n
+                }
+            }
+        }
+        if pkgReport.Functions > 0 {
+            pkgReport.Dominant = dominantStyle(pkgReport.Styles)
+            report.Packages = append(report.Packages, pkgReport)
+        }
+    }
+    sort.Slice(report.Packages, func(i, j int) bool { return report.Packages[i].Package < report.Packages[j].Package })
+    sort.Slice(report.Functions, func(i, j int) bool {
+        if report.Functions[i].File != report.Functions[j].File {
+            return report.Functions[i].File < report.Functions[j].File
+        }
+        return report.Functions[i].Line < report.Functions[j].Line
+    })
+    sort.SliceStable(report.Ignored, func(i, j int) bool {
+        a, b := report.Ignored[i], report.Ignored[j]
+        if ignoredPriority[a.Via] != ignoredPriority[b.Via] {
+            return ignoredPriority[a.Via] < ignoredPriority[b.Via]
+        }
+        if a.File != b.File {
+            return a.File < b.File
+        }
+        return a.Line < b.Line
+    })
+    return report
+}
@@ -0,0 +1,391 @@
+package analyzer
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "os"
+    "path"
+    "slices"
+    "sort"
+    "strings"
+)
+
+// ExtractionSymbol - символ пакета проекта, который использует
+// выделяемый код; UsedBy - функции и типы выделяемых пакетов
+type ExtractionSymbol struct {
+    ID           string   `json:"id"`
+    Kind         string   `json:"kind"`
+    UsedBy       []string `json:"used_by"`
+}
+
+// ExtractionDep - пакет проекта вне выборки, от которого она зависит.
+// Action: take_along (используется только выделяемым кодом и уходит
+// вместе с ним), interface_away (используются только методы - их можно
+// описать интерфейсом в новом модуле), shared (функции, переменные или
+// типы нужны обоим модулям: общий модуль или копия)
+type ExtractionDep struct {
+    Package      string             `json:"package"`
+    Action       string             `json:"action"`
+    Reason       string             `json:"reason"`
+    Symbols      []ExtractionSymbol `json:"symbols"`
+}
+
+// ExtractionModule - внешний модуль, который понадобится в go.mod
+// нового модуля
+type ExtractionModule struct {
+    Path         string   `json:"path"`
+    Version      string   `json:"version,omitempty"`
+    Imports      []string `json:"imports"`
+}
+
+type ExtractionImport struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Import       string   `json:"import"`
+    NewImport    string   `json:"new_import,omitempty"`
+}
+
+// ExtractionConsumer - пакет проекта, импортирующий выделяемый код:
+// его импорты придётся обновить, а модуль - добавить в зависимости
+type ExtractionConsumer struct {
+    Package      string             `json:"package"`
+    Imports      []ExtractionImport `json:"imports"`
+    Symbols      []string           `json:"symbols"`
+}
+
+// ExtractionReport - можно ли выделить пакеты в отдельный модуль и что
+// для этого нужно. Packages - выборка вместе с пакетами, которые уходят
+// с ней (take_along)
+type ExtractionReport struct {
+    Selected     []string             `json:"selected"`
+    Packages     []string             `json:"packages"`
+    Module       string               `json:"module,omitempty"`
+    Feasible     bool                 `json:"feasible"`
+    Blockers     []string             `json:"blockers"`
+    ExternalDeps []ExtractionModule   `json:"external_deps"`
+    InternalDeps []ExtractionDep      `json:"internal_deps"`
+    Consumers    []ExtractionConsumer `json:"consumers"`
+}
+
+// symbolIDPackage - путь пакета из стабильного ID символа
+func symbolIDPackage(id string) string {
+    id = strings.TrimPrefix(id, "go:")
+    slash := strings.LastIndex(id, "/")
+    if dot := strings.Index(id[slash+1:], "."); dot >= 0 {
+        return id[:slash+1+dot]
+    }
+    return id
+}
+
+// internalPath - путь с элементом internal: импортировать его можно
+// только из дерева родителя этого элемента
+func internalPath(pkg string) bool {
+    return strings.HasSuffix(pkg, "/internal") || strings.Contains(pkg, "/internal/") || strings.HasPrefix(pkg, "internal/")
+}
+
+// selectPackages разворачивает выборку: пути импорта, каталоги
+// относительно проекта ("./pkg/x") и шаблоны с "/..."
+func selectPackages(analysis *ProjectAnalysis, specs []string, known map[string]bool) ([]string, error) {
+    selected := make(map[string]bool)
+    for _, spec := range specs {
+        spec = strings.TrimSpace(spec)
+        if spec == "" {
+            continue
+        }
+        pattern := strings.TrimSuffix(spec, "/...")
+        recursive := pattern != spec || spec == "..."
+        if spec == "..." {
+            pattern = "."
+        }
+        if pattern == "." || strings.HasPrefix(pattern, "./") {
+            pattern = packagePathOf(analysis, path.Join(pattern, "x"))
+        }
+        found := false
+        for pkg := range known {
+            if pkg == pattern || recursive && hasPathPrefix(pkg, pattern) {
+                selected[pkg], found = true, true
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("no packages match %q", spec)
+        }
+    }
+    if len(selected) == 0 {
+        return nil, fmt.Errorf("no packages selected")
+    }
+    result := make([]string, 0, len(selected))
+    for pkg := range selected {
+        result = append(result, pkg)
+    }
+    sort.Strings(result)
+    return result, nil
+}
+
+// commonPackageRoot - общий каталог путей пакетов: от него отсчитываются
+// пути в новом модуле
+func commonPackageRoot(pkgs []string) string {
+    root := pkgs[0]
+    for _, pkg := range pkgs[1:] {
+        for root != "" && !hasPathPrefix(pkg, root) {
+            root = path.Dir(root)
+            if root == "." {
+                root = ""
+            }
+        }
+    }
+    return root
+}
+
+// buildExtraction оценивает выделение пакетов в отдельный модуль module
+func buildExtraction(projectPath string, analysis *ProjectAnalysis, specs []string, module string) (*ExtractionReport, error) {
+    imports := make(map[string]map[string]bool)
+    importers := make(map[string]map[string]bool)
+    known := make(map[string]bool)
+    for _, file := range analysis.Files {
+        pkg := packagePathOf(analysis, file.Path)
+        known[pkg] = true
+        if imports[pkg] == nil {
+            imports[pkg] = make(map[string]bool)
+        }
+        for _, imp := range file.Imports {
+            imports[pkg][imp.Path] = true
+        }
+    }
+    for pkg, deps := range imports {
+        for dep := range deps {
+            if known[dep] && dep != pkg {
+                if importers[dep] == nil {
+                    importers[dep] = make(map[string]bool)
+                }
+                importers[dep][pkg] = true
+            }
+        }
+    }
+    selected, err := selectPackages(analysis, specs, known)
+    if err != nil {
+        return nil, err
+    }
+    report := &ExtractionReport{
+        Selected:     selected,
+        Module:       module,
+        Blockers:     []string{},
+        ExternalDeps: []ExtractionModule{},
+        InternalDeps: []ExtractionDep{},
+        Consumers:    []ExtractionConsumer{},
+    }
+
+    // Пакет уходит вместе с выборкой, если все его импортёры уже в ней
+    moving := make(map[string]bool)
+    for _, pkg := range selected {
+        moving[pkg] = true
+    }
+    taken := make(map[string]bool)
+    for changed := true; changed; {
+        changed = false
+        for pkg := range moving {
+            for dep := range imports[pkg] {
+                if !known[dep] || moving[dep] {
+                    continue
+                }
+                all := true
+                for user := range importers[dep] {
+                    all = all && moving[user]
+                }
+                if all {
+                    moving[dep], taken[dep], changed = true, true, true
+                }
+            }
+        }
+    }
+    for pkg := range moving {
+        report.Packages = append(report.Packages, pkg)
+    }
+    sort.Strings(report.Packages)
+
+    // Символьные связи между выделяемым кодом и остальным проектом
+    symbols := goSymbols(analysis, false)
+    used := make(map[string]map[string]map[string]bool)
+    consumed := make(map[string]map[string]bool)
+    if analysis.refs != nil {
+        for _, ref := range analysis.refs.refs {
+            fromPkg, toPkg := symbolIDPackage(ref.from), symbolIDPackage(ref.to)
+            if symbols[ref.to] == nil || fromPkg == toPkg {
+                continue
+            }
+            if moving[fromPkg] && (!moving[toPkg] || taken[toPkg]) {
+                if used[toPkg] == nil {
+                    used[toPkg] = make(map[string]map[string]bool)
+                }
+                if used[toPkg][ref.to] == nil {
+                    used[toPkg][ref.to] = make(map[string]bool)
+                }
+                used[toPkg][ref.to][ref.from] = true
+                continue
+            }
+            if moving[fromPkg] || !moving[toPkg] {
+                continue
+            }
+            if consumed[fromPkg] == nil {
+                consumed[fromPkg] = make(map[string]bool)
+            }
+            consumed[fromPkg][ref.to] = true
+        }
+    }
+
+    // reaches - пакет транзитивно импортирует выделяемый код
+    reaches := func(start string) bool {
+        seen := map[string]bool{start: true}
+        queue := []string{start}
+        for len(queue) > 0 {
+            pkg := queue[0]
+            queue = queue[1:]
+            for dep := range imports[pkg] {
+                if moving[dep] {
+                    return true
+                }
+                if known[dep] && !seen[dep] {
+                    seen[dep] = true
+                    queue = append(queue, dep)
+                }
+            }
+        }
+        return false
+    }
+
+    deps := make(map[string]bool)
+    external := make(map[string]bool)
+    for pkg := range moving {
+        for dep := range imports[pkg] {
+            switch {
+            case taken[dep] || known[dep] && !moving[dep]:
+                deps[dep] = true
+            case !stdPackage(dep) && !hasPathPrefix(dep, analysis.ModuleName):
+                external[dep] = true
+            }
+        }
+    }
+    for dep := range deps {
+        d := ExtractionDep{Package: dep, Symbols: []ExtractionSymbol{}}
+        onlyMethods := len(used[dep]) > 0
+        for id, users := range used[dep] {
+            sym := ExtractionSymbol{ID: id, Kind: symbols[id].kind, UsedBy: []string{}}
+            for user := range users {
+                sym.UsedBy = append(sym.UsedBy, user)
+            }
+            sort.Strings(sym.UsedBy)
+            d.Symbols = append(d.Symbols, sym)
+            // Тип получателя нужен только как носитель методов
+            onlyMethods = onlyMethods && (sym.Kind == "method" || sym.Kind == "interface" || sym.Kind == "struct")
+        }
+        sort.Slice(d.Symbols, func(i, j int) bool { return d.Symbols[i].ID < d.Symbols[j].ID })
+        switch {
+        case taken[dep]:
+            d.Action, d.Reason = "take_along", "imported only by the extracted packages"
+        case onlyMethods:
+            d.Action, d.Reason = "interface_away", "only methods are used; declare an interface in the new module and pass the value in"
+        default:
+            d.Action, d.Reason = "shared", "also used by the rest of the project; move it to a shared module or copy it"
+            if reaches(dep) {
+                report.Blockers = append(report.Blockers, fmt.Sprintf("%s imports the extracted packages and is used by them: the modules would depend on each other", dep))
+            } else if internalPath(dep) {
+                report.Blockers = append(report.Blockers, fmt.Sprintf("%s is internal to %s and cannot be imported from another module", dep, analysis.ModuleName))
+            }
+        }
+        report.InternalDeps = append(report.InternalDeps, d)
+    }
+    sort.Slice(report.InternalDeps, func(i, j int) bool { return report.InternalDeps[i].Package < report.InternalDeps[j].Package })
+
+    // Модуль внешнего импорта - самый длинный подходящий путь модуля
+    var modules []ModuleInfo
+    if len(external) > 0 {
+        graph, err := buildModuleGraph(projectPath)
+        if err != nil {
+            slog.Warn("failed to resolve modules of external imports", "error", err)
+        } else {
+            modules = graph.Modules
+        }
+    }
+    byModule := make(map[string]*ExtractionModule)
+    for imp := range external {
+        var mod *ModuleInfo
+        for i := range modules {
+            if !modules[i].Main && hasPathPrefix(imp, modules[i].Path) && (mod == nil || len(modules[i].Path) > len(mod.Path)) {
+                mod = &modules[i]
+            }
+        }
+        key, version := imp, ""
+        if mod != nil {
+            key, version = mod.Path, mod.Version
+        }
+        if byModule[key] == nil {
+            byModule[key] = &ExtractionModule{Path: key, Version: version}
+        }
+        byModule[key].Imports = append(byModule[key].Imports, imp)
+    }
+    for _, m := range byModule {
+        sort.Strings(m.Imports)
+        report.ExternalDeps = append(report.ExternalDeps, *m)
+    }
+    sort.Slice(report.ExternalDeps, func(i, j int) bool { return report.ExternalDeps[i].Path < report.ExternalDeps[j].Path })
+
+    // Пакеты take_along снаружи не импортируются, корень - по выборке
+    root := commonPackageRoot(report.Selected)
+    consumers := make(map[string]*ExtractionConsumer)
+    for _, file := range analysis.Files {
+        pkg := packagePathOf(analysis, file.Path)
+        if moving[pkg] {
+            continue
+        }
+        for _, imp := range file.Imports {
+            if !moving[imp.Path] {
+                continue
+            }
+            c := consumers[pkg]
+            if c == nil {
+                c = &ExtractionConsumer{Package: pkg, Imports: []ExtractionImport{}, Symbols: []string{}}
+                consumers[pkg] = c
+                for id := range consumed[pkg] {
+                    c.Symbols = append(c.Symbols, id)
+                }
+                sort.Strings(c.Symbols)
+            }
+            ei := ExtractionImport{File: file.Path, Line: imp.Line, Import: imp.Path}
+            if module != "" {
+                ei.NewImport = module + strings.TrimPrefix(imp.Path, root)
+            }
+            c.Imports = append(c.Imports, ei)
+            if internalPath(strings.TrimPrefix(imp.Path, root)) {
+                report.Blockers = append(report.Blockers, fmt.Sprintf("%s imports internal package %s, which will not be importable from outside the new module", pkg, imp.Path))
+            }
+        }
+    }
+    for _, c := range consumers {
+        report.Consumers = append(report.Consumers, *c)
+    }
+    sort.Slice(report.Consumers, func(i, j int) bool { return report.Consumers[i].Package < report.Consumers[j].Package })
+    sort.Strings(report.Blockers)
+    report.Blockers = slices.Compact(report.Blockers)
+    report.Feasible = len(report.Blockers) == 0
+    return report, nil
+}
+
+// runExtractModule - analyzer extract-module: оценка выделения пакетов в
+// отдельный модуль
+func runExtractModule(args []string) {
+    fs := newCommandFlags("extract-module", "-packages <pkg,...> [-module path] [flags] [project_path]")
+    pkgList := fs.String("packages", "", "comma-separated packages to extract: import paths or ./dir, with /... for subpackages")
+    module := fs.String("module", "", "module path of the new module; consumers get their new import paths")
+    projectPath := fs.parse(args)
+    if *pkgList == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    analysis := analyzeProject(context.Background(), projectPath, defaultOptions())
+    report, err := buildExtraction(projectPath, analysis, strings.Split(*pkgList, ","), *module)
+    if err != nil {
+        fatal("module extraction analysis failed", "error", err)
+    }
+    fs.write(report)
+}
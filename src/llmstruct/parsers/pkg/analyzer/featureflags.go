@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type FlagSite struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+    Call         string   `json:"call"`
+}
+
+type FeatureFlag struct {
+    Key          string     `json:"key"`
+    Provider     string     `json:"provider"`
+    Dynamic      bool       `json:"dynamic,omitempty"`
+    Sites        []FlagSite `json:"sites"`
+}
+
+type flagSite struct {
+    key      string
+    provider string
+    dynamic  bool
+    site     FlagSite
+}
+
+// flagProvider - SDK фич-флагов. clientMethods - слишком общие имена
+// (String, Int): вычисление флага, только если получатель - один из
+// типов clients
+type flagProvider struct {
+    name          string
+    pkgPath       string
+    methods       []string
+    clientMethods []string
+    clients       []string
+}
+
+// Известные SDK фич-флагов: пакет и методы вычисления флага
+var flagProviders = []flagProvider{
+    {
+        name:    "launchdarkly",
+        pkgPath: "github.com/launchdarkly/go-server-sdk",
+        methods: []string{"BoolVariation", "StringVariation", "IntVariation", "Float64Variation", "JSONVariation"},
+    },
+    {
+        name:    "launchdarkly",
+        pkgPath: "gopkg.in/launchdarkly/go-server-sdk",
+        methods: []string{"BoolVariation", "StringVariation", "IntVariation", "Float64Variation", "JSONVariation"},
+    },
+    {
+        name:    "openfeature",
+        pkgPath: "github.com/open-feature/go-sdk",
+        methods:       []string{"BooleanValue", "StringValue", "IntValue", "FloatValue", "ObjectValue"},
+        clientMethods: []string{"Boolean", "String", "Int", "Float", "Object"},
+        clients:       []string{"Client", "IClient"},
+    },
+}
+
+func matchFlagProvider(fn *types.Func, opts Options) (string, bool) {
+    if fn.Pkg() == nil {
+        return "", false
+    }
+
+    pkgPath := fn.Pkg().Path()
+    name := fn.Name()
+    for _, provider := range flagProviders {
+        if !strings.HasPrefix(pkgPath, provider.pkgPath) {
+            continue
+        }
+        for _, method := range provider.methods {
+            // Варианты *Detail/*Details и *Ctx считаем тем же вычислением флага
+            if name == method || strings.HasPrefix(name, method+"Detail") || strings.HasPrefix(name, method+"Ctx") {
+                return provider.name, true
+            }
+        }
+        for _, method := range provider.clientMethods {
+            if name == method && flagClientMethod(fn, provider.clients) {
+                return provider.name, true
+            }
+        }
+    }
+
+    fullName := fn.FullName()
+    for _, re := range opts.FlagPatterns {
+        if re.MatchString(fullName) {
+            return "custom", true
+        }
+    }
+
+    return "", false
+}
+
+// flagClientMethod: fn - метод одного из типов clients (или указателя на
+// него)
+func flagClientMethod(fn *types.Func, clients []string) bool {
+    sig, ok := fn.Type().(*types.Signature)
+    if !ok || sig.Recv() == nil {
+        return false
+    }
+    named, ok := deref(sig.Recv().Type()).(*types.Named)
+    if !ok {
+        return false
+    }
+    for _, client := range clients {
+        if named.Obj().Name() == client {
+            return true
+        }
+    }
+    return false
+}
+
+// flagKey ищет ключ флага: первый строковый аргумент вызова, по возможности
+// вычисленный как константа (литерал или именованная константа)
+func flagKey(info *types.Info, call *ast.CallExpr) (string, bool) {
+    for _, arg := range call.Args {
+        tv, ok := info.Types[arg]
+        if !ok || tv.Type == nil {
+            continue
+        }
+        if basic, ok := tv.Type.Underlying().(*types.Basic); !ok || basic.Info()&types.IsString == 0 {
+            continue
+        }
+        if key, ok := constString(info, arg); ok {
+            return key, false
+        }
+        return types.ExprString(arg), true
+    }
+    return "", true
+}
+
+func extractFeatureFlags(pkg *packages.Package, projectPath string, opts Options) []flagSite {
+    if pkg.TypesInfo == nil {
+        return nil
+    }
+
+    var sites []flagSite
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok {
+                return true
+            }
+
+            fn := calleeFunc(pkg.TypesInfo, call)
+            if fn == nil {
+                return true
+            }
+
+            provider, ok := matchFlagProvider(fn, opts)
+            if !ok {
+                return true
+            }
+
+            key, dynamic := flagKey(pkg.TypesInfo, call)
+            pos := pkg.Fset.Position(call.Pos())
+            sites = append(sites, flagSite{
+                key:      key,
+                provider: provider,
+                dynamic:  dynamic,
+                site: FlagSite{
+                    File:     relativePath(projectPath, pos.Filename),
+                    Line:     pos.Line,
+                    Function: funcID(pkg, decl),
+                    Call:     fn.FullName(),
+                },
+            })
+            return true
+        })
+    }
+    return sites
+}
+
+// groupFeatureFlags объединяет места вычисления по ключу флага
+func groupFeatureFlags(sites []flagSite) []FeatureFlag {
+    byKey := make(map[string]*FeatureFlag)
+    var keys []string
+
+    for _, s := range sites {
+        id := s.provider + "\x00" + s.key
+        flag, ok := byKey[id]
+        if !ok {
+            flag = &FeatureFlag{
+                Key:      s.key,
+                Provider: s.provider,
+                Dynamic:  s.dynamic,
+                Sites:    []FlagSite{},
+            }
+            byKey[id] = flag
+            keys = append(keys, id)
+        }
+        flag.Sites = append(flag.Sites, s.site)
+    }
+
+    sort.Strings(keys)
+    var flags []FeatureFlag
+    for _, id := range keys {
+        flags = append(flags, *byKey[id])
+    }
+    return flags
+}
@@ -0,0 +1,218 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// FieldUsage - число чтений и записей поля по всему модулю. Flag:
+// never_read, never_written, unused; поля с тегами (json, db, ...)
+// читаются и пишутся через reflection и не помечаются
+type FieldUsage struct {
+    Name         string   `json:"name"`
+    Line         int      `json:"line"`
+    Reads        int      `json:"reads"`
+    Writes       int      `json:"writes"`
+    Tagged       bool     `json:"tagged,omitempty"`
+    Flag         string   `json:"flag,omitempty"`
+}
+
+// StructFieldUsage - тепловая карта полей структуры проекта
+type StructFieldUsage struct {
+    Struct       string       `json:"struct"`
+    File         string       `json:"file"`
+    Line         int          `json:"line"`
+    Fields       []FieldUsage `json:"fields"`
+}
+
+type fieldCounter struct {
+    reads  int
+    writes int
+}
+
+// fieldUsageBuilder считает обращения к полям структур, объявленных в
+// пакетах проекта
+type fieldUsageBuilder struct {
+    projectPath string
+    structs     []*types.TypeName
+    fset        map[*types.TypeName]*token.FileSet
+    counts      map[*types.Var]*fieldCounter
+}
+
+func newFieldUsageBuilder(projectPath string, pkgs []*packages.Package) *fieldUsageBuilder {
+    b := &fieldUsageBuilder{
+        projectPath: projectPath,
+        fset:        make(map[*types.TypeName]*token.FileSet),
+        counts:      make(map[*types.Var]*fieldCounter),
+    }
+    for _, pkg := range pkgs {
+        if pkg.Types == nil {
+            continue
+        }
+        scope := pkg.Types.Scope()
+        for _, name := range scope.Names() {
+            tn, ok := scope.Lookup(name).(*types.TypeName)
+            if !ok || tn.IsAlias() {
+                continue
+            }
+            st, ok := tn.Type().Underlying().(*types.Struct)
+            if !ok || st.NumFields() == 0 {
+                continue
+            }
+            b.structs = append(b.structs, tn)
+            b.fset[tn] = pkg.Fset
+            for i := 0; i < st.NumFields(); i++ {
+                b.counts[st.Field(i)] = &fieldCounter{}
+            }
+        }
+    }
+    return b
+}
+
+// counter возвращает счётчик поля; поля инстанцированных дженериков
+// сводятся к полям исходного типа
+func (b *fieldUsageBuilder) counter(obj types.Object) *fieldCounter {
+    v, ok := obj.(*types.Var)
+    if !ok || !v.IsField() {
+        return nil
+    }
+    return b.counts[v.Origin()]
+}
+
+func (b *fieldUsageBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    info := pkg.TypesInfo
+    for _, file := range pkg.Syntax {
+        var stack []ast.Node
+        ast.Inspect(file, func(n ast.Node) bool {
+            if n == nil {
+                stack = stack[:len(stack)-1]
+                return false
+            }
+            stack = append(stack, n)
+            switch x := n.(type) {
+            case *ast.SelectorExpr:
+                sel, ok := info.Selections[x]
+                if !ok || sel.Kind() != types.FieldVal {
+                    return true
+                }
+                c := b.counter(sel.Obj())
+                if c == nil {
+                    return true
+                }
+                read, write := fieldAccess(x, stack)
+                if read {
+                    c.reads++
+                }
+                if write {
+                    c.writes++
+                }
+            case *ast.CompositeLit:
+                t := info.TypeOf(x)
+                if ptr, ok := t.(*types.Pointer); ok {
+                    t = ptr.Elem()
+                }
+                st, ok := t.Underlying().(*types.Struct)
+                if !ok {
+                    return true
+                }
+                for i, elt := range x.Elts {
+                    if kv, ok := elt.(*ast.KeyValueExpr); ok {
+                        if key, ok := kv.Key.(*ast.Ident); ok {
+                            if c := b.counter(info.Uses[key]); c != nil {
+                                c.writes++
+                            }
+                        }
+                    } else if i < st.NumFields() {
+                        if c := b.counter(st.Field(i)); c != nil {
+                            c.writes++
+                        }
+                    }
+                }
+            }
+            return true
+        })
+    }
+}
+
+// fieldAccess определяет, читается или пишется поле x.F: присваивание -
+// запись, x.F += 1 и x.F++ - и то и другое, &x.F - запись (значение
+// меняют через указатель)
+func fieldAccess(sel *ast.SelectorExpr, stack []ast.Node) (read, write bool) {
+    if len(stack) < 2 {
+        return true, false
+    }
+    switch parent := stack[len(stack)-2].(type) {
+    case *ast.AssignStmt:
+        for _, lhs := range parent.Lhs {
+            if lhs == sel {
+                return parent.Tok != token.ASSIGN && parent.Tok != token.DEFINE, true
+            }
+        }
+    case *ast.IncDecStmt:
+        return true, true
+    case *ast.UnaryExpr:
+        if parent.Op == token.AND {
+            return false, true
+        }
+    case *ast.RangeStmt:
+        if parent.Key == sel || parent.Value == sel {
+            return false, true
+        }
+    }
+    return true, false
+}
+
+func (b *fieldUsageBuilder) build() []StructFieldUsage {
+    result := []StructFieldUsage{}
+    for _, tn := range b.structs {
+        st := tn.Type().Underlying().(*types.Struct)
+        pos := b.fset[tn].Position(tn.Pos())
+        usage := StructFieldUsage{
+            Struct: objectSymbolID(tn),
+            File:   relativePath(b.projectPath, pos.Filename),
+            Line:   pos.Line,
+            Fields: []FieldUsage{},
+        }
+        for i := 0; i < st.NumFields(); i++ {
+            field := st.Field(i)
+            if field.Name() == "_" {
+                continue
+            }
+            c := b.counts[field]
+            fu := FieldUsage{
+                Name:   field.Name(),
+                Line:   b.fset[tn].Position(field.Pos()).Line,
+                Reads:  c.reads,
+                Writes: c.writes,
+                Tagged: st.Tag(i) != "",
+            }
+            // Встроенные поля участвуют в продвижении методов и полей
+            if !fu.Tagged && !field.Embedded() {
+                switch {
+                case c.reads == 0 && c.writes == 0:
+                    fu.Flag = "unused"
+                case c.reads == 0:
+                    fu.Flag = "never_read"
+                case c.writes == 0:
+                    fu.Flag = "never_written"
+                }
+            }
+            usage.Fields = append(usage.Fields, fu)
+        }
+        result = append(result, usage)
+    }
+    sort.SliceStable(result, func(i, j int) bool {
+        if result[i].File != result[j].File {
+            return result[i].File < result[j].File
+        }
+        return result[i].Line < result[j].Line
+    })
+    return result
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// pathFilter отбирает файлы по шаблонам относительно корня проекта.
+// Шаблон - glob с ** для любого числа каталогов; x/... как у go list
+// эквивалентно x/**. Шаблон, совпавший с каталогом, захватывает всё, что
+// в нём лежит
+type pathFilter struct {
+    include []string
+    exclude []string
+}
+
+func newPathFilter(include, exclude []string) (*pathFilter, error) {
+    f := &pathFilter{}
+    for _, list := range []struct {
+        patterns []string
+        dst      *[]string
+    }{{include, &f.include}, {exclude, &f.exclude}} {
+        for _, pattern := range list.patterns {
+            pattern = normalizePattern(pattern)
+            // Проверяем синтаксис заранее, чтобы не молчать о кривом шаблоне
+            for _, part := range strings.Split(pattern, "/") {
+                if _, err := path.Match(part, ""); err != nil {
+                    return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+                }
+            }
+            *list.dst = append(*list.dst, pattern)
+        }
+    }
+    return f, nil
+}
+
+func normalizePattern(pattern string) string {
+    pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+    pattern = strings.TrimPrefix(pattern, "./")
+    pattern = strings.TrimSuffix(pattern, "/")
+    if pattern == "..." {
+        return "**"
+    }
+    if strings.HasSuffix(pattern, "/...") {
+        pattern = strings.TrimSuffix(pattern, "...") + "**"
+    }
+    return pattern
+}
+
+// globMatch сопоставляет сегменты пути с сегментами шаблона; ** - ноль
+// или больше сегментов. prefix - совпадение шаблона с каталогом пути
+func globMatch(pattern, name []string, prefix bool) bool {
+    if len(pattern) == 0 {
+        return len(name) == 0 || prefix
+    }
+    if pattern[0] == "**" {
+        for i := 0; i <= len(name); i++ {
+            if globMatch(pattern[1:], name[i:], prefix) {
+                return true
+            }
+        }
+        return false
+    }
+    if len(name) == 0 {
+        return false
+    }
+    ok, _ := path.Match(pattern[0], name[0])
+    return ok && globMatch(pattern[1:], name[1:], prefix)
+}
+
+func matchAny(patterns []string, rel string) bool {
+    name := strings.Split(rel, "/")
+    for _, pattern := range patterns {
+        if globMatch(strings.Split(pattern, "/"), name, true) {
+            return true
+        }
+    }
+    return false
+}
+
+// allowed: файл не исключён и попадает под include, если он задан
+func (f *pathFilter) allowed(rel string) bool {
+    rel = filepath.ToSlash(rel)
+    if matchAny(f.exclude, rel) {
+        return false
+    }
+    return len(f.include) == 0 || matchAny(f.include, rel)
+}
+
+// loadPatterns - шаблоны go list для include: каталог до первого сегмента
+// с glob, x/... -> ./x/.... Пакеты вне include тогда не загружаются и не
+// проверяются типами; точный отбор файлов остаётся за apply. nil - нужен
+// весь проект (include не задан или начинается с glob)
+func (f *pathFilter) loadPatterns(projectPath string) []string {
+    var patterns []string
+    for _, pattern := range f.include {
+        var dir []string
+        for _, part := range strings.Split(pattern, "/") {
+            if strings.ContainsAny(part, `*?[\`) {
+                break
+            }
+            dir = append(dir, part)
+        }
+        // Шаблон без glob может быть и каталогом, и файлом
+        if len(dir) > 0 && len(dir) == strings.Count(pattern, "/")+1 {
+            if info, err := os.Stat(filepath.Join(projectPath, filepath.FromSlash(pattern))); err != nil || !info.IsDir() {
+                dir = dir[:len(dir)-1]
+            }
+        }
+        if len(dir) == 0 || dir[0] == ".." {
+            return nil
+        }
+        patterns = append(patterns, "./"+strings.Join(dir, "/")+"/...")
+    }
+    return patterns
+}
+
+func (f *pathFilter) empty() bool {
+    return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// apply убирает из пакетов отфильтрованные файлы (синтаксис и списки
+// файлов согласованно) и пакеты, в которых файлов не осталось
+func (f *pathFilter) apply(projectPath string, pkgs []*packages.Package) ([]*packages.Package, []SkippedInput) {
+    if f.empty() {
+        return pkgs, nil
+    }
+    var kept []*packages.Package
+    var skipped []SkippedInput
+    for _, pkg := range pkgs {
+        var syntax []*ast.File
+        var compiled []string
+        excluded := 0
+        for i, filename := range pkg.CompiledGoFiles {
+            rel, err := filepath.Rel(projectPath, filename)
+            if err == nil && !f.allowed(rel) {
+                excluded++
+                continue
+            }
+            compiled = append(compiled, filename)
+            if i < len(pkg.Syntax) {
+                syntax = append(syntax, pkg.Syntax[i])
+            }
+        }
+        if excluded == 0 {
+            kept = append(kept, pkg)
+            continue
+        }
+        if len(compiled) == 0 {
+            skipped = append(skipped, SkippedInput{Path: pkg.PkgPath, Reason: "package excluded by include/exclude filters"})
+            continue
+        }
+        skipped = append(skipped, SkippedInput{Path: pkg.PkgPath, Reason: fmt.Sprintf("%d file(s) excluded by include/exclude filters", excluded)})
+        pkg.CompiledGoFiles, pkg.Syntax = compiled, syntax
+        var goFiles []string
+        for _, filename := range pkg.GoFiles {
+            if rel, err := filepath.Rel(projectPath, filename); err != nil || f.allowed(rel) {
+                goFiles = append(goFiles, filename)
+            }
+        }
+        pkg.GoFiles = goFiles
+        kept = append(kept, pkg)
+    }
+    return kept, skipped
+}
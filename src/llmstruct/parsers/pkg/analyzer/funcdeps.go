@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// FunctionDeps - от чего зависит функция: импортируемые пакеты, типы
+// проекта и неэкспортированные объекты своего пакета (именно они мешают
+// перенести функцию в другой пакет)
+type FunctionDeps struct {
+    Packages     []string `json:"packages"`
+    Types        []string `json:"types"`
+    Local        []string `json:"local"`
+}
+
+type funcDepsBuilder struct {
+    project map[string]bool
+}
+
+func newFuncDepsBuilder(pkgs []*packages.Package) *funcDepsBuilder {
+    b := &funcDepsBuilder{project: make(map[string]bool)}
+    for _, pkg := range pkgs {
+        b.project[pkg.PkgPath] = true
+    }
+    return b
+}
+
+// deps собирает зависимости по разрешённым идентификаторам сигнатуры и
+// тела функции
+func (b *funcDepsBuilder) deps(pkg *packages.Package, decl *ast.FuncDecl) *FunctionDeps {
+    deps := &FunctionDeps{Packages: []string{}, Types: []string{}, Local: []string{}}
+    self := objectSymbolID(pkg.TypesInfo.Defs[decl.Name])
+    ast.Inspect(decl, func(n ast.Node) bool {
+        id, ok := n.(*ast.Ident)
+        if !ok {
+            return true
+        }
+        obj := pkg.TypesInfo.Uses[id]
+        if obj == nil || obj.Pkg() == nil {
+            return true
+        }
+        if _, isPkg := obj.(*types.PkgName); isPkg {
+            return true
+        }
+        if obj.Pkg() != pkg.Types {
+            deps.Packages = appendUnique(deps.Packages, obj.Pkg().Path())
+        }
+        symbolID := objectSymbolID(obj)
+        if symbolID == "" || symbolID == self {
+            return true
+        }
+        if _, isType := obj.(*types.TypeName); isType && b.project[obj.Pkg().Path()] {
+            deps.Types = appendUnique(deps.Types, symbolID)
+        }
+        if obj.Pkg() == pkg.Types && !obj.Exported() {
+            deps.Local = appendUnique(deps.Local, strings.TrimPrefix(symbolID, goSymbolID(pkg.PkgPath, "")))
+        }
+        return true
+    })
+    sort.Strings(deps.Packages)
+    sort.Strings(deps.Types)
+    sort.Strings(deps.Local)
+    return deps
+}
+
+// annotate заполняет Deps у функций файла
+func (b *funcDepsBuilder) annotate(pkg *packages.Package, file *ast.File, analysis *FileAnalysis) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    for _, decl := range file.Decls {
+        fd, ok := decl.(*ast.FuncDecl)
+        if !ok {
+            continue
+        }
+        line := pkg.Fset.Position(fd.Pos()).Line
+        for i := range analysis.Functions {
+            if fn := &analysis.Functions[i]; fn.Name == fd.Name.Name && fn.Line == line {
+                fn.Deps = b.deps(pkg, fd)
+            }
+        }
+    }
+}
@@ -0,0 +1,30 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "regexp"
+    "strings"
+)
+
+// generatedRe - заголовок сгенерированного файла по соглашению go
+// (https://go.dev/s/generatedcode)
+var generatedRe = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// srcGenerated ищет заголовок до строки package. В отличие от
+// ast.IsGenerated работает и для файлов, разобранных без комментариев
+// (больше MaxFileSize), а это чаще всего и есть сгенерированный код
+func srcGenerated(src []byte) bool {
+    scanner := bufio.NewScanner(bytes.NewReader(src))
+    scanner.Buffer(nil, len(src)+1)
+    for scanner.Scan() {
+        line := strings.TrimRight(scanner.Text(), "\r")
+        if generatedRe.MatchString(line) {
+            return true
+        }
+        if strings.HasPrefix(line, "package ") {
+            return false
+        }
+    }
+    return false
+}
@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type GoroutineLeak struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+    Target       string   `json:"target"`
+    Reasons      []string `json:"reasons"`
+}
+
+type Diagnostics struct {
+    GoroutineLeaks []GoroutineLeak `json:"goroutine_leaks"`
+    RaceCandidates []RaceCandidate `json:"race_candidates"`
+    DeadBranches   []DeadBranch    `json:"dead_branches"`
+    DeadFiles      []DeadFile      `json:"dead_files"`
+    ResourceLeaks  []ResourceLeak  `json:"resource_leaks"`
+}
+
+func newDiagnostics() *Diagnostics {
+    return &Diagnostics{
+        GoroutineLeaks: []GoroutineLeak{},
+        RaceCandidates: []RaceCandidate{},
+        DeadBranches:   []DeadBranch{},
+        DeadFiles:      []DeadFile{},
+        ResourceLeaks:  []ResourceLeak{},
+    }
+}
+
+// funcDecls индексирует объявления функций пакета по объекту go/types
+func funcDecls(pkg *packages.Package) map[*types.Func]*ast.FuncDecl {
+    decls := make(map[*types.Func]*ast.FuncDecl)
+    if pkg.TypesInfo == nil {
+        return decls
+    }
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+                if fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+                    decls[fn] = fd
+                }
+            }
+        }
+    }
+    return decls
+}
+
+// isCancelSignal считает сигналом отмены context.Context и каналы struct{}
+// (done/quit/stop)
+func isCancelSignal(t types.Type) bool {
+    if t == nil {
+        return false
+    }
+    if isContextType(t) {
+        return true
+    }
+    ch, ok := t.Underlying().(*types.Chan)
+    if !ok {
+        return false
+    }
+    st, ok := ch.Elem().Underlying().(*types.Struct)
+    return ok && st.NumFields() == 0
+}
+
+// goroutineRisks ищет в теле горутины блокирующие операции без пути отмены
+func goroutineRisks(info *types.Info, body ast.Node, args []ast.Expr) []string {
+    cancellable := false
+    for _, arg := range args {
+        if tv, ok := info.Types[arg]; ok && isCancelSignal(tv.Type) {
+            cancellable = true
+        }
+    }
+
+    var reasons []string
+    seen := make(map[string]bool)
+    addReason := func(reason string) {
+        if !seen[reason] {
+            seen[reason] = true
+            reasons = append(reasons, reason)
+        }
+    }
+
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch node := n.(type) {
+        case *ast.Ident:
+            if obj := info.Uses[node]; obj != nil {
+                if _, ok := obj.(*types.Var); ok && isCancelSignal(obj.Type()) {
+                    cancellable = true
+                }
+            }
+        case *ast.CallExpr:
+            // time.After в select ограничивает ожидание
+            if fn := calleeFunc(info, node); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "time" && fn.Name() == "After" {
+                cancellable = true
+            }
+        case *ast.SendStmt:
+            addReason("blocking channel send")
+        case *ast.UnaryExpr:
+            if node.Op == token.ARROW {
+                addReason("blocking channel receive")
+            }
+        case *ast.RangeStmt:
+            if tv, ok := info.Types[node.X]; ok && tv.Type != nil {
+                if _, ok := tv.Type.Underlying().(*types.Chan); ok {
+                    addReason("range over channel")
+                }
+            }
+        case *ast.SelectStmt:
+            hasDefault := false
+            for _, clause := range node.Body.List {
+                if cc, ok := clause.(*ast.CommClause); ok && cc.Comm == nil {
+                    hasDefault = true
+                }
+            }
+            if !hasDefault {
+                addReason("select without default")
+            }
+        case *ast.ForStmt:
+            if node.Cond == nil {
+                addReason("infinite loop")
+            }
+        case *ast.FuncLit:
+            // Вложенные замыкания запускаются отдельно
+            return false
+        }
+        return true
+    })
+
+    if cancellable {
+        return nil
+    }
+    return reasons
+}
+
+func extractGoroutineLeaks(pkg *packages.Package, projectPath string) []GoroutineLeak {
+    if pkg.TypesInfo == nil {
+        return nil
+    }
+    decls := funcDecls(pkg)
+
+    var leaks []GoroutineLeak
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            stmt, ok := n.(*ast.GoStmt)
+            if !ok {
+                return true
+            }
+
+            var body ast.Node
+            target := "func literal"
+            switch fun := stmt.Call.Fun.(type) {
+            case *ast.FuncLit:
+                body = fun.Body
+            default:
+                fn := calleeFunc(pkg.TypesInfo, stmt.Call)
+                if fn == nil {
+                    return true
+                }
+                target = fn.FullName()
+                if fd, ok := decls[fn]; ok {
+                    body = fd.Body
+                }
+            }
+            // Тело вне пакета не анализируем
+            if body == nil {
+                return true
+            }
+
+            reasons := goroutineRisks(pkg.TypesInfo, body, stmt.Call.Args)
+            if len(reasons) == 0 {
+                return true
+            }
+
+            pos := pkg.Fset.Position(stmt.Pos())
+            leaks = append(leaks, GoroutineLeak{
+                File:     relativePath(projectPath, pos.Filename),
+                Line:     pos.Line,
+                Function: funcID(pkg, decl),
+                Target:   target,
+                Reasons:  reasons,
+            })
+            return true
+        })
+    }
+    return leaks
+}
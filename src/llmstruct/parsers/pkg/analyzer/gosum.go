@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "golang.org/x/mod/module"
+    "golang.org/x/mod/sumdb/dirhash"
+)
+
+type ChecksumIssue struct {
+    Module       string   `json:"module"`
+    Version      string   `json:"version"`
+    Problem      string   `json:"problem"`
+    Expected     string   `json:"expected,omitempty"`
+    Actual       string   `json:"actual,omitempty"`
+}
+
+// parseGoSum читает go.sum: ключ "path version" для архива модуля и
+// "path version/go.mod" для его go.mod
+func parseGoSum(path string) (map[string]string, error) {
+    content, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    sums := make(map[string]string)
+    for _, line := range strings.Split(string(content), "\n") {
+        fields := strings.Fields(line)
+        if len(fields) != 3 {
+            continue
+        }
+        sums[fields[0]+" "+fields[1]] = fields[2]
+    }
+    return sums, nil
+}
+
+// cachedSums возвращает хэши модуля из кэша загрузок: архив (.ziphash) и
+// go.mod (.mod); пустая строка - файла нет в кэше
+func cachedSums(modCache, path, version string) (string, string) {
+    escPath, err := module.EscapePath(path)
+    if err != nil {
+        return "", ""
+    }
+    escVersion, err := module.EscapeVersion(version)
+    if err != nil {
+        return "", ""
+    }
+    base := filepath.Join(modCache, "cache", "download", escPath, "@v", escVersion)
+
+    var zipSum, modSum string
+    if data, err := os.ReadFile(base + ".ziphash"); err == nil {
+        zipSum = strings.TrimSpace(string(data))
+    }
+    if fileExists(base + ".mod") {
+        modSum, _ = dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+            return os.Open(base + ".mod")
+        })
+    }
+    return zipSum, modSum
+}
+
+// providesPackage: модуль содержит хотя бы один из импортируемых пакетов
+func providesPackage(modPath string, imports []string) bool {
+    for _, imp := range imports {
+        if hasPathPrefix(imp, modPath) {
+            return true
+        }
+    }
+    return false
+}
+
+// verifyChecksums заполняет контрольные суммы модулей из go.sum и сверяет
+// их с требованиями и кэшем модулей
+func verifyChecksums(projectPath string, graph *ModuleGraph, imports []string) error {
+    graph.ChecksumIssues = []ChecksumIssue{}
+    sums, err := parseGoSum(filepath.Join(projectPath, "go.sum"))
+    if err != nil && !os.IsNotExist(err) {
+        return err
+    }
+
+    var modCache string
+    if out, err := runGo(projectPath, "env", "GOMODCACHE"); err == nil {
+        modCache = strings.TrimSpace(string(out))
+    }
+
+    for i := range graph.Modules {
+        m := &graph.Modules[i]
+        path, version := m.Path, m.Version
+        if m.Replace != nil {
+            // Локальная замена не проходит через go.sum
+            if m.Replace.Version == "" {
+                continue
+            }
+            path, version = m.Replace.Path, m.Replace.Version
+        }
+        if m.Main || version == "" {
+            continue
+        }
+
+        m.Sum = sums[path+" "+version]
+        m.GoModSum = sums[path+" "+version+"/go.mod"]
+        issue := func(problem, expected, actual string) {
+            graph.ChecksumIssues = append(graph.ChecksumIssues, ChecksumIssue{
+                Module:   path,
+                Version:  version,
+                Problem:  problem,
+                Expected: expected,
+                Actual:   actual,
+            })
+        }
+
+        // Хэш архива обязателен только для модулей, из которых импортируются пакеты
+        if m.Sum == "" && providesPackage(m.Path, imports) {
+            issue("missing from go.sum", "", "")
+        }
+        if m.GoModSum == "" {
+            issue("go.mod hash missing from go.sum", "", "")
+        }
+
+        if modCache == "" {
+            continue
+        }
+        zipSum, modSum := cachedSums(modCache, path, version)
+        if m.Sum != "" && zipSum != "" && zipSum != m.Sum {
+            issue("module cache mismatch", m.Sum, zipSum)
+        }
+        if m.GoModSum != "" && modSum != "" && modSum != m.GoModSum {
+            issue("go.mod cache mismatch", m.GoModSum, modSum)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,170 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/constant"
+    "go/token"
+    "go/types"
+    "regexp"
+    "sort"
+    "strconv"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// HardCodedValue - зашитое в код значение, которое обычно должно
+// приходить из конфигурации. Kind: timezone, locale, host_port,
+// magic_number
+type HardCodedValue struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    Kind         string   `json:"kind"`
+    Value        string   `json:"value"`
+    Context      string   `json:"context,omitempty"`
+}
+
+var (
+    timezonePattern = regexp.MustCompile(`^(Africa|America|Antarctica|Arctic|Asia|Atlantic|Australia|Europe|Indian|Pacific|Etc)/[A-Za-z0-9_+\-]+(/[A-Za-z0-9_+\-]+)?$`)
+    localePattern   = regexp.MustCompile(`^[a-z]{2,3}[_-][A-Z]{2}(\.(UTF-8|utf8))?$`)
+    hostPortPattern = regexp.MustCompile(`^(?:[a-z][a-z0-9+.-]*://)?(?:[^/@\s]*@)?(localhost|\d{1,3}(?:\.\d{1,3}){3}|\[[0-9a-fA-F:]+\]|[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)?:(\d{2,5})(?:/\S*)?$`)
+)
+
+// Числа, которые не считаются магическими: единицы, основания и
+// множители времени и размеров
+var ordinaryNumbers = map[string]bool{
+    "0": true, "1": true, "2": true, "10": true, "100": true, "1000": true,
+    "24": true, "60": true, "1024": true, "0.5": true, "1.0": true, "0.0": true,
+}
+
+var unitNumbers = map[string]bool{"24": true, "60": true, "1000": true, "1024": true}
+
+// hardCodedString классифицирует строковый литерал
+func hardCodedString(s string) string {
+    switch {
+    case timezonePattern.MatchString(s):
+        return "timezone"
+    case localePattern.MatchString(s):
+        return "locale"
+    }
+    if m := hostPortPattern.FindStringSubmatch(s); m != nil {
+        if port, err := strconv.Atoi(m[2]); err == nil && port > 0 && port <= 65535 {
+            return "host_port"
+        }
+    }
+    return ""
+}
+
+// isMagicOperand - числовой литерал в сравнении или арифметике бизнес-
+// логики; сдвиги, длительности и обычные числа пропускаются
+func isMagicOperand(info *types.Info, lit *ast.BasicLit, parent ast.Node) bool {
+    bin, ok := parent.(*ast.BinaryExpr)
+    if !ok || bin.Op == token.SHL || bin.Op == token.SHR || bin.Op == token.AND || bin.Op == token.OR || bin.Op == token.XOR {
+        return false
+    }
+    for _, operand := range []ast.Expr{bin.X, bin.Y} {
+        if t, ok := info.TypeOf(operand).(*types.Named); ok && t.Obj().Pkg() != nil && t.Obj().Pkg().Path() == "time" {
+            return false
+        }
+        // 64 * 1024, 7 * 24: размер или период в единицах
+        if other, ok := operand.(*ast.BasicLit); ok && other != lit && bin.Op == token.MUL && unitNumbers[other.Value] {
+            return false
+        }
+    }
+    value := lit.Value
+    if tv, ok := info.Types[lit]; ok && tv.Value != nil && tv.Value.Kind() == constant.Int {
+        // 0x10 и 16 - одно и то же число
+        value = tv.Value.ExactString()
+    }
+    return !ordinaryNumbers[value]
+}
+
+// extractHardCoded ищет зашитые часовые пояса, локали, адреса host:port и
+// магические числа в условиях и вычислениях библиотечного кода
+func extractHardCoded(pkgs []*packages.Package, projectPath string) []HardCodedValue {
+    values := []HardCodedValue{}
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        info := pkg.TypesInfo
+        for _, file := range pkg.Syntax {
+            var stack []ast.Node
+            ast.Inspect(file, func(n ast.Node) bool {
+                if n == nil {
+                    stack = stack[:len(stack)-1]
+                    return false
+                }
+                switch n.(type) {
+                case *ast.ImportSpec, *ast.CommentGroup:
+                    return false
+                }
+                stack = append(stack, n)
+                lit, ok := n.(*ast.BasicLit)
+                if !ok || len(stack) < 2 {
+                    return true
+                }
+                parent := stack[len(stack)-2]
+                // Теги структур - не значения
+                if field, ok := parent.(*ast.Field); ok && field.Tag == lit {
+                    return true
+                }
+                var fd *ast.FuncDecl
+                inConst := false
+                for _, node := range stack {
+                    switch s := node.(type) {
+                    case *ast.FuncDecl:
+                        fd = s
+                    case *ast.GenDecl:
+                        inConst = s.Tok == token.CONST
+                    }
+                }
+                var kind, context string
+                switch lit.Kind {
+                case token.STRING:
+                    s, err := strconv.Unquote(lit.Value)
+                    if err != nil {
+                        return true
+                    }
+                    kind = hardCodedString(s)
+                case token.INT, token.FLOAT:
+                    if fd == nil || inConst || pkg.Name == "main" || !isMagicOperand(info, lit, parent) {
+                        return true
+                    }
+                    kind = "magic_number"
+                    context = types.ExprString(parent.(ast.Expr))
+                }
+                if kind == "" {
+                    return true
+                }
+                if kind != "magic_number" {
+                    if call, ok := parent.(*ast.CallExpr); ok {
+                        context = types.ExprString(call.Fun)
+                    }
+                }
+                pos := pkg.Fset.Position(lit.Pos())
+                value := HardCodedValue{
+                    Package: pkg.PkgPath,
+                    File:    relativePath(projectPath, pos.Filename),
+                    Line:    pos.Line,
+                    Kind:    kind,
+                    Value:   lit.Value,
+                    Context: context,
+                }
+                if fd != nil {
+                    value.Function = funcID(pkg, fd)
+                }
+                values = append(values, value)
+                return true
+            })
+        }
+    }
+    sort.Slice(values, func(i, j int) bool {
+        if values[i].File != values[j].File {
+            return values[i].File < values[j].File
+        }
+        return values[i].Line < values[j].Line
+    })
+    return values
+}
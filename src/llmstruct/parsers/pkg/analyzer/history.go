@@ -0,0 +1,531 @@
+package analyzer
+
+import (
+    "bytes"
+    "compress/gzip"
+    "context"
+    "crypto/sha256"
+    "encoding/csv"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const defaultHistoryDir = ".llmstruct/history"
+
+// SnapshotMetrics - метрики снимка для трендов; Coverage - доля
+// покрытых операторов из coverprofile, если он был передан
+type SnapshotMetrics struct {
+    Files         int      `json:"files"`
+    Lines         int      `json:"lines"`
+    Functions     int      `json:"functions"`
+    Complexity    int      `json:"complexity"`
+    AvgComplexity float64  `json:"avg_complexity"`
+    APISize       int      `json:"api_size"`
+    Tokens        int      `json:"tokens"`
+    Coverage      *float64 `json:"coverage,omitempty"`
+}
+
+// Snapshot - запись журнала истории. Object - хэш содержимого анализа:
+// одинаковые анализы хранятся один раз
+type Snapshot struct {
+    ID           string          `json:"id"`
+    Object       string          `json:"object"`
+    Time         time.Time       `json:"time"`
+    Commit       string          `json:"commit,omitempty"`
+    Label        string          `json:"label,omitempty"`
+    Metrics      SnapshotMetrics `json:"metrics"`
+}
+
+type HistoryRecord struct {
+    Snapshot     Snapshot `json:"snapshot"`
+    Created      bool     `json:"created"`
+    Pruned       int      `json:"pruned"`
+    Alerts       []Alert  `json:"alerts,omitempty"`
+}
+
+// SnapshotDiff - изменения между двумя снимками: разница метрик (to -
+// from) и изменения API и форматов, как у -diff-base
+type SnapshotDiff struct {
+    From         Snapshot           `json:"from"`
+    To           Snapshot           `json:"to"`
+    Metrics      map[string]float64 `json:"metrics"`
+    Changes      *AnalysisDiff      `json:"changes"`
+}
+
+type TrendPoint struct {
+    ID           string             `json:"id"`
+    Time         time.Time          `json:"time"`
+    Commit       string             `json:"commit,omitempty"`
+    Label        string             `json:"label,omitempty"`
+    Values       map[string]float64 `json:"values"`
+}
+
+type MetricTrend struct {
+    Metrics      []string     `json:"metrics"`
+    Points       []TrendPoint `json:"points"`
+}
+
+// Метрики трендов в порядке колонок CSV
+var historyMetrics = []string{"files", "lines", "functions", "complexity", "avg_complexity", "api_size", "tokens", "coverage"}
+
+// historyStore - каталог истории: index.json со списком снимков в порядке
+// записи и objects/xx/<sha256>.json.gz с самими анализами
+type historyStore struct {
+    dir       string
+    Snapshots []Snapshot `json:"snapshots"`
+}
+
+func openHistory(dir string) (*historyStore, error) {
+    h := &historyStore{dir: dir, Snapshots: []Snapshot{}}
+    data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+    if errors.Is(err, os.ErrNotExist) {
+        return h, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(data, h); err != nil {
+        return nil, fmt.Errorf("history index: %v", err)
+    }
+    return h, nil
+}
+
+func (h *historyStore) save() error {
+    data, err := json.MarshalIndent(h, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(h.dir, 0o755); err != nil {
+        return err
+    }
+    return writeAtomic(filepath.Join(h.dir, "index.json"), append(data, '\n'))
+}
+
+func (h *historyStore) objectPath(hash string) string {
+    return filepath.Join(h.dir, "objects", hash[:2], hash+".json.gz")
+}
+
+// snapshotMetrics считает метрики по результату анализа; тесты не входят
+func snapshotMetrics(analysis *ProjectAnalysis) SnapshotMetrics {
+    m := SnapshotMetrics{Lines: analysis.TotalLines, APISize: len(goSymbols(analysis, true))}
+    for _, file := range analysis.Files {
+        m.Tokens += file.Tokens
+        if !file.HasTests {
+            m.Files++
+        }
+    }
+    for _, p := range analysis.Complexity {
+        m.Functions += p.Functions
+        m.Complexity += p.Total
+    }
+    if m.Functions > 0 {
+        m.AvgComplexity = roundMetric(float64(m.Complexity) / float64(m.Functions))
+    }
+    return m
+}
+
+func (m SnapshotMetrics) values() map[string]float64 {
+    values := map[string]float64{
+        "files":          float64(m.Files),
+        "lines":          float64(m.Lines),
+        "functions":      float64(m.Functions),
+        "complexity":     float64(m.Complexity),
+        "avg_complexity": m.AvgComplexity,
+        "api_size":       float64(m.APISize),
+        "tokens":         float64(m.Tokens),
+    }
+    if m.Coverage != nil {
+        values["coverage"] = *m.Coverage
+    }
+    return values
+}
+
+// coverProfileTotal - доля покрытых операторов по профилю go test
+// -coverprofile; блоки, повторённые в профиле, считаются один раз
+func coverProfileTotal(path string) (float64, error) {
+    files, err := readCoverProfile(path)
+    if err != nil {
+        return 0, err
+    }
+    total, covered := 0, 0
+    for _, blocks := range files {
+        for _, b := range blocks {
+            total += b.stmts
+            if b.covered {
+                covered += b.stmts
+            }
+        }
+    }
+    if total == 0 {
+        return 0, nil
+    }
+    return roundMetric(float64(covered) / float64(total) * 100), nil
+}
+
+// record сохраняет анализ; повтор последнего снимка (тот же анализ и
+// коммит) не добавляет записи. Время этапов в снимок не входит, иначе
+// одинаковые анализы различались бы
+func (h *historyStore) record(analysis *ProjectAnalysis, commit, label string, coverage *float64) (Snapshot, bool, error) {
+    stored := *analysis
+    stored.Meta = nil
+    data, err := json.Marshal(&stored)
+    if err != nil {
+        return Snapshot{}, false, err
+    }
+    sum := sha256.Sum256(data)
+    hash := hex.EncodeToString(sum[:])
+    metrics := snapshotMetrics(analysis)
+    metrics.Coverage = coverage
+    if n := len(h.Snapshots); n > 0 {
+        last := h.Snapshots[n-1]
+        if last.Object == hash && last.Commit == commit && label == "" && coverage == nil {
+            return last, false, nil
+        }
+    }
+    path := h.objectPath(hash)
+    if _, err := os.Stat(path); err != nil {
+        var buf bytes.Buffer
+        zw := gzip.NewWriter(&buf)
+        if _, err := zw.Write(data); err != nil {
+            return Snapshot{}, false, err
+        }
+        if err := zw.Close(); err != nil {
+            return Snapshot{}, false, err
+        }
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            return Snapshot{}, false, err
+        }
+        if err := writeAtomic(path, buf.Bytes()); err != nil {
+            return Snapshot{}, false, err
+        }
+    }
+    now := time.Now().UTC().Truncate(time.Second)
+    id := sha256.Sum256([]byte(hash + now.Format(time.RFC3339Nano) + commit + label))
+    snap := Snapshot{
+        ID:      hex.EncodeToString(id[:])[:12],
+        Object:  hash,
+        Time:    now,
+        Commit:  commit,
+        Label:   label,
+        Metrics: metrics,
+    }
+    h.Snapshots = append(h.Snapshots, snap)
+    return snap, true, h.save()
+}
+
+func (h *historyStore) load(snap Snapshot) (*ProjectAnalysis, error) {
+    f, err := os.Open(h.objectPath(snap.Object))
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    zr, err := gzip.NewReader(f)
+    if err != nil {
+        return nil, err
+    }
+    data, err := io.ReadAll(zr)
+    if err != nil {
+        return nil, err
+    }
+    var analysis ProjectAnalysis
+    if err := json.Unmarshal(data, &analysis); err != nil {
+        return nil, fmt.Errorf("snapshot %s: %v", snap.ID, err)
+    }
+    return &analysis, nil
+}
+
+// resolve находит снимок: latest, latest~N (N-й перед последним), префикс
+// ID, метка или коммит
+func (h *historyStore) resolve(ref string) (Snapshot, error) {
+    n := len(h.Snapshots)
+    if rest, ok := strings.CutPrefix(ref, "latest"); ok {
+        back := 0
+        if rest != "" {
+            v, err := strconv.Atoi(strings.TrimPrefix(rest, "~"))
+            if err != nil || !strings.HasPrefix(rest, "~") || v < 0 {
+                return Snapshot{}, fmt.Errorf("invalid snapshot reference %q", ref)
+            }
+            back = v
+        }
+        if back >= n {
+            return Snapshot{}, fmt.Errorf("snapshot %q not found: history has %d snapshot(s)", ref, n)
+        }
+        return h.Snapshots[n-1-back], nil
+    }
+    var found []Snapshot
+    for i := n - 1; i >= 0; i-- {
+        s := h.Snapshots[i]
+        if strings.HasPrefix(s.ID, ref) || s.Label == ref || ref != "" && strings.HasPrefix(s.Commit, ref) {
+            found = append(found, s)
+        }
+    }
+    switch {
+    case len(found) == 0:
+        return Snapshot{}, fmt.Errorf("snapshot %q not found", ref)
+    case len(found) > 1 && found[0].ID != ref && found[0].Label != ref:
+        return Snapshot{}, fmt.Errorf("snapshot %q is ambiguous (%d matches)", ref, len(found))
+    }
+    // Для метки или коммита берётся последний снимок
+    return found[0], nil
+}
+
+// prune оставляет keep последних снимков и удаляет объекты, на которые
+// больше никто не ссылается
+func (h *historyStore) prune(keep int) (int, error) {
+    if keep <= 0 || len(h.Snapshots) <= keep {
+        return 0, nil
+    }
+    removed := h.Snapshots[:len(h.Snapshots)-keep]
+    h.Snapshots = append([]Snapshot{}, h.Snapshots[len(h.Snapshots)-keep:]...)
+    if err := h.save(); err != nil {
+        return 0, err
+    }
+    live := make(map[string]bool)
+    for _, s := range h.Snapshots {
+        live[s.Object] = true
+    }
+    for _, s := range removed {
+        if !live[s.Object] {
+            live[s.Object] = true
+            if err := os.Remove(h.objectPath(s.Object)); err != nil && !errors.Is(err, os.ErrNotExist) {
+                return len(removed), err
+            }
+            // Каталог объектов удаляется, только если опустел
+            os.Remove(filepath.Dir(h.objectPath(s.Object)))
+        }
+    }
+    return len(removed), nil
+}
+
+func (h *historyStore) diff(from, to Snapshot) (*SnapshotDiff, error) {
+    base, err := h.load(from)
+    if err != nil {
+        return nil, err
+    }
+    head, err := h.load(to)
+    if err != nil {
+        return nil, err
+    }
+    delta := make(map[string]float64)
+    before, after := from.Metrics.values(), to.Metrics.values()
+    for _, name := range historyMetrics {
+        a, okA := before[name]
+        b, okB := after[name]
+        if okA && okB {
+            delta[name] = roundMetric(b - a)
+        }
+    }
+    // Исходников снимков нет: переименования ищутся по сигнатурам и членам
+    return &SnapshotDiff{
+        From:    from,
+        To:      to,
+        Metrics: delta,
+        Changes: diffAnalyses(from.ID, from.Commit, base, "", head, ""),
+    }, nil
+}
+
+func (h *historyStore) trend(metrics []string) *MetricTrend {
+    trend := &MetricTrend{Metrics: metrics, Points: []TrendPoint{}}
+    for _, s := range h.Snapshots {
+        all := s.Metrics.values()
+        values := make(map[string]float64)
+        for _, name := range metrics {
+            if v, ok := all[name]; ok {
+                values[name] = v
+            }
+        }
+        trend.Points = append(trend.Points, TrendPoint{ID: s.ID, Time: s.Time, Commit: s.Commit, Label: s.Label, Values: values})
+    }
+    return trend
+}
+
+func (t *MetricTrend) writeCSV(w io.Writer) error {
+    cw := csv.NewWriter(w)
+    cw.Write(append([]string{"id", "time", "commit", "label"}, t.Metrics...))
+    for _, p := range t.Points {
+        row := []string{p.ID, p.Time.Format(time.RFC3339), p.Commit, p.Label}
+        for _, name := range t.Metrics {
+            cell := ""
+            if v, ok := p.Values[name]; ok {
+                cell = strconv.FormatFloat(v, 'f', -1, 64)
+            }
+            row = append(row, cell)
+        }
+        cw.Write(row)
+    }
+    cw.Flush()
+    return cw.Error()
+}
+
+// historyFlags добавляет к общим флагам каталог истории
+func historyFlags(name, usage string) (*commandFlags, *string) {
+    fs := newCommandFlags("history "+name, usage)
+    dir := fs.String("dir", defaultHistoryDir, "history directory, relative to the project")
+    return fs, dir
+}
+
+func openHistoryDir(projectPath, dir string) *historyStore {
+    if !filepath.IsAbs(dir) {
+        dir = filepath.Join(projectPath, dir)
+    }
+    h, err := openHistory(dir)
+    if err != nil {
+        fatal("failed to open history", "dir", dir, "error", err)
+    }
+    return h
+}
+
+// runHistory - analyzer history <record|check|list|diff|trend|prune>: журнал
+// снимков анализа
+func runHistory(args []string) {
+    usage := "Usage: analyzer history <record|check|list|diff|trend|prune> [flags] [project_path]"
+    if len(args) == 0 {
+        fmt.Fprintln(os.Stderr, usage)
+        os.Exit(2)
+    }
+    switch args[0] {
+    case "record":
+        fs, dir := historyFlags("record", "[-label name] [-coverprofile file] [-keep n] [-alerts file] [project_path]")
+        label := fs.String("label", "", "label of the snapshot, e.g. a release")
+        coverProfile := fs.String("coverprofile", "", "record statement coverage from this go test -coverprofile `file`")
+        keep := fs.Int("keep", 0, "keep only this many latest snapshots (0 = all)")
+        alertsFile := fs.String("alerts", defaultAlertsFile, "metric thresholds checked against the previous snapshot, relative to the project")
+        projectPath := fs.parse(args[1:])
+        rules := loadProjectAlertRules(projectPath, *alertsFile)
+        var coverage *float64
+        if *coverProfile != "" {
+            v, err := coverProfileTotal(*coverProfile)
+            if err != nil {
+                fatal("failed to read coverage profile", "error", err)
+            }
+            coverage = &v
+        }
+        h := openHistoryDir(projectPath, *dir)
+        analysis := analyzeProject(context.Background(), projectPath, defaultOptions())
+        commit := ""
+        if out, err := runGit(projectPath, "rev-parse", "HEAD"); err == nil {
+            commit = strings.TrimSpace(string(out))
+        }
+        snap, created, err := h.record(analysis, commit, *label, coverage)
+        if err != nil {
+            fatal("failed to record snapshot", "error", err)
+        }
+        // Повторный снимок того же анализа сравнивается с тем же
+        // предыдущим, поэтому перезапуск не скрывает регрессию
+        alerts, err := h.evaluateAlerts(rules, h.predecessor(snap), snap)
+        if err != nil {
+            fatal("failed to check metric thresholds", "error", err)
+        }
+        pruned, err := h.prune(*keep)
+        if err != nil {
+            fatal("failed to prune history", "error", err)
+        }
+        fs.write(HistoryRecord{Snapshot: snap, Created: created, Pruned: pruned, Alerts: alerts})
+        exitOnAlerts(alerts)
+    case "check":
+        fs, dir := historyFlags("check", "[-to ref] [-from ref] [-alerts file] [project_path]")
+        to := fs.String("to", "latest", "snapshot to check: latest, latest~N, ID prefix, label or commit")
+        from := fs.String("from", "", "baseline snapshot (default: the one recorded before -to)")
+        alertsFile := fs.String("alerts", defaultAlertsFile, "metric thresholds, relative to the project")
+        projectPath := fs.parse(args[1:])
+        rules := loadProjectAlertRules(projectPath, *alertsFile)
+        h := openHistoryDir(projectPath, *dir)
+        b, err := h.resolve(*to)
+        if err != nil {
+            fatal("invalid snapshot", "error", err)
+        }
+        a := h.predecessor(b)
+        if *from != "" {
+            s, err := h.resolve(*from)
+            if err != nil {
+                fatal("invalid snapshot", "error", err)
+            }
+            a = &s
+        }
+        alerts, err := h.evaluateAlerts(rules, a, b)
+        if err != nil {
+            fatal("failed to check metric thresholds", "error", err)
+        }
+        fs.write(alerts)
+        exitOnAlerts(alerts)
+    case "list":
+        fs, dir := historyFlags("list", "[project_path]")
+        projectPath := fs.parse(args[1:])
+        fs.write(openHistoryDir(projectPath, *dir).Snapshots)
+    case "diff":
+        fs, dir := historyFlags("diff", "[-from ref] [-to ref] [project_path]")
+        from := fs.String("from", "latest~1", "older snapshot: latest, latest~N, ID prefix, label or commit")
+        to := fs.String("to", "latest", "newer snapshot: latest, latest~N, ID prefix, label or commit")
+        projectPath := fs.parse(args[1:])
+        h := openHistoryDir(projectPath, *dir)
+        a, err := h.resolve(*from)
+        if err != nil {
+            fatal("invalid snapshot", "error", err)
+        }
+        b, err := h.resolve(*to)
+        if err != nil {
+            fatal("invalid snapshot", "error", err)
+        }
+        diff, err := h.diff(a, b)
+        if err != nil {
+            fatal("failed to diff snapshots", "error", err)
+        }
+        fs.write(diff)
+    case "trend":
+        fs, dir := historyFlags("trend", "[-metrics a,b] [-csv] [project_path]")
+        metricList := fs.String("metrics", strings.Join(historyMetrics, ","), "comma-separated metrics: "+strings.Join(historyMetrics, ", "))
+        asCSV := fs.Bool("csv", false, "write CSV instead of JSON")
+        projectPath := fs.parse(args[1:])
+        var metrics []string
+        for _, name := range strings.Split(*metricList, ",") {
+            name = strings.TrimSpace(name)
+            if !slices.Contains(historyMetrics, name) {
+                fatal("invalid options", "error", fmt.Sprintf("unknown metric %q (supported: %s)", name, strings.Join(historyMetrics, ", ")))
+            }
+            metrics = append(metrics, name)
+        }
+        trend := openHistoryDir(projectPath, *dir).trend(metrics)
+        if !*asCSV {
+            fs.write(trend)
+            return
+        }
+        var buf bytes.Buffer
+        if err := trend.writeCSV(&buf); err != nil {
+            fatal("failed to write trend", "error", err)
+        }
+        var err error
+        if *fs.output != "" {
+            err = writeAtomic(*fs.output, buf.Bytes())
+        } else {
+            _, err = os.Stdout.Write(buf.Bytes())
+        }
+        if err != nil {
+            fatal("failed to write trend", "path", *fs.output, "error", err)
+        }
+    case "prune":
+        fs, dir := historyFlags("prune", "-keep n [project_path]")
+        keep := fs.Int("keep", 0, "keep this many latest snapshots")
+        projectPath := fs.parse(args[1:])
+        if *keep <= 0 {
+            fs.Usage()
+            os.Exit(2)
+        }
+        pruned, err := openHistoryDir(projectPath, *dir).prune(*keep)
+        if err != nil {
+            fatal("failed to prune history", "error", err)
+        }
+        fs.write(map[string]int{"pruned": pruned})
+    default:
+        fmt.Fprintln(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
@@ -0,0 +1,485 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/constant"
+    "go/types"
+    "path/filepath"
+    "sort"
+    "strings"
+    "unicode"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// StylePattern - вариант соглашения, сколько раз он встречается, его доля
+// среди вариантов того же соглашения и пример из кода
+type StylePattern struct {
+    Pattern      string   `json:"pattern"`
+    Count        int      `json:"count"`
+    Share        float64  `json:"share"`
+    Example      string   `json:"example"`
+}
+
+// HouseStyle - соглашения кодовой базы в машиночитаемом виде, чтобы
+// сгенерированный код им следовал. Варианты каждого соглашения
+// отсортированы по частоте: первый - принятый в проекте
+type HouseStyle struct {
+    ErrorWrap          []StylePattern `json:"error_wrap"`
+    ErrorMessages      []StylePattern `json:"error_messages"`
+    Logging            []StylePattern `json:"logging"`
+    ConstructorNames   []StylePattern `json:"constructor_names"`
+    ConstructorReturns []StylePattern `json:"constructor_returns"`
+    ConstructorParams  []StylePattern `json:"constructor_params"`
+    TestNames          []StylePattern `json:"test_names"`
+    TestStructure      []StylePattern `json:"test_structure"`
+    ReceiverNames      []StylePattern `json:"receiver_names"`
+    ReceiverKinds      []StylePattern `json:"receiver_kinds"`
+    // InconsistentReceivers - типы, у методов которых разные имена
+    // получателя: "pkg.Type: a, b"
+    InconsistentReceivers []string    `json:"inconsistent_receivers"`
+}
+
+// styleCounter считает варианты одного соглашения; пример - первый
+// встреченный
+type styleCounter struct {
+    counts   map[string]int
+    examples map[string]string
+}
+
+func newStyleCounter() *styleCounter {
+    return &styleCounter{counts: make(map[string]int), examples: make(map[string]string)}
+}
+
+func (c *styleCounter) add(pattern, example string) {
+    if _, ok := c.examples[pattern]; !ok {
+        c.examples[pattern] = example
+    }
+    c.counts[pattern]++
+}
+
+func (c *styleCounter) patterns() []StylePattern {
+    total := 0
+    for _, n := range c.counts {
+        total += n
+    }
+    patterns := []StylePattern{}
+    for p, n := range c.counts {
+        patterns = append(patterns, StylePattern{Pattern: p, Count: n, Share: roundMetric(float64(n) / float64(total)), Example: c.examples[p]})
+    }
+    sort.Slice(patterns, func(i, j int) bool {
+        if patterns[i].Count != patterns[j].Count {
+            return patterns[i].Count > patterns[j].Count
+        }
+        return patterns[i].Pattern < patterns[j].Pattern
+    })
+    return patterns
+}
+
+// Типовые начала сообщений об ошибках
+var errorLeads = []string{"failed to", "unable to", "cannot", "can't", "could not", "couldn't", "error"}
+
+// errorFormatPattern сводит формат fmt.Errorf к форме: "<context>: %w",
+// "failed to <action>: %w", "%w: <context>", "<message>" (без причины)
+func errorFormatPattern(format string) string {
+    lead := "<context>"
+    lower := strings.ToLower(format)
+    for _, l := range errorLeads {
+        if strings.HasPrefix(lower, l+" ") {
+            lead = l + " <action>"
+            break
+        }
+    }
+    for _, verb := range []string{"%w", "%v", "%s"} {
+        switch {
+        case strings.HasSuffix(format, ": "+verb):
+            return lead + ": " + verb
+        case strings.HasPrefix(format, verb+": "):
+            return verb + ": <context>"
+        }
+    }
+    if strings.Contains(format, "%w") {
+        return lead + " with %w inside"
+    }
+    return "<message>"
+}
+
+// errorMessagePattern - регистр первой буквы и точка в конце сообщения
+func errorMessagePattern(msg string) string {
+    pattern := "lowercase"
+    for _, r := range msg {
+        if unicode.IsUpper(r) {
+            pattern = "capitalized"
+        }
+        break
+    }
+    if strings.HasSuffix(msg, ".") || strings.HasSuffix(msg, "!") {
+        pattern += ", trailing punctuation"
+    }
+    return pattern
+}
+
+// loggingPackage - пакет логирования: стандартные и известные библиотеки
+func loggingPackage(path string) bool {
+    if path == "log" || path == "log/slog" {
+        return true
+    }
+    for _, known := range knownFrameworks {
+        if known.category == "logging" && hasPathPrefix(path, known.prefix) {
+            return true
+        }
+    }
+    return false
+}
+
+// logCallPattern - "slog.Info", "log.Printf" или "zap.SugaredLogger.Infow";
+// пустая строка - не логирование
+func logCallPattern(fn *types.Func) string {
+    if fn.Pkg() == nil {
+        return ""
+    }
+    sig, _ := fn.Type().(*types.Signature)
+    if sig == nil || sig.Recv() == nil {
+        name := strings.TrimSuffix(fn.Name(), "Context")
+        if loggingPackage(fn.Pkg().Path()) && (logMethods[name] || strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Panic")) {
+            return fn.Pkg().Name() + "." + fn.Name()
+        }
+        return ""
+    }
+    if !logMethods[fn.Name()] {
+        return ""
+    }
+    recv := sig.Recv().Type()
+    if ptr, ok := recv.(*types.Pointer); ok {
+        recv = ptr.Elem()
+    }
+    named, ok := recv.(*types.Named)
+    if !ok || named.Obj().Pkg() == nil {
+        return ""
+    }
+    obj := named.Obj()
+    if !loggingPackage(obj.Pkg().Path()) && !strings.Contains(obj.Name(), "Logger") {
+        return ""
+    }
+    return obj.Pkg().Name() + "." + obj.Name() + "." + fn.Name()
+}
+
+// Префиксы имён конструкторов; у неэкспортированных - со строчной буквы
+var constructorPrefixes = []string{"New", "Make", "Open", "Create", "Must", "new", "make", "open", "create", "must"}
+
+// constructorType - тип пакета, который возвращает функция первым
+// результатом, и форма результатов: "*T", "*T, error", "T", "interface"
+func constructorType(pkg *types.Package, sig *types.Signature) (string, string) {
+    if sig.Results().Len() == 0 {
+        return "", ""
+    }
+    t := sig.Results().At(0).Type()
+    shape := "T"
+    if ptr, ok := t.(*types.Pointer); ok {
+        t, shape = ptr.Elem(), "*T"
+    }
+    named, ok := t.(*types.Named)
+    if !ok || named.Obj().Pkg() != pkg {
+        return "", ""
+    }
+    if _, ok := named.Underlying().(*types.Interface); ok {
+        shape = "interface"
+    }
+    switch {
+    case sig.Results().Len() == 2 && isErrorType(sig.Results().At(1).Type()):
+        shape += ", error"
+    case sig.Results().Len() > 1:
+        shape += ", ..."
+    }
+    return named.Obj().Name(), shape
+}
+
+// constructorParams - вид параметров: функциональные опции, структура
+// конфигурации, без аргументов или позиционные
+func constructorParams(sig *types.Signature) string {
+    params := sig.Params()
+    if params.Len() == 0 {
+        return "no arguments"
+    }
+    last := params.At(params.Len() - 1).Type()
+    if slice, ok := last.(*types.Slice); ok && sig.Variadic() {
+        if _, ok := slice.Elem().Underlying().(*types.Signature); ok {
+            return "functional options"
+        }
+    }
+    for i := 0; i < params.Len(); i++ {
+        t := params.At(i).Type()
+        if ptr, ok := t.(*types.Pointer); ok {
+            t = ptr.Elem()
+        }
+        if named, ok := t.(*types.Named); ok {
+            name := named.Obj().Name()
+            if _, isStruct := named.Underlying().(*types.Struct); isStruct && (strings.HasSuffix(name, "Config") || strings.HasSuffix(name, "Options") || strings.HasSuffix(name, "Opts") || strings.HasSuffix(name, "Params")) {
+                return "config struct"
+            }
+        }
+    }
+    return "positional"
+}
+
+// receiverNamePattern сравнивает имя получателя с именем типа; для
+// routeBuilder "b" - инициал последнего слова, "rb" - инициалы
+func receiverNamePattern(name, typeName string) string {
+    lower := strings.ToLower(typeName)
+    var initials []rune
+    lastWord := 0
+    for i, r := range typeName {
+        if i == 0 || unicode.IsUpper(r) {
+            initials = append(initials, unicode.ToLower(r))
+            lastWord = i
+        }
+    }
+    last := strings.ToLower(typeName[lastWord:])
+    switch {
+    case name == "" || name == "_":
+        return "unnamed"
+    case typeName == "":
+        return "other"
+    case name == "this" || name == "self" || name == "me":
+        return "this/self"
+    case len(name) == 1 && name == lower[:1]:
+        return "first letter"
+    case len(name) > 1 && name == string(initials):
+        return "initials"
+    case strings.ToLower(name) == lower:
+        return "type name"
+    case len(name) == 1 && name == last[:1]:
+        return "last word initial"
+    case name == last:
+        return "last word"
+    case strings.HasPrefix(lower, strings.ToLower(name)):
+        return "type name prefix"
+    case subsequence(strings.ToLower(name), lower):
+        return "abbreviation"
+    }
+    return "other"
+}
+
+// subsequence - буквы s по порядку встречаются в t: idx в index
+func subsequence(s, t string) bool {
+    i := 0
+    for j := 0; i < len(s) && j < len(t); j++ {
+        if s[i] == t[j] {
+            i++
+        }
+    }
+    return i == len(s)
+}
+
+// testNamePattern сопоставляет имя теста с символами пакета: Test<Func>,
+// Test<Type>_<Method>, Test<Func>_<case> и т. д.
+func testNamePattern(name string, funcs, types, methods map[string]bool) string {
+    rest := strings.TrimPrefix(name, "Test")
+    head, tail, split := strings.Cut(rest, "_")
+    switch {
+    case split && types[head] && methods[head+"."+tail]:
+        return "Test<Type>_<Method>"
+    case split && funcs[head]:
+        return "Test<Func>_<case>"
+    case split && types[head]:
+        return "Test<Type>_<case>"
+    case split:
+        return "Test<Scenario>_<case>"
+    case funcs[rest]:
+        return "Test<Func>"
+    case types[rest]:
+        return "Test<Type>"
+    }
+    for m := range methods {
+        if strings.ReplaceAll(m, ".", "") == rest {
+            return "Test<Type><Method>"
+        }
+    }
+    return "Test<Scenario>"
+}
+
+// extractHouseStyle собирает соглашения проекта: формы оборачивания
+// ошибок, вызовы логирования, конструкторы, имена тестов и получателей.
+// Сгенерированные файлы не учитываются - это не стиль проекта
+func extractHouseStyle(pkgs []*packages.Package, projectPath string) *HouseStyle {
+    errorWrap, errorMessages, logging := newStyleCounter(), newStyleCounter(), newStyleCounter()
+    ctorNames, ctorReturns, ctorParams := newStyleCounter(), newStyleCounter(), newStyleCounter()
+    recvNames, recvKinds := newStyleCounter(), newStyleCounter()
+    receivers := make(map[string]map[string]bool)
+    // Символы по каталогам для сопоставления имён тестов
+    funcs := make(map[string]map[string]bool)
+    typeNames := make(map[string]map[string]bool)
+    methods := make(map[string]map[string]bool)
+    set := func(m map[string]map[string]bool, dir, name string) {
+        if m[dir] == nil {
+            m[dir] = make(map[string]bool)
+        }
+        m[dir][name] = true
+    }
+
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        info := pkg.TypesInfo
+        for i, file := range pkg.Syntax {
+            if ast.IsGenerated(file) || i >= len(pkg.CompiledGoFiles) {
+                continue
+            }
+            rel := relativePath(projectPath, pkg.CompiledGoFiles[i])
+            dir := filepath.ToSlash(filepath.Dir(rel))
+            for _, decl := range file.Decls {
+                switch d := decl.(type) {
+                case *ast.GenDecl:
+                    for _, spec := range d.Specs {
+                        if ts, ok := spec.(*ast.TypeSpec); ok {
+                            set(typeNames, dir, ts.Name.Name)
+                        }
+                    }
+                case *ast.FuncDecl:
+                    fn, _ := info.Defs[d.Name].(*types.Func)
+                    if fn == nil {
+                        continue
+                    }
+                    sig := fn.Type().(*types.Signature)
+                    if d.Recv == nil || len(d.Recv.List) == 0 {
+                        set(funcs, dir, d.Name.Name)
+                        for _, prefix := range constructorPrefixes {
+                            if !strings.HasPrefix(d.Name.Name, prefix) {
+                                continue
+                            }
+                            typeName, shape := constructorType(pkg.Types, sig)
+                            if typeName == "" {
+                                break
+                            }
+                            name := prefix + "<Other>"
+                            switch d.Name.Name {
+                            case prefix:
+                                name = prefix
+                            case prefix + typeName, prefix + strings.ToUpper(typeName[:1]) + typeName[1:]:
+                                name = prefix + "<Type>"
+                            }
+                            ctorNames.add(name, d.Name.Name)
+                            ctorReturns.add(shape, d.Name.Name)
+                            ctorParams.add(constructorParams(sig), d.Name.Name)
+                            break
+                        }
+                        continue
+                    }
+                    field := d.Recv.List[0]
+                    typeName := receiverTypeName(extractTypeString(field.Type))
+                    set(methods, dir, typeName+"."+d.Name.Name)
+                    recvName := ""
+                    if len(field.Names) > 0 {
+                        recvName = field.Names[0].Name
+                    }
+                    example := strings.TrimSpace(recvName + " " + extractTypeString(field.Type))
+                    recvNames.add(receiverNamePattern(recvName, typeName), example)
+                    if _, ok := sig.Recv().Type().(*types.Pointer); ok {
+                        recvKinds.add("pointer", example)
+                    } else {
+                        recvKinds.add("value", example)
+                    }
+                    if recvName != "" && recvName != "_" {
+                        key := pkg.Name + "." + typeName
+                        if receivers[key] == nil {
+                            receivers[key] = make(map[string]bool)
+                        }
+                        receivers[key][recvName] = true
+                    }
+                }
+            }
+
+            ast.Inspect(file, func(n ast.Node) bool {
+                call, ok := n.(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                fn := calleeFunc(info, call)
+                if fn == nil || fn.Pkg() == nil {
+                    return true
+                }
+                pos := pkg.Fset.Position(call.Pos())
+                where := fmt.Sprintf("%s:%d", relativePath(projectPath, pos.Filename), pos.Line)
+                if p := logCallPattern(fn); p != "" {
+                    logging.add(p, where)
+                    return true
+                }
+                // Строка-сообщение: первый аргумент-константа
+                message := func(arg int) (string, bool) {
+                    if len(call.Args) <= arg {
+                        return "", false
+                    }
+                    tv, ok := info.Types[call.Args[arg]]
+                    if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+                        return "", false
+                    }
+                    return constant.StringVal(tv.Value), true
+                }
+                switch {
+                case fn.Pkg().Path() == "fmt" && fn.Name() == "Errorf":
+                    if format, ok := message(0); ok {
+                        errorWrap.add(errorFormatPattern(format), format)
+                        errorMessages.add(errorMessagePattern(format), format)
+                    }
+                case fn.Name() == "New" && (fn.Pkg().Path() == "errors" || fn.Pkg().Name() == "errors"):
+                    if msg, ok := message(0); ok {
+                        errorMessages.add(errorMessagePattern(msg), msg)
+                    }
+                case strings.HasPrefix(fn.Name(), "Wrap") && fn.Pkg().Name() == "errors":
+                    if msg, ok := message(1); ok {
+                        errorWrap.add(fn.Pkg().Name()+"."+fn.Name()+"(err, <context>)", msg)
+                        errorMessages.add(errorMessagePattern(msg), msg)
+                    }
+                }
+                return true
+            })
+        }
+    }
+
+    testNames, testStructure := newStyleCounter(), newStyleCounter()
+    for _, test := range collectTestFuncs(projectPath) {
+        if test.kind != "test" {
+            continue
+        }
+        testNames.add(testNamePattern(test.name, funcs[test.dir], typeNames[test.dir], methods[test.dir]), test.name)
+        structure := "plain"
+        switch {
+        case test.table && test.selects["t.Run"]:
+            structure = "table-driven with t.Run"
+        case test.table:
+            structure = "table-driven"
+        case test.selects["t.Run"]:
+            structure = "subtests"
+        }
+        testStructure.add(structure, test.name)
+    }
+
+    style := &HouseStyle{
+        ErrorWrap:             errorWrap.patterns(),
+        ErrorMessages:         errorMessages.patterns(),
+        Logging:               logging.patterns(),
+        ConstructorNames:      ctorNames.patterns(),
+        ConstructorReturns:    ctorReturns.patterns(),
+        ConstructorParams:     ctorParams.patterns(),
+        TestNames:             testNames.patterns(),
+        TestStructure:         testStructure.patterns(),
+        ReceiverNames:         recvNames.patterns(),
+        ReceiverKinds:         recvKinds.patterns(),
+        InconsistentReceivers: []string{},
+    }
+    for key, names := range receivers {
+        if len(names) < 2 {
+            continue
+        }
+        list := make([]string, 0, len(names))
+        for name := range names {
+            list = append(list, name)
+        }
+        sort.Strings(list)
+        style.InconsistentReceivers = append(style.InconsistentReceivers, key+": "+strings.Join(list, ", "))
+    }
+    sort.Strings(style.InconsistentReceivers)
+    return style
+}
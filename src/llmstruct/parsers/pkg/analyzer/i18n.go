@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/token"
+    "go/types"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// UserMessage - строковый литерал, который видит пользователь: текст
+// ошибки API, тело ответа или данные шаблона. Sink: api_error, response,
+// template
+type UserMessage struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    Text         string   `json:"text"`
+    Sink         string   `json:"sink"`
+    Call         string   `json:"call"`
+    Format       bool     `json:"format,omitempty"`
+}
+
+// Функции, аргументы которых (начиная с индекса) - текст ошибки API
+var apiErrorSinks = map[string]int{
+    "net/http.Error":                           1,
+    "google.golang.org/grpc/status.Error":      1,
+    "google.golang.org/grpc/status.Errorf":     1,
+    "google.golang.org/grpc/status.New":        1,
+    "google.golang.org/grpc/status.Newf":       1,
+    "github.com/labstack/echo/v4.NewHTTPError": 1,
+    "github.com/gofiber/fiber/v2.NewError":     1,
+}
+
+// Методы контекстов веб-фреймворков, пишущие тело ответа
+var responseMethods = map[string]bool{
+    "JSON": true, "IndentedJSON": true, "PureJSON": true, "XML": true, "YAML": true,
+    "String": true, "SendString": true, "AbortWithStatusJSON": true, "AbortWithError": true,
+}
+
+var (
+    formatVerb       = regexp.MustCompile(`%[-+# 0-9.\[\]*]*[vTtbcdoOqxXUeEfFgGsp]`)
+    templateFileName = regexp.MustCompile(`^[\w./-]+\.(html|tmpl|tpl|gohtml|txt)$`)
+)
+
+// userFacing отбрасывает идентификаторы, ключи и имена шаблонов; strict -
+// для приёмников, где строка может быть и служебной
+func userFacing(s string, strict bool) bool {
+    letters := 0
+    for _, r := range s {
+        if unicode.IsLetter(r) {
+            letters++
+        }
+    }
+    if letters < 2 || templateFileName.MatchString(s) {
+        return false
+    }
+    if !strict {
+        return true
+    }
+    first := []rune(strings.TrimSpace(s))[0]
+    return strings.Contains(strings.TrimSpace(s), " ") || unicode.IsUpper(first)
+}
+
+// isResponseWriter - значение реализует http.ResponseWriter
+func isResponseWriter(t types.Type) bool {
+    if t == nil {
+        return false
+    }
+    for _, name := range []string{"Header", "Write", "WriteHeader"} {
+        obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+        if _, ok := obj.(*types.Func); !ok {
+            return false
+        }
+    }
+    return true
+}
+
+// messageSink определяет, выводит ли вызов текст пользователю, и
+// возвращает вид приёмника и аргументы с текстом
+func messageSink(info *types.Info, call *ast.CallExpr) (string, []ast.Expr) {
+    fn := calleeFunc(info, call)
+    if fn == nil || fn.Pkg() == nil {
+        return "", nil
+    }
+    path, name := fn.Pkg().Path(), fn.Name()
+    if from, ok := apiErrorSinks[path+"."+name]; ok && len(call.Args) > from {
+        return "api_error", call.Args[from:]
+    }
+    sig, _ := fn.Type().(*types.Signature)
+    isMethod := sig != nil && sig.Recv() != nil
+    switch {
+    case path == "fmt" && strings.HasPrefix(name, "Fprint") || path == "io" && name == "WriteString":
+        if len(call.Args) > 1 && isResponseWriter(info.TypeOf(call.Args[0])) {
+            return "response", call.Args[1:]
+        }
+    case isMethod && (path == "html/template" || path == "text/template") && (name == "Execute" || name == "ExecuteTemplate"):
+        return "template", call.Args[len(call.Args)-1:]
+    case isMethod && name == "HTML" || strings.Contains(strings.ToLower(name), "render"):
+        return "template", call.Args
+    case isMethod && responseMethods[name] && (strings.HasPrefix(path, "github.com/gin-gonic/") || strings.HasPrefix(path, "github.com/labstack/echo") || strings.HasPrefix(path, "github.com/gofiber/")):
+        return "response", call.Args
+    }
+    return "", nil
+}
+
+// extractUserMessages собирает видимые пользователю строки: тексты ошибок
+// API, литералы в телах ответов и данных шаблонов
+func extractUserMessages(pkgs []*packages.Package, projectPath string) []UserMessage {
+    messages := []UserMessage{}
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        info := pkg.TypesInfo
+        for _, file := range pkg.Syntax {
+            inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+                call, ok := n.(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                sink, args := messageSink(info, call)
+                if sink == "" {
+                    return true
+                }
+                for _, arg := range args {
+                    ast.Inspect(arg, func(m ast.Node) bool {
+                        switch x := m.(type) {
+                        case *ast.KeyValueExpr:
+                            // Ключи gin.H{"error": ...} - не текст
+                            ast.Inspect(x.Value, func(v ast.Node) bool {
+                                if lit, ok := v.(*ast.BasicLit); ok {
+                                    messages = appendUserMessage(messages, pkg, projectPath, decl, lit, sink, call)
+                                }
+                                return true
+                            })
+                            return false
+                        case *ast.IndexExpr:
+                            return false
+                        case *ast.BasicLit:
+                            messages = appendUserMessage(messages, pkg, projectPath, decl, x, sink, call)
+                        }
+                        return true
+                    })
+                }
+                return true
+            })
+        }
+    }
+    sort.SliceStable(messages, func(i, j int) bool {
+        if messages[i].File != messages[j].File {
+            return messages[i].File < messages[j].File
+        }
+        return messages[i].Line < messages[j].Line
+    })
+    return messages
+}
+
+func appendUserMessage(messages []UserMessage, pkg *packages.Package, projectPath string, decl *ast.FuncDecl, lit *ast.BasicLit, sink string, call *ast.CallExpr) []UserMessage {
+    if lit.Kind != token.STRING {
+        return messages
+    }
+    text, err := strconv.Unquote(lit.Value)
+    if err != nil || !userFacing(text, sink != "api_error") {
+        return messages
+    }
+    pos := pkg.Fset.Position(lit.Pos())
+    msg := UserMessage{
+        Package: pkg.PkgPath,
+        File:    relativePath(projectPath, pos.Filename),
+        Line:    pos.Line,
+        Text:    text,
+        Sink:    sink,
+        Call:    types.ExprString(call.Fun),
+        Format:  formatVerb.MatchString(text),
+    }
+    if decl != nil {
+        msg.Function = funcID(pkg, decl)
+    }
+    return append(messages, msg)
+}
+
+// poString записывает строку в синтаксисе gettext: многострочный текст
+// разбивается по переводам строк
+func poString(s string) string {
+    if !strings.Contains(s, "\n") || strings.Index(s, "\n") == len(s)-1 {
+        return strconv.Quote(s)
+    }
+    var b strings.Builder
+    b.WriteString(`""`)
+    for _, part := range strings.SplitAfter(s, "\n") {
+        if part != "" {
+            b.WriteString("\n" + strconv.Quote(part))
+        }
+    }
+    return b.String()
+}
+
+// gettextCatalog строит шаблон каталога (.pot): одинаковые тексты
+// объединяются, ссылки на места сохраняются
+func gettextCatalog(messages []UserMessage) string {
+    var order []string
+    refs := make(map[string][]string)
+    format := make(map[string]bool)
+    for _, msg := range messages {
+        if _, ok := refs[msg.Text]; !ok {
+            order = append(order, msg.Text)
+        }
+        refs[msg.Text] = appendUnique(refs[msg.Text], fmt.Sprintf("%s:%d", msg.File, msg.Line))
+        format[msg.Text] = format[msg.Text] || msg.Format
+    }
+    var b strings.Builder
+    b.WriteString("msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n")
+    for _, text := range order {
+        b.WriteString("\n")
+        if format[text] {
+            b.WriteString("#. Go format string: keep the %-verbs\n")
+        }
+        b.WriteString("#: " + strings.Join(refs[text], " ") + "\n")
+        b.WriteString("msgid " + poString(text) + "\nmsgstr \"\"\n")
+    }
+    return b.String()
+}
@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+)
+
+type ImpactRef struct {
+    From         string   `json:"from"`
+    Kind         string   `json:"kind"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+}
+
+// ImpactCaller - транзитивно вызывающая функция; Via - через кого
+type ImpactCaller struct {
+    ID           string   `json:"id"`
+    Depth        int      `json:"depth"`
+    Via          string   `json:"via"`
+}
+
+type ImpactTest struct {
+    Name         string   `json:"name"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Covers       []string `json:"covers"`
+}
+
+// ImpactReport - всё, что затрагивает изменение символа
+type ImpactReport struct {
+    Symbol          string         `json:"symbol"`
+    Kind            string         `json:"kind"`
+    File            string         `json:"file"`
+    Line            int            `json:"line"`
+    Depth           int            `json:"depth"`
+    References      []ImpactRef    `json:"references"`
+    Callers         []ImpactCaller `json:"callers"`
+    Interfaces      []string       `json:"interfaces"`
+    Implementations []string       `json:"implementations"`
+    Tests           []ImpactTest   `json:"tests"`
+}
+
+// resolveSymbol находит символ по стабильному ID ("go:example.com/pkg.Func"),
+// полному пути ("example.com/pkg.Func") или короткому имени ("pkg.Func",
+// "pkg.Type.Method")
+func resolveSymbol(symbols map[string]*goSymbol, query string) (*goSymbol, error) {
+    if sym := symbols[query]; sym != nil {
+        return sym, nil
+    }
+    if sym := symbols["go:"+query]; sym != nil {
+        return sym, nil
+    }
+    var matches []string
+    for id := range symbols {
+        if strings.HasSuffix(id, "/"+query) || strings.HasSuffix(id, ":"+query) {
+            matches = append(matches, id)
+        }
+    }
+    switch len(matches) {
+    case 0:
+        return nil, fmt.Errorf("symbol %q not found", query)
+    case 1:
+        return symbols[matches[0]], nil
+    }
+    sort.Strings(matches)
+    return nil, fmt.Errorf("symbol %q is ambiguous: %s", query, strings.Join(matches, ", "))
+}
+
+// dispatchTargets - символы, через которые возможен вызов метода: методы
+// интерфейсов, которым удовлетворяет тип получателя
+func dispatchTargets(refs *refIndex, sym *goSymbol) []string {
+    if sym.kind != "method" {
+        return nil
+    }
+    recv, method, _ := strings.Cut(sym.name, ".")
+    typeID := strings.TrimSuffix(sym.id, sym.name) + recv
+    var targets []string
+    for _, iface := range refs.implements[typeID] {
+        // Метод интерфейса есть в индексе, только если его где-то вызывают
+        if id := iface + "." + method; len(refs.byTo[id]) > 0 {
+            targets = append(targets, id)
+        }
+    }
+    return targets
+}
+
+// buildImpact собирает прямые ссылки на символ, вызывающих до depth
+// уровней (включая вызовы через интерфейсы), связанные интерфейсы и
+// реализации, а также тесты, упоминающие символ или его вызывающих
+func buildImpact(projectPath string, analysis *ProjectAnalysis, query string, depth int) (*ImpactReport, error) {
+    symbols := goSymbols(analysis, false)
+    sym, err := resolveSymbol(symbols, query)
+    if err != nil {
+        return nil, err
+    }
+    refs := analysis.refs
+    report := &ImpactReport{
+        Symbol:          sym.id,
+        Kind:            sym.kind,
+        File:            sym.file,
+        Line:            sym.line,
+        Depth:           depth,
+        References:      []ImpactRef{},
+        Callers:         []ImpactCaller{},
+        Interfaces:      []string{},
+        Implementations: []string{},
+        Tests:           []ImpactTest{},
+    }
+
+    targets := append([]string{sym.id}, dispatchTargets(refs, sym)...)
+    for _, target := range targets {
+        for _, i := range refs.byTo[target] {
+            ref := refs.refs[i]
+            report.References = append(report.References, ImpactRef{From: ref.from, Kind: ref.kind, File: ref.file, Line: ref.line})
+        }
+    }
+
+    switch sym.kind {
+    case "struct":
+        report.Interfaces = append(report.Interfaces, refs.implements[sym.id]...)
+    case "interface":
+        report.Implementations = append(report.Implementations, refs.implementedBy[sym.id]...)
+    case "method":
+        report.Interfaces = append(report.Interfaces, targets[1:]...)
+    }
+
+    // Обход вызывающих в ширину; уровень 1 - прямые вызывающие
+    seen := map[string]bool{sym.id: true}
+    frontier := targets
+    for level := 1; level <= depth && len(frontier) > 0; level++ {
+        var next []string
+        for _, id := range frontier {
+            for _, caller := range refs.callers(id) {
+                if seen[caller] {
+                    continue
+                }
+                seen[caller] = true
+                report.Callers = append(report.Callers, ImpactCaller{ID: caller, Depth: level, Via: id})
+                next = append(next, caller)
+                if callerSym := symbols[caller]; callerSym != nil {
+                    next = append(next, dispatchTargets(refs, callerSym)...)
+                }
+            }
+        }
+        frontier = next
+    }
+
+    affected := []*goSymbol{sym}
+    for _, caller := range report.Callers {
+        if callerSym := symbols[caller.ID]; callerSym != nil {
+            affected = append(affected, callerSym)
+        }
+    }
+    for _, test := range collectTestFuncs(projectPath) {
+        var covers []string
+        for _, s := range affected {
+            if test.references(s) {
+                covers = append(covers, s.id)
+            }
+        }
+        if len(covers) > 0 {
+            report.Tests = append(report.Tests, ImpactTest{Name: test.name, File: test.file, Line: test.line, Covers: covers})
+        }
+    }
+    return report, nil
+}
+
+func runImpact(args []string) {
+    fs := newCommandFlags("impact", "-symbol <pkg.Func> [flags] [project_path]")
+    symbol := fs.String("symbol", "", "symbol to analyze: pkg.Func, pkg.Type.Method or a full stable ID")
+    depth := fs.Int("depth", 3, "follow transitive callers up to this many levels")
+    projectPath := fs.parse(args)
+    if *symbol == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    analysis := analyzeProject(context.Background(), projectPath, defaultOptions())
+    report, err := buildImpact(projectPath, analysis, *symbol, *depth)
+    if err != nil {
+        fatal("impact analysis failed", "error", err)
+    }
+    fs.write(report)
+}
@@ -0,0 +1,289 @@
+package analyzer
+
+import (
+    "go/ast"
+    "sort"
+    "strconv"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// CycleSymbol - символ целевого пакета, из-за которого существует ребро
+// импорта; Uses - число обращений, File/Line - первое из них
+type CycleSymbol struct {
+    Name         string   `json:"name"`
+    Kind         string   `json:"kind"`
+    Uses         int      `json:"uses"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+}
+
+// CycleBreak - ребро цикла, которое предлагается убрать, и как это сделать
+type CycleBreak struct {
+    From         string        `json:"from"`
+    To           string        `json:"to"`
+    Symbols      []CycleSymbol `json:"symbols"`
+    Action       string        `json:"action"`
+    Suggestion   string        `json:"suggestion"`
+}
+
+// ImportCycle - сильно связная группа пакетов проекта с минимальным
+// (жадно подобранным) набором рёбер, удаление которых разрывает цикл
+type ImportCycle struct {
+    Packages     []string     `json:"packages"`
+    Breaks       []CycleBreak `json:"breaks"`
+}
+
+type importUse struct {
+    uses       int
+    file       string
+    line       int
+}
+
+// importCycleBuilder собирает импорты между пакетами проекта по синтаксису:
+// при цикле типы пакетов неполны, а разбор файлов остаётся доступен
+type importCycleBuilder struct {
+    projectPath string
+    project     map[string]bool
+    // edges[from][to][символ] - обращения from к символам to
+    edges       map[string]map[string]map[string]*importUse
+}
+
+func newImportCycleBuilder(projectPath string, pkgs []*packages.Package) *importCycleBuilder {
+    b := &importCycleBuilder{projectPath: projectPath, project: make(map[string]bool), edges: make(map[string]map[string]map[string]*importUse)}
+    for _, pkg := range pkgs {
+        b.project[pkg.PkgPath] = true
+    }
+    return b
+}
+
+func (b *importCycleBuilder) addPackage(pkg *packages.Package) {
+    for _, file := range pkg.Syntax {
+        // Локальное имя импорта -> путь пакета проекта
+        names := make(map[string]string)
+        for _, imp := range file.Imports {
+            path, err := strconv.Unquote(imp.Path.Value)
+            if err != nil || !b.project[path] || path == pkg.PkgPath {
+                continue
+            }
+            name := path[strings.LastIndex(path, "/")+1:]
+            if imp.Name != nil {
+                name = imp.Name.Name
+            } else if imported := pkg.Imports[path]; imported != nil && imported.Name != "" {
+                name = imported.Name
+            }
+            names[name] = path
+            b.use(pkg.PkgPath, path, "", 0, "", 0)
+        }
+        if len(names) == 0 {
+            continue
+        }
+        ast.Inspect(file, func(n ast.Node) bool {
+            sel, ok := n.(*ast.SelectorExpr)
+            if !ok {
+                return true
+            }
+            if x, ok := sel.X.(*ast.Ident); ok && names[x.Name] != "" {
+                pos := pkg.Fset.Position(sel.Pos())
+                b.use(pkg.PkgPath, names[x.Name], sel.Sel.Name, 1, relativePath(b.projectPath, pos.Filename), pos.Line)
+            }
+            return true
+        })
+    }
+}
+
+func (b *importCycleBuilder) use(from, to, symbol string, uses int, file string, line int) {
+    if b.edges[from] == nil {
+        b.edges[from] = make(map[string]map[string]*importUse)
+    }
+    if b.edges[from][to] == nil {
+        b.edges[from][to] = make(map[string]*importUse)
+    }
+    if symbol == "" {
+        return
+    }
+    u := b.edges[from][to][symbol]
+    if u == nil {
+        u = &importUse{file: file, line: line}
+        b.edges[from][to][symbol] = u
+    }
+    u.uses += uses
+}
+
+// components находит сильно связные компоненты графа импортов (Тарьян)
+func (b *importCycleBuilder) components() [][]string {
+    index := make(map[string]int)
+    low := make(map[string]int)
+    onStack := make(map[string]bool)
+    var stack []string
+    var result [][]string
+    var visit func(v string)
+    visit = func(v string) {
+        index[v] = len(index)
+        low[v] = index[v]
+        stack = append(stack, v)
+        onStack[v] = true
+        for w := range b.edges[v] {
+            if _, seen := index[w]; !seen {
+                visit(w)
+                low[v] = min(low[v], low[w])
+            } else if onStack[w] {
+                low[v] = min(low[v], index[w])
+            }
+        }
+        if low[v] == index[v] {
+            var component []string
+            for {
+                w := stack[len(stack)-1]
+                stack = stack[:len(stack)-1]
+                onStack[w] = false
+                component = append(component, w)
+                if w == v {
+                    break
+                }
+            }
+            if len(component) > 1 {
+                sort.Strings(component)
+                result = append(result, component)
+            }
+        }
+    }
+    var nodes []string
+    for v := range b.edges {
+        nodes = append(nodes, v)
+    }
+    sort.Strings(nodes)
+    for _, v := range nodes {
+        if _, seen := index[v]; !seen {
+            visit(v)
+        }
+    }
+    return result
+}
+
+// acyclic проверяет, что рёбра компоненты без removed не образуют цикла
+func (b *importCycleBuilder) acyclic(component []string, removed map[[2]string]bool) bool {
+    in := make(map[string]bool)
+    for _, p := range component {
+        in[p] = true
+    }
+    state := make(map[string]int)
+    var visit func(v string) bool
+    visit = func(v string) bool {
+        state[v] = 1
+        for w := range b.edges[v] {
+            if !in[w] || removed[[2]string{v, w}] {
+                continue
+            }
+            if state[w] == 1 || state[w] == 0 && !visit(w) {
+                return false
+            }
+        }
+        state[v] = 2
+        return true
+    }
+    for _, v := range component {
+        if state[v] == 0 && !visit(v) {
+            return false
+        }
+    }
+    return true
+}
+
+// build подбирает для каждой компоненты рёбра с наименьшим числом
+// используемых символов, пока граф не станет ацикличным, и предлагает
+// перенос символов или интерфейс на стороне импортирующего пакета
+func (b *importCycleBuilder) build(symbols map[string]*goSymbol) []ImportCycle {
+    cycles := []ImportCycle{}
+    for _, component := range b.components() {
+        in := make(map[string]bool)
+        for _, p := range component {
+            in[p] = true
+        }
+        var candidates [][2]string
+        for _, from := range component {
+            for to := range b.edges[from] {
+                if in[to] {
+                    candidates = append(candidates, [2]string{from, to})
+                }
+            }
+        }
+        cost := func(e [2]string) int { return len(b.edges[e[0]][e[1]]) }
+        sort.Slice(candidates, func(i, j int) bool {
+            if cost(candidates[i]) != cost(candidates[j]) {
+                return cost(candidates[i]) < cost(candidates[j])
+            }
+            return candidates[i][0]+" "+candidates[i][1] < candidates[j][0]+" "+candidates[j][1]
+        })
+
+        removed := make(map[[2]string]bool)
+        var order [][2]string
+        for _, edge := range candidates {
+            if b.acyclic(component, removed) {
+                break
+            }
+            removed[edge] = true
+            order = append(order, edge)
+        }
+        // Рёбра, без удаления которых граф и так ацикличен, возвращаем
+        for i := len(order) - 1; i >= 0; i-- {
+            delete(removed, order[i])
+            if !b.acyclic(component, removed) {
+                removed[order[i]] = true
+            }
+        }
+
+        cycle := ImportCycle{Packages: component, Breaks: []CycleBreak{}}
+        for _, edge := range order {
+            if removed[edge] {
+                cycle.Breaks = append(cycle.Breaks, b.suggest(edge[0], edge[1], symbols))
+            }
+        }
+        cycles = append(cycles, cycle)
+    }
+    return cycles
+}
+
+func hasMethods(symbols map[string]*goSymbol, typeID string) bool {
+    for id, sym := range symbols {
+        if sym.kind == "method" && strings.HasPrefix(id, typeID+".") {
+            return true
+        }
+    }
+    return false
+}
+
+func (b *importCycleBuilder) suggest(from, to string, symbols map[string]*goSymbol) CycleBreak {
+    brk := CycleBreak{From: from, To: to, Symbols: []CycleSymbol{}}
+    behaviour := true
+    var names []string
+    for name, u := range b.edges[from][to] {
+        kind := "unknown"
+        if sym := symbols[goSymbolID(to, name)]; sym != nil {
+            kind = sym.kind
+        }
+        // Интерфейс имеет смысл только для типов с поведением
+        if kind != "interface" && !(kind == "struct" && hasMethods(symbols, goSymbolID(to, name))) {
+            behaviour = false
+        }
+        brk.Symbols = append(brk.Symbols, CycleSymbol{Name: name, Kind: kind, Uses: u.uses, File: u.file, Line: u.line})
+        names = append(names, name)
+    }
+    sort.Slice(brk.Symbols, func(i, j int) bool { return brk.Symbols[i].Name < brk.Symbols[j].Name })
+    sort.Strings(names)
+
+    list := strings.Join(names, ", ")
+    switch {
+    case len(names) == 0:
+        brk.Action = "remove_import"
+        brk.Suggestion = "the import is unused or blank; remove it"
+    case behaviour:
+        brk.Action = "interface"
+        brk.Suggestion = "declare in " + from + " an interface with the methods it needs from " + list + " and depend on it instead of importing " + to
+    default:
+        brk.Action = "move"
+        brk.Suggestion = "move " + list + " from " + to + " into " + from + " or into a new package imported by both"
+    }
+    return brk
+}
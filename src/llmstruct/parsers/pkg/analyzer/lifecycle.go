@@ -0,0 +1,362 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type SignalHandler struct {
+    Call         string   `json:"call"`
+    Signals      []string `json:"signals"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+}
+
+type LifecycleComponent struct {
+    Kind         string   `json:"kind"`
+    Start        string   `json:"start"`
+    Variable     string   `json:"variable,omitempty"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+    Stopped      bool     `json:"stopped"`
+    StoppedBy    []string `json:"stopped_by"`
+    Reason       string   `json:"reason,omitempty"`
+}
+
+type Lifecycle struct {
+    Signals      []SignalHandler      `json:"signals"`
+    Components   []LifecycleComponent `json:"components"`
+}
+
+type lifecycleAPI struct {
+    kind     string
+    pkgPath  string
+    recv     string
+    names    []string
+}
+
+// Запуск долгоживущих компонентов. Для методов (recv != "") компонентом
+// считается получатель, для функций - возвращаемое значение
+var lifecycleAPIs = []lifecycleAPI{
+    {kind: "http-server", pkgPath: "net/http", recv: "Server", names: []string{"ListenAndServe", "ListenAndServeTLS", "Serve", "ServeTLS"}},
+    {kind: "grpc-server", pkgPath: "google.golang.org/grpc", recv: "Server", names: []string{"Serve"}},
+    {kind: "listener", pkgPath: "net", names: []string{"Listen", "ListenTCP", "ListenUnix", "ListenPacket"}},
+    {kind: "database", pkgPath: "database/sql", names: []string{"Open", "OpenDB"}},
+    {kind: "database", pkgPath: "github.com/jackc/pgx", names: []string{"Connect", "New", "NewWithConfig"}},
+    {kind: "grpc-client", pkgPath: "google.golang.org/grpc", names: []string{"Dial", "DialContext", "NewClient"}},
+    {kind: "ticker", pkgPath: "time", names: []string{"NewTicker"}},
+}
+
+// Методы, освобождающие компонент
+var stopMethods = map[string]bool{
+    "Close": true, "Shutdown": true, "Stop": true, "GracefulStop": true,
+}
+
+type lifecycleStart struct {
+    obj      types.Object
+    comp     LifecycleComponent
+}
+
+type lifecycleStop struct {
+    obj      types.Object
+    function string
+    site     string
+}
+
+// lifecycleBuilder прослеживает запуск компонентов и вызовы их остановки
+// в коде, достижимом из main()
+type lifecycleBuilder struct {
+    projectPath string
+    parent      map[types.Object]types.Object
+    starts      []lifecycleStart
+    stops       []lifecycleStop
+    signals     []SignalHandler
+    refs        map[string][]string
+    mains       []string
+}
+
+func newLifecycleBuilder(projectPath string) *lifecycleBuilder {
+    return &lifecycleBuilder{
+        projectPath: projectPath,
+        parent:      make(map[types.Object]types.Object),
+        refs:        make(map[string][]string),
+    }
+}
+
+func (b *lifecycleBuilder) find(obj types.Object) types.Object {
+    for {
+        p, ok := b.parent[obj]
+        if !ok || p == obj {
+            return obj
+        }
+        obj = p
+    }
+}
+
+func (b *lifecycleBuilder) union(a, c types.Object) {
+    ra, rc := b.find(a), b.find(c)
+    if ra != rc {
+        b.parent[rc] = ra
+    }
+}
+
+// hasStopMethod: у типа есть метод остановки, значит его стоит отслеживать
+func hasStopMethod(t types.Type) bool {
+    if t == nil {
+        return false
+    }
+    mset := types.NewMethodSet(t)
+    if _, ok := t.(*types.Pointer); !ok {
+        if _, isIface := t.Underlying().(*types.Interface); !isIface {
+            mset = types.NewMethodSet(types.NewPointer(t))
+        }
+    }
+    for name := range stopMethods {
+        if mset.Lookup(nil, name) != nil {
+            return true
+        }
+    }
+    return false
+}
+
+func lifecycleKind(fn *types.Func) (string, bool) {
+    sig := fn.Type().(*types.Signature)
+    for _, api := range lifecycleAPIs {
+        if !hasPathPrefix(fn.Pkg().Path(), api.pkgPath) {
+            continue
+        }
+        if (api.recv == "") != (sig.Recv() == nil) {
+            continue
+        }
+        if api.recv != "" {
+            if named := namedStruct(sig.Recv().Type()); named == nil || named.Obj().Name() != api.recv {
+                continue
+            }
+        }
+        for _, name := range api.names {
+            if name == fn.Name() {
+                return api.kind, true
+            }
+        }
+    }
+    return "", false
+}
+
+func (b *lifecycleBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    info := pkg.TypesInfo
+
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            if fd, ok := decl.(*ast.FuncDecl); ok && pkg.Name == "main" && fd.Name.Name == "main" && fd.Recv == nil {
+                b.mains = append(b.mains, funcID(pkg, fd))
+            }
+        }
+
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            function := funcID(pkg, decl)
+            newComponent := func(kind string, fn *types.Func, obj types.Object, call *ast.CallExpr) {
+                pos := pkg.Fset.Position(call.Pos())
+                comp := LifecycleComponent{
+                    Kind:      kind,
+                    Start:     fn.FullName(),
+                    File:      relativePath(b.projectPath, pos.Filename),
+                    Line:      pos.Line,
+                    Function:  function,
+                    StoppedBy: []string{},
+                }
+                if obj != nil {
+                    comp.Variable = obj.Name()
+                }
+                b.starts = append(b.starts, lifecycleStart{obj: obj, comp: comp})
+            }
+
+            switch node := n.(type) {
+            case *ast.Ident:
+                // Ссылки на функции проекта (вызовы и передача как значения)
+                if fn, ok := info.Uses[node].(*types.Func); ok && decl != nil {
+                    b.refs[function] = appendUnique(b.refs[function], fn.FullName())
+                }
+
+            case *ast.AssignStmt, *ast.ValueSpec:
+                var lhs []ast.Expr
+                var rhs []ast.Expr
+                if assign, ok := node.(*ast.AssignStmt); ok {
+                    lhs, rhs = assign.Lhs, assign.Rhs
+                } else {
+                    spec := node.(*ast.ValueSpec)
+                    rhs = spec.Values
+                    for _, name := range spec.Names {
+                        lhs = append(lhs, name)
+                    }
+                }
+                for i, value := range rhs {
+                    if i >= len(lhs) {
+                        break
+                    }
+                    target := varObject(info, lhs[i])
+                    if call, ok := value.(*ast.CallExpr); ok {
+                        fn := calleeFunc(info, call)
+                        if fn == nil || fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil {
+                            continue
+                        }
+                        if kind, ok := lifecycleKind(fn); ok {
+                            newComponent(kind, fn, target, call)
+                        }
+                        continue
+                    }
+                    // Псевдонимы: s := srv, a.db = db
+                    if source := varObject(info, value); source != nil && target != nil && hasStopMethod(source.Type()) {
+                        b.union(source, target)
+                    }
+                }
+
+            case *ast.KeyValueExpr:
+                key, ok := node.Key.(*ast.Ident)
+                if !ok {
+                    return true
+                }
+                field, _ := info.Uses[key].(*types.Var)
+                if source := varObject(info, node.Value); field != nil && source != nil && hasStopMethod(source.Type()) {
+                    b.union(source, field)
+                }
+
+            case *ast.CallExpr:
+                fn := calleeFunc(info, node)
+                if fn == nil || fn.Pkg() == nil {
+                    return true
+                }
+                sig := fn.Type().(*types.Signature)
+
+                switch {
+                case fn.Pkg().Path() == "os/signal" && (fn.Name() == "Notify" || fn.Name() == "NotifyContext"):
+                    pos := pkg.Fset.Position(node.Pos())
+                    handler := SignalHandler{
+                        Call:     fn.FullName(),
+                        Signals:  []string{},
+                        File:     relativePath(b.projectPath, pos.Filename),
+                        Line:     pos.Line,
+                        Function: function,
+                    }
+                    for _, arg := range node.Args[1:] {
+                        handler.Signals = append(handler.Signals, types.ExprString(arg))
+                    }
+                    b.signals = append(b.signals, handler)
+
+                case fn.Pkg().Path() == "net/http" && sig.Recv() == nil && (fn.Name() == "ListenAndServe" || fn.Name() == "ListenAndServeTLS"):
+                    // Пакетный сервер невозможно остановить
+                    newComponent("http-server", fn, nil, node)
+
+                case sig.Recv() != nil:
+                    sel, ok := node.Fun.(*ast.SelectorExpr)
+                    if !ok {
+                        return true
+                    }
+                    if stopMethods[fn.Name()] {
+                        if obj := varObject(info, sel.X); obj != nil {
+                            pos := pkg.Fset.Position(node.Pos())
+                            b.stops = append(b.stops, lifecycleStop{
+                                obj:      obj,
+                                function: function,
+                                site:     fmt.Sprintf("%s (%s, %s:%d)", function, fn.Name(), relativePath(b.projectPath, pos.Filename), pos.Line),
+                            })
+                        }
+                        return true
+                    }
+                    if kind, ok := lifecycleKind(fn); ok {
+                        newComponent(kind, fn, varObject(info, sel.X), node)
+                    }
+                }
+
+                // Передача компонента в параметр функции
+                for i, arg := range node.Args {
+                    if i >= sig.Params().Len() || (sig.Variadic() && i >= sig.Params().Len()-1) {
+                        break
+                    }
+                    if obj := varObject(info, arg); obj != nil && hasStopMethod(obj.Type()) {
+                        b.union(sig.Params().At(i), obj)
+                    }
+                }
+            }
+            return true
+        })
+    }
+}
+
+// reachable возвращает функции, достижимые из main(); без main
+// анализируется весь код
+func (b *lifecycleBuilder) reachable() map[string]bool {
+    if len(b.mains) == 0 {
+        return nil
+    }
+    seen := make(map[string]bool)
+    queue := append([]string(nil), b.mains...)
+    for len(queue) > 0 {
+        function := queue[0]
+        queue = queue[1:]
+        if seen[function] {
+            continue
+        }
+        seen[function] = true
+        queue = append(queue, b.refs[function]...)
+    }
+    return seen
+}
+
+func (b *lifecycleBuilder) build() *Lifecycle {
+    reachable := b.reachable()
+    inScope := func(function string) bool {
+        return reachable == nil || reachable[function]
+    }
+
+    stops := make(map[types.Object][]string)
+    for _, stop := range b.stops {
+        if inScope(stop.function) {
+            root := b.find(stop.obj)
+            stops[root] = appendUnique(stops[root], stop.site)
+        }
+    }
+
+    result := &Lifecycle{Signals: []SignalHandler{}, Components: []LifecycleComponent{}}
+    for _, handler := range b.signals {
+        if inScope(handler.Function) {
+            result.Signals = append(result.Signals, handler)
+        }
+    }
+    for _, start := range b.starts {
+        if !inScope(start.comp.Function) {
+            continue
+        }
+        comp := start.comp
+        switch {
+        case start.obj != nil:
+            comp.StoppedBy = append(comp.StoppedBy, stops[b.find(start.obj)]...)
+            comp.Stopped = len(comp.StoppedBy) > 0
+            if !comp.Stopped {
+                comp.Reason = "no Close/Shutdown/Stop call reachable from main"
+            }
+        case comp.Kind == "http-server":
+            comp.Reason = "package-level server has no Shutdown; use http.Server"
+        default:
+            comp.Reason = "component is not stored, so it cannot be stopped"
+        }
+        result.Components = append(result.Components, comp)
+    }
+
+    if len(result.Signals) == 0 && len(result.Components) == 0 {
+        return nil
+    }
+    // Неостановленные компоненты первыми
+    sort.SliceStable(result.Components, func(i, j int) bool {
+        return !result.Components[i].Stopped && result.Components[j].Stopped
+    })
+    return result
+}
@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "strings"
+    "sync"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// Limits ограничивает патологические входы; 0 означает без ограничения
+type Limits struct {
+    MaxFileSize  int64
+    MaxFiles     int
+    MaxSymbols   int
+}
+
+type SkippedInput struct {
+    Path         string   `json:"path"`
+    Reason       string   `json:"reason"`
+    Size         int64    `json:"size,omitempty"`
+}
+
+// fileParser разбирает файлы для загрузчика и запоминает, у каких файлов
+// отброшены тела функций; ParseFile вызывается из нескольких горутин
+type fileParser struct {
+    project   string
+    opts      Options
+    mu        sync.Mutex
+    oversized map[string]int64
+    stripped  map[string]bool
+    generated map[string]bool
+    sampling  *SamplingInfo
+}
+
+func newFileParser(projectPath string, opts Options) *fileParser {
+    p := &fileParser{
+        project:   projectPath,
+        opts:      opts,
+        oversized: make(map[string]int64),
+        stripped:  make(map[string]bool),
+        generated: make(map[string]bool),
+    }
+    if opts.Sample != "" {
+        p.sampling = &SamplingInfo{Mode: opts.Sample, Rate: opts.SampleRate, Approximate: true}
+    }
+    return p
+}
+
+func (p *fileParser) size(filename string) (int64, bool) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    size, ok := p.oversized[filename]
+    return size, ok
+}
+
+// isGenerated - у файла заголовок "Code generated ... DO NOT EDIT."
+func (p *fileParser) isGenerated(filename string) bool {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return p.generated[filename]
+}
+
+// spurious: ошибка вызвана отброшенными телами функций - импорт,
+// использовавшийся только в телах, становится "неиспользуемым"
+func (p *fileParser) spurious(err packages.Error) bool {
+    if !strings.Contains(err.Msg, "imported and not used") && !strings.Contains(err.Msg, "imported as") {
+        return false
+    }
+    filename, _, _ := strings.Cut(err.Pos, ":")
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return p.stripped[filename]
+}
+
+// parseFile разбирает файл как загрузчик по умолчанию, но у файлов больше
+// MaxFileSize отбрасывает тела функций: объявления остаются для проверки
+// типов, а анализ тел не может зависнуть на гигантском файле. В режиме
+// выборки тела отбрасываются и у функций, не попавших в выборку
+func (p *fileParser) parseFile(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+    if srcGenerated(src) {
+        p.mu.Lock()
+        p.generated[filename] = true
+        p.mu.Unlock()
+    }
+    limits := p.opts.Limits
+    oversized := limits.MaxFileSize > 0 && int64(len(src)) > limits.MaxFileSize
+    if !oversized && p.sampling == nil {
+        return parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+    }
+
+    mode := parser.AllErrors | parser.ParseComments
+    if oversized {
+        // Комментарии нужны и без тел: doc, директивы и build-теги
+        mode = parser.SkipObjectResolution | parser.ParseComments
+    }
+    file, err := parser.ParseFile(fset, filename, src, mode)
+    if file == nil {
+        return file, err
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if oversized {
+        p.oversized[filename] = int64(len(src))
+    }
+    for _, decl := range file.Decls {
+        fd, ok := decl.(*ast.FuncDecl)
+        if !ok || fd.Body == nil {
+            continue
+        }
+        keep := !oversized
+        if p.sampling != nil && !hasPathPrefix(filename, p.project) {
+            // Тела зависимостей в выборке не нужны вовсе
+            fd.Body = nil
+            p.stripped[filename] = true
+            continue
+        }
+        // init всегда с телом: в нём регистрация плагинов, драйверов и
+        // обработчиков, которую больше нигде не видно
+        if fd.Recv == nil && fd.Name.Name == "init" {
+            continue
+        }
+        if keep && p.sampling != nil {
+            keep = keepBody(filename, fd, p.opts.SampleRate)
+            p.sampling.Functions++
+            if keep {
+                p.sampling.BodiesAnalyzed++
+            }
+        }
+        if !keep {
+            fd.Body = nil
+            p.stripped[filename] = true
+        }
+    }
+    return file, err
+}
+// truncateSymbols оставляет в файле не больше max символов, заполняя
+// списки по порядку: функции, структуры, интерфейсы, переменные, константы
+func truncateSymbols(analysis *FileAnalysis, max int) {
+    total := len(analysis.Functions) + len(analysis.Structs) + len(analysis.Interfaces) + len(analysis.Variables) + len(analysis.Constants)
+    if max <= 0 || total <= max {
+        return
+    }
+
+    budget := max
+    take := func(n int) int {
+        if n > budget {
+            n = budget
+        }
+        budget -= n
+        return n
+    }
+    analysis.Functions = analysis.Functions[:take(len(analysis.Functions))]
+    analysis.Structs = analysis.Structs[:take(len(analysis.Structs))]
+    analysis.Interfaces = analysis.Interfaces[:take(len(analysis.Interfaces))]
+    analysis.Variables = analysis.Variables[:take(len(analysis.Variables))]
+    analysis.Constants = analysis.Constants[:take(len(analysis.Constants))]
+    analysis.Truncated = append(analysis.Truncated, fmt.Sprintf("symbols truncated to %d of %d (max symbols per file)", max, total))
+}
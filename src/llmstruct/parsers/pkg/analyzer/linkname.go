@@ -0,0 +1,261 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "go/ast"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// LinknameDirective - //go:linkname или ссылка на символ runtime из
+// ассемблера. Kind: pull (тело берётся из Target), push (символ отдаётся
+// под именем Target), asm_reference. Risk high - зависимость от символов
+// стандартной библиотеки: с Go 1.23 линковщик запрещает такие pull, если
+// символ не помечен в std, и внутренности runtime меняются между релизами
+type LinknameDirective struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Local        string   `json:"local,omitempty"`
+    Target       string   `json:"target"`
+    Kind         string   `json:"kind"`
+    Risk         string   `json:"risk"`
+}
+
+// InternalImport - импорт внутреннего пакета стандартной библиотеки
+// (runtime/internal/..., internal/...)
+type InternalImport struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Import       string   `json:"import"`
+}
+
+// LinknameDependency - внешний пакет, использующий go:linkname: при
+// обновлении Go его версию нужно проверять в первую очередь
+type LinknameDependency struct {
+    Package      string   `json:"package"`
+    Directives   int      `json:"directives"`
+    Targets      []string `json:"targets"`
+    Risk         string   `json:"risk"`
+}
+
+type LinknameReport struct {
+    Directives      []LinknameDirective  `json:"directives"`
+    InternalImports []InternalImport     `json:"internal_imports"`
+    Dependencies    []LinknameDependency `json:"dependencies"`
+}
+
+var asmRuntimeRe = regexp.MustCompile(`\bruntime·(\w+)`)
+
+// stdPackage - путь пакета стандартной библиотеки (без точки в первом
+// элементе)
+func stdPackage(path string) bool {
+    first, _, _ := strings.Cut(path, "/")
+    return path != "" && !strings.Contains(first, ".")
+}
+
+func stdInternal(path string) bool {
+    return stdPackage(path) && (strings.HasPrefix(path, "internal/") || strings.Contains(path, "/internal/") || strings.HasPrefix(path, "vendor/"))
+}
+
+// linknameTarget делит цель "import/path.name" на пакет и имя; имя
+// может быть методом: runtime.(*mheap).alloc
+func linknameTarget(target string) (string, string) {
+    slash := strings.LastIndex(target, "/")
+    dot := strings.Index(target[slash+1:], ".")
+    if dot < 0 {
+        return "", target
+    }
+    return target[:slash+1+dot], target[slash+1+dot+1:]
+}
+
+func linknameRisk(pkgPath, target string) string {
+    targetPkg, _ := linknameTarget(target)
+    if targetPkg != "" && targetPkg != pkgPath && stdPackage(targetPkg) {
+        return "high"
+    }
+    return "low"
+}
+
+// parseLinkname разбирает строку директивы; ok=false для других комментариев
+func parseLinkname(text string) (local, target string, ok bool) {
+    fields := strings.Fields(strings.TrimPrefix(text, "//go:linkname"))
+    if !strings.HasPrefix(text, "//go:linkname ") || len(fields) == 0 {
+        return "", "", false
+    }
+    if len(fields) > 1 {
+        target = fields[1]
+    }
+    return fields[0], target, true
+}
+
+// fileLinknames собирает директивы файла проекта; вид определяется по
+// объявлению: функция без тела берёт реализацию у цели
+func fileLinknames(pkg *packages.Package, file *ast.File, projectPath string) []LinknameDirective {
+    var directives []LinknameDirective
+    for _, group := range file.Comments {
+        for _, c := range group.List {
+            local, target, ok := parseLinkname(c.Text)
+            if !ok {
+                continue
+            }
+            pos := pkg.Fset.Position(c.Pos())
+            d := LinknameDirective{
+                Package: pkg.PkgPath,
+                File:    relativePath(projectPath, pos.Filename),
+                Line:    pos.Line,
+                Local:   local,
+                Target:  target,
+                Kind:    "push",
+            }
+            if target == "" {
+                // Однаргументная форма экспортирует символ под своим именем
+                d.Target = pkg.PkgPath + "." + local
+            } else {
+                for _, decl := range file.Decls {
+                    switch x := decl.(type) {
+                    case *ast.FuncDecl:
+                        if x.Recv == nil && x.Name.Name == local && x.Body == nil {
+                            d.Kind = "pull"
+                        }
+                    case *ast.GenDecl:
+                        // Переменная разделяет хранилище с целью
+                        for _, spec := range x.Specs {
+                            if vs, ok := spec.(*ast.ValueSpec); ok {
+                                for _, name := range vs.Names {
+                                    if name.Name == local && len(vs.Values) == 0 {
+                                        d.Kind = "pull"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+            d.Risk = "low"
+            if d.Kind == "pull" {
+                d.Risk = linknameRisk(pkg.PkgPath, d.Target)
+            }
+            directives = append(directives, d)
+        }
+    }
+    return directives
+}
+
+// asmRuntimeRefs находит в ассемблерных файлах пакета обращения к
+// символам runtime (runtime·getg и т.п.)
+func asmRuntimeRefs(pkg *packages.Package, projectPath string) []LinknameDirective {
+    var refs []LinknameDirective
+    for _, filename := range pkg.OtherFiles {
+        if filepath.Ext(filename) != ".s" {
+            continue
+        }
+        data, err := os.ReadFile(filename)
+        if err != nil {
+            continue
+        }
+        scanner := bufio.NewScanner(bytes.NewReader(data))
+        for line := 1; scanner.Scan(); line++ {
+            for _, m := range asmRuntimeRe.FindAllStringSubmatch(scanner.Text(), -1) {
+                refs = append(refs, LinknameDirective{
+                    Package: pkg.PkgPath,
+                    File:    relativePath(projectPath, filename),
+                    Line:    line,
+                    Target:  "runtime." + m[1],
+                    Kind:    "asm_reference",
+                    Risk:    "high",
+                })
+            }
+        }
+    }
+    return refs
+}
+
+// dependencyLinknames просматривает исходники внешних зависимостей
+// текстом, не разбирая их: директивы редки, а пакетов много
+func dependencyLinknames(pkg *packages.Package) *LinknameDependency {
+    dep := &LinknameDependency{Package: pkg.PkgPath, Targets: []string{}, Risk: "low"}
+    for _, filename := range pkg.CompiledGoFiles {
+        data, err := os.ReadFile(filename)
+        if err != nil || !bytes.Contains(data, []byte("//go:linkname ")) {
+            continue
+        }
+        scanner := bufio.NewScanner(bytes.NewReader(data))
+        for scanner.Scan() {
+            _, target, ok := parseLinkname(strings.TrimSpace(scanner.Text()))
+            if !ok || target == "" {
+                continue
+            }
+            dep.Directives++
+            dep.Targets = appendUnique(dep.Targets, target)
+            if linknameRisk(pkg.PkgPath, target) == "high" {
+                dep.Risk = "high"
+            }
+        }
+    }
+    if dep.Directives == 0 {
+        return nil
+    }
+    sort.Strings(dep.Targets)
+    return dep
+}
+
+// extractLinknames собирает go:linkname проекта и зависимостей, ссылки
+// ассемблера на runtime и импорты внутренних пакетов std
+func extractLinknames(pkgs []*packages.Package, projectPath string) *LinknameReport {
+    report := &LinknameReport{
+        Directives:      []LinknameDirective{},
+        InternalImports: []InternalImport{},
+        Dependencies:    []LinknameDependency{},
+    }
+    project := make(map[string]bool)
+    for _, pkg := range pkgs {
+        project[pkg.PkgPath] = true
+        report.Directives = append(report.Directives, asmRuntimeRefs(pkg, projectPath)...)
+        for _, file := range pkg.Syntax {
+            report.Directives = append(report.Directives, fileLinknames(pkg, file, projectPath)...)
+            for _, imp := range file.Imports {
+                path := strings.Trim(imp.Path.Value, `"`)
+                if !stdInternal(path) {
+                    continue
+                }
+                pos := pkg.Fset.Position(imp.Pos())
+                report.InternalImports = append(report.InternalImports, InternalImport{
+                    Package: pkg.PkgPath,
+                    File:    relativePath(projectPath, pos.Filename),
+                    Line:    pos.Line,
+                    Import:  path,
+                })
+            }
+        }
+    }
+    packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+        if project[pkg.PkgPath] || stdPackage(pkg.PkgPath) {
+            return
+        }
+        if dep := dependencyLinknames(pkg); dep != nil {
+            report.Dependencies = append(report.Dependencies, *dep)
+        }
+    })
+    sort.SliceStable(report.Directives, func(i, j int) bool {
+        if report.Directives[i].File != report.Directives[j].File {
+            return report.Directives[i].File < report.Directives[j].File
+        }
+        return report.Directives[i].Line < report.Directives[j].Line
+    })
+    sort.SliceStable(report.InternalImports, func(i, j int) bool {
+        if report.InternalImports[i].File != report.InternalImports[j].File {
+            return report.InternalImports[i].File < report.InternalImports[j].File
+        }
+        return report.InternalImports[i].Line < report.InternalImports[j].Line
+    })
+    sort.Slice(report.Dependencies, func(i, j int) bool { return report.Dependencies[i].Package < report.Dependencies[j].Package })
+    return report
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+    "fmt"
+    "log/slog"
+    "os"
+)
+
+// setupLogger направляет структурированные логи в stderr, чтобы stdout
+// оставался чистым JSON-результатом
+func setupLogger(verbose, quiet bool, format string) error {
+    level := slog.LevelInfo
+    switch {
+    case verbose && quiet:
+        return fmt.Errorf("-v and -q are mutually exclusive")
+    case verbose:
+        level = slog.LevelDebug
+    case quiet:
+        level = slog.LevelWarn
+    }
+
+    options := &slog.HandlerOptions{Level: level}
+    var handler slog.Handler
+    switch format {
+    case "text":
+        handler = slog.NewTextHandler(os.Stderr, options)
+    case "json":
+        handler = slog.NewJSONHandler(os.Stderr, options)
+    default:
+        return fmt.Errorf("unknown log format %q (supported: text, json)", format)
+    }
+    slog.SetDefault(slog.New(handler))
+    return nil
+}
+
+// fatalHooks выполняются перед выходом по fatal в обратном порядке:
+// os.Exit пропускает defer, а временные файлы вывода нужно удалить
+var fatalHooks []func()
+
+func onFatal(hook func()) {
+    fatalHooks = append(fatalHooks, hook)
+}
+
+// fatal логирует ошибку и завершает процесс
+func fatal(msg string, args ...any) {
+    slog.Error(msg, args...)
+    for i := len(fatalHooks) - 1; i >= 0; i-- {
+        fatalHooks[i]()
+    }
+    os.Exit(1)
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type TypeImplementations struct {
+    Type         string   `json:"type"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Interfaces   []string `json:"interfaces"`
+}
+
+type wellKnownInterface struct {
+    name    string
+    method  string
+    params  []string
+    results []string
+}
+
+// Интерфейсы сериализации и форматирования, описанные по сигнатуре метода,
+// чтобы не требовать загрузки соответствующих пакетов
+var wellKnownInterfaces = []wellKnownInterface{
+    {name: "fmt.Stringer", method: "String", results: []string{"string"}},
+    {name: "fmt.GoStringer", method: "GoString", results: []string{"string"}},
+    {name: "fmt.Formatter", method: "Format", params: []string{"fmt.State", "rune"}},
+    {name: "error", method: "Error", results: []string{"string"}},
+    {name: "encoding/json.Marshaler", method: "MarshalJSON", results: []string{"[]byte", "error"}},
+    {name: "encoding/json.Unmarshaler", method: "UnmarshalJSON", params: []string{"[]byte"}, results: []string{"error"}},
+    {name: "encoding.TextMarshaler", method: "MarshalText", results: []string{"[]byte", "error"}},
+    {name: "encoding.TextUnmarshaler", method: "UnmarshalText", params: []string{"[]byte"}, results: []string{"error"}},
+    {name: "encoding.BinaryMarshaler", method: "MarshalBinary", results: []string{"[]byte", "error"}},
+    {name: "encoding.BinaryUnmarshaler", method: "UnmarshalBinary", params: []string{"[]byte"}, results: []string{"error"}},
+    {name: "encoding/xml.Marshaler", method: "MarshalXML", params: []string{"*encoding/xml.Encoder", "encoding/xml.StartElement"}, results: []string{"error"}},
+    {name: "encoding/xml.Unmarshaler", method: "UnmarshalXML", params: []string{"*encoding/xml.Decoder", "encoding/xml.StartElement"}, results: []string{"error"}},
+    {name: "encoding/gob.GobEncoder", method: "GobEncode", results: []string{"[]byte", "error"}},
+    {name: "encoding/gob.GobDecoder", method: "GobDecode", params: []string{"[]byte"}, results: []string{"error"}},
+    {name: "database/sql.Scanner", method: "Scan", params: []string{"any"}, results: []string{"error"}},
+    {name: "database/sql/driver.Valuer", method: "Value", results: []string{"database/sql/driver.Value", "error"}},
+    {name: "gopkg.in/yaml.v3.Marshaler", method: "MarshalYAML", results: []string{"any", "error"}},
+    {name: "gopkg.in/yaml.v3.Unmarshaler", method: "UnmarshalYAML", params: []string{"*gopkg.in/yaml.v3.Node"}, results: []string{"error"}},
+    {name: "google.golang.org/protobuf/proto.Message", method: "ProtoReflect", results: []string{"google.golang.org/protobuf/reflect/protoreflect.Message"}},
+}
+
+func tupleStrings(tuple *types.Tuple) []string {
+    var result []string
+    for i := 0; i < tuple.Len(); i++ {
+        t := types.TypeString(tuple.At(i).Type(), nil)
+        if t == "interface{}" {
+            t = "any"
+        }
+        result = append(result, t)
+    }
+    return result
+}
+
+func equalStrings(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// implementedInterfaces проверяет методы типа (с учётом указателя на тип)
+// против списка известных интерфейсов
+func implementedInterfaces(named *types.Named) []string {
+    mset := types.NewMethodSet(types.NewPointer(named))
+    var result []string
+    for _, iface := range wellKnownInterfaces {
+        sel := mset.Lookup(nil, iface.method)
+        if sel == nil {
+            continue
+        }
+        sig, ok := sel.Type().(*types.Signature)
+        if !ok {
+            continue
+        }
+        if equalStrings(tupleStrings(sig.Params()), iface.params) && equalStrings(tupleStrings(sig.Results()), iface.results) {
+            // Скрытые указателем методы значения помечаем явно
+            name := iface.name
+            if valueSel := types.NewMethodSet(named).Lookup(nil, iface.method); valueSel == nil {
+                name += " (pointer receiver)"
+            }
+            result = append(result, name)
+        }
+    }
+    return result
+}
+
+func extractImplementations(pkg *packages.Package, projectPath string) []TypeImplementations {
+    if pkg.Types == nil {
+        return nil
+    }
+
+    var result []TypeImplementations
+    scope := pkg.Types.Scope()
+    for _, name := range scope.Names() {
+        tn, ok := scope.Lookup(name).(*types.TypeName)
+        if !ok || tn.IsAlias() {
+            continue
+        }
+        named, ok := tn.Type().(*types.Named)
+        if !ok {
+            continue
+        }
+        if _, ok := named.Underlying().(*types.Interface); ok {
+            continue
+        }
+
+        ifaces := implementedInterfaces(named)
+        if len(ifaces) == 0 {
+            continue
+        }
+        pos := pkg.Fset.Position(tn.Pos())
+        result = append(result, TypeImplementations{
+            Type:       pkg.PkgPath + "." + name,
+            File:       relativePath(projectPath, pos.Filename),
+            Line:       pos.Line,
+            Interfaces: ifaces,
+        })
+    }
+    sort.SliceStable(result, func(i, j int) bool {
+        return result[i].Type < result[j].Type
+    })
+    return result
+}
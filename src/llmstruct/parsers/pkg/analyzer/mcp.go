@@ -0,0 +1,338 @@
+package analyzer
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+)
+
+// Версия протокола Model Context Protocol, которую реализует сервер
+const mcpProtocolVersion = "2024-11-05"
+
+type rpcRequest struct {
+    JSONRPC      string          `json:"jsonrpc"`
+    ID           json.RawMessage `json:"id,omitempty"`
+    Method       string          `json:"method"`
+    Params       json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+    Code         int      `json:"code"`
+    Message      string   `json:"message"`
+}
+
+type rpcResponse struct {
+    JSONRPC      string          `json:"jsonrpc"`
+    ID           json.RawMessage `json:"id"`
+    Result       any             `json:"result,omitempty"`
+    Error        *rpcError       `json:"error,omitempty"`
+}
+
+type mcpTool struct {
+    Name         string         `json:"name"`
+    Description  string         `json:"description"`
+    InputSchema  map[string]any `json:"inputSchema"`
+}
+
+type mcpContent struct {
+    Type         string   `json:"type"`
+    Text         string   `json:"text"`
+}
+
+type mcpToolResult struct {
+    Content      []mcpContent `json:"content"`
+    IsError      bool         `json:"isError,omitempty"`
+}
+
+// SymbolDetail - ответ get_symbol: символ, его объявление и связи
+type SymbolDetail struct {
+    Symbol          SymbolMatch `json:"symbol"`
+    Declaration     any         `json:"declaration,omitempty"`
+    Callers         []string    `json:"callers"`
+    Callees         []string    `json:"callees"`
+    References      int         `json:"references"`
+    Interfaces      []string    `json:"interfaces,omitempty"`
+    Implementations []string    `json:"implementations,omitempty"`
+}
+
+// ProjectStructure - ответ get_project_structure: пакеты и файлы проекта
+// без содержимого; разделы анализа добавляются по запросу
+type ProjectStructure struct {
+    ModuleName   string                     `json:"module_name"`
+    GoVersion    string                     `json:"go_version"`
+    TotalLines   int                        `json:"total_lines"`
+    Packages     []PackageFiles             `json:"packages"`
+    Sections     map[string]json.RawMessage `json:"sections,omitempty"`
+}
+
+type PackageFiles struct {
+    Package      string   `json:"package"`
+    Files        []string `json:"files"`
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+    schema := map[string]any{"type": "object", "properties": properties}
+    if len(required) > 0 {
+        schema["required"] = required
+    }
+    return schema
+}
+
+var mcpTools = []mcpTool{
+    {
+        Name:        "get_project_structure",
+        Description: "Module name, Go version and the packages of the project with their files. Pass sections (e.g. routes, call_graph, services) to include those analysis sections.",
+        InputSchema: objectSchema(map[string]any{
+            "sections": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "top-level analysis sections to include"},
+        }),
+    },
+    {
+        Name:        "get_symbol",
+        Description: "Declaration of a function, method, type, variable or constant with its callers, callees and interface relations. Accepts a stable ID (go:example.com/pkg.Func) or a short name (pkg.Func, pkg.Type.Method).",
+        InputSchema: objectSchema(map[string]any{
+            "symbol": map[string]any{"type": "string"},
+        }, "symbol"),
+    },
+    {
+        Name:        "search_symbols",
+        Description: "Find symbols whose ID contains the query (case-insensitive); exact name matches come first.",
+        InputSchema: objectSchema(map[string]any{
+            "query": map[string]any{"type": "string"},
+            "kind":  map[string]any{"type": "string", "enum": []string{"function", "method", "struct", "interface", "variable", "constant"}},
+            "limit": map[string]any{"type": "integer", "minimum": 1, "default": 50},
+        }, "query"),
+    },
+    {
+        Name:        "get_file_outline",
+        Description: "Imports, functions, types, variables and constants of a Go file, by path relative to the project root.",
+        InputSchema: objectSchema(map[string]any{
+            "path": map[string]any{"type": "string"},
+        }, "path"),
+    },
+}
+
+// symbolDecl находит объявление символа в разборе его файла
+func symbolDecl(file FileAnalysis, sym *goSymbol) any {
+    name := sym.name[strings.LastIndex(sym.name, ".")+1:]
+    for _, fn := range file.Functions {
+        if fn.Name == name && fn.Line == sym.line {
+            return fn
+        }
+    }
+    for _, group := range [][]Struct{file.Structs, file.Interfaces} {
+        for _, st := range group {
+            if st.Name == name && st.Line == sym.line {
+                return st
+            }
+            for _, m := range st.Methods {
+                if m.Name == name && m.Line == sym.line {
+                    return m
+                }
+            }
+        }
+    }
+    for _, group := range [][]Variable{file.Variables, file.Constants} {
+        for _, v := range group {
+            if v.Name == name && v.Line == sym.line {
+                return v
+            }
+        }
+    }
+    return nil
+}
+
+func (s *analysisServer) projectStructure(sections []string) (*ProjectStructure, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    a := s.analysis
+    structure := &ProjectStructure{ModuleName: a.ModuleName, GoVersion: a.GoVersion, TotalLines: a.TotalLines, Packages: []PackageFiles{}}
+    byPackage := make(map[string][]string)
+    for _, file := range a.Files {
+        pkg := packagePathOf(a, file.Path)
+        byPackage[pkg] = append(byPackage[pkg], file.Path)
+    }
+    for pkg, files := range byPackage {
+        sort.Strings(files)
+        structure.Packages = append(structure.Packages, PackageFiles{Package: pkg, Files: files})
+    }
+    sort.Slice(structure.Packages, func(i, j int) bool { return structure.Packages[i].Package < structure.Packages[j].Package })
+    if len(sections) == 0 {
+        return structure, nil
+    }
+    data, err := json.Marshal(a)
+    if err != nil {
+        return nil, err
+    }
+    var all map[string]json.RawMessage
+    if err := json.Unmarshal(data, &all); err != nil {
+        return nil, err
+    }
+    structure.Sections = make(map[string]json.RawMessage)
+    for _, name := range sections {
+        section, ok := all[name]
+        if !ok {
+            return nil, fmt.Errorf("section %q is empty or unknown", name)
+        }
+        structure.Sections[name] = section
+    }
+    return structure, nil
+}
+
+func (s *analysisServer) symbolDetail(query string) (*SymbolDetail, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    sym, err := resolveSymbol(s.symbols, query)
+    if err != nil {
+        return nil, err
+    }
+    refs := s.analysis.refs
+    detail := &SymbolDetail{
+        Symbol:     symbolMatch(sym),
+        Callers:    refs.callers(sym.id),
+        Callees:    refs.callees(sym.id),
+        References: len(refs.byTo[sym.id]),
+    }
+    if i, ok := s.files[sym.file]; ok {
+        detail.Declaration = symbolDecl(s.analysis.Files[i], sym)
+    }
+    switch sym.kind {
+    case "struct":
+        detail.Interfaces = refs.implements[sym.id]
+    case "interface":
+        detail.Implementations = refs.implementedBy[sym.id]
+    case "method":
+        detail.Interfaces = dispatchTargets(refs, sym)
+    }
+    if detail.Callers == nil {
+        detail.Callers = []string{}
+    }
+    if detail.Callees == nil {
+        detail.Callees = []string{}
+    }
+    return detail, nil
+}
+
+// callTool выполняет инструмент; ошибка аргументов или поиска
+// возвращается как результат с isError, а не как ошибка протокола
+func (s *analysisServer) callTool(name string, args json.RawMessage) (any, error) {
+    var params struct {
+        Sections []string `json:"sections"`
+        Symbol   string   `json:"symbol"`
+        Query    string   `json:"query"`
+        Kind     string   `json:"kind"`
+        Limit    int      `json:"limit"`
+        Path     string   `json:"path"`
+    }
+    if len(args) > 0 {
+        if err := json.Unmarshal(args, &params); err != nil {
+            return nil, fmt.Errorf("invalid arguments: %v", err)
+        }
+    }
+    switch name {
+    case "get_project_structure":
+        return s.projectStructure(params.Sections)
+    case "get_symbol":
+        return s.symbolDetail(params.Symbol)
+    case "search_symbols":
+        if params.Limit <= 0 {
+            params.Limit = 50
+        }
+        return s.searchSymbols(params.Query, params.Kind, params.Limit), nil
+    case "get_file_outline":
+        path := strings.TrimPrefix(params.Path, "./")
+        s.mu.RLock()
+        defer s.mu.RUnlock()
+        i, ok := s.files[path]
+        if !ok {
+            return nil, fmt.Errorf("file not found: %s", path)
+        }
+        return s.analysis.Files[i], nil
+    }
+    return nil, fmt.Errorf("unknown tool %q", name)
+}
+
+// handleRPC обрабатывает запрос; nil - уведомление, ответ не нужен
+func (s *analysisServer) handleRPC(req rpcRequest) *rpcResponse {
+    if req.ID == nil {
+        return nil
+    }
+    resp := &rpcResponse{JSONRPC: "2.0", ID: req.ID}
+    switch req.Method {
+    case "initialize":
+        resp.Result = map[string]any{
+            "protocolVersion": mcpProtocolVersion,
+            "capabilities":    map[string]any{"tools": map[string]any{}},
+            "serverInfo":      map[string]any{"name": "llmstruct", "version": "1"},
+        }
+    case "ping":
+        resp.Result = map[string]any{}
+    case "tools/list":
+        resp.Result = map[string]any{"tools": mcpTools}
+    case "tools/call":
+        var call struct {
+            Name      string          `json:"name"`
+            Arguments json.RawMessage `json:"arguments"`
+        }
+        if err := json.Unmarshal(req.Params, &call); err != nil {
+            resp.Error = &rpcError{Code: -32602, Message: "invalid params: " + err.Error()}
+            break
+        }
+        result := mcpToolResult{Content: []mcpContent{}}
+        value, err := s.callTool(call.Name, call.Arguments)
+        if err == nil {
+            var data []byte
+            if data, err = json.Marshal(value); err == nil {
+                result.Content = append(result.Content, mcpContent{Type: "text", Text: string(data)})
+            }
+        }
+        if err != nil {
+            result.IsError = true
+            result.Content = append(result.Content, mcpContent{Type: "text", Text: err.Error()})
+        }
+        resp.Result = result
+    default:
+        resp.Error = &rpcError{Code: -32601, Message: "method not found: " + req.Method}
+    }
+    return resp
+}
+
+// serveMCP читает запросы JSON-RPC построчно и пишет ответы в out
+func (s *analysisServer) serveMCP(in io.Reader, out io.Writer) error {
+    scanner := bufio.NewScanner(in)
+    scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+    enc := json.NewEncoder(out)
+    for scanner.Scan() {
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" {
+            continue
+        }
+        var req rpcRequest
+        var resp *rpcResponse
+        if err := json.Unmarshal([]byte(line), &req); err != nil {
+            resp = &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: -32700, Message: "parse error: " + err.Error()}}
+        } else {
+            resp = s.handleRPC(req)
+        }
+        if resp == nil {
+            continue
+        }
+        if err := enc.Encode(resp); err != nil {
+            return err
+        }
+    }
+    return scanner.Err()
+}
+
+// runMCP - analyzer mcp: сервер Model Context Protocol на stdin/stdout
+func runMCP(args []string) {
+    fs := newCommandFlags("mcp", "[project_path]")
+    projectPath := fs.parse(args)
+    server := newAnalysisServer(projectPath)
+    if err := server.serveMCP(os.Stdin, os.Stdout); err != nil {
+        fatal("mcp server failed", "error", err)
+    }
+}
@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "os/exec"
+    "sort"
+    "strings"
+)
+
+type ModuleReplace struct {
+    Path         string   `json:"path"`
+    Version      string   `json:"version,omitempty"`
+}
+
+type ModuleInfo struct {
+    Path         string         `json:"path"`
+    Version      string         `json:"version,omitempty"`
+    Main         bool           `json:"main,omitempty"`
+    Direct       bool           `json:"direct"`
+    Indirect     bool           `json:"indirect"`
+    Replace      *ModuleReplace `json:"replace,omitempty"`
+    Sum          string         `json:"sum,omitempty"`
+    GoModSum     string         `json:"go_mod_sum,omitempty"`
+}
+
+type ModuleEdge struct {
+    From         string   `json:"from"`
+    To           string   `json:"to"`
+}
+
+type ModuleGraph struct {
+    Modules      []ModuleInfo `json:"modules"`
+    Edges        []ModuleEdge `json:"edges"`
+    ChecksumIssues []ChecksumIssue `json:"checksum_issues"`
+}
+
+// goListModule - запись `go list -m -json`
+type goListModule struct {
+    Path     string
+    Version  string
+    Main     bool
+    Indirect bool
+    Replace  *struct {
+        Path    string
+        Version string
+    }
+}
+
+func runGo(dir string, args ...string) ([]byte, error) {
+    cmd := exec.Command("go", args...)
+    cmd.Dir = dir
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    if err != nil {
+        return nil, fmt.Errorf("go %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+    }
+    return out, nil
+}
+
+// buildModuleGraph строит граф модулей по `go list -m -json all` и `go mod graph`;
+// -e не даёт неполному go.sum сорвать построение графа
+func buildModuleGraph(projectPath string) (*ModuleGraph, error) {
+    out, err := runGo(projectPath, "list", "-m", "-e", "-json", "all")
+    if err != nil {
+        return nil, err
+    }
+
+    graph := &ModuleGraph{Modules: []ModuleInfo{}, Edges: []ModuleEdge{}}
+    var mainPath string
+    dec := json.NewDecoder(bytes.NewReader(out))
+    for dec.More() {
+        var m goListModule
+        if err := dec.Decode(&m); err != nil {
+            return nil, fmt.Errorf("go list -m: %v", err)
+        }
+        info := ModuleInfo{Path: m.Path, Version: m.Version, Main: m.Main, Indirect: m.Indirect}
+        if m.Replace != nil {
+            info.Replace = &ModuleReplace{Path: m.Replace.Path, Version: m.Replace.Version}
+        }
+        if m.Main {
+            mainPath = m.Path
+        }
+        graph.Modules = append(graph.Modules, info)
+    }
+
+    out, err = runGo(projectPath, "mod", "graph")
+    if err != nil {
+        return nil, err
+    }
+    direct := make(map[string]bool)
+    for _, line := range strings.Split(string(out), "\n") {
+        fields := strings.Fields(line)
+        if len(fields) != 2 {
+            continue
+        }
+        graph.Edges = append(graph.Edges, ModuleEdge{From: fields[0], To: fields[1]})
+        // Прямые требования - рёбра из главного модуля (без версии)
+        if fields[0] == mainPath {
+            path, _, _ := strings.Cut(fields[1], "@")
+            direct[path] = true
+        }
+    }
+    for i := range graph.Modules {
+        m := &graph.Modules[i]
+        m.Direct = !m.Main && direct[m.Path] && !m.Indirect
+    }
+    sort.SliceStable(graph.Modules, func(i, j int) bool {
+        if graph.Modules[i].Main != graph.Modules[j].Main {
+            return graph.Modules[i].Main
+        }
+        return graph.Modules[i].Path < graph.Modules[j].Path
+    })
+    return graph, nil
+}
+
+// dot выводит граф модулей в формате Graphviz; прямые зависимости
+// выделяются, косвенные рисуются пунктиром
+func (g *ModuleGraph) dot() string {
+    var sb strings.Builder
+    sb.WriteString("digraph modules {\n")
+    sb.WriteString("    rankdir=LR;\n")
+    sb.WriteString("    node [shape=box];\n")
+    for _, m := range g.Modules {
+        id := m.Path
+        if m.Version != "" {
+            id += "@" + m.Version
+        }
+        attrs := []string{}
+        switch {
+        case m.Main:
+            attrs = append(attrs, "style=filled", "fillcolor=lightblue")
+        case m.Direct:
+            attrs = append(attrs, "style=bold")
+        case m.Indirect:
+            attrs = append(attrs, "style=dashed")
+        }
+        if m.Replace != nil {
+            label := m.Replace.Path
+            if m.Replace.Version != "" {
+                label += "@" + m.Replace.Version
+            }
+            attrs = append(attrs, fmt.Sprintf("xlabel=%q", "=> "+label))
+        }
+        fmt.Fprintf(&sb, "    %q [%s];\n", id, strings.Join(attrs, ", "))
+    }
+    for _, e := range g.Edges {
+        fmt.Fprintf(&sb, "    %q -> %q;\n", e.From, e.To)
+    }
+    sb.WriteString("}\n")
+    return sb.String()
+}
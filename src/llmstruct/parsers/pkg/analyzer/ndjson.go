@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+    "bufio"
+    "encoding/json"
+    "io"
+    "os"
+)
+
+// ndjsonFile - запись о файле в потоке NDJSON
+type ndjsonFile struct {
+    Type         string   `json:"type"`
+    FileAnalysis
+}
+
+// ndjsonSummary - итоговая запись: все секции проекта без файлов
+type ndjsonSummary struct {
+    Type         string         `json:"type"`
+    *ProjectAnalysis
+    Files        []FileAnalysis `json:"files,omitempty"`
+}
+
+// ndjsonWriter пишет результат построчно по мере анализа файлов, не
+// собирая весь JSON в памяти; с путём вывод атомарный, как у writeResult
+type ndjsonWriter struct {
+    out      *bufio.Writer
+    enc      *json.Encoder
+    tmp      *os.File
+    path     string
+}
+
+func newNDJSONWriter(outputPath string) (*ndjsonWriter, error) {
+    w := &ndjsonWriter{path: outputPath}
+    var dst io.Writer = os.Stdout
+    if outputPath != "" {
+        tmp, err := createAtomic(outputPath)
+        if err != nil {
+            return nil, err
+        }
+        w.tmp, dst = tmp, tmp
+    }
+    w.out = bufio.NewWriter(dst)
+    w.enc = json.NewEncoder(w.out)
+    return w, nil
+}
+
+// file - FileSink анализа: файл уходит в вывод сразу после разбора
+func (w *ndjsonWriter) file(analysis FileAnalysis) error {
+    return w.enc.Encode(ndjsonFile{Type: "file", FileAnalysis: analysis})
+}
+
+// finish пишет итоговую запись и завершает вывод
+func (w *ndjsonWriter) finish(analysis *ProjectAnalysis) error {
+    if err := w.enc.Encode(ndjsonSummary{Type: "summary", ProjectAnalysis: analysis}); err != nil {
+        w.abort()
+        return err
+    }
+    if err := w.out.Flush(); err != nil {
+        w.abort()
+        return err
+    }
+    if w.tmp == nil {
+        return nil
+    }
+    tmp := w.tmp
+    w.tmp = nil
+    if err := commitAtomic(tmp, w.path); err != nil {
+        os.Remove(tmp.Name())
+        return err
+    }
+    return nil
+}
+
+// abort удаляет недописанный временный файл; после finish ничего не делает
+func (w *ndjsonWriter) abort() {
+    if w.tmp != nil {
+        w.tmp.Close()
+        os.Remove(w.tmp.Name())
+        w.tmp = nil
+    }
+}
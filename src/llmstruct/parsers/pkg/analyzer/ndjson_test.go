@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+    "context"
+    "testing"
+)
+
+func TestFileSinkDoesNotKeepFiles(t *testing.T) {
+    project := writeTestProject(t, map[string]string{
+        "go.mod": "module example.com/stream\n\ngo 1.21\n",
+        "a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+        "b/b.go": "package b\n\nfunc B() int { return 2 }\n",
+    })
+    opts := testOptions(t)
+    var sunk []string
+    opts.FileSink = func(file FileAnalysis) error {
+        sunk = append(sunk, file.Path)
+        return nil
+    }
+    analysis := analyzeProject(context.Background(), project, opts)
+
+    if len(sunk) != 2 {
+        t.Errorf("sunk files = %v, want a/a.go and b/b.go", sunk)
+    }
+    if len(analysis.Files) != 0 {
+        t.Errorf("analysis keeps %d streamed files", len(analysis.Files))
+    }
+    if analysis.TotalLines != 8 {
+        t.Errorf("total lines = %d, want 8", analysis.TotalLines)
+    }
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+)
+
+// writeAtomic пишет файл через временный файл в том же каталоге и rename,
+// поэтому прерванный процесс не оставляет усечённый результат
+func writeAtomic(path string, data []byte) error {
+    tmp, err := createAtomic(path)
+    if err != nil {
+        return err
+    }
+    // После успешного rename удалять уже нечего
+    defer os.Remove(tmp.Name())
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    return commitAtomic(tmp, path)
+}
+
+// createAtomic создаёт временный файл рядом с path для последующего
+// commitAtomic
+func createAtomic(path string) (*os.File, error) {
+    return os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+}
+
+// commitAtomic сбрасывает временный файл на диск и переименовывает его в path
+func commitAtomic(tmp *os.File, path string) error {
+    if err := tmp.Sync(); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    if err := os.Chmod(tmp.Name(), 0644); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
+
+// Форматы вывода результата; pr-comment - только для сравнений
+var outputFormats = []string{"json", "yaml", "pr-comment"}
+
+func validFormat(format string) error {
+    if !slices.Contains(outputFormats, format) {
+        return fmt.Errorf("unknown output format %q (want %s)", format, strings.Join(outputFormats, " or "))
+    }
+    return nil
+}
+
+// withoutMeta убирает из анализа meta (время этапов и попадания в кэш):
+// YAML хранится в репозитории и не должен меняться без изменений кода
+func withoutMeta(result any) any {
+    switch r := result.(type) {
+    case *ProjectAnalysis:
+        stripped := *r
+        stripped.Meta = nil
+        return &stripped
+    case BatchAnalysis:
+        projects := make([]*ProjectAnalysis, len(r.Projects))
+        for i, p := range r.Projects {
+            projects[i] = withoutMeta(p).(*ProjectAnalysis)
+        }
+        r.Projects = projects
+        return r
+    }
+    return result
+}
+
+// renderResult сериализует результат в JSON, YAML или комментарий к PR;
+// compact действует только на JSON
+func renderResult(result any, format string, compact bool) ([]byte, error) {
+    var output []byte
+    var err error
+    switch {
+    case format == "pr-comment":
+        r, ok := result.(prCommenter)
+        if !ok {
+            return nil, fmt.Errorf("-format pr-comment is only supported for diffs")
+        }
+        var text string
+        text, err = r.prComment()
+        output = []byte(text)
+    case format == "yaml":
+        output, err = json.Marshal(withoutMeta(result))
+        if err == nil {
+            output, err = encodeYAML(output)
+        }
+    case compact:
+        output, err = json.Marshal(result)
+    default:
+        output, err = json.MarshalIndent(result, "", "  ")
+    }
+    if err != nil {
+        return nil, err
+    }
+    if format == "json" {
+        output = append(output, '\n')
+    }
+    return output, nil
+}
+
+// writeResult пишет результат в файл (атомарно) или в stdout, если путь
+// пуст
+func writeResult(result any, outputPath, format string, compact bool) error {
+    output, err := renderResult(result, format, compact)
+    if err != nil {
+        return err
+    }
+
+    if outputPath != "" {
+        return writeAtomic(outputPath, output)
+    }
+    _, err = os.Stdout.Write(output)
+    return err
+}
@@ -0,0 +1,336 @@
+package analyzer
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path"
+    "sort"
+    "strings"
+)
+
+// FunctionChange - изменение функции или метода между двумя анализами:
+// added, removed, changed, moved (в другой файл или пакет), renamed.
+// Details - что изменилось: signature, body (по числу токенов и строк),
+// complexity
+type FunctionChange struct {
+    Symbol       string   `json:"symbol"`
+    Kind         string   `json:"kind"`
+    Change       string   `json:"change"`
+    File         string   `json:"file"`
+    OldSymbol    string   `json:"old_symbol,omitempty"`
+    OldFile      string   `json:"old_file,omitempty"`
+    Before       string   `json:"before,omitempty"`
+    After        string   `json:"after,omitempty"`
+    Details      []string `json:"details,omitempty"`
+}
+
+// FileMove - файл, удалённый в одном месте и добавленный в другом с теми
+// же символами; Symbols - число совпавших имён
+type FileMove struct {
+    From         string   `json:"from"`
+    To           string   `json:"to"`
+    Symbols      int      `json:"symbols"`
+}
+
+// OutputsDiff - результат analyzer diff: изменения функций и файлов между
+// двумя сохранёнными анализами и изменения API и формата сериализации
+type OutputsDiff struct {
+    Old          string           `json:"old"`
+    New          string           `json:"new"`
+    Functions    []FunctionChange `json:"functions"`
+    FilesAdded   []string         `json:"files_added"`
+    FilesRemoved []string         `json:"files_removed"`
+    FilesMoved   []FileMove       `json:"files_moved"`
+    Changes      *AnalysisDiff    `json:"changes"`
+}
+
+// Доля общих имён символов, с которой пара удалённый/добавленный файл
+// считается переносом
+const fileMoveThreshold = 0.5
+
+func readAnalysis(file string) (*ProjectAnalysis, error) {
+    data, err := os.ReadFile(file)
+    if err != nil {
+        return nil, err
+    }
+    var analysis ProjectAnalysis
+    if err := json.Unmarshal(data, &analysis); err != nil {
+        return nil, fmt.Errorf("%s: %v", file, err)
+    }
+    return &analysis, nil
+}
+
+// diffFunc - функция анализа с файлом и видом
+type diffFunc struct {
+    *Function
+    file string
+    kind string
+}
+
+// functionsOf - функции и методы анализа по стабильному ID
+func functionsOf(analysis *ProjectAnalysis) map[string]diffFunc {
+    funcs := make(map[string]diffFunc)
+    for i := range analysis.Files {
+        file := &analysis.Files[i]
+        pkgPath := packagePathOf(analysis, file.Path)
+        for j := range file.Functions {
+            fn := &file.Functions[j]
+            name, kind := fn.Name, "function"
+            if fn.IsMethod {
+                name, kind = receiverTypeName(fn.Receiver)+"."+fn.Name, "method"
+            }
+            funcs[goSymbolID(pkgPath, name)] = diffFunc{fn, file.Path, kind}
+        }
+    }
+    return funcs
+}
+
+// functionDetails - что изменилось в функции; тексты в анализе не
+// хранятся, поэтому изменение тела определяется по токенам и длине
+func functionDetails(old, cur diffFunc) []string {
+    var details []string
+    if funcSignature(*old.Function) != funcSignature(*cur.Function) {
+        details = append(details, "signature")
+    }
+    if old.Tokens != cur.Tokens || old.EndLine-old.Line != cur.EndLine-cur.Line {
+        details = append(details, "body")
+    }
+    if old.Complexity != cur.Complexity {
+        details = append(details, fmt.Sprintf("complexity %d -> %d", old.Complexity, cur.Complexity))
+    }
+    return details
+}
+
+// diffFiles находит добавленные, удалённые и перенесённые файлы: перенос -
+// пара с тем же именем файла или с большинством общих имён символов
+func diffFiles(base, head *ProjectAnalysis) (added, removed []string, moves []FileMove) {
+    names := func(file *FileAnalysis) map[string]bool {
+        set := make(map[string]bool)
+        for _, fn := range file.Functions {
+            set[fn.Receiver+"."+fn.Name] = true
+        }
+        for _, st := range append(append([]Struct{}, file.Structs...), file.Interfaces...) {
+            set[st.Name] = true
+        }
+        return set
+    }
+    before, after := make(map[string]*FileAnalysis), make(map[string]*FileAnalysis)
+    for i := range base.Files {
+        before[base.Files[i].Path] = &base.Files[i]
+    }
+    for i := range head.Files {
+        after[head.Files[i].Path] = &head.Files[i]
+    }
+    var gone, fresh []*FileAnalysis
+    for p, f := range before {
+        if after[p] == nil {
+            gone = append(gone, f)
+        }
+    }
+    for p, f := range after {
+        if before[p] == nil {
+            fresh = append(fresh, f)
+        }
+    }
+
+    type candidate struct {
+        from, to string
+        common   int
+        score    float64
+    }
+    var candidates []candidate
+    for _, g := range gone {
+        old := names(g)
+        for _, f := range fresh {
+            cur := names(f)
+            common := 0
+            for n := range old {
+                if cur[n] {
+                    common++
+                }
+            }
+            score := 0.0
+            if total := len(old) + len(cur) - common; total > 0 {
+                score = float64(common) / float64(total)
+            }
+            if path.Base(g.Path) == path.Base(f.Path) {
+                score += fileMoveThreshold
+            }
+            if score >= fileMoveThreshold {
+                candidates = append(candidates, candidate{g.Path, f.Path, common, score})
+            }
+        }
+    }
+    sort.Slice(candidates, func(i, j int) bool {
+        if candidates[i].score != candidates[j].score {
+            return candidates[i].score > candidates[j].score
+        }
+        return candidates[i].from+candidates[i].to < candidates[j].from+candidates[j].to
+    })
+    used := make(map[string]bool)
+    moves = []FileMove{}
+    for _, c := range candidates {
+        if used[c.from] || used[c.to] {
+            continue
+        }
+        used[c.from], used[c.to] = true, true
+        moves = append(moves, FileMove{From: c.from, To: c.to, Symbols: c.common})
+    }
+    added, removed = []string{}, []string{}
+    for _, f := range fresh {
+        if !used[f.Path] {
+            added = append(added, f.Path)
+        }
+    }
+    for _, g := range gone {
+        if !used[g.Path] {
+            removed = append(removed, g.Path)
+        }
+    }
+    sort.Strings(added)
+    sort.Strings(removed)
+    sort.Slice(moves, func(i, j int) bool { return moves[i].From < moves[j].From })
+    return added, removed, moves
+}
+
+// diffOutputs сравнивает два сохранённых анализа. Исходников нет:
+// переименования ищутся по сигнатурам и членам, как в history diff
+func diffOutputs(oldPath, newPath string, base, head *ProjectAnalysis) *OutputsDiff {
+    changes := diffAnalyses(oldPath, "", base, "", head, "")
+    oldFuncs, newFuncs := functionsOf(base), functionsOf(head)
+    diff := &OutputsDiff{Old: oldPath, New: newPath, Functions: []FunctionChange{}, Changes: changes}
+    diff.FilesAdded, diff.FilesRemoved, diff.FilesMoved = diffFiles(base, head)
+
+    paired := make(map[string]bool)
+    for _, r := range changes.Renames {
+        old, okOld := oldFuncs[r.From]
+        cur, okCur := newFuncs[r.To]
+        if !okOld || !okCur {
+            continue
+        }
+        paired[r.From], paired[r.To] = true, true
+        change := "renamed"
+        if r.Change == "moved" {
+            change = "moved"
+        }
+        diff.Functions = append(diff.Functions, FunctionChange{
+            Symbol:    r.To,
+            Kind:      cur.kind,
+            Change:    change,
+            File:      cur.file,
+            OldSymbol: r.From,
+            OldFile:   old.file,
+            Before:    funcSignature(*old.Function),
+            After:     funcSignature(*cur.Function),
+            Details:   functionDetails(old, cur),
+        })
+    }
+    for id, old := range oldFuncs {
+        if paired[id] {
+            continue
+        }
+        cur, ok := newFuncs[id]
+        if !ok {
+            diff.Functions = append(diff.Functions, FunctionChange{Symbol: id, Kind: old.kind, Change: "removed", File: old.file, Before: funcSignature(*old.Function)})
+            continue
+        }
+        c := FunctionChange{Symbol: id, Kind: cur.kind, Change: "changed", File: cur.file, Details: functionDetails(old, cur)}
+        if cur.file != old.file {
+            c.Change, c.OldFile = "moved", old.file
+        } else if len(c.Details) == 0 {
+            continue
+        }
+        if before, after := funcSignature(*old.Function), funcSignature(*cur.Function); before != after {
+            c.Before, c.After = before, after
+        }
+        diff.Functions = append(diff.Functions, c)
+    }
+    for id, cur := range newFuncs {
+        if _, ok := oldFuncs[id]; !ok && !paired[id] {
+            diff.Functions = append(diff.Functions, FunctionChange{Symbol: id, Kind: cur.kind, Change: "added", File: cur.file, After: funcSignature(*cur.Function)})
+        }
+    }
+    sort.Slice(diff.Functions, func(i, j int) bool {
+        if diff.Functions[i].File != diff.Functions[j].File {
+            return diff.Functions[i].File < diff.Functions[j].File
+        }
+        return diff.Functions[i].Symbol < diff.Functions[j].Symbol
+    })
+    return diff
+}
+
+// prComment - сводка изменений для промпта ревью: таблица функций и
+// файлов перед изменениями API
+func (d *OutputsDiff) prComment() (string, error) {
+    var b strings.Builder
+    fmt.Fprintf(&b, "### Changes from `%s` to `%s`\n\n", d.Old, d.New)
+    counts := make(map[string]int)
+    for _, c := range d.Functions {
+        counts[c.Change]++
+    }
+    var summary []string
+    for _, change := range []string{"added", "removed", "changed", "moved", "renamed"} {
+        if counts[change] > 0 {
+            summary = append(summary, fmt.Sprintf("%d %s", counts[change], change))
+        }
+    }
+    if len(summary) == 0 {
+        b.WriteString("No function changes.\n\n")
+    } else {
+        b.WriteString("Functions: " + strings.Join(summary, " · ") + "\n\n")
+        b.WriteString("| Function | Change | File | Details |\n|---|---|---|---|\n")
+        for i, c := range d.Functions {
+            if i == 200 {
+                fmt.Fprintf(&b, "\n...and %d more\n", len(d.Functions)-i)
+                break
+            }
+            var details []string
+            if c.OldSymbol != "" {
+                details = append(details, "from "+mdCell(shortSymbol(c.OldSymbol)))
+            }
+            details = append(details, c.Details...)
+            file := c.File
+            if c.OldFile != "" && c.OldFile != c.File {
+                file = c.OldFile + " → " + c.File
+            }
+            fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", mdCell(shortSymbol(c.Symbol)), c.Change, mdCell(file), strings.Join(details, ", "))
+        }
+        b.WriteString("\n")
+    }
+    if len(d.FilesAdded)+len(d.FilesRemoved)+len(d.FilesMoved) > 0 {
+        b.WriteString("<details open><summary>Files</summary>\n\n")
+        for _, f := range d.FilesAdded {
+            fmt.Fprintf(&b, "- added `%s`\n", f)
+        }
+        for _, f := range d.FilesRemoved {
+            fmt.Fprintf(&b, "- removed `%s`\n", f)
+        }
+        for _, m := range d.FilesMoved {
+            fmt.Fprintf(&b, "- moved `%s` → `%s`\n", m.From, m.To)
+        }
+        b.WriteString("\n</details>\n\n")
+    }
+    b.WriteString(renderPRComment(d.Changes, nil))
+    return b.String(), nil
+}
+
+// runDiff - analyzer diff old.json new.json: сравнение двух сохранённых
+// результатов анализа
+func runDiff(args []string) {
+    fs := newCommandFlags("diff", "<old.json> <new.json>")
+    fs.parse(args)
+    if fs.NArg() != 2 {
+        fs.Usage()
+        os.Exit(2)
+    }
+    base, err := readAnalysis(fs.Arg(0))
+    if err != nil {
+        fatal("failed to read analysis", "error", err)
+    }
+    head, err := readAnalysis(fs.Arg(1))
+    if err != nil {
+        fatal("failed to read analysis", "error", err)
+    }
+    fs.write(diffOutputs(fs.Arg(0), fs.Arg(1), base, head))
+}
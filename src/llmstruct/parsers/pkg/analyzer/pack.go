@@ -0,0 +1,355 @@
+package analyzer
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+)
+
+// PackSymbol - символ в контекстном пакете: сигнатура всегда, doc и
+// исходный текст объявления - пока хватает бюджета. Coverage - покрытие
+// функции тестами (с -coverprofile), 0 - непроверенный код
+type PackSymbol struct {
+    ID           string   `json:"id"`
+    Kind         string   `json:"kind"`
+    Line         int      `json:"line"`
+    Signature    string   `json:"signature"`
+    Coverage     *float64 `json:"coverage,omitempty"`
+    Doc          string   `json:"doc,omitempty"`
+    Body         string   `json:"body,omitempty"`
+}
+
+type PackFile struct {
+    Path         string       `json:"path"`
+    Package      string       `json:"package"`
+    Symbols      []PackSymbol `json:"symbols"`
+}
+
+// ContextPack - часть анализа, помещающаяся в бюджет токенов. Level -
+// последний этап, до которого дошло заполнение: signatures, docstrings
+// или bodies; Omitted* - что не поместилось на каждом этапе
+type ContextPack struct {
+    Module         string     `json:"module"`
+    GoVersion      string     `json:"go_version"`
+    Tokenizer      string     `json:"tokenizer"`
+    Budget         int        `json:"budget"`
+    Tokens         int        `json:"tokens"`
+    Level          string     `json:"level"`
+    OmittedSymbols int        `json:"omitted_symbols"`
+    OmittedDocs    int        `json:"omitted_docs"`
+    OmittedBodies  int        `json:"omitted_bodies"`
+    Files          []PackFile `json:"files"`
+}
+
+// packEntry - кандидат в пакет с приоритетом
+type packEntry struct {
+    file     *FileAnalysis
+    symbol   PackSymbol
+    exported bool
+    refs     int
+    endLine  int
+    doc      string
+}
+
+// packSignature - сигнатура символа в виде Go-объявления без тела
+func packSignature(kind, name string, fn *Function, st *Struct, v *Variable) string {
+    switch {
+    case fn != nil:
+        sig := strings.TrimPrefix(funcSignature(*fn), "func")
+        if fn.IsMethod {
+            return "func (" + fn.Receiver + ") " + fn.Name + sig
+        }
+        return "func " + name + sig
+    case st != nil:
+        var members []string
+        for _, f := range st.Fields {
+            members = append(members, f.String())
+        }
+        for _, m := range st.Methods {
+            members = append(members, m.Name+strings.TrimPrefix(funcSignature(m), "func"))
+        }
+        return "type " + name + " " + kind + " { " + strings.Join(members, "; ") + " }"
+    case v != nil && v.IsConstant:
+        sig := strings.TrimSpace("const " + name + " " + v.Type)
+        if v.Value != "" {
+            sig += " = " + v.Value
+        }
+        return sig
+    }
+    return strings.TrimSpace("var " + name + " " + v.Type)
+}
+
+// packEntries собирает символы файлов проекта, кроме тестов
+func packEntries(analysis *ProjectAnalysis) []*packEntry {
+    var entries []*packEntry
+    for i := range analysis.Files {
+        file := &analysis.Files[i]
+        if file.HasTests {
+            continue
+        }
+        pkgPath := packagePathOf(analysis, file.Path)
+        add := func(name, kind string, line, endLine int, exported bool, doc, signature string) {
+            id := goSymbolID(pkgPath, name)
+            e := &packEntry{
+                file:     file,
+                symbol:   PackSymbol{ID: id, Kind: kind, Line: line, Signature: signature},
+                exported: exported,
+                endLine:  endLine,
+                doc:      doc,
+            }
+            if analysis.refs != nil {
+                e.refs = len(analysis.refs.byTo[id])
+            }
+            entries = append(entries, e)
+        }
+        for j := range file.Functions {
+            fn := &file.Functions[j]
+            name, kind := fn.Name, "function"
+            if fn.IsMethod {
+                name, kind = receiverTypeName(fn.Receiver)+"."+fn.Name, "method"
+            }
+            add(name, kind, fn.Line, fn.EndLine, fn.IsExported, fn.Docstring, packSignature(kind, fn.Name, fn, nil, nil))
+            entries[len(entries)-1].symbol.Coverage = fn.Coverage
+        }
+        for j := range file.Structs {
+            st := &file.Structs[j]
+            add(st.Name, "struct", st.Line, st.EndLine, st.IsExported, st.Docstring, packSignature("struct", st.Name, nil, st, nil))
+        }
+        for j := range file.Interfaces {
+            st := &file.Interfaces[j]
+            add(st.Name, "interface", st.Line, st.EndLine, st.IsExported, st.Docstring, packSignature("interface", st.Name, nil, st, nil))
+        }
+        for _, group := range [][]Variable{file.Variables, file.Constants} {
+            for j := range group {
+                v := &group[j]
+                kind := "variable"
+                if v.IsConstant {
+                    kind = "constant"
+                }
+                add(v.Name, kind, v.Line, v.Line, v.IsExported, "", packSignature(kind, v.Name, nil, nil, v))
+            }
+        }
+    }
+    // Экспортируемый API и самые используемые символы - первыми
+    sort.SliceStable(entries, func(i, j int) bool {
+        a, b := entries[i], entries[j]
+        if a.exported != b.exported {
+            return a.exported
+        }
+        if a.refs != b.refs {
+            return a.refs > b.refs
+        }
+        return a.symbol.ID < b.symbol.ID
+    })
+    return entries
+}
+
+// jsonTokens - стоимость значения в выводе пакета
+func jsonTokens(count tokenCounter, v any) int {
+    data, _ := json.Marshal(v)
+    return count(data)
+}
+
+// packStep - шаг заполнения пакета: символ, его doc или тело
+type packStep struct {
+    entry *packEntry
+    stage string
+    text  string
+}
+
+// buildContextPack заполняет бюджет по этапам: сигнатуры всех символов в
+// порядке приоритета, затем doc-комментарии, затем исходный текст
+// объявлений. Что не помещается целиком, пропускается, а место достаётся
+// следующим по приоритету. Части оцениваются по компактному JSON, поэтому
+// затем пакет измеряется целиком в том виде, как его выводит render, и
+// последние шаги отбрасываются, пока вывод не уложится в бюджет
+func buildContextPack(projectPath string, analysis *ProjectAnalysis, budget int, tokenizer string, render func(any) ([]byte, error)) (*ContextPack, error) {
+    count, err := newTokenCounter(tokenizer)
+    if err != nil {
+        return nil, err
+    }
+    if tokenizer == "" {
+        tokenizer = "cl100k"
+    }
+    pack := &ContextPack{
+        Module:    analysis.ModuleName,
+        GoVersion: analysis.GoVersion,
+        Tokenizer: tokenizer,
+        Budget:    budget,
+        Level:     "signatures",
+        Files:     []PackFile{},
+    }
+    estimate := jsonTokens(count, pack)
+    fits := func(cost int) bool {
+        if estimate+cost > budget {
+            return false
+        }
+        estimate += cost
+        return true
+    }
+
+    entries := packEntries(analysis)
+    var steps []packStep
+    symbols := 0
+    fileIncluded := make(map[*FileAnalysis]bool)
+    for _, e := range entries {
+        cost := jsonTokens(count, e.symbol)
+        if !fileIncluded[e.file] {
+            cost += jsonTokens(count, PackFile{Path: e.file.Path, Package: e.file.Package})
+        }
+        if !fits(cost) {
+            break
+        }
+        fileIncluded[e.file] = true
+        steps = append(steps, packStep{entry: e, stage: "signatures"})
+        symbols++
+    }
+    included := entries[:symbols]
+    docs, bodies := 0, -1
+    if symbols == len(entries) {
+        omitted := 0
+        for _, e := range included {
+            if e.doc == "" {
+                continue
+            }
+            docs++
+            if fits(jsonTokens(count, e.doc) + 2) {
+                steps = append(steps, packStep{entry: e, stage: "docstrings", text: e.doc})
+            } else {
+                omitted++
+            }
+        }
+        if omitted == 0 {
+            bodies = 0
+            src := &sourceLines{root: projectPath, files: make(map[string][]string)}
+            for _, e := range included {
+                if e.symbol.Kind != "function" && e.symbol.Kind != "method" {
+                    continue
+                }
+                body := src.span(e.file.Path, e.symbol.Line, e.endLine)
+                if body == "" {
+                    continue
+                }
+                bodies++
+                if fits(jsonTokens(count, body) + 2) {
+                    steps = append(steps, packStep{entry: e, stage: "bodies", text: body})
+                }
+            }
+        }
+    }
+
+    // apply собирает пакет из первых n шагов и измеряет вывод; Tokens сам
+    // входит в вывод, поэтому пересчитывается, пока число не перестанет расти
+    apply := func(n int) (int, error) {
+        for _, e := range included {
+            e.symbol.Doc, e.symbol.Body = "", ""
+        }
+        var symbolSteps []*packEntry
+        applied := map[string]int{}
+        for _, step := range steps[:n] {
+            applied[step.stage]++
+            switch step.stage {
+            case "signatures":
+                symbolSteps = append(symbolSteps, step.entry)
+            case "docstrings":
+                step.entry.symbol.Doc = step.text
+            case "bodies":
+                step.entry.symbol.Body = step.text
+            }
+        }
+        pack.Level, pack.OmittedSymbols, pack.OmittedDocs, pack.OmittedBodies = "signatures", len(entries)-len(symbolSteps), 0, 0
+        if pack.OmittedSymbols == 0 {
+            pack.Level, pack.OmittedDocs = "docstrings", docs-applied["docstrings"]
+            if pack.OmittedDocs == 0 && bodies >= 0 {
+                pack.Level, pack.OmittedBodies = "bodies", bodies-applied["bodies"]
+            }
+        }
+
+        // В выводе - порядок файлов и строк, а не приоритета
+        byFile := make(map[string]*PackFile)
+        for _, e := range symbolSteps {
+            f := byFile[e.file.Path]
+            if f == nil {
+                f = &PackFile{Path: e.file.Path, Package: e.file.Package}
+                byFile[e.file.Path] = f
+            }
+            f.Symbols = append(f.Symbols, e.symbol)
+        }
+        pack.Files = []PackFile{}
+        for _, f := range byFile {
+            sort.SliceStable(f.Symbols, func(i, j int) bool { return f.Symbols[i].Line < f.Symbols[j].Line })
+            pack.Files = append(pack.Files, *f)
+        }
+        sort.Slice(pack.Files, func(i, j int) bool { return pack.Files[i].Path < pack.Files[j].Path })
+
+        pack.Tokens = 0
+        for {
+            data, err := render(pack)
+            if err != nil {
+                return 0, err
+            }
+            n := count(data)
+            if n <= pack.Tokens {
+                return pack.Tokens, nil
+            }
+            pack.Tokens = n
+        }
+    }
+
+    tokens, err := apply(len(steps))
+    if err != nil || tokens <= budget {
+        return pack, err
+    }
+    // Размер вывода растёт с числом шагов: ищется наибольшее число шагов,
+    // которое укладывается в бюджет
+    lo, hi := 0, len(steps)
+    for lo < hi {
+        mid := (lo + hi + 1) / 2
+        if tokens, err = apply(mid); err != nil {
+            return nil, err
+        }
+        if tokens <= budget {
+            lo = mid
+        } else {
+            hi = mid - 1
+        }
+    }
+    if tokens, err = apply(lo); err != nil {
+        return nil, err
+    }
+    if tokens > budget {
+        return nil, fmt.Errorf("budget of %d tokens is below the %d tokens of an empty pack", budget, tokens)
+    }
+    return pack, nil
+}
+
+// runPack - analyzer pack: структура проекта для промпта LLM в пределах
+// бюджета токенов
+func runPack(args []string) {
+    fs := newCommandFlags("pack", "-budget <tokens> [flags] [project_path]")
+    budget := fs.Int("budget", 0, "token budget of the pack")
+    tokenizer := fs.String("tokenizer", "cl100k", "token estimate: cl100k (BPE approximation) or chars (4 bytes per token)")
+    scope := fs.String("scope", "", "pack only packages owned by team:<name> or owner:<owner> in CODEOWNERS plus the project packages they import")
+    coverProfile := fs.String("coverprofile", "", "mark each function with its statement coverage from a go test -coverprofile `file`")
+    projectPath := fs.parse(args)
+    if *budget <= 0 {
+        fs.Usage()
+        os.Exit(2)
+    }
+    opts := defaultOptions()
+    opts.Tokenizer, opts.Scope, opts.CoverProfile = *tokenizer, *scope, *coverProfile
+    if err := opts.validate(); err != nil {
+        fatal("invalid options", "error", err)
+    }
+
+    analysis := analyzeProject(context.Background(), projectPath, opts)
+    render := func(v any) ([]byte, error) { return renderResult(v, *fs.format, *fs.compact) }
+    pack, err := buildContextPack(projectPath, analysis, *budget, *tokenizer, render)
+    if err != nil {
+        fatal("failed to build context pack", "error", err)
+    }
+    fs.write(pack)
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "testing"
+)
+
+// packFixture - анализ небольшого проекта и рендер пакета в компактный JSON
+func packFixture(t *testing.T) (string, *ProjectAnalysis, func(any) ([]byte, error)) {
+    t.Helper()
+    project := writeTestProject(t, map[string]string{
+        "go.mod": "module example.com/packed\n\ngo 1.21\n",
+        "calc/calc.go": `package calc
+
+// Limit caps every result.
+const Limit = 100
+
+// Add returns the sum of a and b capped at Limit.
+func Add(a, b int) int {
+    if a+b > Limit {
+        return Limit
+    }
+    return a + b
+}
+
+// Scale multiplies v by k. The documentation is long on purpose so that a
+// budget can hold every signature but not every doc comment of the package.
+func Scale(v, k int) int { return v * k }
+
+func helper() int { return Add(1, 2) }
+`,
+    })
+    // Фиктивный токенизатор: один байт - один токен
+    tokenizers["bytes"] = func(text []byte) int { return len(text) }
+    t.Cleanup(func() { delete(tokenizers, "bytes") })
+    analysis := analyzeProject(context.Background(), project, testOptions(t))
+    render := func(v any) ([]byte, error) { return renderResult(v, "json", true) }
+    return project, analysis, render
+}
+
+func TestContextPackBudget(t *testing.T) {
+    project, analysis, render := packFixture(t)
+    full, err := buildContextPack(project, analysis, 1<<20, "bytes", render)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if full.Level != "bodies" || full.OmittedSymbols+full.OmittedDocs+full.OmittedBodies != 0 {
+        t.Fatalf("unlimited pack: level %s, omitted %d/%d/%d", full.Level, full.OmittedSymbols, full.OmittedDocs, full.OmittedBodies)
+    }
+    symbols := 0
+    for _, f := range full.Files {
+        symbols += len(f.Symbols)
+    }
+
+    type result struct {
+        pack *ContextPack
+        err  error
+    }
+    results := make(map[int]result)
+    for budget := 0; budget <= full.Tokens; budget++ {
+        pack, err := buildContextPack(project, analysis, budget, "bytes", render)
+        results[budget] = result{pack, err}
+        if err != nil {
+            continue
+        }
+        // Tokens - точный размер вывода, включая само число Tokens
+        data, _ := render(pack)
+        if pack.Tokens != len(data) || pack.Tokens > budget {
+            t.Fatalf("budget %d: tokens %d, output %d bytes", budget, pack.Tokens, len(data))
+        }
+    }
+
+    included := func(p *ContextPack) int {
+        n := 0
+        for _, f := range p.Files {
+            n += len(f.Symbols)
+        }
+        return n
+    }
+    tests := []struct {
+        name  string
+        match func(budget int, r result) bool
+    }{
+        {"exact fit", func(budget int, r result) bool {
+            // Budget входит в вывод, поэтому полный пакет ровно в бюджет -
+            // не обязательно full.Tokens
+            return r.err == nil && r.pack.Tokens == budget && r.pack.Level == "bodies" && r.pack.OmittedBodies == 0
+        }},
+        {"partial signatures", func(budget int, r result) bool {
+            return r.err == nil && r.pack.Level == "signatures" && r.pack.OmittedSymbols > 0 &&
+                included(r.pack) > 0 && included(r.pack)+r.pack.OmittedSymbols == symbols
+        }},
+        {"docs skipped", func(budget int, r result) bool {
+            return r.err == nil && r.pack.Level == "docstrings" && r.pack.OmittedSymbols == 0 && r.pack.OmittedDocs > 0
+        }},
+        {"bodies skipped", func(budget int, r result) bool {
+            return r.err == nil && r.pack.Level == "bodies" && r.pack.OmittedDocs == 0 && r.pack.OmittedBodies > 0
+        }},
+        {"below empty pack", func(budget int, r result) bool {
+            return r.err != nil && strings.Contains(r.err.Error(), fmt.Sprintf("budget of %d tokens is below", budget))
+        }},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            for budget := 0; budget <= full.Tokens; budget++ {
+                if tt.match(budget, results[budget]) {
+                    return
+                }
+            }
+            t.Errorf("no budget up to %d produces this pack", full.Tokens)
+        })
+    }
+
+    // Ошибка - только у бюджетов меньше пустого пакета
+    empty := -1
+    for budget := 0; budget <= full.Tokens; budget++ {
+        if results[budget].err == nil {
+            if empty < 0 {
+                empty = budget
+            }
+        } else if empty >= 0 {
+            t.Errorf("budget %d fails after budget %d succeeded: %v", budget, empty, results[budget].err)
+        }
+    }
+    if r := results[empty]; r.pack.Level != "signatures" || included(r.pack) != 0 || r.pack.OmittedSymbols != symbols {
+        t.Errorf("smallest budget %d: level %s, %d symbols, %d omitted", empty, r.pack.Level, included(r.pack), r.pack.OmittedSymbols)
+    }
+}
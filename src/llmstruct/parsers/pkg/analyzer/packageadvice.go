@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+    "math"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// PackageSplit - пакет, символы которого распадаются на несвязанные
+// группы; каждую группу можно вынести в отдельный пакет
+type PackageSplit struct {
+    Package      string     `json:"package"`
+    Symbols      int        `json:"symbols"`
+    Cohesion     float64    `json:"cohesion"`
+    Groups       [][]string `json:"groups"`
+    Loose        []string   `json:"loose,omitempty"`
+}
+
+// PackageMerge - маленький пакет, который использует только один пакет
+// проекта, причём большую часть его символов
+type PackageMerge struct {
+    Package      string   `json:"package"`
+    Into         string   `json:"into"`
+    Symbols      int      `json:"symbols"`
+    Used         int      `json:"used"`
+    Reason       string   `json:"reason"`
+}
+
+type PackageAdvice struct {
+    Splits       []PackageSplit `json:"splits"`
+    Merges       []PackageMerge `json:"merges"`
+}
+
+const (
+    // Группа меньше этого размера не считается самостоятельной частью
+    minSplitGroup = 3
+    // Пакеты не больше этого числа символов - кандидаты на слияние
+    maxMergeSymbols = 5
+    // Доля символов пакета, которую должен использовать его пользователь
+    minMergeCoupling = 0.5
+)
+
+var identPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// unionFind - объединение символов в связные группы
+type unionFind map[string]string
+
+func (u unionFind) find(x string) string {
+    for u[x] != x {
+        u[x] = u[u[x]]
+        x = u[x]
+    }
+    return x
+}
+
+func (u unionFind) union(a, b string) {
+    u[u.find(a)] = u.find(b)
+}
+
+// cohesionNode сводит метод к его типу: метод связан с типом по
+// определению и отдельно от него не переносится
+func cohesionNode(sym *goSymbol) string {
+    if sym.kind == "method" {
+        recv, _, _ := strings.Cut(sym.name, ".")
+        return recv
+    }
+    return sym.name
+}
+
+// buildPackageAdvice оценивает связность символов внутри пакетов по
+// ссылкам между ними (вызовы, использование типов, поля структур) и
+// предлагает разделение пакетов с несвязанными группами символов и
+// слияние крошечных пакетов с их единственным пользователем
+func buildPackageAdvice(analysis *ProjectAnalysis) *PackageAdvice {
+    advice := &PackageAdvice{Splits: []PackageSplit{}, Merges: []PackageMerge{}}
+    if analysis.refs == nil {
+        return advice
+    }
+    symbols := goSymbols(analysis, false)
+    pkgOf := make(map[string]string)
+    nodes := make(map[string]unionFind)
+    for id, sym := range symbols {
+        pkg := strings.TrimSuffix(strings.TrimPrefix(id, "go:"), "."+sym.name)
+        pkgOf[id] = pkg
+        if nodes[pkg] == nil {
+            nodes[pkg] = make(unionFind)
+        }
+        node := cohesionNode(sym)
+        nodes[pkg][node] = node
+    }
+
+    // users[пакет][пользователь] - какие символы пакета он использует
+    users := make(map[string]map[string]map[string]bool)
+    // Связи внутри пакета: ссылки из функций и типы полей структур
+    for _, ref := range analysis.refs.refs {
+        from, to := symbols[ref.from], symbols[ref.to]
+        if from == nil || to == nil {
+            continue
+        }
+        fromPkg, toPkg := pkgOf[ref.from], pkgOf[ref.to]
+        if fromPkg == toPkg {
+            nodes[fromPkg].union(cohesionNode(from), cohesionNode(to))
+            continue
+        }
+        if users[toPkg] == nil {
+            users[toPkg] = make(map[string]map[string]bool)
+        }
+        if users[toPkg][fromPkg] == nil {
+            users[toPkg][fromPkg] = make(map[string]bool)
+        }
+        users[toPkg][fromPkg][cohesionNode(to)] = true
+    }
+    for id, sym := range symbols {
+        pkg := pkgOf[id]
+        for _, typ := range sym.members {
+            for _, name := range identPattern.FindAllString(typ, -1) {
+                if _, ok := nodes[pkg][name]; ok {
+                    nodes[pkg].union(sym.name, name)
+                }
+            }
+        }
+    }
+
+    for pkg, uf := range nodes {
+        groups := make(map[string][]string)
+        for node := range uf {
+            root := uf.find(node)
+            groups[root] = append(groups[root], node)
+        }
+        var parts [][]string
+        var loose []string
+        largest := 0
+        for _, group := range groups {
+            sort.Strings(group)
+            largest = max(largest, len(group))
+            if len(group) >= minSplitGroup {
+                parts = append(parts, group)
+            } else {
+                loose = append(loose, group...)
+            }
+        }
+        if len(parts) >= 2 {
+            sort.Slice(parts, func(i, j int) bool {
+                if len(parts[i]) != len(parts[j]) {
+                    return len(parts[i]) > len(parts[j])
+                }
+                return parts[i][0] < parts[j][0]
+            })
+            sort.Strings(loose)
+            advice.Splits = append(advice.Splits, PackageSplit{
+                Package:  pkg,
+                Symbols:  len(uf),
+                Cohesion: math.Round(float64(largest)/float64(len(uf))*100) / 100,
+                Groups:   parts,
+                Loose:    loose,
+            })
+        }
+
+        if len(uf) > maxMergeSymbols || len(users[pkg]) != 1 {
+            continue
+        }
+        for into, used := range users[pkg] {
+            if float64(len(used)) < minMergeCoupling*float64(len(uf)) {
+                continue
+            }
+            advice.Merges = append(advice.Merges, PackageMerge{
+                Package: pkg,
+                Into:    into,
+                Symbols: len(uf),
+                Used:    len(used),
+                Reason:  "small package used only by " + into + " within the project",
+            })
+        }
+    }
+    sort.Slice(advice.Splits, func(i, j int) bool { return advice.Splits[i].Package < advice.Splits[j].Package })
+    sort.Slice(advice.Merges, func(i, j int) bool { return advice.Merges[i].Package < advice.Merges[j].Package })
+    return advice
+}
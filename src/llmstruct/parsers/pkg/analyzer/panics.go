@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+    "strings"
+    "unicode"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// PanicSite - место в библиотечном коде, где ошибка превращается в panic
+// или завершение процесса вместо возврата вызывающему. Kind:
+// must_wrapper (функция Must*), panic_on_error (if err != nil { panic }),
+// exit_on_error (log.Fatal/os.Exit в той же ветке)
+type PanicSite struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+    Kind         string   `json:"kind"`
+    Detail       string   `json:"detail,omitempty"`
+}
+
+// isMustName: Must, MustParse, но не Mustache
+func isMustName(name string) bool {
+    rest, ok := strings.CutPrefix(name, "Must")
+    return ok && (rest == "" || unicode.IsUpper(rune(rest[0])))
+}
+
+// panicCall возвращает "panic" или "exit" для оператора, который
+// прерывает выполнение
+func panicCall(info *types.Info, stmt ast.Stmt) string {
+    expr, ok := stmt.(*ast.ExprStmt)
+    if !ok || !isTerminating(info, stmt) {
+        return ""
+    }
+    if id, ok := ast.Unparen(expr.X.(*ast.CallExpr).Fun).(*ast.Ident); ok && id.Name == "panic" {
+        return "panic"
+    }
+    return "exit"
+}
+
+// errNotNil распознаёт условие err != nil (или nil != err) для значения
+// типа error
+func errNotNil(info *types.Info, cond ast.Expr) (ast.Expr, bool) {
+    bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+    if !ok || bin.Op != token.NEQ {
+        return nil, false
+    }
+    for _, pair := range [][2]ast.Expr{{bin.X, bin.Y}, {bin.Y, bin.X}} {
+        if id, ok := pair[1].(*ast.Ident); ok && id.Name == "nil" {
+            if t := info.TypeOf(pair[0]); t != nil && isErrorType(t) {
+                return pair[0], true
+            }
+        }
+    }
+    return nil, false
+}
+
+// extractPanics составляет перечень мест, где библиотечные пакеты (не
+// main) паникуют или завершают процесс при ошибке
+func extractPanics(pkgs []*packages.Package, projectPath string) []PanicSite {
+    sites := []PanicSite{}
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil || pkg.Name == "main" {
+            continue
+        }
+        info := pkg.TypesInfo
+        for _, file := range pkg.Syntax {
+            for _, decl := range file.Decls {
+                fd, ok := decl.(*ast.FuncDecl)
+                if !ok || fd.Body == nil {
+                    continue
+                }
+                site := func(node ast.Node, kind, detail string) {
+                    pos := pkg.Fset.Position(node.Pos())
+                    sites = append(sites, PanicSite{
+                        Package:  pkg.PkgPath,
+                        File:     relativePath(projectPath, pos.Filename),
+                        Line:     pos.Line,
+                        Function: funcID(pkg, fd),
+                        Kind:     kind,
+                        Detail:   detail,
+                    })
+                }
+
+                if isMustName(fd.Name.Name) {
+                    panics, wrapped := false, ""
+                    ast.Inspect(fd.Body, func(n ast.Node) bool {
+                        switch x := n.(type) {
+                        case *ast.ExprStmt:
+                            if panicCall(info, x) != "" {
+                                panics = true
+                            }
+                        case *ast.CallExpr:
+                            if wrapped == "" && returnsError(info, x) {
+                                wrapped = types.ExprString(x.Fun)
+                            }
+                        }
+                        return true
+                    })
+                    if panics {
+                        detail := "panics instead of returning the error"
+                        if wrapped != "" {
+                            detail = "panics on the error from " + wrapped
+                        }
+                        site(fd, "must_wrapper", detail)
+                        continue
+                    }
+                }
+
+                ast.Inspect(fd.Body, func(n ast.Node) bool {
+                    ifStmt, ok := n.(*ast.IfStmt)
+                    if !ok {
+                        return true
+                    }
+                    errExpr, ok := errNotNil(info, ifStmt.Cond)
+                    if !ok {
+                        return true
+                    }
+                    for _, stmt := range ifStmt.Body.List {
+                        switch panicCall(info, stmt) {
+                        case "panic":
+                            site(stmt, "panic_on_error", "panics when "+types.ExprString(errExpr)+" != nil")
+                        case "exit":
+                            site(stmt, "exit_on_error", types.ExprString(stmt.(*ast.ExprStmt).X.(*ast.CallExpr).Fun)+" when "+types.ExprString(errExpr)+" != nil")
+                        }
+                    }
+                    return true
+                })
+            }
+        }
+    }
+    sort.Slice(sites, func(i, j int) bool {
+        if sites[i].File != sites[j].File {
+            return sites[i].File < sites[j].File
+        }
+        return sites[i].Line < sites[j].Line
+    })
+    return sites
+}
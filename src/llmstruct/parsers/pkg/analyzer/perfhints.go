@@ -0,0 +1,297 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// PerfHint - место в функции, где на каждой итерации цикла выделяется
+// память
+type PerfHint struct {
+    Line         int      `json:"line"`
+    Kind         string   `json:"kind"`
+    Detail       string   `json:"detail"`
+}
+
+// FunctionPerfHints - подсказки для ревью производительности по функции
+type FunctionPerfHints struct {
+    Function     string     `json:"function"`
+    File         string     `json:"file"`
+    Line         int        `json:"line"`
+    Hints        []PerfHint `json:"hints"`
+}
+
+// perfHintFinder эвристически ищет выделения памяти в циклах одной функции
+type perfHintFinder struct {
+    fset      *token.FileSet
+    info      *types.Info
+    // Локальные слайсы и мапы, созданные без ёмкости
+    unsized   map[types.Object]bool
+    reported  map[[2]any]bool
+    hints     []PerfHint
+}
+
+// loopOf возвращает ближайший цикл, тело (или условие) которого содержит
+// последний узел стека; функция-литерал прерывает поиск
+func loopOf(stack []ast.Node) ast.Node {
+    for i := len(stack) - 2; i >= 0; i-- {
+        child := stack[i+1]
+        switch s := stack[i].(type) {
+        case *ast.FuncLit:
+            return nil
+        case *ast.ForStmt:
+            // for {} и for cond {} без счётчика - обычно циклы обработки
+            // событий, а не горячие циклы по данным
+            if s.Post == nil {
+                continue
+            }
+            if child == s.Body || child == s.Cond || child == s.Post {
+                return s
+            }
+        case *ast.RangeStmt:
+            if child == s.Body {
+                return s
+            }
+        }
+    }
+    return nil
+}
+
+// collectUnsized отмечает локальные слайсы и мапы без заданной ёмкости:
+// var x []T, x := []T{}, x := make([]T, 0), x := make(map[K]V), x := map[K]V{}
+func (f *perfHintFinder) collectUnsized(body *ast.BlockStmt) {
+    unsized := func(expr ast.Expr) bool {
+        switch e := expr.(type) {
+        case *ast.CompositeLit:
+            return len(e.Elts) == 0
+        case *ast.CallExpr:
+            if id, ok := e.Fun.(*ast.Ident); !ok || id.Name != "make" {
+                return false
+            }
+            t := f.info.TypeOf(e)
+            if t == nil {
+                return false
+            }
+            if isMap(t) {
+                return len(e.Args) == 1
+            }
+            if len(e.Args) != 2 {
+                return false
+            }
+            tv := f.info.Types[e.Args[1]]
+            return tv.Value != nil && tv.Value.String() == "0"
+        }
+        return false
+    }
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch s := n.(type) {
+        case *ast.ValueSpec:
+            for i, name := range s.Names {
+                obj := f.info.Defs[name]
+                if obj == nil {
+                    continue
+                }
+                if len(s.Values) == 0 {
+                    if _, ok := obj.Type().Underlying().(*types.Slice); ok {
+                        f.unsized[obj] = true
+                    }
+                } else if i < len(s.Values) && unsized(s.Values[i]) {
+                    f.unsized[obj] = true
+                }
+            }
+        case *ast.AssignStmt:
+            if s.Tok != token.DEFINE || len(s.Lhs) != len(s.Rhs) {
+                return true
+            }
+            for i, lhs := range s.Lhs {
+                if id, ok := lhs.(*ast.Ident); ok && f.info.Defs[id] != nil && unsized(s.Rhs[i]) {
+                    f.unsized[f.info.Defs[id]] = true
+                }
+            }
+        }
+        return true
+    })
+}
+
+// outside сообщает, объявлен ли объект вне цикла
+func outside(obj types.Object, loop ast.Node) bool {
+    return obj.Pos() < loop.Pos() || obj.Pos() > loop.End()
+}
+
+// report добавляет подсказку один раз на пару (цикл, узел или переменная)
+func (f *perfHintFinder) report(loop ast.Node, what any, pos token.Pos, kind, detail string) {
+    key := [2]any{loop, what}
+    if f.reported[key] {
+        return
+    }
+    f.reported[key] = true
+    f.hints = append(f.hints, PerfHint{Line: f.fset.Position(pos).Line, Kind: kind, Detail: detail})
+}
+
+func (f *perfHintFinder) objectOf(expr ast.Expr) types.Object {
+    if id, ok := ast.Unparen(expr).(*ast.Ident); ok {
+        return f.info.Uses[id]
+    }
+    return nil
+}
+
+func isByteSlice(t types.Type) bool {
+    s, ok := t.Underlying().(*types.Slice)
+    if !ok {
+        return false
+    }
+    b, ok := s.Elem().Underlying().(*types.Basic)
+    return ok && b.Kind() == types.Byte
+}
+
+func isMap(t types.Type) bool {
+    _, ok := t.Underlying().(*types.Map)
+    return ok
+}
+
+func isString(t types.Type) bool {
+    if t == nil {
+        return false
+    }
+    b, ok := t.Underlying().(*types.Basic)
+    return ok && b.Info()&types.IsString != 0
+}
+
+// conversionElided - преобразования []byte <-> string, которые компилятор
+// выполняет без копирования: ключ мапы, сравнение, range по []byte(s)
+func conversionElided(stack []ast.Node) bool {
+    conv := stack[len(stack)-1]
+    switch parent := stack[len(stack)-2].(type) {
+    case *ast.IndexExpr:
+        return parent.Index == conv
+    case *ast.BinaryExpr:
+        return parent.Op == token.EQL || parent.Op == token.NEQ || parent.Op == token.LSS || parent.Op == token.GTR
+    case *ast.RangeStmt:
+        return parent.X == conv
+    }
+    return false
+}
+
+// mapGrowth отмечает запись в мапу без подсказки размера внутри цикла
+func (f *perfHintFinder) mapGrowth(loop ast.Node, lhs ast.Expr) {
+    index, ok := lhs.(*ast.IndexExpr)
+    if !ok {
+        return
+    }
+    if obj := f.objectOf(index.X); obj != nil && f.unsized[obj] && outside(obj, loop) && isMap(obj.Type()) {
+        f.report(loop, obj, lhs.Pos(), "map_no_size_hint", "map "+obj.Name()+" is filled in a loop without a size hint; rehashing allocates as it grows")
+    }
+}
+
+func (f *perfHintFinder) inspect(body *ast.BlockStmt) {
+    var stack []ast.Node
+    ast.Inspect(body, func(n ast.Node) bool {
+        if n == nil {
+            stack = stack[:len(stack)-1]
+            return false
+        }
+        stack = append(stack, n)
+        loop := loopOf(stack)
+        if loop == nil {
+            return true
+        }
+        switch x := n.(type) {
+        case *ast.AssignStmt:
+            if x.Tok == token.ADD_ASSIGN && len(x.Lhs) == 1 && isString(f.info.TypeOf(x.Lhs[0])) {
+                f.report(loop, x, x.Pos(), "string_concat", "string += in a loop copies the string every iteration; use strings.Builder")
+            }
+            for i, rhs := range x.Rhs {
+                call, ok := rhs.(*ast.CallExpr)
+                if !ok || i >= len(x.Lhs) || len(call.Args) == 0 {
+                    continue
+                }
+                if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "append" || f.info.Uses[id] != types.Universe.Lookup("append") {
+                    continue
+                }
+                obj := f.objectOf(call.Args[0])
+                if obj != nil && f.unsized[obj] && outside(obj, loop) && obj == f.objectOf(x.Lhs[i]) {
+                    f.report(loop, obj, x.Pos(), "append_no_prealloc", "append to "+obj.Name()+" grows the slice in a loop; preallocate with make(..., 0, n)")
+                }
+            }
+            for _, lhs := range x.Lhs {
+                f.mapGrowth(loop, lhs)
+            }
+        case *ast.IncDecStmt:
+            f.mapGrowth(loop, x.X)
+        case *ast.CompositeLit:
+            if t := f.info.TypeOf(x); t != nil && isMap(t) {
+                f.report(loop, x, x.Pos(), "map_in_loop", "a new map is allocated every iteration; reuse one and clear() it")
+            }
+        case *ast.CallExpr:
+            if id, ok := x.Fun.(*ast.Ident); ok && id.Name == "make" && f.info.Uses[id] == types.Universe.Lookup("make") {
+                if t := f.info.TypeOf(x); t != nil && isMap(t) {
+                    f.report(loop, x, x.Pos(), "map_in_loop", "a new map is allocated every iteration; reuse one and clear() it")
+                }
+                return true
+            }
+            if tv, ok := f.info.Types[x.Fun]; ok && tv.IsType() && len(x.Args) == 1 {
+                arg := f.info.Types[x.Args[0]]
+                if arg.Value != nil || conversionElided(stack) {
+                    return true
+                }
+                to, from := tv.Type, arg.Type
+                if isByteSlice(to) && isString(from) || isString(to) && isByteSlice(from) {
+                    f.report(loop, x, x.Pos(), "conversion", types.ExprString(x)+" copies its operand every iteration")
+                }
+                return true
+            }
+            if fn := calleeFunc(f.info, x); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" {
+                switch fn.Name() {
+                case "Sprintf", "Sprint", "Sprintln":
+                    f.report(loop, x, x.Pos(), "sprintf_in_loop", "fmt."+fn.Name()+" allocates every iteration; consider strconv or strings.Builder")
+                }
+            }
+        }
+        return true
+    })
+}
+
+// extractPerfHints статически ищет шаблоны с лишними выделениями памяти в
+// циклах: append без предвыделения, fmt.Sprintf, преобразования
+// []byte/string, создание и рост мап, конкатенацию строк
+func extractPerfHints(pkgs []*packages.Package, projectPath string) []FunctionPerfHints {
+    result := []FunctionPerfHints{}
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        for _, file := range pkg.Syntax {
+            for _, decl := range file.Decls {
+                fd, ok := decl.(*ast.FuncDecl)
+                if !ok || fd.Body == nil {
+                    continue
+                }
+                f := &perfHintFinder{fset: pkg.Fset, info: pkg.TypesInfo, unsized: make(map[types.Object]bool), reported: make(map[[2]any]bool)}
+                f.collectUnsized(fd.Body)
+                f.inspect(fd.Body)
+                if len(f.hints) == 0 {
+                    continue
+                }
+                sort.SliceStable(f.hints, func(i, j int) bool { return f.hints[i].Line < f.hints[j].Line })
+                pos := pkg.Fset.Position(fd.Pos())
+                result = append(result, FunctionPerfHints{
+                    Function: funcID(pkg, fd),
+                    File:     relativePath(projectPath, pos.Filename),
+                    Line:     pos.Line,
+                    Hints:    f.hints,
+                })
+            }
+        }
+    }
+    sort.Slice(result, func(i, j int) bool {
+        if result[i].File != result[j].File {
+            return result[i].File < result[j].File
+        }
+        return result[i].Line < result[j].Line
+    })
+    return result
+}
@@ -0,0 +1,287 @@
+package analyzer
+
+import (
+    "bufio"
+    "go/ast"
+    "go/types"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// BuildModeUse - сборка с -buildmode (plugin, c-shared, ...) в скриптах,
+// Makefile, Dockerfile, CI или go:generate; Target - собираемый пакет
+type BuildModeUse struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Mode         string   `json:"mode"`
+    Target       string   `json:"target,omitempty"`
+    Command      string   `json:"command"`
+}
+
+// PluginLoad - вызов plugin.Open; Path - путь, если он известен статически
+type PluginLoad struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    Path         string   `json:"path,omitempty"`
+}
+
+// PluginLookup - поиск символа в загруженном плагине. Type - тип, к
+// которому приводится найденный символ; Providers - пакеты плагинов
+// проекта, экспортирующие символ с этим именем
+type PluginLookup struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    Symbol       string   `json:"symbol,omitempty"`
+    Type         string   `json:"type,omitempty"`
+    Providers    []string `json:"providers,omitempty"`
+}
+
+// PluginPackage - пакет проекта, который собирается как плагин: указан в
+// -buildmode=plugin или является package main без func main
+type PluginPackage struct {
+    Package      string   `json:"package"`
+    Dir          string   `json:"dir"`
+    Reason       string   `json:"reason"`
+    Exports      []string `json:"exports"`
+}
+
+// PluginReport - динамически загружаемый код, который иначе не виден в
+// статической модели
+type PluginReport struct {
+    BuildModes   []BuildModeUse  `json:"build_modes,omitempty"`
+    Packages     []PluginPackage `json:"packages,omitempty"`
+    Loads        []PluginLoad    `json:"loads,omitempty"`
+    Lookups      []PluginLookup  `json:"lookups,omitempty"`
+}
+
+var (
+    buildModeFlagRe = regexp.MustCompile(`-buildmode[= ]["']?([a-z-]+)`)
+    // Ключ конфигураций вроде .goreleaser.yml: buildmode: c-shared
+    buildModeKeyRe  = regexp.MustCompile(`^\s*-?\s*buildmode:\s*["']?([a-z-]+)`)
+    buildTargetRe   = regexp.MustCompile(`^\.\.?(/[\w./-]*)?$`)
+)
+
+// Каталоги CI, которые обходятся несмотря на точку в имени
+var ciDirs = map[string]bool{".github": true, ".gitlab": true, ".circleci": true}
+
+// buildFile - файл, где может встретиться команда сборки
+func buildFile(name string) bool {
+    lower := strings.ToLower(name)
+    switch {
+    case lower == "makefile" || lower == "gnumakefile" || lower == "justfile" || strings.HasPrefix(lower, "dockerfile") || lower == ".gitlab-ci.yml":
+        return true
+    }
+    switch filepath.Ext(lower) {
+    case ".mk", ".sh", ".bash", ".yml", ".yaml", ".go", ".dockerfile":
+        return true
+    }
+    return false
+}
+
+// extractBuildModes ищет -buildmode в файлах сборки проекта
+func extractBuildModes(projectPath string) ([]BuildModeUse, error) {
+    var uses []BuildModeUse
+    err := filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if d.IsDir() {
+            name := d.Name()
+            if path != projectPath && (skippedDirs[name] || strings.HasPrefix(name, ".") && !ciDirs[name]) {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if !d.Type().IsRegular() || !buildFile(d.Name()) {
+            return nil
+        }
+        f, err := os.Open(path)
+        if err != nil {
+            return nil
+        }
+        defer f.Close()
+        scanner := bufio.NewScanner(f)
+        scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+        isGo := strings.HasSuffix(path, ".go")
+        for line := 1; scanner.Scan(); line++ {
+            text := scanner.Text()
+            m := buildModeFlagRe.FindStringSubmatchIndex(text)
+            if m == nil && !isGo {
+                m = buildModeKeyRe.FindStringSubmatchIndex(text)
+            }
+            if m == nil {
+                continue
+            }
+            use := BuildModeUse{
+                File:    relativePath(projectPath, path),
+                Line:    line,
+                Mode:    text[m[2]:m[3]],
+                Command: strings.TrimSpace(text),
+            }
+            // Пакет - первый относительный путь после флага
+            for _, word := range strings.Fields(text[m[1]:]) {
+                word = strings.Trim(word, `"',`)
+                if buildTargetRe.MatchString(word) {
+                    use.Target = word
+                    break
+                }
+            }
+            uses = append(uses, use)
+        }
+        return nil
+    })
+    return uses, err
+}
+
+// pluginExports - экспортируемые функции и переменные пакета: Lookup
+// находит только их
+func pluginExports(pkg *packages.Package) []string {
+    exports := []string{}
+    scope := pkg.Types.Scope()
+    for _, name := range scope.Names() {
+        switch obj := scope.Lookup(name).(type) {
+        case *types.Func, *types.Var:
+            if obj.Exported() {
+                exports = append(exports, name)
+            }
+        }
+    }
+    return exports
+}
+
+// pluginPackages находит пакеты проекта, собираемые как плагины
+func pluginPackages(projectPath string, pkgs []*packages.Package, modes []BuildModeUse) []PluginPackage {
+    targets := make(map[string]bool)
+    for _, use := range modes {
+        if use.Mode == "plugin" && use.Target != "" {
+            // Путь относителен каталогу файла со сборкой, обычно корня
+            dir := filepath.Join(projectPath, filepath.Dir(use.File), use.Target)
+            targets[filepath.Clean(dir)] = true
+        }
+    }
+    var result []PluginPackage
+    for _, pkg := range pkgs {
+        if pkg.Types == nil || pkg.Name != "main" || len(pkg.GoFiles) == 0 {
+            continue
+        }
+        dir := filepath.Dir(pkg.GoFiles[0])
+        reason := ""
+        switch {
+        case targets[dir]:
+            reason = "build_mode"
+        case pkg.Types.Scope().Lookup("main") == nil && !strings.HasSuffix(pkg.PkgPath, ".test"):
+            reason = "main_without_func_main"
+        default:
+            continue
+        }
+        result = append(result, PluginPackage{
+            Package: pkg.PkgPath,
+            Dir:     relativePath(projectPath, dir),
+            Reason:  reason,
+            Exports: pluginExports(pkg),
+        })
+    }
+    sort.Slice(result, func(i, j int) bool { return result[i].Package < result[j].Package })
+    return result
+}
+
+// pluginCall определяет вызов plugin.Open или (*plugin.Plugin).Lookup
+func pluginCall(info *types.Info, call *ast.CallExpr) string {
+    fn := calleeFunc(info, call)
+    if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "plugin" {
+        return ""
+    }
+    if fn.Name() == "Open" || fn.Name() == "Lookup" {
+        return fn.Name()
+    }
+    return ""
+}
+
+// extractPlugins собирает сборки с -buildmode, пакеты-плагины, загрузки
+// плагинов и имена символов, которые в них ищутся
+func extractPlugins(projectPath string, pkgs []*packages.Package) (*PluginReport, error) {
+    report := &PluginReport{}
+    modes, err := extractBuildModes(projectPath)
+    report.BuildModes = modes
+    report.Packages = pluginPackages(projectPath, pkgs, modes)
+    providers := make(map[string][]string)
+    for _, p := range report.Packages {
+        for _, name := range p.Exports {
+            providers[name] = append(providers[name], p.Package)
+        }
+    }
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        info := pkg.TypesInfo
+        for _, file := range pkg.Syntax {
+            // Переменные с результатом Lookup и их поиски, для приведения
+            // типа sym.(func() Handler)
+            lookups := make(map[types.Object]int)
+            var found []PluginLookup
+            inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+                switch x := n.(type) {
+                case *ast.AssignStmt:
+                    if len(x.Rhs) != 1 || len(x.Lhs) == 0 {
+                        break
+                    }
+                    call, ok := ast.Unparen(x.Rhs[0]).(*ast.CallExpr)
+                    if !ok || pluginCall(info, call) != "Lookup" {
+                        break
+                    }
+                    if id, ok := x.Lhs[0].(*ast.Ident); ok {
+                        obj := info.Defs[id]
+                        if obj == nil {
+                            obj = info.Uses[id]
+                        }
+                        if obj != nil {
+                            // Запись добавится ниже, при обходе самого вызова
+                            lookups[obj] = len(found)
+                        }
+                    }
+                case *ast.CallExpr:
+                    kind := pluginCall(info, x)
+                    if kind == "" {
+                        break
+                    }
+                    pos := pkg.Fset.Position(x.Pos())
+                    rel := relativePath(projectPath, pos.Filename)
+                    arg := ""
+                    if len(x.Args) == 1 {
+                        arg, _ = constString(info, x.Args[0])
+                    }
+                    if kind == "Open" {
+                        report.Loads = append(report.Loads, PluginLoad{Package: pkg.PkgPath, File: rel, Line: pos.Line, Function: funcID(pkg, decl), Path: arg})
+                        break
+                    }
+                    found = append(found, PluginLookup{Package: pkg.PkgPath, File: rel, Line: pos.Line, Function: funcID(pkg, decl), Symbol: arg, Providers: providers[arg]})
+                case *ast.TypeAssertExpr:
+                    id, ok := ast.Unparen(x.X).(*ast.Ident)
+                    if !ok || x.Type == nil {
+                        break
+                    }
+                    if i, ok := lookups[info.Uses[id]]; ok && i < len(found) && found[i].Type == "" {
+                        found[i].Type = types.TypeString(info.TypeOf(x.Type), nil)
+                    }
+                }
+                return true
+            })
+            report.Lookups = append(report.Lookups, found...)
+        }
+    }
+    if len(report.BuildModes) == 0 && len(report.Packages) == 0 && len(report.Loads) == 0 && len(report.Lookups) == 0 {
+        return nil, err
+    }
+    return report, err
+}
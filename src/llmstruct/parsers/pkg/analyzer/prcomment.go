@@ -0,0 +1,261 @@
+package analyzer
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+const (
+    // Пороги, с которых изменённый символ считается горячей точкой
+    hotspotComplexity = 15
+    hotspotCallers    = 10
+    // GitHub принимает комментарии до 65536 символов; запас - на
+    // подпись бота
+    prCommentLimit = 60000
+)
+
+// DiffHotspot - изменённый символ, ошибка в котором обходится дорого:
+// сложная функция или символ с большим числом вызывающих
+type DiffHotspot struct {
+    Symbol       string   `json:"symbol"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Complexity   int      `json:"complexity,omitempty"`
+    Callers      int      `json:"callers,omitempty"`
+}
+
+// diffHotspots отбирает из изменённых (не удалённых) символов сложные и
+// часто вызываемые; вызывающие известны, только если есть индекс ссылок
+func diffHotspots(head *ProjectAnalysis, changes []APIChange) []DiffHotspot {
+    symbols := goSymbols(head, false)
+    complexity := make(map[string]int)
+    for _, file := range head.Files {
+        pkgPath := packagePathOf(head, file.Path)
+        for _, fn := range file.Functions {
+            name := fn.Name
+            if fn.IsMethod {
+                name = receiverTypeName(fn.Receiver) + "." + fn.Name
+            }
+            complexity[goSymbolID(pkgPath, name)] = fn.Complexity
+        }
+    }
+    hotspots := []DiffHotspot{}
+    seen := make(map[string]bool)
+    for _, change := range changes {
+        id := change.Symbol
+        if change.Change == "removed" || strings.HasSuffix(change.Kind, " member") {
+            continue
+        }
+        if symbols[id] == nil {
+            // Переименованный символ - под новым именем
+            id = change.After
+        }
+        sym := symbols[id]
+        if sym == nil || seen[id] {
+            continue
+        }
+        seen[id] = true
+        h := DiffHotspot{Symbol: id, File: sym.file, Line: sym.line, Complexity: complexity[id]}
+        if head.refs != nil {
+            h.Callers = len(head.refs.callers(id))
+        }
+        if h.Complexity >= hotspotComplexity || h.Callers >= hotspotCallers {
+            hotspots = append(hotspots, h)
+        }
+    }
+    sort.Slice(hotspots, func(i, j int) bool {
+        a, b := hotspots[i], hotspots[j]
+        if a.Complexity+a.Callers != b.Complexity+b.Callers {
+            return a.Complexity+a.Callers > b.Complexity+b.Callers
+        }
+        return a.Symbol < b.Symbol
+    })
+    return hotspots
+}
+
+// prCommenter - результат, который можно вывести как комментарий к pull
+// request (-format pr-comment)
+type prCommenter interface {
+    prComment() (string, error)
+}
+
+func (a *ProjectAnalysis) prComment() (string, error) {
+    if a.Diff == nil {
+        return "", fmt.Errorf("-format pr-comment needs -diff-base")
+    }
+    return renderPRComment(a.Diff, nil), nil
+}
+
+// В комментарий попадают только изменившиеся метрики
+func (d *SnapshotDiff) prComment() (string, error) {
+    metrics := make(map[string]float64)
+    for name, v := range d.Metrics {
+        if v != 0 {
+            metrics[name] = v
+        }
+    }
+    return renderPRComment(d.Changes, metrics), nil
+}
+
+// mdCell экранирует значение для ячейки таблицы Markdown
+func mdCell(s string) string {
+    if s == "" {
+        return ""
+    }
+    s = strings.ReplaceAll(s, "|", "\\|")
+    s = strings.ReplaceAll(s, "`", "'")
+    return "`" + s + "`"
+}
+
+func shortSymbol(id string) string {
+    return strings.TrimPrefix(id, "go:")
+}
+
+// renderPRComment выводит diff в Markdown для комментария: сводка,
+// горячие точки, несовместимые изменения открыты, остальное свёрнуто.
+// Если текст не помещается в лимит, таблицы укорачиваются
+func renderPRComment(diff *AnalysisDiff, metrics map[string]float64) string {
+    var text string
+    for rows := 100; ; rows /= 2 {
+        text = prCommentBody(diff, metrics, rows)
+        if len(text) <= prCommentLimit || rows <= 5 {
+            break
+        }
+    }
+    if len(text) > prCommentLimit {
+        text = text[:prCommentLimit] + "\n\n_(truncated)_\n"
+    }
+    return text
+}
+
+func prCommentBody(diff *AnalysisDiff, metrics map[string]float64, rows int) string {
+    var b strings.Builder
+    var breaking, added, changed []APIChange
+    for _, c := range diff.APIChanges {
+        switch {
+        case c.Breaking:
+            breaking = append(breaking, c)
+        case c.Change == "added":
+            added = append(added, c)
+        default:
+            changed = append(changed, c)
+        }
+    }
+    wireBreaking := 0
+    for _, c := range diff.WireChanges {
+        if c.Breaking {
+            wireBreaking++
+        }
+    }
+
+    base := diff.Base
+    if len(diff.BaseCommit) >= 7 && diff.BaseCommit[:7] != base {
+        base += " (" + diff.BaseCommit[:7] + ")"
+    }
+    fmt.Fprintf(&b, "### API changes since `%s`\n\n", base)
+    if len(diff.APIChanges) == 0 && len(diff.WireChanges) == 0 && len(metrics) == 0 {
+        b.WriteString("No API or wire format changes.\n")
+        return b.String()
+    }
+    summary := []string{
+        fmt.Sprintf("**%d breaking**", len(breaking)+wireBreaking),
+        fmt.Sprintf("%d added", len(added)),
+    }
+    if len(changed) > 0 {
+        summary = append(summary, fmt.Sprintf("%d other", len(changed)))
+    }
+    if len(diff.Renames) > 0 {
+        summary = append(summary, fmt.Sprintf("%d renamed", len(diff.Renames)))
+    }
+    if len(diff.WireChanges) > 0 {
+        summary = append(summary, fmt.Sprintf("%d wire format", len(diff.WireChanges)))
+    }
+    b.WriteString(strings.Join(summary, " · ") + "\n\n")
+
+    if len(diff.Hotspots) > 0 {
+        b.WriteString("> [!WARNING]\n> Changes touch hotspots:\n")
+        for i, h := range diff.Hotspots {
+            if i == rows {
+                fmt.Fprintf(&b, "> - ...and %d more\n", len(diff.Hotspots)-rows)
+                break
+            }
+            var why []string
+            if h.Complexity >= hotspotComplexity {
+                why = append(why, fmt.Sprintf("complexity %d", h.Complexity))
+            }
+            if h.Callers >= hotspotCallers {
+                why = append(why, fmt.Sprintf("%d callers", h.Callers))
+            }
+            fmt.Fprintf(&b, "> - `%s` (%s:%d): %s\n", shortSymbol(h.Symbol), h.File, h.Line, strings.Join(why, ", "))
+        }
+        b.WriteString("\n")
+    }
+
+    apiTable := func(title string, changes []APIChange, open bool) {
+        if len(changes) == 0 {
+            return
+        }
+        attr := ""
+        if open {
+            attr = " open"
+        }
+        fmt.Fprintf(&b, "<details%s><summary>%s (%d)</summary>\n\n", attr, title, len(changes))
+        b.WriteString("| Symbol | Change | Before | After |\n|---|---|---|---|\n")
+        for i, c := range changes {
+            if i == rows {
+                fmt.Fprintf(&b, "\n...and %d more\n", len(changes)-rows)
+                break
+            }
+            fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", mdCell(shortSymbol(c.Symbol)), c.Change, mdCell(shortSymbol(c.Before)), mdCell(shortSymbol(c.After)))
+        }
+        b.WriteString("\n</details>\n\n")
+    }
+    apiTable("Breaking API changes", breaking, true)
+    apiTable("Added API", added, false)
+    apiTable("Other API changes", changed, false)
+
+    if len(diff.WireChanges) > 0 {
+        attr := ""
+        if wireBreaking > 0 {
+            attr = " open"
+        }
+        fmt.Fprintf(&b, "<details%s><summary>Wire format changes (%d)</summary>\n\n", attr, len(diff.WireChanges))
+        b.WriteString("| Schema | Field | Change | Breaking |\n|---|---|---|---|\n")
+        for i, c := range diff.WireChanges {
+            if i == rows {
+                fmt.Fprintf(&b, "\n...and %d more\n", len(diff.WireChanges)-rows)
+                break
+            }
+            mark := ""
+            if c.Breaking {
+                mark = "yes"
+            }
+            fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", mdCell(c.Schema), mdCell(c.Field), c.Change, mark)
+        }
+        b.WriteString("\n</details>\n\n")
+    }
+
+    if len(metrics) > 0 {
+        b.WriteString("<details><summary>Metrics</summary>\n\n| Metric | Change |\n|---|---|\n")
+        for _, name := range historyMetrics {
+            if v, ok := metrics[name]; ok {
+                fmt.Fprintf(&b, "| %s | %+g |\n", name, v)
+            }
+        }
+        b.WriteString("\n</details>\n\n")
+    }
+
+    if len(diff.Commits) > 0 {
+        fmt.Fprintf(&b, "<details><summary>Commits (%d)</summary>\n\n", len(diff.Commits))
+        for i, c := range diff.Commits {
+            if i == rows {
+                fmt.Fprintf(&b, "- ...and %d more\n", len(diff.Commits)-rows)
+                break
+            }
+            fmt.Fprintf(&b, "- %s %s (%s)\n", c.SHA[:min(7, len(c.SHA))], c.Message, c.Author)
+        }
+        b.WriteString("\n</details>\n")
+    }
+    return b.String()
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+)
+
+type ProjectKind struct {
+    Kind         string   `json:"kind"`
+    Confidence   float64  `json:"confidence"`
+    Evidence     []string `json:"evidence"`
+}
+
+type Framework struct {
+    Name         string   `json:"name"`
+    Category     string   `json:"category"`
+    Packages     []string `json:"packages"`
+}
+
+type ProjectProfile struct {
+    Primary      string        `json:"primary"`
+    Kinds        []ProjectKind `json:"kinds"`
+    Frameworks   []Framework   `json:"frameworks"`
+}
+
+type knownFramework struct {
+    name     string
+    category string
+    prefix   string
+}
+
+// Крупные фреймворки и библиотеки, определяемые по путям импорта
+var knownFrameworks = []knownFramework{
+    {"gin", "http", "github.com/gin-gonic/gin"},
+    {"echo", "http", "github.com/labstack/echo"},
+    {"chi", "http", "github.com/go-chi/chi"},
+    {"fiber", "http", "github.com/gofiber/fiber"},
+    {"gorilla/mux", "http", "github.com/gorilla/mux"},
+    {"httprouter", "http", "github.com/julienschmidt/httprouter"},
+    {"grpc", "rpc", "google.golang.org/grpc"},
+    {"connect", "rpc", "connectrpc.com/connect"},
+    {"twirp", "rpc", "github.com/twitchtv/twirp"},
+    {"cobra", "cli", "github.com/spf13/cobra"},
+    {"urfave/cli", "cli", "github.com/urfave/cli"},
+    {"kingpin", "cli", "github.com/alecthomas/kingpin"},
+    {"kong", "cli", "github.com/alecthomas/kong"},
+    {"viper", "config", "github.com/spf13/viper"},
+    {"controller-runtime", "kubernetes", "sigs.k8s.io/controller-runtime"},
+    {"client-go", "kubernetes", "k8s.io/client-go"},
+    {"terraform-plugin-sdk", "terraform", "github.com/hashicorp/terraform-plugin-sdk"},
+    {"terraform-plugin-framework", "terraform", "github.com/hashicorp/terraform-plugin-framework"},
+    {"gorm", "database", "gorm.io/gorm"},
+    {"sqlx", "database", "github.com/jmoiron/sqlx"},
+    {"ent", "database", "entgo.io/ent"},
+    {"pgx", "database", "github.com/jackc/pgx"},
+    {"mongo-driver", "database", "go.mongodb.org/mongo-driver"},
+    {"go-redis", "database", "github.com/redis/go-redis"},
+    {"sarama", "messaging", "github.com/IBM/sarama"},
+    {"kafka-go", "messaging", "github.com/segmentio/kafka-go"},
+    {"nats", "messaging", "github.com/nats-io/nats.go"},
+    {"zap", "logging", "go.uber.org/zap"},
+    {"logrus", "logging", "github.com/sirupsen/logrus"},
+    {"zerolog", "logging", "github.com/rs/zerolog"},
+    {"prometheus", "observability", "github.com/prometheus/client_golang"},
+    {"opentelemetry", "observability", "go.opentelemetry.io/otel"},
+    {"fx", "di", "go.uber.org/fx"},
+    {"wire", "di", "github.com/google/wire"},
+}
+
+// detectFrameworks сопоставляет импортируемые пакеты с известными фреймворками
+func detectFrameworks(dependencies []string) []Framework {
+    frameworks := []Framework{}
+    for _, known := range knownFrameworks {
+        var pkgs []string
+        for _, dep := range dependencies {
+            if hasPathPrefix(dep, known.prefix) || strings.HasPrefix(dep, known.prefix+"/v") {
+                pkgs = append(pkgs, dep)
+            }
+        }
+        if len(pkgs) > 0 {
+            frameworks = append(frameworks, Framework{Name: known.name, Category: known.category, Packages: pkgs})
+        }
+    }
+    return frameworks
+}
+
+// buildProjectProfile эвристически классифицирует проект по уже собранным
+// разделам анализа: CLI, HTTP API, gRPC-сервис, библиотека, оператор
+// Kubernetes или провайдер Terraform
+func buildProjectProfile(result *ProjectAnalysis) *ProjectProfile {
+    profile := &ProjectProfile{Kinds: []ProjectKind{}, Frameworks: detectFrameworks(result.Dependencies)}
+    categories := make(map[string][]string)
+    for _, fw := range profile.Frameworks {
+        categories[fw.Category] = append(categories[fw.Category], fw.Name)
+    }
+
+    mainFiles := 0
+    reconcilers := 0
+    for _, file := range result.Files {
+        if file.HasTests {
+            continue
+        }
+        if file.Package == "main" {
+            mainFiles++
+        }
+        for _, fn := range file.Functions {
+            if fn.IsMethod && fn.Name == "Reconcile" {
+                reconcilers++
+            }
+        }
+    }
+    grpcServers := 0
+    if result.Services != nil {
+        for _, server := range result.Services.Servers {
+            if server.Protocol == "grpc" {
+                grpcServers++
+            }
+        }
+    }
+
+    add := func(kind string, confidence float64, evidence ...string) {
+        confidence = math.Min(1, math.Round(confidence*100)/100)
+        profile.Kinds = append(profile.Kinds, ProjectKind{Kind: kind, Confidence: confidence, Evidence: evidence})
+    }
+
+    if fws := categories["terraform"]; len(fws) > 0 {
+        add("terraform-provider", 0.9, "imports "+strings.Join(fws, ", "))
+    }
+    if fws := categories["kubernetes"]; len(fws) > 0 && reconcilers > 0 {
+        add("operator", 0.9, "imports "+strings.Join(fws, ", "), fmt.Sprintf("%d Reconcile methods", reconcilers))
+    }
+    if grpcServers > 0 {
+        add("grpc-service", 0.9, fmt.Sprintf("%d gRPC servers registered", grpcServers))
+    }
+    if len(result.Routes) > 0 {
+        evidence := []string{fmt.Sprintf("%d HTTP routes", len(result.Routes))}
+        if fws := categories["http"]; len(fws) > 0 {
+            evidence = append(evidence, "imports "+strings.Join(fws, ", "))
+        }
+        add("http-api", 0.5+0.05*float64(len(result.Routes)), evidence...)
+    } else if fws := categories["http"]; len(fws) > 0 {
+        add("http-api", 0.4, "imports "+strings.Join(fws, ", "))
+    }
+    if mainFiles > 0 {
+        evidence := []string{fmt.Sprintf("%d files in package main", mainFiles)}
+        confidence := 0.4
+        if fws := categories["cli"]; len(fws) > 0 {
+            evidence = append(evidence, "imports "+strings.Join(fws, ", "))
+            confidence = 0.8
+        }
+        if result.ConfigSurface != nil && len(result.ConfigSurface.Flags) > 0 {
+            evidence = append(evidence, fmt.Sprintf("%d command-line flags", len(result.ConfigSurface.Flags)))
+            confidence += 0.1
+        }
+        // Сервер с main - прежде всего сервис, а не утилита
+        if len(profile.Kinds) > 0 {
+            confidence -= 0.2
+        }
+        add("cli", confidence, evidence...)
+    } else {
+        add("library", 0.8, "no package main")
+    }
+
+    sort.SliceStable(profile.Kinds, func(i, j int) bool {
+        return profile.Kinds[i].Confidence > profile.Kinds[j].Confidence
+    })
+    profile.Primary = profile.Kinds[0].Kind
+    return profile
+}
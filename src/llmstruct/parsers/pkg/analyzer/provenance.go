@@ -0,0 +1,263 @@
+package analyzer
+
+import (
+    "bytes"
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "encoding/pem"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "runtime/debug"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Provenance - происхождение результата: откуда и чем он получен.
+// SourceDigest - sha256 по списку "sha256  путь" проанализированных файлов
+// (как у sha256sum, пути отсортированы): по нему verify -source проверяет,
+// что результат соответствует дереву исходников
+type Provenance struct {
+    Repository   string    `json:"repository,omitempty"`
+    Commit       string    `json:"commit,omitempty"`
+    Dirty        bool      `json:"dirty"`
+    SourceDigest string    `json:"source_digest"`
+    Analyzer     string    `json:"analyzer"`
+    GoVersion    string    `json:"go_version"`
+    Flags        []string  `json:"flags"`
+    CreatedAt    time.Time `json:"created_at"`
+}
+
+// analyzerVersion - версия модуля анализатора и ревизия, из которой он
+// собран
+func analyzerVersion() string {
+    info, ok := debug.ReadBuildInfo()
+    if !ok {
+        return "unknown"
+    }
+    var revision, modified string
+    for _, s := range info.Settings {
+        switch {
+        case s.Key == "vcs.revision":
+            revision = s.Value
+        case s.Key == "vcs.modified" && s.Value == "true":
+            modified = ", modified"
+        }
+    }
+    if revision == "" {
+        return info.Main.Version
+    }
+    return info.Main.Version + " (" + revision + modified + ")"
+}
+
+// sourceDigest хэширует файлы анализа; отсутствующий файл - ошибка,
+// иначе проверка по другому дереву могла бы пройти
+func sourceDigest(projectPath string, files []FileAnalysis) (string, error) {
+    paths := make([]string, 0, len(files))
+    for _, f := range files {
+        paths = append(paths, filepath.ToSlash(f.Path))
+    }
+    sort.Strings(paths)
+    h := sha256.New()
+    for _, p := range paths {
+        content, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(p)))
+        if err != nil {
+            return "", err
+        }
+        fmt.Fprintf(h, "%x  %s\n", sha256.Sum256(content), p)
+    }
+    return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildProvenance описывает проект и запуск; flags - явно заданные флаги
+// командной строки
+func buildProvenance(projectPath string, analysis *ProjectAnalysis, flags []string) (*Provenance, error) {
+    digest, err := sourceDigest(projectPath, analysis.Files)
+    if err != nil {
+        return nil, err
+    }
+    p := &Provenance{
+        SourceDigest: digest,
+        Analyzer:     analyzerVersion(),
+        GoVersion:    runtime.Version(),
+        Flags:        flags,
+        CreatedAt:    time.Now().UTC().Truncate(time.Second),
+    }
+    // Вне git-репозитория остаются только хэш исходников и сведения о запуске
+    if out, err := runGit(projectPath, "rev-parse", "HEAD"); err == nil {
+        p.Commit = strings.TrimSpace(string(out))
+        if out, err := runGit(projectPath, "remote", "get-url", "origin"); err == nil {
+            // Токен из URL remote не должен попасть в подписанный артефакт
+            p.Repository = stripUserinfo(strings.TrimSpace(string(out)))
+        }
+        if out, err := runGit(projectPath, "status", "--porcelain", "--", "."); err == nil {
+            p.Dirty = len(bytes.TrimSpace(out)) > 0
+        }
+    }
+    return p, nil
+}
+
+// setFlags - явно заданные флаги в виде -name=value
+func setFlags(fs *flag.FlagSet) []string {
+    flags := []string{}
+    fs.Visit(func(f *flag.Flag) {
+        flags = append(flags, "-"+f.Name+"="+f.Value.String())
+    })
+    return flags
+}
+
+// Ключи хранятся в PEM: закрытый - PKCS #8, открытый - PKIX, как их
+// читает cosign
+func readPrivateKey(path string) (ed25519.PrivateKey, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    block, _ := pem.Decode(data)
+    if block == nil || block.Type != "PRIVATE KEY" {
+        return nil, fmt.Errorf("%s: expected a PEM PKCS #8 private key", path)
+    }
+    key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %v", path, err)
+    }
+    priv, ok := key.(ed25519.PrivateKey)
+    if !ok {
+        return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+    }
+    return priv, nil
+}
+
+func readPublicKey(path string) (ed25519.PublicKey, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    block, _ := pem.Decode(data)
+    if block == nil || block.Type != "PUBLIC KEY" {
+        return nil, fmt.Errorf("%s: expected a PEM public key", path)
+    }
+    key, err := x509.ParsePKIXPublicKey(block.Bytes)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %v", path, err)
+    }
+    pub, ok := key.(ed25519.PublicKey)
+    if !ok {
+        return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+    }
+    return pub, nil
+}
+
+// signFile пишет рядом с файлом path.sig - подпись Ed25519 содержимого в
+// base64, которую принимает cosign verify-blob --key
+func signFile(path, keyPath string) error {
+    key, err := readPrivateKey(keyPath)
+    if err != nil {
+        return err
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+    return writeAtomic(path+".sig", []byte(sig+"\n"))
+}
+
+// ArtifactVerification - результат analyzer verify
+type ArtifactVerification struct {
+    File         string      `json:"file"`
+    Signature    bool        `json:"signature"`
+    Provenance   *Provenance `json:"provenance,omitempty"`
+    // Source - совпал ли хэш исходников с деревом -source
+    Source       *bool       `json:"source,omitempty"`
+}
+
+// runKeygen - analyzer keygen -key <file>: пара ключей Ed25519 для
+// -sign-key (file) и verify (file.pub)
+func runKeygen(args []string) {
+    fs := newCommandFlags("keygen", "-key <file>")
+    keyPath := fs.String("key", "", "write the private key to `file` and the public key to file.pub")
+    fs.parse(args)
+    if *keyPath == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+    pub, priv, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        fatal("failed to generate key", "error", err)
+    }
+    privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+    if err != nil {
+        fatal("failed to encode key", "error", err)
+    }
+    pubDER, err := x509.MarshalPKIXPublicKey(pub)
+    if err != nil {
+        fatal("failed to encode key", "error", err)
+    }
+    if err := os.WriteFile(*keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+        fatal("failed to write key", "path", *keyPath, "error", err)
+    }
+    if err := writeAtomic(*keyPath+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})); err != nil {
+        fatal("failed to write key", "path", *keyPath+".pub", "error", err)
+    }
+    fs.write(map[string]string{"private_key": *keyPath, "public_key": *keyPath + ".pub"})
+}
+
+// runVerify - analyzer verify -key <pub> [-source dir] <file>: проверяет
+// подпись результата и, если задан -source, хэш исходников из provenance
+func runVerify(args []string) {
+    fs := newCommandFlags("verify", "-key <public key> [-signature file] [-source project_path] <file>")
+    keyPath := fs.String("key", "", "Ed25519 public key (PEM) created by keygen")
+    sigPath := fs.String("signature", "", "signature file (default: <file>.sig)")
+    source := fs.String("source", "", "also check that the provenance source digest matches this project tree")
+    file := fs.parse(args)
+    if *keyPath == "" || fs.NArg() == 0 {
+        fs.Usage()
+        os.Exit(2)
+    }
+    if *sigPath == "" {
+        *sigPath = file + ".sig"
+    }
+    pub, err := readPublicKey(*keyPath)
+    if err != nil {
+        fatal("failed to read public key", "error", err)
+    }
+    data, err := os.ReadFile(file)
+    if err != nil {
+        fatal("failed to read artifact", "error", err)
+    }
+    sigText, err := os.ReadFile(*sigPath)
+    if err != nil {
+        fatal("failed to read signature", "error", err)
+    }
+    sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+    if err != nil {
+        fatal("invalid signature", "path", *sigPath, "error", err)
+    }
+    result := ArtifactVerification{File: file, Signature: ed25519.Verify(pub, data, sig)}
+
+    var analysis ProjectAnalysis
+    if err := json.Unmarshal(data, &analysis); err == nil {
+        result.Provenance = analysis.Provenance
+    }
+    if *source != "" {
+        if result.Provenance == nil {
+            fatal("cannot check source", "error", "artifact has no provenance")
+        }
+        digest, err := sourceDigest(*source, analysis.Files)
+        match := err == nil && digest == result.Provenance.SourceDigest
+        result.Source = &match
+    }
+    fs.write(result)
+    if !result.Signature || result.Source != nil && !*result.Source {
+        fatal("verification failed", "file", file)
+    }
+}
@@ -0,0 +1,217 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// symbolRef - ссылка из тела функции на символ проекта: call - вызов,
+// ref - любое другое использование (значение функции, тип, переменная)
+type symbolRef struct {
+    from   string
+    to     string
+    kind   string
+    file   string
+    line   int
+}
+
+// refIndex - ссылки между символами проекта в обе стороны, ключи -
+// стабильные ID единой схемы
+type refIndex struct {
+    refs          []symbolRef
+    byFrom        map[string][]int
+    byTo          map[string][]int
+    // Реализации интерфейсов проекта типами проекта
+    implements    map[string][]string
+    implementedBy map[string][]string
+}
+
+// objectSymbolID переводит объект go/types в стабильный ID символа; для
+// локальных объектов и полей возвращает ""
+func objectSymbolID(obj types.Object) string {
+    if obj == nil || obj.Pkg() == nil {
+        return ""
+    }
+    switch o := obj.(type) {
+    case *types.Func:
+        o = o.Origin()
+        sig, _ := o.Type().(*types.Signature)
+        if sig != nil && sig.Recv() != nil {
+            recv := sig.Recv().Type()
+            if ptr, ok := recv.(*types.Pointer); ok {
+                recv = ptr.Elem()
+            }
+            named, ok := recv.(*types.Named)
+            if !ok {
+                return ""
+            }
+            return goSymbolID(o.Pkg().Path(), named.Obj().Name()+"."+o.Name())
+        }
+        return goSymbolID(o.Pkg().Path(), o.Name())
+    case *types.TypeName, *types.Var, *types.Const:
+        if obj.Parent() != obj.Pkg().Scope() {
+            return ""
+        }
+        return goSymbolID(obj.Pkg().Path(), obj.Name())
+    }
+    return ""
+}
+
+// refIndexBuilder собирает ссылки только на символы пакетов проекта
+type refIndexBuilder struct {
+    projectPath string
+    project     map[string]bool
+    seen        map[[3]string]bool
+    index       *refIndex
+    concrete    []*types.TypeName
+    interfaces  []*types.TypeName
+}
+
+func newRefIndexBuilder(projectPath string, pkgs []*packages.Package) *refIndexBuilder {
+    b := &refIndexBuilder{
+        projectPath: projectPath,
+        project:     make(map[string]bool),
+        seen:        make(map[[3]string]bool),
+        index: &refIndex{
+            byFrom:        make(map[string][]int),
+            byTo:          make(map[string][]int),
+            implements:    make(map[string][]string),
+            implementedBy: make(map[string][]string),
+        },
+    }
+    for _, pkg := range pkgs {
+        b.project[pkg.PkgPath] = true
+    }
+    return b
+}
+
+func (b *refIndexBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil || pkg.Types == nil {
+        return
+    }
+    scope := pkg.Types.Scope()
+    for _, name := range scope.Names() {
+        tn, ok := scope.Lookup(name).(*types.TypeName)
+        if !ok || tn.IsAlias() {
+            continue
+        }
+        if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+            // Ограничения типов и пустые интерфейсы не реализуются явно
+            if iface.IsMethodSet() && iface.NumMethods() > 0 {
+                b.interfaces = append(b.interfaces, tn)
+            }
+        } else if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() == 0 {
+            b.concrete = append(b.concrete, tn)
+        }
+    }
+    for _, file := range pkg.Syntax {
+        callIdents := make(map[*ast.Ident]bool)
+        // Типы параметров и результатов - тоже ссылки функции
+        for _, decl := range file.Decls {
+            if fd, ok := decl.(*ast.FuncDecl); ok {
+                ast.Inspect(fd.Type, func(n ast.Node) bool {
+                    if id, ok := n.(*ast.Ident); ok {
+                        b.addRef(pkg, fd, id, false)
+                    }
+                    return true
+                })
+            }
+        }
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            if decl == nil {
+                return true
+            }
+            switch x := n.(type) {
+            case *ast.CallExpr:
+                switch fun := ast.Unparen(x.Fun).(type) {
+                case *ast.Ident:
+                    callIdents[fun] = true
+                case *ast.SelectorExpr:
+                    callIdents[fun.Sel] = true
+                case *ast.IndexExpr:
+                    // Явное инстанцирование: F[int](x)
+                    if id, ok := fun.X.(*ast.Ident); ok {
+                        callIdents[id] = true
+                    }
+                }
+            case *ast.Ident:
+                b.addRef(pkg, decl, x, callIdents[x])
+            }
+            return true
+        })
+    }
+}
+
+// addRef записывает ссылку из функции decl через идентификатор id
+func (b *refIndexBuilder) addRef(pkg *packages.Package, decl *ast.FuncDecl, id *ast.Ident, call bool) {
+    obj := pkg.TypesInfo.Uses[id]
+    if obj == nil || obj.Pkg() == nil || !b.project[obj.Pkg().Path()] {
+        return
+    }
+    to := objectSymbolID(obj)
+    from := objectSymbolID(pkg.TypesInfo.Defs[decl.Name])
+    if to == "" || from == "" || to == from && !call {
+        return
+    }
+    kind := "ref"
+    if _, isFunc := obj.(*types.Func); isFunc && call {
+        kind = "call"
+    }
+    key := [3]string{from, to, kind}
+    if b.seen[key] {
+        return
+    }
+    b.seen[key] = true
+    pos := pkg.Fset.Position(id.Pos())
+    b.index.refs = append(b.index.refs, symbolRef{from: from, to: to, kind: kind, file: relativePath(b.projectPath, pos.Filename), line: pos.Line})
+}
+
+func (b *refIndexBuilder) build() *refIndex {
+    idx := b.index
+    sort.SliceStable(idx.refs, func(i, j int) bool {
+        if idx.refs[i].from != idx.refs[j].from {
+            return idx.refs[i].from < idx.refs[j].from
+        }
+        return idx.refs[i].to < idx.refs[j].to
+    })
+    for i, ref := range idx.refs {
+        idx.byFrom[ref.from] = append(idx.byFrom[ref.from], i)
+        idx.byTo[ref.to] = append(idx.byTo[ref.to], i)
+    }
+    for _, tn := range b.concrete {
+        for _, iface := range b.interfaces {
+            it := iface.Type().Underlying().(*types.Interface)
+            if types.Implements(tn.Type(), it) || types.Implements(types.NewPointer(tn.Type()), it) {
+                typeID, ifaceID := objectSymbolID(tn), objectSymbolID(iface)
+                idx.implements[typeID] = append(idx.implements[typeID], ifaceID)
+                idx.implementedBy[ifaceID] = append(idx.implementedBy[ifaceID], typeID)
+            }
+        }
+    }
+    return idx
+}
+
+// callers возвращает функции, вызывающие символ
+func (idx *refIndex) callers(id string) []string {
+    var out []string
+    for _, i := range idx.byTo[id] {
+        if idx.refs[i].kind == "call" {
+            out = appendUnique(out, idx.refs[i].from)
+        }
+    }
+    return out
+}
+
+// callees возвращает функции проекта, вызываемые символом
+func (idx *refIndex) callees(id string) []string {
+    var out []string
+    for _, i := range idx.byFrom[id] {
+        if idx.refs[i].kind == "call" {
+            out = appendUnique(out, idx.refs[i].to)
+        }
+    }
+    return out
+}
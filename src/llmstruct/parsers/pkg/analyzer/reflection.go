@@ -0,0 +1,346 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// ReflectionSite - место, где код обращается к типам через reflect.
+// Types - типы, над которыми идёт рефлексия; Via: static (тип аргумента
+// известен), callers (аргумент - параметр, типы взяты из вызовов функции),
+// tag (чтение тега - структуры проекта с этим ключом тега), unresolved
+type ReflectionSite struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    Call         string   `json:"call"`
+    Types        []string `json:"types,omitempty"`
+    Via          string   `json:"via"`
+    TagKey       string   `json:"tag_key,omitempty"`
+}
+
+type paramKey struct {
+    fn    *types.Func
+    index int
+}
+
+type callSite struct {
+    info *types.Info
+    decl *ast.FuncDecl
+    call *ast.CallExpr
+}
+
+// reflectionResolver выводит типы значений, попадающих в reflect, в том
+// числе через параметры-интерфейсы: func dump(v any) { reflect.TypeOf(v) }
+// связывается с типами аргументов во всех вызовах dump
+type reflectionResolver struct {
+    calls    map[*types.Func][]callSite
+    resolved map[paramKey][]types.Type
+}
+
+func deref(t types.Type) types.Type {
+    if ptr, ok := t.(*types.Pointer); ok {
+        return ptr.Elem()
+    }
+    return t
+}
+
+// paramIndex - номер параметра функции decl, на который ссылается expr
+func paramIndex(info *types.Info, decl *ast.FuncDecl, expr ast.Expr) (*types.Func, int) {
+    id, ok := ast.Unparen(expr).(*ast.Ident)
+    if !ok || decl == nil {
+        return nil, -1
+    }
+    fn, ok := info.Defs[decl.Name].(*types.Func)
+    if !ok {
+        return nil, -1
+    }
+    params := fn.Type().(*types.Signature).Params()
+    for i := 0; i < params.Len(); i++ {
+        if info.Uses[id] == params.At(i) {
+            return fn, i
+        }
+    }
+    return nil, -1
+}
+
+// valueTypes возвращает конкретные типы выражения; callers - типы получены из
+// вызовов функции
+func (r *reflectionResolver) valueTypes(info *types.Info, decl *ast.FuncDecl, expr ast.Expr) (out []types.Type, callers bool) {
+    if u, ok := ast.Unparen(expr).(*ast.UnaryExpr); ok {
+        expr = u.X
+    }
+    t := info.TypeOf(expr)
+    if t == nil {
+        return nil, false
+    }
+    if !types.IsInterface(t) {
+        return []types.Type{deref(t)}, false
+    }
+    if fn, i := paramIndex(info, decl, expr); fn != nil {
+        return r.param(paramKey{fn, i}), true
+    }
+    return nil, false
+}
+
+// param собирает типы аргументов во всех вызовах функции; аргумент,
+// который сам является параметром вызывающей функции, разворачивается
+// дальше по её вызовам
+func (r *reflectionResolver) param(key paramKey) []types.Type {
+    if out, ok := r.resolved[key]; ok {
+        return out
+    }
+    // Рекурсия видит пустой результат
+    r.resolved[key] = nil
+    var out []types.Type
+    for _, site := range r.calls[key.fn] {
+        if key.index >= len(site.call.Args) || site.call.Ellipsis.IsValid() {
+            continue
+        }
+        ts, _ := r.valueTypes(site.info, site.decl, site.call.Args[key.index])
+        for _, t := range ts {
+            dup := false
+            for _, seen := range out {
+                dup = dup || types.Identical(seen, t)
+            }
+            if !dup {
+                out = append(out, t)
+            }
+        }
+    }
+    r.resolved[key] = out
+    return out
+}
+
+// reflectCall определяет вызов reflect, который получает тип значения
+// или читает тег, и возвращает его имя
+func reflectCall(info *types.Info, call *ast.CallExpr) string {
+    fn := calleeFunc(info, call)
+    if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" {
+        return ""
+    }
+    sig := fn.Type().(*types.Signature)
+    if sig.Recv() != nil {
+        if named, ok := deref(sig.Recv().Type()).(*types.Named); ok && named.Obj().Name() == "StructTag" && (fn.Name() == "Get" || fn.Name() == "Lookup") {
+            return "reflect.StructTag." + fn.Name()
+        }
+        return ""
+    }
+    switch fn.Name() {
+    case "TypeOf", "ValueOf", "TypeFor":
+        return "reflect." + fn.Name()
+    }
+    return ""
+}
+
+// structsWithTag - структуры проекта, у полей которых есть тег с ключом
+func structsWithTag(pkgs []*packages.Package) map[string][]types.Type {
+    tagged := make(map[string][]types.Type)
+    for _, pkg := range pkgs {
+        if pkg.Types == nil {
+            continue
+        }
+        scope := pkg.Types.Scope()
+        for _, name := range scope.Names() {
+            tn, ok := scope.Lookup(name).(*types.TypeName)
+            if !ok || tn.IsAlias() {
+                continue
+            }
+            st, ok := tn.Type().Underlying().(*types.Struct)
+            if !ok {
+                continue
+            }
+            keys := make(map[string]bool)
+            for i := 0; i < st.NumFields(); i++ {
+                for _, key := range tagKeys(st.Tag(i)) {
+                    keys[key] = true
+                }
+            }
+            for key := range keys {
+                tagged[key] = append(tagged[key], tn.Type())
+            }
+        }
+    }
+    return tagged
+}
+
+// tagKeys разбирает тег вида `json:"name" db:"id"` на ключи так же, как
+// reflect.StructTag.Lookup
+func tagKeys(tag string) []string {
+    var keys []string
+    for tag != "" {
+        i := 0
+        for i < len(tag) && tag[i] == ' ' {
+            i++
+        }
+        tag = tag[i:]
+        i = 0
+        for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+            i++
+        }
+        if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+            break
+        }
+        name := tag[:i]
+        tag = tag[i+1:]
+        for i = 1; i < len(tag) && tag[i] != '"'; i++ {
+            if tag[i] == '\\' {
+                i++
+            }
+        }
+        if i >= len(tag) {
+            break
+        }
+        keys = append(keys, name)
+        tag = tag[i+1:]
+    }
+    return keys
+}
+
+// extractReflection находит обращения к reflect и типы, над которыми они
+// выполняются; для типов проекта возвращает ссылки вида reflect из
+// функции на тип, которых не видно в обычном анализе ссылок
+func extractReflection(pkgs []*packages.Package, projectPath string) ([]ReflectionSite, []symbolRef) {
+    r := &reflectionResolver{
+        calls:    make(map[*types.Func][]callSite),
+        resolved: make(map[paramKey][]types.Type),
+    }
+    type pending struct {
+        pkg  *packages.Package
+        decl *ast.FuncDecl
+        call *ast.CallExpr
+        name string
+    }
+    var found []pending
+    project := make(map[string]bool)
+    for _, pkg := range pkgs {
+        project[pkg.PkgPath] = true
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        for _, file := range pkg.Syntax {
+            inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+                call, ok := n.(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                if name := reflectCall(pkg.TypesInfo, call); name != "" {
+                    found = append(found, pending{pkg, decl, call, name})
+                } else if fn := calleeFunc(pkg.TypesInfo, call); fn != nil {
+                    r.calls[fn.Origin()] = append(r.calls[fn.Origin()], callSite{pkg.TypesInfo, decl, call})
+                }
+                return true
+            })
+        }
+    }
+    if len(found) == 0 {
+        return nil, nil
+    }
+    tagged := structsWithTag(pkgs)
+    sites := []ReflectionSite{}
+    var refs []symbolRef
+    seen := make(map[[2]string]bool)
+    for _, f := range found {
+        info := f.pkg.TypesInfo
+        pos := f.pkg.Fset.Position(f.call.Pos())
+        site := ReflectionSite{
+            Package:  f.pkg.PkgPath,
+            File:     relativePath(projectPath, pos.Filename),
+            Line:     pos.Line,
+            Function: funcID(f.pkg, f.decl),
+            Call:     f.name,
+            Via:      "unresolved",
+        }
+        var targets []types.Type
+        switch f.name {
+        case "reflect.TypeFor":
+            if inst, ok := typeArgs(info, f.call.Fun); ok {
+                targets, site.Via = []types.Type{deref(inst)}, "static"
+            }
+        case "reflect.TypeOf", "reflect.ValueOf":
+            if len(f.call.Args) == 1 {
+                ts, callers := r.valueTypes(info, f.decl, f.call.Args[0])
+                if len(ts) > 0 {
+                    targets, site.Via = ts, "static"
+                    if callers {
+                        site.Via = "callers"
+                    }
+                }
+            }
+        default:
+            if len(f.call.Args) == 1 {
+                if key, ok := constString(info, f.call.Args[0]); ok {
+                    site.TagKey = key
+                    if targets = tagged[key]; len(targets) > 0 {
+                        site.Via = "tag"
+                    }
+                }
+            }
+        }
+        for _, t := range targets {
+            site.Types = appendUnique(site.Types, types.TypeString(t, nil))
+        }
+        sort.Strings(site.Types)
+        sites = append(sites, site)
+        // Связь функции с типами проекта
+        if f.decl == nil {
+            continue
+        }
+        from := objectSymbolID(info.Defs[f.decl.Name])
+        for _, t := range targets {
+            named, ok := t.(*types.Named)
+            if !ok || named.Obj().Pkg() == nil || !project[named.Obj().Pkg().Path()] {
+                continue
+            }
+            to := objectSymbolID(named.Obj())
+            if from == "" || to == "" || seen[[2]string{from, to}] {
+                continue
+            }
+            seen[[2]string{from, to}] = true
+            refs = append(refs, symbolRef{from: from, to: to, kind: "reflect", file: site.File, line: site.Line})
+        }
+    }
+    sort.SliceStable(sites, func(i, j int) bool {
+        if sites[i].File != sites[j].File {
+            return sites[i].File < sites[j].File
+        }
+        return sites[i].Line < sites[j].Line
+    })
+    return sites, refs
+}
+
+// typeArgs возвращает аргумент типа явного инстанцирования F[T]
+func typeArgs(info *types.Info, fun ast.Expr) (types.Type, bool) {
+    var id *ast.Ident
+    switch e := ast.Unparen(fun).(type) {
+    case *ast.IndexExpr:
+        switch x := e.X.(type) {
+        case *ast.Ident:
+            id = x
+        case *ast.SelectorExpr:
+            id = x.Sel
+        }
+    }
+    if id == nil {
+        return nil, false
+    }
+    inst, ok := info.Instances[id]
+    if !ok || inst.TypeArgs.Len() == 0 {
+        return nil, false
+    }
+    return inst.TypeArgs.At(0), true
+}
+
+// add дополняет построенный индекс ссылками
+func (idx *refIndex) add(refs []symbolRef) {
+    for _, ref := range refs {
+        idx.refs = append(idx.refs, ref)
+        i := len(idx.refs) - 1
+        idx.byFrom[ref.from] = append(idx.byFrom[ref.from], i)
+        idx.byTo[ref.to] = append(idx.byTo[ref.to], i)
+    }
+}
@@ -0,0 +1,217 @@
+package analyzer
+
+import (
+    "go/scanner"
+    "go/token"
+    "math"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// SymbolRename - символ, переименованный или перенесённый между ревизиями;
+// From/To - стабильные ID, по которым переносятся аннотации, описания и
+// эмбеддинги
+type SymbolRename struct {
+    Kind         string   `json:"kind"`
+    From         string   `json:"from"`
+    To           string   `json:"to"`
+    Change       string   `json:"change"`
+    Similarity   float64  `json:"similarity"`
+    File         string   `json:"file"`
+}
+
+const (
+    // Минимальное сходство пары удалённый/добавленный символ
+    renameThreshold = 0.75
+    // Тела короче этого числа токенов слишком похожи друг на друга, чтобы
+    // по ним узнавать переименование
+    minRenameTokens = 12
+)
+
+// sourceLines кэширует строки файлов дерева ревизии; пустой root - дерева
+// нет (снимки истории), тексты пустые
+type sourceLines struct {
+    root  string
+    files map[string][]string
+}
+
+func (s *sourceLines) span(file string, line, endLine int) string {
+    lines, ok := s.files[file]
+    if !ok && s.root != "" {
+        content, err := os.ReadFile(filepath.Join(s.root, file))
+        if err == nil {
+            lines = strings.Split(string(content), "\n")
+        }
+        s.files[file] = lines
+    }
+    if line < 1 || endLine > len(lines) || line > endLine {
+        return ""
+    }
+    return strings.Join(lines[line-1:endLine], "\n")
+}
+
+// bodyTokens разбивает тело функции (от первой "{") на токены; собственное
+// имя заменяется, чтобы рекурсия не мешала сравнению
+func bodyTokens(src, name string) []string {
+    i := strings.Index(src, "{")
+    if i < 0 {
+        return nil
+    }
+    src = src[i:]
+    fset := token.NewFileSet()
+    file := fset.AddFile("", -1, len(src))
+    var s scanner.Scanner
+    s.Init(file, []byte(src), nil, 0)
+    var toks []string
+    for {
+        _, tok, lit := s.Scan()
+        if tok == token.EOF {
+            break
+        }
+        switch {
+        case tok == token.IDENT && lit == name:
+            lit = "$self"
+        case lit == "" || tok == token.SEMICOLON:
+            lit = tok.String()
+        }
+        toks = append(toks, lit)
+    }
+    return toks
+}
+
+// bodyShingles возвращает множество триграмм токенов тела и число токенов
+func bodyShingles(src, name string) (map[string]bool, int) {
+    toks := bodyTokens(src, name)
+    shingles := make(map[string]bool)
+    for i := 0; i+3 <= len(toks); i++ {
+        shingles[strings.Join(toks[i:i+3], " ")] = true
+    }
+    return shingles, len(toks)
+}
+
+func jaccard[K comparable, V any](a, b map[K]V) float64 {
+    if len(a) == 0 && len(b) == 0 {
+        return 1
+    }
+    common := 0
+    for k := range a {
+        if _, ok := b[k]; ok {
+            common++
+        }
+    }
+    return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// shortName - имя без получателя: "Server.Handle" -> "Handle"
+func shortName(name string) string {
+    return name[strings.LastIndex(name, ".")+1:]
+}
+
+// renameScore оценивает, что added - это removed под новым именем или в
+// другом пакете: функции сравниваются по сигнатуре и телу, типы - по
+// набору полей/методов. 0 - пара не рассматривается
+func renameScore(removed, added *goSymbol, oldSrc, newSrc *sourceLines) float64 {
+    if removed.kind != added.kind {
+        return 0
+    }
+    sameName := shortName(removed.name) == shortName(added.name)
+    switch removed.kind {
+    case "function", "method":
+        before, n := bodyShingles(oldSrc.span(removed.file, removed.line, removed.endLine), shortName(removed.name))
+        after, m := bodyShingles(newSrc.span(added.file, added.line, added.endLine), shortName(added.name))
+        body := jaccard(before, after)
+        sig := 0.0
+        if removed.signature == added.signature {
+            sig = 1
+        }
+        if sameName {
+            return 0.5 + 0.25*sig + 0.25*body
+        }
+        if n < minRenameTokens || m < minRenameTokens {
+            return 0
+        }
+        return 0.3*sig + 0.7*body
+    case "struct", "interface":
+        members := jaccard(removed.members, added.members)
+        if sameName {
+            return 0.5 + 0.5*members
+        }
+        if len(removed.members) < 2 {
+            return 0
+        }
+        return members
+    default:
+        // Переменные и константы узнаются только при переносе с тем же именем
+        if sameName && removed.signature == added.signature {
+            return 1
+        }
+        return 0
+    }
+}
+
+// detectRenames сопоставляет удалённые и добавленные символы по сходству;
+// пары выбираются жадно, от самых похожих
+func detectRenames(base *ProjectAnalysis, basePath string, head *ProjectAnalysis, headPath string) []SymbolRename {
+    before, after := goSymbols(base, false), goSymbols(head, false)
+    var removed, added []*goSymbol
+    for id, sym := range before {
+        if after[id] == nil {
+            removed = append(removed, sym)
+        }
+    }
+    for id, sym := range after {
+        if before[id] == nil {
+            added = append(added, sym)
+        }
+    }
+    oldSrc := &sourceLines{root: basePath, files: make(map[string][]string)}
+    newSrc := &sourceLines{root: headPath, files: make(map[string][]string)}
+
+    type candidate struct {
+        from, to *goSymbol
+        score    float64
+    }
+    var candidates []candidate
+    for _, r := range removed {
+        for _, a := range added {
+            if score := renameScore(r, a, oldSrc, newSrc); score >= renameThreshold {
+                candidates = append(candidates, candidate{r, a, score})
+            }
+        }
+    }
+    sort.Slice(candidates, func(i, j int) bool {
+        if candidates[i].score != candidates[j].score {
+            return candidates[i].score > candidates[j].score
+        }
+        return candidates[i].from.id+candidates[i].to.id < candidates[j].from.id+candidates[j].to.id
+    })
+
+    renames := []SymbolRename{}
+    used := make(map[string]bool)
+    for _, c := range candidates {
+        if used[c.from.id] || used[c.to.id] {
+            continue
+        }
+        used[c.from.id], used[c.to.id] = true, true
+        change := "renamed"
+        moved := strings.TrimSuffix(c.from.id, c.from.name) != strings.TrimSuffix(c.to.id, c.to.name)
+        switch {
+        case moved && shortName(c.from.name) == shortName(c.to.name):
+            change = "moved"
+        case moved:
+            change = "moved and renamed"
+        }
+        renames = append(renames, SymbolRename{
+            Kind:       c.to.kind,
+            From:       c.from.id,
+            To:         c.to.id,
+            Change:     change,
+            Similarity: math.Round(c.score*100) / 100,
+            File:       c.to.file,
+        })
+    }
+    sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+    return renames
+}
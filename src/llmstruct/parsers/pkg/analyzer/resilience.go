@@ -0,0 +1,299 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type ResiliencePattern struct {
+    Kind         string   `json:"kind"`
+    Mechanism    string   `json:"mechanism"`
+    Setting      string   `json:"setting,omitempty"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    Protects     []string `json:"protects"`
+}
+
+type resilienceAPI struct {
+    kind     string
+    pkgPath  string
+    names    []string
+}
+
+// Известные API таймаутов, retry, circuit breaker и rate limit
+var resilienceAPIs = []resilienceAPI{
+    {kind: "timeout", pkgPath: "context", names: []string{"WithTimeout", "WithDeadline", "WithTimeoutCause", "WithDeadlineCause"}},
+    {kind: "retry", pkgPath: "github.com/cenkalti/backoff", names: []string{"Retry", "RetryNotify", "RetryWithData", "RetryNotifyWithData"}},
+    {kind: "retry", pkgPath: "github.com/avast/retry-go", names: []string{"Do", "DoWithData"}},
+    {kind: "retry", pkgPath: "github.com/sethvargo/go-retry", names: []string{"Do"}},
+    {kind: "retry", pkgPath: "github.com/hashicorp/go-retryablehttp", names: []string{"NewClient"}},
+    {kind: "circuit_breaker", pkgPath: "github.com/sony/gobreaker", names: []string{"NewCircuitBreaker", "Execute"}},
+    {kind: "circuit_breaker", pkgPath: "github.com/afex/hystrix-go/hystrix", names: []string{"Do", "DoC", "Go", "GoC"}},
+    {kind: "circuit_breaker", pkgPath: "github.com/eapache/go-resiliency/breaker", names: []string{"New", "Run", "Go"}},
+    {kind: "rate_limit", pkgPath: "golang.org/x/time/rate", names: []string{"NewLimiter", "Wait", "WaitN", "Allow", "AllowN", "Reserve", "ReserveN"}},
+    {kind: "rate_limit", pkgPath: "go.uber.org/ratelimit", names: []string{"New", "Take"}},
+}
+
+// Пакеты, вызовы которых считаются внешними (сеть, БД, RPC)
+var externalCallPackages = []string{
+    "net/http",
+    "net",
+    "database/sql",
+    "google.golang.org/grpc",
+    "github.com/jackc/pgx",
+    "gorm.io/gorm",
+    "github.com/jmoiron/sqlx",
+    "github.com/redis/go-redis",
+    "github.com/go-redis/redis",
+    "github.com/aws/aws-sdk-go",
+}
+
+func hasPathPrefix(pkgPath, prefix string) bool {
+    return pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/")
+}
+
+func resilienceKind(fn *types.Func) (string, bool) {
+    if fn.Pkg() == nil {
+        return "", false
+    }
+    for _, api := range resilienceAPIs {
+        // Версии модулей (/v4, /v5) не входят в префикс
+        if !hasPathPrefix(fn.Pkg().Path(), api.pkgPath) {
+            continue
+        }
+        for _, name := range api.names {
+            if fn.Name() == name {
+                return api.kind, true
+            }
+        }
+    }
+    return "", false
+}
+
+// isExternalCall считает вызов внешним, если он идёт в сетевой/БД пакет
+// либо в функцию другого пакета, принимающую context.Context первым
+func isExternalCall(pkg *packages.Package, fn *types.Func) bool {
+    if fn.Pkg() == nil || fn.Pkg() == pkg.Types || fn.Pkg().Path() == "context" {
+        return false
+    }
+    if _, ok := resilienceKind(fn); ok {
+        return false
+    }
+    for _, prefix := range externalCallPackages {
+        if hasPathPrefix(fn.Pkg().Path(), prefix) {
+            return true
+        }
+    }
+    sig, ok := fn.Type().(*types.Signature)
+    return ok && sig.Params().Len() > 0 && isContextType(sig.Params().At(0).Type())
+}
+
+// externalCalls собирает внешние вызовы внутри узла; filter (если задан)
+// отбирает только вызовы, использующие нужные аргументы
+func externalCalls(pkg *packages.Package, root ast.Node, filter func(call *ast.CallExpr) bool) []string {
+    seen := make(map[string]bool)
+    calls := []string{}
+    ast.Inspect(root, func(n ast.Node) bool {
+        call, ok := n.(*ast.CallExpr)
+        if !ok {
+            return true
+        }
+        fn := calleeFunc(pkg.TypesInfo, call)
+        if fn == nil || !isExternalCall(pkg, fn) {
+            return true
+        }
+        if filter != nil && !filter(call) {
+            return true
+        }
+        if name := fn.FullName(); !seen[name] {
+            seen[name] = true
+            calls = append(calls, name)
+        }
+        return true
+    })
+    sort.Strings(calls)
+    return calls
+}
+
+// usesObject проверяет, ссылается ли выражение на один из объектов objs
+func usesObject(info *types.Info, node ast.Node, objs map[types.Object]bool) bool {
+    found := false
+    ast.Inspect(node, func(n ast.Node) bool {
+        if id, ok := n.(*ast.Ident); ok && objs[info.Uses[id]] {
+            found = true
+        }
+        return !found
+    })
+    return found
+}
+
+// derivedObjects возвращает объект и все переменные функции, значения
+// которых получены из него (req := NewRequestWithContext(ctx, ...))
+func derivedObjects(info *types.Info, body *ast.BlockStmt, obj types.Object) map[types.Object]bool {
+    objs := map[types.Object]bool{obj: true}
+    for changed := true; changed; {
+        changed = false
+        ast.Inspect(body, func(n ast.Node) bool {
+            assign, ok := n.(*ast.AssignStmt)
+            if !ok {
+                return true
+            }
+            tainted := false
+            for _, rhs := range assign.Rhs {
+                if usesObject(info, rhs, objs) {
+                    tainted = true
+                }
+            }
+            if !tainted {
+                return true
+            }
+            for _, lhs := range assign.Lhs {
+                if id, ok := lhs.(*ast.Ident); ok {
+                    if o := info.ObjectOf(id); o != nil && !objs[o] {
+                        objs[o] = true
+                        changed = true
+                    }
+                }
+            }
+            return true
+        })
+    }
+    return objs
+}
+
+// httpClientTimeout распознаёт литерал http.Client{Timeout: ...}
+func httpClientTimeout(info *types.Info, lit *ast.CompositeLit) (string, bool) {
+    tv, ok := info.Types[lit]
+    if !ok || types.TypeString(tv.Type, nil) != "net/http.Client" {
+        return "", false
+    }
+    for _, elt := range lit.Elts {
+        kv, ok := elt.(*ast.KeyValueExpr)
+        if !ok {
+            continue
+        }
+        if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Timeout" {
+            return types.ExprString(kv.Value), true
+        }
+    }
+    return "", false
+}
+
+func extractResilience(pkg *packages.Package, projectPath string) []ResiliencePattern {
+    if pkg.TypesInfo == nil {
+        return nil
+    }
+
+    var patterns []ResiliencePattern
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            fd, ok := decl.(*ast.FuncDecl)
+            if !ok || fd.Body == nil {
+                continue
+            }
+
+            // Контексты с таймаутом: ctx, cancel := context.WithTimeout(...)
+            timeoutCtx := make(map[*ast.CallExpr]types.Object)
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                assign, ok := n.(*ast.AssignStmt)
+                if !ok || len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+                    return true
+                }
+                call, ok := assign.Rhs[0].(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                if id, ok := assign.Lhs[0].(*ast.Ident); ok {
+                    if obj := pkg.TypesInfo.ObjectOf(id); obj != nil {
+                        timeoutCtx[call] = obj
+                    }
+                }
+                return true
+            })
+
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                call, ok := n.(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                fn := calleeFunc(pkg.TypesInfo, call)
+                if fn == nil {
+                    return true
+                }
+                kind, ok := resilienceKind(fn)
+                if !ok {
+                    return true
+                }
+
+                pos := pkg.Fset.Position(call.Pos())
+                pattern := ResiliencePattern{
+                    Kind:      kind,
+                    Mechanism: fn.FullName(),
+                    File:      relativePath(projectPath, pos.Filename),
+                    Line:      pos.Line,
+                    Function:  funcID(pkg, fd),
+                    Protects:  []string{},
+                }
+
+                if kind == "timeout" {
+                    if len(call.Args) > 1 {
+                        pattern.Setting = types.ExprString(call.Args[1])
+                    }
+                    if obj, ok := timeoutCtx[call]; ok {
+                        objs := derivedObjects(pkg.TypesInfo, fd.Body, obj)
+                        pattern.Protects = externalCalls(pkg, fd.Body, func(c *ast.CallExpr) bool {
+                            return usesObject(pkg.TypesInfo, c, objs)
+                        })
+                    }
+                } else {
+                    // Защищаемые вызовы внутри переданных замыканий, иначе -
+                    // все внешние вызовы функции
+                    var closures []ast.Node
+                    for _, arg := range call.Args {
+                        if lit, ok := arg.(*ast.FuncLit); ok {
+                            closures = append(closures, lit)
+                        }
+                    }
+                    if len(closures) == 0 {
+                        closures = append(closures, fd.Body)
+                    }
+                    for _, closure := range closures {
+                        pattern.Protects = append(pattern.Protects, externalCalls(pkg, closure, nil)...)
+                    }
+                }
+
+                patterns = append(patterns, pattern)
+                return true
+            })
+        }
+
+        // Таймауты HTTP-клиентов, в том числе на уровне пакета
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            lit, ok := n.(*ast.CompositeLit)
+            if !ok {
+                return true
+            }
+            setting, ok := httpClientTimeout(pkg.TypesInfo, lit)
+            if !ok {
+                return true
+            }
+            pos := pkg.Fset.Position(lit.Pos())
+            patterns = append(patterns, ResiliencePattern{
+                Kind:      "timeout",
+                Mechanism: "net/http.Client.Timeout",
+                Setting:   setting,
+                File:      relativePath(projectPath, pos.Filename),
+                Line:      pos.Line,
+                Function:  funcID(pkg, decl),
+                Protects:  []string{},
+            })
+            return true
+        })
+    }
+    return patterns
+}
@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/token"
+    "go/types"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// ResourceLeak - открытый ресурс, Close которого не вызывается на всех
+// путях функции (эвристика: defer, явный Close и передача владения)
+type ResourceLeak struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+    Variable     string   `json:"variable"`
+    Resource     string   `json:"resource"`
+    Open         string   `json:"open"`
+    Reason       string   `json:"reason"`
+}
+
+// Типы ресурсов и что нужно закрыть
+var closableTypes = map[string]string{
+    "*os.File":                      "Close",
+    "*net/http.Response":            "Body.Close",
+    "*database/sql.Rows":            "Close",
+    "*database/sql.Stmt":            "Close",
+    "*database/sql.Conn":            "Close",
+    "*github.com/jmoiron/sqlx.Rows": "Close",
+    "*github.com/jmoiron/sqlx.Stmt": "Close",
+}
+
+type openedResource struct {
+    obj      types.Object
+    errObj   types.Object
+    call     *ast.CallExpr
+    resource string
+}
+
+type resourceLeakFinder struct {
+    info   *types.Info
+    body   *ast.BlockStmt
+    module string
+}
+
+// isClose - вызов v.Close() или v.Body.Close() для объекта ресурса
+func (f *resourceLeakFinder) isClose(call *ast.CallExpr, obj types.Object) bool {
+    sel, ok := call.Fun.(*ast.SelectorExpr)
+    if !ok || sel.Sel.Name != "Close" {
+        return false
+    }
+    id := rootIdent(sel.X)
+    return id != nil && f.info.Uses[id] == obj
+}
+
+// escapes сообщает, передаётся ли владение ресурсом: возврат, сохранение
+// в другую переменную или структуру, отправка в канал, передача функции
+// проекта или сторонней библиотеки (функции стандартной библиотеки
+// ресурс только читают)
+func (f *resourceLeakFinder) escapes(obj types.Object) bool {
+    escaped := false
+    var stack []ast.Node
+    ast.Inspect(f.body, func(n ast.Node) bool {
+        if n == nil {
+            stack = stack[:len(stack)-1]
+            return false
+        }
+        stack = append(stack, n)
+        id, ok := n.(*ast.Ident)
+        if !ok || f.info.Uses[id] != obj || len(stack) < 2 {
+            return !escaped
+        }
+        switch parent := stack[len(stack)-2].(type) {
+        case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.SendStmt:
+            escaped = true
+        case *ast.AssignStmt:
+            for _, rhs := range parent.Rhs {
+                if rhs == id {
+                    escaped = true
+                }
+            }
+        case *ast.CallExpr:
+            if parent.Fun == id {
+                break
+            }
+            fn := calleeFunc(f.info, parent)
+            if fn == nil || fn.Pkg() == nil {
+                escaped = true
+                break
+            }
+            first, _, _ := strings.Cut(fn.Pkg().Path(), "/")
+            if strings.Contains(first, ".") || f.module != "" && hasPathPrefix(fn.Pkg().Path(), f.module) {
+                escaped = true
+            }
+        }
+        return !escaped
+    })
+    return escaped
+}
+
+// errCheckReturn - return внутри if err != nil сразу после открытия:
+// ресурса на этом пути нет
+func (f *resourceLeakFinder) errCheckReturn(errObj types.Object, ret *ast.ReturnStmt) bool {
+    if errObj == nil {
+        return false
+    }
+    inside := false
+    ast.Inspect(f.body, func(n ast.Node) bool {
+        ifStmt, ok := n.(*ast.IfStmt)
+        if !ok || inside {
+            return !inside
+        }
+        if ret.Pos() >= ifStmt.Body.Pos() && ret.End() <= ifStmt.Body.End() && usesObject(f.info, ifStmt.Cond, map[types.Object]bool{errObj: true}) {
+            inside = true
+        }
+        return true
+    })
+    return inside
+}
+
+func (f *resourceLeakFinder) check(r openedResource) string {
+    if f.escapes(r.obj) {
+        return ""
+    }
+    deferred := false
+    firstClose := token.NoPos
+    var returns []*ast.ReturnStmt
+    ast.Inspect(f.body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.DeferStmt:
+            ast.Inspect(x, func(m ast.Node) bool {
+                if call, ok := m.(*ast.CallExpr); ok && f.isClose(call, r.obj) {
+                    deferred = true
+                }
+                return !deferred
+            })
+            return false
+        case *ast.CallExpr:
+            if f.isClose(x, r.obj) && (firstClose == token.NoPos || x.Pos() < firstClose) {
+                firstClose = x.Pos()
+            }
+        case *ast.ReturnStmt:
+            if x.Pos() > r.call.End() {
+                returns = append(returns, x)
+            }
+        }
+        return true
+    })
+    if deferred {
+        return ""
+    }
+    if firstClose == token.NoPos {
+        return closableTypes[r.resource] + " is never called"
+    }
+    for _, ret := range returns {
+        if ret.Pos() < firstClose && !f.errCheckReturn(r.errObj, ret) {
+            return "return before " + closableTypes[r.resource] + " skips it; use defer"
+        }
+    }
+    return ""
+}
+
+// extractResourceLeaks ищет файлы, тела HTTP-ответов и ресурсы
+// database/sql, которые функция открывает, но не закрывает на всех путях
+func extractResourceLeaks(pkg *packages.Package, projectPath, module string) []ResourceLeak {
+    if pkg.TypesInfo == nil {
+        return nil
+    }
+    var leaks []ResourceLeak
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            fd, ok := decl.(*ast.FuncDecl)
+            if !ok || fd.Body == nil {
+                continue
+            }
+            f := &resourceLeakFinder{info: pkg.TypesInfo, body: fd.Body, module: module}
+            var opened []openedResource
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                assign, ok := n.(*ast.AssignStmt)
+                if !ok || len(assign.Rhs) != 1 {
+                    return true
+                }
+                call, ok := assign.Rhs[0].(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                var errObj types.Object
+                if id, ok := assign.Lhs[len(assign.Lhs)-1].(*ast.Ident); ok {
+                    if obj := pkg.TypesInfo.ObjectOf(id); obj != nil && isErrorType(obj.Type()) {
+                        errObj = obj
+                    }
+                }
+                for _, lhs := range assign.Lhs {
+                    id, ok := lhs.(*ast.Ident)
+                    if !ok || id.Name == "_" {
+                        continue
+                    }
+                    obj := pkg.TypesInfo.ObjectOf(id)
+                    if obj == nil {
+                        continue
+                    }
+                    resource := types.TypeString(obj.Type(), nil)
+                    if _, ok := closableTypes[resource]; ok {
+                        opened = append(opened, openedResource{obj: obj, errObj: errObj, call: call, resource: resource})
+                    }
+                }
+                return true
+            })
+            for _, r := range opened {
+                reason := f.check(r)
+                if reason == "" {
+                    continue
+                }
+                pos := pkg.Fset.Position(r.call.Pos())
+                leaks = append(leaks, ResourceLeak{
+                    File:     relativePath(projectPath, pos.Filename),
+                    Line:     pos.Line,
+                    Function: funcID(pkg, fd),
+                    Variable: r.obj.Name(),
+                    Resource: strings.TrimPrefix(r.resource, "*"),
+                    Open:     types.ExprString(r.call.Fun),
+                    Reason:   reason,
+                })
+            }
+        }
+    }
+    return leaks
+}
@@ -0,0 +1,268 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+type ReviewSymbol struct {
+    ID           string   `json:"id"`
+    Kind         string   `json:"kind"`
+    Change       string   `json:"change"`
+    RenamedFrom  string   `json:"renamed_from,omitempty"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    EndLine      int      `json:"end_line"`
+    Signature    string   `json:"signature,omitempty"`
+    Body         string   `json:"body"`
+    Callers      []string `json:"callers"`
+    Callees      []string `json:"callees"`
+}
+
+type ReviewTest struct {
+    Name         string   `json:"name"`
+    Kind         string   `json:"kind"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Covers       []string `json:"covers"`
+    Body         string   `json:"body"`
+}
+
+// ReviewFinding - диагностика, попавшая в изменённый символ
+type ReviewFinding struct {
+    Rule         string   `json:"rule"`
+    Symbol       string   `json:"symbol"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Message      string   `json:"message"`
+}
+
+// ReviewPack - всё, что нужно для ревью изменения и ничего лишнего:
+// изменённые символы с телами, их прямые вызывающие и вызываемые,
+// связанные тесты, диагностики и несовместимые изменения API/формата
+type ReviewPack struct {
+    Base         string          `json:"base"`
+    BaseCommit   string          `json:"base_commit"`
+    Symbols      []ReviewSymbol  `json:"symbols"`
+    Tests        []ReviewTest    `json:"tests"`
+    Findings     []ReviewFinding `json:"findings"`
+    APIChanges   []APIChange     `json:"api_changes"`
+    WireChanges  []WireChange    `json:"wire_changes"`
+}
+
+type lineRange struct {
+    start, end int
+}
+
+// changedLines возвращает изменённые строки рабочего дерева относительно
+// commit по файлам (пути относительно проекта); удаление строк отмечает
+// соседние строки
+func changedLines(projectPath, commit string) (map[string][]lineRange, error) {
+    out, err := runGit(projectPath, "diff", "--no-color", "--no-prefix", "--relative", "-U0", commit, "--", ".")
+    if err != nil {
+        return nil, err
+    }
+    changes := make(map[string][]lineRange)
+    file := ""
+    sc := bufio.NewScanner(bytes.NewReader(out))
+    sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    for sc.Scan() {
+        line := sc.Text()
+        switch {
+        case strings.HasPrefix(line, "+++ "):
+            file = strings.TrimPrefix(line, "+++ ")
+            if file == "/dev/null" {
+                file = ""
+            }
+        case strings.HasPrefix(line, "@@ ") && file != "":
+            // @@ -a,b +c,d @@
+            fields := strings.Fields(line)
+            if len(fields) < 3 {
+                continue
+            }
+            startStr, countStr, hasCount := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+            start, _ := strconv.Atoi(startStr)
+            count := 1
+            if hasCount {
+                count, _ = strconv.Atoi(countStr)
+            }
+            r := lineRange{start, start + count - 1}
+            if count == 0 {
+                r = lineRange{start, start + 1}
+            }
+            changes[file] = append(changes[file], r)
+        }
+    }
+    return changes, sc.Err()
+}
+
+func overlaps(ranges []lineRange, start, end int) bool {
+    for _, r := range ranges {
+        if r.start <= end && start <= r.end {
+            return true
+        }
+    }
+    return false
+}
+
+// reviewFindings собирает диагностики в пределах изменённых символов
+func reviewFindings(diagnostics *Diagnostics, symbols []ReviewSymbol) []ReviewFinding {
+    findings := []ReviewFinding{}
+    if diagnostics == nil {
+        return findings
+    }
+    add := func(rule, file string, line int, message string) {
+        for _, sym := range symbols {
+            if sym.Change != "removed" && sym.File == file && sym.Line <= line && line <= sym.EndLine {
+                findings = append(findings, ReviewFinding{Rule: rule, Symbol: sym.ID, File: file, Line: line, Message: message})
+                return
+            }
+        }
+    }
+    for _, leak := range diagnostics.GoroutineLeaks {
+        add("goroutine_leak", leak.File, leak.Line, fmt.Sprintf("goroutine %s may leak: %s", leak.Target, strings.Join(leak.Reasons, "; ")))
+    }
+    for _, race := range diagnostics.RaceCandidates {
+        add("race_candidate", race.File, race.Line, fmt.Sprintf("%s priority race on %s", race.Priority, race.Variable))
+    }
+    for _, dead := range diagnostics.DeadBranches {
+        message := dead.Reason
+        if dead.Condition != "" {
+            message = fmt.Sprintf("condition %s is always %s: %s", dead.Condition, dead.Value, dead.Reason)
+        }
+        add("dead_branch", dead.File, dead.Line, message)
+    }
+    for _, leak := range diagnostics.ResourceLeaks {
+        add("resource_leak", leak.File, leak.Line, fmt.Sprintf("%s from %s: %s", leak.Variable, leak.Open, leak.Reason))
+    }
+    return findings
+}
+
+func newReviewSymbol(sym *goSymbol, change string, src *sourceLines, refs *refIndex) ReviewSymbol {
+    review := ReviewSymbol{
+        ID:      sym.id,
+        Kind:    sym.kind,
+        Change:  change,
+        File:    sym.file,
+        Line:    sym.line,
+        EndLine: sym.endLine,
+        Body:    src.span(sym.file, sym.line, sym.endLine),
+        Callers: []string{},
+        Callees: []string{},
+    }
+    if sym.kind == "function" || sym.kind == "method" {
+        review.Signature = sym.signature
+    }
+    if refs != nil {
+        review.Callers = append(review.Callers, refs.callers(sym.id)...)
+        review.Callees = append(review.Callees, refs.callees(sym.id)...)
+    }
+    return review
+}
+
+// buildReviewPack сравнивает рабочее дерево с точкой ответвления от base
+// (git merge-base base HEAD), как это делает ревью pull request
+func buildReviewPack(projectPath, base string, opts Options) (*ReviewPack, error) {
+    out, err := runGit(projectPath, "merge-base", base, "HEAD")
+    if err != nil {
+        return nil, err
+    }
+    mergeBase := strings.TrimSpace(string(out))
+
+    head := analyzeProject(context.Background(), projectPath, opts)
+    baseAnalysis, commit, basePath, cleanup, err := analyzeRevision(projectPath, mergeBase, opts)
+    defer cleanup()
+    if err != nil {
+        return nil, err
+    }
+    changes, err := changedLines(projectPath, commit)
+    if err != nil {
+        return nil, err
+    }
+    diff := diffAnalyses(base, commit, baseAnalysis, basePath, head, projectPath)
+
+    renamedFrom := make(map[string]string)
+    renamedTo := make(map[string]bool)
+    for _, r := range diff.Renames {
+        renamedFrom[r.To] = r.From
+        renamedTo[r.From] = true
+    }
+    headSyms, baseSyms := goSymbols(head, false), goSymbols(baseAnalysis, false)
+    headSrc := &sourceLines{root: projectPath, files: make(map[string][]string)}
+    baseSrc := &sourceLines{root: basePath, files: make(map[string][]string)}
+
+    pack := &ReviewPack{
+        Base:        base,
+        BaseCommit:  commit,
+        Symbols:     []ReviewSymbol{},
+        Tests:       []ReviewTest{},
+        APIChanges:  diff.APIChanges,
+        WireChanges: diff.WireChanges,
+    }
+    var changed []*goSymbol
+    for id, sym := range headSyms {
+        change := ""
+        switch {
+        case renamedFrom[id] != "":
+            change = "renamed"
+        case baseSyms[id] == nil:
+            change = "added"
+        case overlaps(changes[sym.file], sym.line, sym.endLine):
+            change = "modified"
+        default:
+            continue
+        }
+        review := newReviewSymbol(sym, change, headSrc, head.refs)
+        review.RenamedFrom = renamedFrom[id]
+        pack.Symbols = append(pack.Symbols, review)
+        changed = append(changed, sym)
+    }
+    for id, sym := range baseSyms {
+        if headSyms[id] == nil && !renamedTo[id] {
+            pack.Symbols = append(pack.Symbols, newReviewSymbol(sym, "removed", baseSrc, baseAnalysis.refs))
+        }
+    }
+    sort.Slice(pack.Symbols, func(i, j int) bool { return pack.Symbols[i].ID < pack.Symbols[j].ID })
+
+    for _, test := range collectTestFuncs(projectPath) {
+        var covers []string
+        for _, sym := range changed {
+            if test.references(sym) {
+                covers = append(covers, sym.id)
+            }
+        }
+        if len(covers) == 0 {
+            continue
+        }
+        sort.Strings(covers)
+        pack.Tests = append(pack.Tests, ReviewTest{
+            Name:   test.name,
+            Kind:   test.kind,
+            File:   test.file,
+            Line:   test.line,
+            Covers: covers,
+            Body:   headSrc.span(test.file, test.line, test.endLine),
+        })
+    }
+    pack.Findings = reviewFindings(head.Diagnostics, pack.Symbols)
+    return pack, nil
+}
+
+func runReviewPack(args []string) {
+    fs := newCommandFlags("review-pack", "[flags] [project_path]")
+    base := fs.String("base", "main", "branch or revision the change is reviewed against (compared from the merge base)")
+    projectPath := fs.parse(args)
+
+    opts := defaultOptions()
+    opts.Diagnostics = true
+    pack, err := buildReviewPack(projectPath, *base, opts)
+    if err != nil {
+        fatal("failed to build review pack", "base", *base, "error", err)
+    }
+    fs.write(pack)
+}
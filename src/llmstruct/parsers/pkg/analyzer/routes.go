@@ -0,0 +1,535 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/types"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type Middleware struct {
+    Name         string   `json:"name"`
+    Scope        string   `json:"scope"`
+}
+
+type Route struct {
+    Method       string       `json:"method"`
+    Path         string       `json:"path"`
+    Handler      string       `json:"handler"`
+    Router       string       `json:"router"`
+    Middleware   []Middleware `json:"middleware"`
+    File         string       `json:"file"`
+    Line         int          `json:"line"`
+    Function     string       `json:"function,omitempty"`
+    Request      []string     `json:"request,omitempty"`
+    Response     []string     `json:"response,omitempty"`
+}
+
+// routerNode - роутер или группа маршрутов с префиксом и middleware
+type routerNode struct {
+    lib       string
+    prefix    string
+    parent    *routerNode
+    parentMws int
+    mws       []string
+    wrappers  []string
+}
+
+type pendingRoute struct {
+    node     *routerNode
+    snapshot int
+    routeMws []string
+    handler  handlerRef
+    route    Route
+}
+
+type routeBuilder struct {
+    projectPath string
+    nodes       map[types.Object]*routerNode
+    defaultMux  *routerNode
+    routes      []pendingRoute
+    contracts   *contractResolver
+}
+
+func newRouteBuilder(projectPath string) *routeBuilder {
+    return &routeBuilder{
+        projectPath: projectPath,
+        nodes:       make(map[types.Object]*routerNode),
+        defaultMux:  &routerNode{lib: "net/http"},
+        contracts:   newContractResolver(),
+    }
+}
+
+// Пакеты роутеров; версии модулей (/v5) учитываются префиксом
+var routerPackages = []struct {
+    lib     string
+    pkgPath string
+}{
+    {"net/http", "net/http"},
+    {"gorilla/mux", "github.com/gorilla/mux"},
+    {"chi", "github.com/go-chi/chi"},
+    {"gin", "github.com/gin-gonic/gin"},
+    {"echo", "github.com/labstack/echo"},
+}
+
+var httpMethods = map[string]string{
+    "Get": "GET", "Post": "POST", "Put": "PUT", "Patch": "PATCH", "Delete": "DELETE",
+    "Head": "HEAD", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE",
+    "GET": "GET", "POST": "POST", "PUT": "PUT", "PATCH": "PATCH", "DELETE": "DELETE",
+    "HEAD": "HEAD", "OPTIONS": "OPTIONS", "CONNECT": "CONNECT", "TRACE": "TRACE", "Any": "ANY",
+}
+
+func routerLibrary(t types.Type) string {
+    if t == nil {
+        return ""
+    }
+    if ptr, ok := t.(*types.Pointer); ok {
+        t = ptr.Elem()
+    }
+    named, ok := t.(*types.Named)
+    if !ok || named.Obj().Pkg() == nil {
+        return ""
+    }
+    path := named.Obj().Pkg().Path()
+    for _, rp := range routerPackages {
+        if path == rp.pkgPath || strings.HasPrefix(path, rp.pkgPath+"/") {
+            if rp.lib == "net/http" && named.Obj().Name() != "ServeMux" {
+                return ""
+            }
+            return rp.lib
+        }
+    }
+    return ""
+}
+
+// Для gin и chi middleware действует только на маршруты, объявленные
+// после Use; gorilla и echo применяют его ко всему роутеру
+func orderedMiddleware(lib string) bool {
+    return lib == "gin" || lib == "chi"
+}
+
+func (b *routeBuilder) nodeFor(info *types.Info, expr ast.Expr) *routerNode {
+    obj := varObject(info, expr)
+    if obj == nil {
+        return nil
+    }
+    if node, ok := b.nodes[obj]; ok {
+        return node
+    }
+    lib := routerLibrary(obj.Type())
+    if lib == "" {
+        return nil
+    }
+    node := &routerNode{lib: lib}
+    b.nodes[obj] = node
+    return node
+}
+
+func (b *routeBuilder) child(parent *routerNode, prefix string, mws []string) *routerNode {
+    return &routerNode{
+        lib:       parent.lib,
+        prefix:    prefix,
+        parent:    parent,
+        parentMws: len(parent.mws),
+        mws:       mws,
+    }
+}
+
+// handlerName возвращает полное имя функции-обработчика либо выражение
+func handlerName(pkg *packages.Package, expr ast.Expr) string {
+    switch e := expr.(type) {
+    case *ast.FuncLit:
+        pos := pkg.Fset.Position(e.Pos())
+        return fmt.Sprintf("func literal (%s:%d)", pos.Filename[strings.LastIndex(pos.Filename, "/")+1:], pos.Line)
+    case *ast.Ident, *ast.SelectorExpr:
+        var id *ast.Ident
+        if sel, ok := e.(*ast.SelectorExpr); ok {
+            id = sel.Sel
+        } else {
+            id = e.(*ast.Ident)
+        }
+        if fn, ok := pkg.TypesInfo.Uses[id].(*types.Func); ok {
+            return fn.FullName()
+        }
+    }
+    return types.ExprString(expr)
+}
+
+// unwrapHandler снимает обёртки mw(next) и преобразования http.HandlerFunc(h),
+// возвращая внутренний обработчик и цепочку middleware снаружи внутрь
+func unwrapHandler(pkg *packages.Package, expr ast.Expr) (ast.Expr, []string) {
+    var mws []string
+    for {
+        call, ok := expr.(*ast.CallExpr)
+        if !ok || len(call.Args) == 0 {
+            return expr, mws
+        }
+        if tv, ok := pkg.TypesInfo.Types[call.Fun]; ok && tv.IsType() {
+            expr = call.Args[0]
+            continue
+        }
+        fn := calleeFunc(pkg.TypesInfo, call)
+        if fn == nil {
+            return expr, mws
+        }
+        // Обёрткой считаем функцию, последний аргумент которой - обработчик
+        last := call.Args[len(call.Args)-1]
+        lastType := pkg.TypesInfo.TypeOf(last)
+        if lastType == nil || !isHandlerType(lastType) {
+            return expr, mws
+        }
+        mws = append(mws, fn.FullName())
+        expr = last
+    }
+}
+
+// isHandlerType: http.Handler, функции-обработчики и типы с методом ServeHTTP
+func isHandlerType(t types.Type) bool {
+    switch types.TypeString(t, nil) {
+    case "net/http.Handler", "net/http.HandlerFunc", "func(net/http.ResponseWriter, *net/http.Request)":
+        return true
+    }
+    return types.NewMethodSet(t).Lookup(nil, "ServeHTTP") != nil
+}
+
+func splitPattern(pattern string) (string, string) {
+    // Шаблоны Go 1.22: "GET /users/{id}"
+    if method, path, ok := strings.Cut(pattern, " "); ok && !strings.HasPrefix(method, "/") {
+        return strings.ToUpper(method), strings.TrimSpace(path)
+    }
+    return "ANY", pattern
+}
+
+func (b *routeBuilder) addRoute(pkg *packages.Package, decl *ast.FuncDecl, call *ast.CallExpr, node *routerNode, extra []string, method, path string, handler ast.Expr, routeMws []string) {
+    pos := pkg.Fset.Position(call.Pos())
+    inner, wrapped := unwrapHandler(pkg, handler)
+    b.routes = append(b.routes, pendingRoute{
+        node:     node,
+        snapshot: len(node.mws),
+        routeMws: append(append(append([]string{}, extra...), routeMws...), wrapped...),
+        handler:  handlerRef{info: pkg.TypesInfo, expr: inner},
+        route: Route{
+            Method:   method,
+            Path:     path,
+            Handler:  handlerName(pkg, inner),
+            Router:   node.lib,
+            File:     relativePath(b.projectPath, pos.Filename),
+            Line:     pos.Line,
+            Function: funcID(pkg, decl),
+        },
+    })
+}
+
+func exprNames(pkg *packages.Package, exprs []ast.Expr) []string {
+    var names []string
+    for _, expr := range exprs {
+        names = append(names, handlerName(pkg, expr))
+    }
+    return names
+}
+
+// receiverNode определяет роутер, на котором вызывается метод; r.With(mw)
+// даёт дополнительные middleware уровня маршрута
+func (b *routeBuilder) receiverNode(pkg *packages.Package, expr ast.Expr) (*routerNode, []string) {
+    if call, ok := expr.(*ast.CallExpr); ok {
+        if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "With" {
+            node, extra := b.receiverNode(pkg, sel.X)
+            return node, append(extra, exprNames(pkg, call.Args)...)
+        }
+        return nil, nil
+    }
+    return b.nodeFor(pkg.TypesInfo, expr), nil
+}
+
+// groupFromCall распознаёт создание группы: r.Group("/api", mw),
+// r.PathPrefix("/api").Subrouter(), r.With(mw)
+func (b *routeBuilder) groupFromCall(pkg *packages.Package, call *ast.CallExpr) *routerNode {
+    sel, ok := call.Fun.(*ast.SelectorExpr)
+    if !ok {
+        return nil
+    }
+    switch sel.Sel.Name {
+    case "Group":
+        parent, _ := b.receiverNode(pkg, sel.X)
+        if parent == nil || len(call.Args) == 0 {
+            return nil
+        }
+        prefix, ok := constString(pkg.TypesInfo, call.Args[0])
+        if !ok {
+            return nil
+        }
+        return b.child(parent, prefix, exprNames(pkg, call.Args[1:]))
+    case "With":
+        parent, extra := b.receiverNode(pkg, sel.X)
+        if parent == nil {
+            return nil
+        }
+        return b.child(parent, "", append(extra, exprNames(pkg, call.Args)...))
+    case "Subrouter":
+        inner, ok := sel.X.(*ast.CallExpr)
+        if !ok {
+            return nil
+        }
+        innerSel, ok := inner.Fun.(*ast.SelectorExpr)
+        if !ok || innerSel.Sel.Name != "PathPrefix" || len(inner.Args) == 0 {
+            return nil
+        }
+        parent := b.nodeFor(pkg.TypesInfo, innerSel.X)
+        prefix, ok := constString(pkg.TypesInfo, inner.Args[0])
+        if parent == nil || !ok {
+            return nil
+        }
+        return b.child(parent, prefix, nil)
+    }
+    return nil
+}
+
+func (b *routeBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    info := pkg.TypesInfo
+    b.contracts.addPackage(pkg)
+
+    for _, file := range pkg.Syntax {
+        // gorilla: r.HandleFunc(...).Methods("GET") - методы у внешнего вызова
+        gorillaMethods := make(map[*ast.CallExpr][]string)
+
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            switch node := n.(type) {
+            case *ast.AssignStmt:
+                if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+                    return true
+                }
+                call, ok := node.Rhs[0].(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                if group := b.groupFromCall(pkg, call); group != nil {
+                    if obj := varObject(info, node.Lhs[0]); obj != nil {
+                        b.nodes[obj] = group
+                    }
+                } else if fn := calleeFunc(info, call); fn != nil && fn.FullName() == "github.com/gin-gonic/gin.Default" {
+                    // gin.Default() подключает Logger и Recovery
+                    if obj := varObject(info, node.Lhs[0]); obj != nil {
+                        b.nodes[obj] = &routerNode{lib: "gin", mws: []string{"github.com/gin-gonic/gin.Logger", "github.com/gin-gonic/gin.Recovery"}}
+                    }
+                }
+                return true
+
+            case *ast.CompositeLit:
+                // http.Server{Handler: mw(mux)}
+                if tv, ok := info.Types[node]; !ok || types.TypeString(tv.Type, nil) != "net/http.Server" {
+                    return true
+                }
+                for _, elt := range node.Elts {
+                    if kv, ok := elt.(*ast.KeyValueExpr); ok {
+                        if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Handler" {
+                            b.addWrappers(pkg, kv.Value)
+                        }
+                    }
+                }
+                return true
+
+            case *ast.CallExpr:
+                b.visitCall(pkg, decl, node, gorillaMethods)
+            }
+            return true
+        })
+    }
+}
+
+// addWrappers привязывает обёртки вокруг роутера (logging(mux)) как
+// глобальные middleware
+func (b *routeBuilder) addWrappers(pkg *packages.Package, handler ast.Expr) {
+    inner, wrapped := unwrapHandler(pkg, handler)
+    if node := b.nodeFor(pkg.TypesInfo, inner); node != nil {
+        node.wrappers = append(node.wrappers, wrapped...)
+    }
+}
+
+func (b *routeBuilder) visitCall(pkg *packages.Package, decl *ast.FuncDecl, call *ast.CallExpr, gorillaMethods map[*ast.CallExpr][]string) {
+    info := pkg.TypesInfo
+    fn := calleeFunc(info, call)
+
+    // Функции пакета net/http: http.HandleFunc, http.ListenAndServe
+    if fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "net/http" && fn.Type().(*types.Signature).Recv() == nil {
+        switch fn.Name() {
+        case "Handle", "HandleFunc":
+            if len(call.Args) == 2 {
+                if pattern, ok := constString(info, call.Args[0]); ok {
+                    method, path := splitPattern(pattern)
+                    b.addRoute(pkg, decl, call, b.defaultMux, nil, method, path, call.Args[1], nil)
+                }
+            }
+        case "ListenAndServe", "ListenAndServeTLS":
+            // nil означает DefaultServeMux без обёрток
+            if len(call.Args) >= 2 {
+                b.addWrappers(pkg, call.Args[len(call.Args)-1])
+            }
+        }
+        return
+    }
+
+    sel, ok := call.Fun.(*ast.SelectorExpr)
+    if !ok {
+        return
+    }
+
+    if sel.Sel.Name == "Methods" {
+        if inner, ok := sel.X.(*ast.CallExpr); ok {
+            for _, arg := range call.Args {
+                if m, ok := constString(info, arg); ok {
+                    gorillaMethods[inner] = append(gorillaMethods[inner], strings.ToUpper(m))
+                }
+            }
+        }
+        return
+    }
+
+    node, extra := b.receiverNode(pkg, sel.X)
+    if node == nil {
+        return
+    }
+    name := sel.Sel.Name
+    args := call.Args
+    pathArg := func(i int) (string, bool) {
+        if i >= len(args) {
+            return "", false
+        }
+        return constString(info, args[i])
+    }
+
+    switch {
+    case name == "Use":
+        node.mws = append(node.mws, append(extra, exprNames(pkg, args)...)...)
+
+    case name == "Route" || name == "Group" && node.lib == "chi":
+        // chi: r.Route("/x", func(r chi.Router) {...}), r.Group(func(r chi.Router) {...})
+        if len(args) == 0 {
+            return
+        }
+        prefix := ""
+        if name == "Route" {
+            prefix, _ = pathArg(0)
+        }
+        lit, ok := args[len(args)-1].(*ast.FuncLit)
+        if !ok || lit.Type.Params == nil || len(lit.Type.Params.List) == 0 || len(lit.Type.Params.List[0].Names) == 0 {
+            return
+        }
+        if obj := info.Defs[lit.Type.Params.List[0].Names[0]]; obj != nil {
+            b.nodes[obj] = b.child(node, prefix, extra)
+        }
+
+    case name == "Mount":
+        // chi: r.Mount("/admin", adminRouter)
+        prefix, ok := pathArg(0)
+        if !ok || len(args) < 2 {
+            return
+        }
+        if sub := b.nodeFor(info, args[1]); sub != nil && sub.parent == nil {
+            sub.parent, sub.prefix, sub.parentMws = node, prefix, len(node.mws)
+        }
+
+    case name == "Handle" || name == "HandleFunc":
+        path, ok := pathArg(0)
+        if !ok || len(args) < 2 {
+            return
+        }
+        method := "ANY"
+        if node.lib == "net/http" {
+            method, path = splitPattern(path)
+        }
+        if methods, ok := gorillaMethods[call]; ok {
+            method = strings.Join(methods, ",")
+        }
+        // gin: r.Handle("GET", "/x", handlers...)
+        if node.lib == "gin" && len(args) >= 3 {
+            method = strings.ToUpper(path)
+            if path, ok = pathArg(1); !ok {
+                return
+            }
+            b.addRoute(pkg, decl, call, node, extra, method, path, args[len(args)-1], exprNames(pkg, args[2:len(args)-1]))
+            return
+        }
+        b.addRoute(pkg, decl, call, node, extra, method, path, args[1], nil)
+
+    case name == "Method" || name == "MethodFunc":
+        // chi: r.Method("GET", "/x", h)
+        method, ok := pathArg(0)
+        path, ok2 := pathArg(1)
+        if ok && ok2 && len(args) >= 3 {
+            b.addRoute(pkg, decl, call, node, extra, strings.ToUpper(method), path, args[2], nil)
+        }
+
+    case httpMethods[name] != "":
+        path, ok := pathArg(0)
+        if !ok || len(args) < 2 {
+            return
+        }
+        switch node.lib {
+        case "gin":
+            // r.GET(path, mw..., handler)
+            b.addRoute(pkg, decl, call, node, extra, httpMethods[name], path, args[len(args)-1], exprNames(pkg, args[1:len(args)-1]))
+        case "echo":
+            // e.GET(path, handler, mw...)
+            b.addRoute(pkg, decl, call, node, extra, httpMethods[name], path, args[1], exprNames(pkg, args[2:]))
+        default:
+            b.addRoute(pkg, decl, call, node, extra, httpMethods[name], path, args[1], nil)
+        }
+    }
+}
+
+// chain собирает middleware узла от корня: до limit элементов собственного
+// списка (для упорядоченных роутеров)
+func chain(node *routerNode, limit int) []Middleware {
+    var result []Middleware
+    scope := "group"
+    if node.parent == nil {
+        scope = "global"
+    } else {
+        parentLimit := len(node.parent.mws)
+        if orderedMiddleware(node.lib) {
+            parentLimit = node.parentMws
+        }
+        result = chain(node.parent, parentLimit)
+    }
+    for _, name := range node.wrappers {
+        result = append(result, Middleware{Name: name, Scope: scope})
+    }
+    if !orderedMiddleware(node.lib) || limit > len(node.mws) {
+        limit = len(node.mws)
+    }
+    for _, name := range node.mws[:limit] {
+        result = append(result, Middleware{Name: name, Scope: scope})
+    }
+    return result
+}
+
+func fullPrefix(node *routerNode) string {
+    if node == nil {
+        return ""
+    }
+    return fullPrefix(node.parent) + node.prefix
+}
+
+func (b *routeBuilder) build() []Route {
+    var routes []Route
+    for _, pending := range b.routes {
+        route := pending.route
+        route.Path = fullPrefix(pending.node) + route.Path
+        route.Middleware = chain(pending.node, pending.snapshot)
+        // Обработчик может быть объявлен в пакете, разобранном позже
+        route.Request, route.Response = b.contracts.resolve(pending.handler)
+        for _, name := range pending.routeMws {
+            route.Middleware = append(route.Middleware, Middleware{Name: name, Scope: "route"})
+        }
+        if route.Middleware == nil {
+            route.Middleware = []Middleware{}
+        }
+        routes = append(routes, route)
+    }
+    return routes
+}
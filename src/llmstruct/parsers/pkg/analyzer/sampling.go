@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+    "go/ast"
+    "hash/fnv"
+    "path/filepath"
+)
+
+type SamplingInfo struct {
+    Mode           string   `json:"mode"`
+    Rate           float64  `json:"rate"`
+    Functions      int      `json:"functions"`
+    BodiesAnalyzed int      `json:"bodies_analyzed"`
+    Approximate    bool     `json:"approximate"`
+}
+
+// bodyWeight - важность тела функции для выборки: точки входа берутся
+// всегда, экспортированный API чаще внутренних помощников
+func bodyWeight(fd *ast.FuncDecl) float64 {
+    switch fd.Name.Name {
+    case "main", "init", "ServeHTTP":
+        return 0
+    }
+    weight := 1.0
+    if fd.Name.IsExported() {
+        weight = 2.5
+    }
+    return weight
+}
+
+// keepBody детерминированно решает, попадает ли тело функции в выборку:
+// хэш имени сравнивается с вероятностью rate * вес, поэтому повторные
+// запуски дают ту же выборку
+func keepBody(filename string, fd *ast.FuncDecl, rate float64) bool {
+    weight := bodyWeight(fd)
+    if weight == 0 {
+        return true
+    }
+    probability := rate * weight
+    if probability >= 1 {
+        return true
+    }
+
+    key := filepath.Base(filename) + ":" + fd.Name.Name
+    if fd.Recv != nil && len(fd.Recv.List) > 0 {
+        key = filepath.Base(filename) + ":" + extractTypeString(fd.Recv.List[0].Type) + "." + fd.Name.Name
+    }
+    h := fnv.New32a()
+    h.Write([]byte(key))
+    return float64(h.Sum32())/float64(1<<32) < probability
+}
@@ -0,0 +1,435 @@
+package analyzer
+
+import (
+    "context"
+    "fmt"
+    "go/ast"
+    "go/format"
+    "go/parser"
+    "go/token"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "golang.org/x/tools/go/ast/astutil"
+)
+
+// ScaffoldFile - файл, который создаёт scaffold
+type ScaffoldFile struct {
+    Path         string   `json:"path"`
+    Content      string   `json:"content"`
+    Exists       bool     `json:"exists"`
+}
+
+// ScaffoldSnippet - строки, которые вставляются в существующий файл после
+// строки Line (регистрация маршрутов)
+type ScaffoldSnippet struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Content      string   `json:"content"`
+    Reason       string   `json:"reason"`
+}
+
+// ScaffoldPlan - результат analyzer scaffold: новые файлы, вставки и
+// команды, которые нужно выполнить после (go generate для моков)
+type ScaffoldPlan struct {
+    Kind         string            `json:"kind"`
+    Like         string            `json:"like"`
+    From         string            `json:"from"`
+    Name         string            `json:"name"`
+    Files        []ScaffoldFile    `json:"files"`
+    Snippets     []ScaffoldSnippet `json:"snippets"`
+    // Mocks - сгенерированные файлы с образцом в имени: моки, которые
+    // нужно сгенерировать и для нового имени
+    Mocks        []string          `json:"mocks"`
+    Commands     []string          `json:"commands"`
+    Written      bool              `json:"written"`
+}
+
+// scaffoldKinds - виды заготовок
+var scaffoldKinds = map[string]bool{"new-handler": true}
+
+// nameWords разбивает имя на слова: UserProfile, userProfile,
+// user_profile и user-profile дают user, profile
+func nameWords(name string) []string {
+    var words []string
+    var cur []rune
+    flush := func() {
+        if len(cur) > 0 {
+            words = append(words, strings.ToLower(string(cur)))
+            cur = nil
+        }
+    }
+    for _, r := range name {
+        switch {
+        case r == '_' || r == '-' || r == ' ':
+            flush()
+        case unicode.IsUpper(r) && len(cur) > 0 && !unicode.IsUpper(cur[len(cur)-1]):
+            flush()
+            cur = append(cur, r)
+        default:
+            cur = append(cur, r)
+        }
+    }
+    flush()
+    return words
+}
+
+// nameForms - написания имени в коде: UserProfile, userProfile,
+// user_profile, user-profile, USER_PROFILE, userprofile
+func nameForms(words []string) []string {
+    title := func(w string) string { return strings.ToUpper(w[:1]) + w[1:] }
+    var camel strings.Builder
+    for _, w := range words {
+        camel.WriteString(title(w))
+    }
+    c := camel.String()
+    return []string{
+        c,
+        strings.ToLower(c[:1]) + c[1:],
+        strings.Join(words, "_"),
+        strings.Join(words, "-"),
+        strings.ToUpper(strings.Join(words, "_")),
+        strings.Join(words, ""),
+    }
+}
+
+// renamer заменяет все написания образца на соответствующие написания
+// нового имени; длинные формы проверяются раньше коротких
+func renamer(from, to string) *strings.Replacer {
+    src, dst := nameForms(nameWords(from)), nameForms(nameWords(to))
+    type pair struct{ old, new string }
+    seen := make(map[string]bool)
+    var pairs []pair
+    for i := range src {
+        if !seen[src[i]] {
+            seen[src[i]] = true
+            pairs = append(pairs, pair{src[i], dst[i]})
+        }
+    }
+    sort.SliceStable(pairs, func(i, j int) bool { return len(pairs[i].old) > len(pairs[j].old) })
+    var args []string
+    for _, p := range pairs {
+        args = append(args, p.old, p.new)
+    }
+    return strings.NewReplacer(args...)
+}
+
+// likeStem выводит образец из имени файла: user_handler.go -> User
+func likeStem(file string) string {
+    base := strings.TrimSuffix(filepath.Base(file), ".go")
+    for _, affix := range []string{"_handlers", "_handler", "handlers_", "handler_"} {
+        base = strings.TrimSuffix(strings.TrimPrefix(base, affix), affix)
+    }
+    words := nameWords(base)
+    if len(words) == 0 {
+        return ""
+    }
+    return nameForms(words)[0]
+}
+
+// declStart - начало объявления вместе с doc-комментарием
+func declStart(decl ast.Decl) token.Pos {
+    switch d := decl.(type) {
+    case *ast.FuncDecl:
+        if d.Doc != nil {
+            return d.Doc.Pos()
+        }
+    case *ast.GenDecl:
+        if d.Doc != nil {
+            return d.Doc.Pos()
+        }
+    }
+    return decl.Pos()
+}
+
+// declNames - имена, которые вводит объявление; у метода - и тип получателя
+func declNames(decl ast.Decl) []string {
+    var names []string
+    switch d := decl.(type) {
+    case *ast.FuncDecl:
+        names = append(names, d.Name.Name)
+        if d.Recv != nil && len(d.Recv.List) > 0 {
+            names = append(names, receiverTypeName(extractTypeString(d.Recv.List[0].Type)))
+        }
+    case *ast.GenDecl:
+        for _, spec := range d.Specs {
+            switch s := spec.(type) {
+            case *ast.TypeSpec:
+                names = append(names, s.Name.Name)
+            case *ast.ValueSpec:
+                for _, n := range s.Names {
+                    names = append(names, n.Name)
+                }
+            }
+        }
+    }
+    return names
+}
+
+func declSource(fset *token.FileSet, content []byte, decl ast.Decl) string {
+    return string(content[fset.Position(declStart(decl)).Offset:fset.Position(decl.End()).Offset])
+}
+
+// finishSource убирает импорты, ставшие ненужными после замены тел, и
+// форматирует файл
+func finishSource(src string) string {
+    fset := token.NewFileSet()
+    file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+    if err != nil {
+        return src
+    }
+    // DeleteNamedImport меняет file.Imports, поэтому обходится копия
+    for _, imp := range append([]*ast.ImportSpec(nil), file.Imports...) {
+        path, _ := strconv.Unquote(imp.Path.Value)
+        if imp.Name != nil && (imp.Name.Name == "_" || imp.Name.Name == ".") {
+            continue
+        }
+        if !astutil.UsesImport(file, path) {
+            name := ""
+            if imp.Name != nil {
+                name = imp.Name.Name
+            }
+            astutil.DeleteNamedImport(fset, file, name, path)
+        }
+    }
+    var b strings.Builder
+    if err := format.Node(&b, fset, file); err != nil {
+        return src
+    }
+    return b.String()
+}
+
+// buildScaffold строит заготовку по образцу like: объявления, в именах
+// которых встречается образец from, копируются под новым именем. Функции -
+// экземпляры шаблонов (-templates) сохраняют тело как принятую в проекте
+// форму, у остальных тело - panic("TODO"). Для тестов образца создаются
+// заглушки, для его маршрутов - строки регистрации рядом с исходными
+func buildScaffold(projectPath string, analysis *ProjectAnalysis, kind, like, from, name string) (*ScaffoldPlan, error) {
+    like = filepath.ToSlash(like)
+    content, err := os.ReadFile(filepath.Join(projectPath, like))
+    if err != nil {
+        return nil, err
+    }
+    if from == "" {
+        if from = likeStem(like); from == "" {
+            return nil, fmt.Errorf("cannot derive the pattern name from %s; use -from", like)
+        }
+    }
+    rename := renamer(from, name)
+    mentions := func(s string) bool { return rename.Replace(s) != s }
+    if !mentions(string(content)) {
+        return nil, fmt.Errorf("%s does not mention %q; use -from", like, from)
+    }
+    plan := &ScaffoldPlan{Kind: kind, Like: like, From: from, Name: name, Files: []ScaffoldFile{}, Snippets: []ScaffoldSnippet{}, Mocks: []string{}, Commands: []string{}}
+
+    fset := token.NewFileSet()
+    file, err := parser.ParseFile(fset, like, content, parser.ParseComments)
+    if err != nil {
+        return nil, err
+    }
+    templateLines := make(map[int]bool)
+    for _, tmpl := range analysis.Templates {
+        for _, inst := range tmpl.Instances {
+            if filepath.ToSlash(inst.File) == like {
+                templateLines[inst.Line] = true
+            }
+        }
+    }
+    pkgPath := packagePathOf(analysis, like)
+
+    var b strings.Builder
+    fmt.Fprintf(&b, "package %s\n\n", file.Name.Name)
+    // Директивы go:generate образца (моки) - под новым именем
+    generate := false
+    for _, group := range file.Comments {
+        for _, c := range group.List {
+            if strings.HasPrefix(c.Text, "//go:generate ") && mentions(c.Text) {
+                b.WriteString(rename.Replace(c.Text) + "\n")
+                generate = true
+            }
+        }
+    }
+    if generate {
+        b.WriteString("\n")
+        plan.Commands = append(plan.Commands, "go generate ./"+filepath.ToSlash(filepath.Dir(like)))
+    }
+    handlers := make(map[string]string)
+    copied := 0
+    for _, decl := range file.Decls {
+        if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+            b.WriteString(declSource(fset, content, decl) + "\n\n")
+            continue
+        }
+        if !mentions(strings.Join(declNames(decl), " ")) {
+            continue
+        }
+        text := declSource(fset, content, decl)
+        copied++
+        fd, ok := decl.(*ast.FuncDecl)
+        if !ok || fd.Body == nil || templateLines[fset.Position(fd.Pos()).Line] {
+            b.WriteString(rename.Replace(text) + "\n\n")
+        } else {
+            head := text[:fset.Position(fd.Body.Lbrace).Offset-fset.Position(declStart(decl)).Offset]
+            fmt.Fprintf(&b, "%s{\n\tpanic(\"TODO: implement %s\")\n}\n\n", rename.Replace(head), rename.Replace(fd.Name.Name))
+        }
+        if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil {
+            handlers[pkgPath+"."+fd.Name.Name] = rename.Replace(fd.Name.Name)
+        }
+    }
+    if copied == 0 {
+        return nil, fmt.Errorf("no declarations in %s are named after %q; use -from", like, from)
+    }
+    target := rename.Replace(like)
+    if target == like {
+        target = strings.TrimSuffix(like, ".go") + "_" + strings.Join(nameWords(name), "_") + ".go"
+    }
+    plan.Files = append(plan.Files, ScaffoldFile{Path: target, Content: finishSource(b.String()), Exists: fileExists(filepath.Join(projectPath, target))})
+
+    // Заглушки тестов, названных по образцу
+    src := &sourceLines{root: projectPath, files: make(map[string][]string)}
+    testFiles := make(map[string][]TestMapping)
+    for _, m := range analysis.TestMapping {
+        if m.Kind == "example" || !mentions(m.Test) {
+            continue
+        }
+        for _, t := range m.Targets {
+            if t.File == like {
+                testFiles[m.File] = append(testFiles[m.File], m)
+                break
+            }
+        }
+    }
+    var testPaths []string
+    for path := range testFiles {
+        testPaths = append(testPaths, path)
+    }
+    sort.Strings(testPaths)
+    for _, path := range testPaths {
+        pkgName := file.Name.Name
+        if tf, err := parser.ParseFile(token.NewFileSet(), filepath.Join(projectPath, path), nil, parser.PackageClauseOnly); err == nil {
+            pkgName = tf.Name.Name
+        }
+        var tb strings.Builder
+        fmt.Fprintf(&tb, "package %s\n\nimport \"testing\"\n", pkgName)
+        for _, m := range testFiles[path] {
+            param := map[string]string{"test": "t *testing.T", "benchmark": "b *testing.B", "fuzz": "f *testing.F"}[m.Kind]
+            fmt.Fprintf(&tb, "\nfunc %s(%s) {\n\t%s.Skip(\"TODO: port %s\")\n}\n", rename.Replace(m.Test), param, param[:1], m.Test)
+        }
+        testTarget := rename.Replace(path)
+        if testTarget == path {
+            testTarget = strings.TrimSuffix(target, ".go") + "_test.go"
+        }
+        plan.Files = append(plan.Files, ScaffoldFile{Path: testTarget, Content: finishSource(tb.String()), Exists: fileExists(filepath.Join(projectPath, testTarget))})
+    }
+
+    // Регистрация маршрутов: строки регистрации обработчиков образца под
+    // новым именем - после последней из них
+    byFile := make(map[string][]Route)
+    for _, route := range analysis.Routes {
+        if _, ok := handlers[route.Handler]; ok {
+            byFile[route.File] = append(byFile[route.File], route)
+        }
+    }
+    for path, routes := range byFile {
+        sort.Slice(routes, func(i, j int) bool { return routes[i].Line < routes[j].Line })
+        var lines []string
+        for _, route := range routes {
+            line := src.span(path, route.Line, route.Line)
+            if renamed := rename.Replace(line); renamed != line {
+                lines = appendUnique(lines, renamed)
+            }
+        }
+        if len(lines) > 0 {
+            plan.Snippets = append(plan.Snippets, ScaffoldSnippet{
+                File:    path,
+                Line:    routes[len(routes)-1].Line,
+                Content: strings.Join(lines, "\n"),
+                Reason:  "route registration",
+            })
+        }
+    }
+    sort.Slice(plan.Snippets, func(i, j int) bool { return plan.Snippets[i].File < plan.Snippets[j].File })
+
+    for _, f := range analysis.Files {
+        if f.IsGenerated && mentions(f.Path) {
+            plan.Mocks = append(plan.Mocks, f.Path)
+        }
+    }
+    return plan, nil
+}
+
+// applyScaffold создаёт файлы и вставляет строки; существующие файлы не
+// перезаписываются
+func applyScaffold(projectPath string, plan *ScaffoldPlan) error {
+    for _, f := range plan.Files {
+        if f.Exists {
+            return fmt.Errorf("%s already exists", f.Path)
+        }
+    }
+    for _, f := range plan.Files {
+        path := filepath.Join(projectPath, filepath.FromSlash(f.Path))
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            return err
+        }
+        if err := writeAtomic(path, []byte(f.Content)); err != nil {
+            return err
+        }
+    }
+    for _, s := range plan.Snippets {
+        path := filepath.Join(projectPath, filepath.FromSlash(s.File))
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return err
+        }
+        lines := strings.SplitAfter(string(data), "\n")
+        if s.Line > len(lines) {
+            return fmt.Errorf("%s: line %d out of range", s.File, s.Line)
+        }
+        insert := s.Content + "\n"
+        out := strings.Join(lines[:s.Line], "") + insert + strings.Join(lines[s.Line:], "")
+        if err := writeAtomic(path, []byte(out)); err != nil {
+            return err
+        }
+    }
+    plan.Written = true
+    return nil
+}
+
+// runScaffold - analyzer scaffold new-handler -like <file> -name <Name>:
+// заготовка нового обработчика по образцу существующего; без -write
+// только план
+func runScaffold(args []string) {
+    fs := newCommandFlags("scaffold", "new-handler -like <file> -name <Name> [-from <Name>] [-write] [project_path]")
+    like := fs.String("like", "", "existing file to follow, relative to the project (e.g. pkg/api/user_handler.go)")
+    name := fs.String("name", "", "name of the new entity (e.g. Order)")
+    from := fs.String("from", "", "entity name in the -like file (default: derived from its file name)")
+    write := fs.Bool("write", false, "create the files and insert route registrations instead of only printing the plan")
+    if len(args) == 0 || !scaffoldKinds[args[0]] {
+        fs.Usage()
+        os.Exit(2)
+    }
+    kind := args[0]
+    projectPath := fs.parse(args[1:])
+    if *like == "" || *name == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    opts := defaultOptions()
+    opts.Templates, opts.TestMapping = true, true
+    analysis := analyzeProject(context.Background(), projectPath, opts)
+    plan, err := buildScaffold(projectPath, analysis, kind, *like, *from, *name)
+    if err != nil {
+        fatal("scaffold failed", "error", err)
+    }
+    if *write {
+        if err := applyScaffold(projectPath, plan); err != nil {
+            fatal("failed to write scaffold", "error", err)
+        }
+    }
+    fs.write(plan)
+}
@@ -0,0 +1,213 @@
+package analyzer
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "log/slog"
+    "net/http"
+    "os"
+    "os/signal"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+)
+
+// SymbolMatch - символ проекта в ответе GET /symbols
+type SymbolMatch struct {
+    ID           string   `json:"id"`
+    Name         string   `json:"name"`
+    Kind         string   `json:"kind"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Signature    string   `json:"signature,omitempty"`
+}
+
+// analysisServer отдаёт результат анализа по HTTP, чтобы IDE и агенты не
+// перезапускали анализатор на каждый запрос. Анализ выполняется при
+// старте и заново по POST /refresh
+type analysisServer struct {
+    projectPath string
+    mu          sync.RWMutex
+    analysis    *ProjectAnalysis
+    files       map[string]int
+    symbols     map[string]*goSymbol
+    refreshing  sync.Mutex
+}
+
+func newAnalysisServer(projectPath string) *analysisServer {
+    s := &analysisServer{projectPath: projectPath}
+    s.refresh(context.Background())
+    return s
+}
+
+// refresh анализирует проект заново; запросы до конца анализа получают
+// предыдущий результат, прерванный анализ его не заменяет
+func (s *analysisServer) refresh(ctx context.Context) error {
+    s.refreshing.Lock()
+    defer s.refreshing.Unlock()
+    analysis := analyzeProject(ctx, s.projectPath, defaultOptions())
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+    files := make(map[string]int, len(analysis.Files))
+    for i, file := range analysis.Files {
+        files[filepath.ToSlash(file.Path)] = i
+    }
+    symbols := goSymbols(analysis, false)
+    s.mu.Lock()
+    s.analysis, s.files, s.symbols = analysis, files, symbols
+    s.mu.Unlock()
+    return nil
+}
+
+func (s *analysisServer) handler() http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /project", s.handleProject)
+    mux.HandleFunc("GET /files/{path...}", s.handleFile)
+    mux.HandleFunc("GET /symbols", s.handleSymbols)
+    mux.HandleFunc("GET /impact", s.handleImpact)
+    mux.HandleFunc("POST /refresh", s.handleRefresh)
+    return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    if err := json.NewEncoder(w).Encode(v); err != nil {
+        slog.Debug("failed to write response", "error", err)
+    }
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+    writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func (s *analysisServer) handleProject(w http.ResponseWriter, r *http.Request) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    writeJSON(w, http.StatusOK, s.analysis)
+}
+
+func (s *analysisServer) handleFile(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.PathValue("path"), "./")
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    i, ok := s.files[path]
+    if !ok {
+        writeError(w, http.StatusNotFound, "file not found: "+path)
+        return
+    }
+    writeJSON(w, http.StatusOK, s.analysis.Files[i])
+}
+
+func symbolMatch(sym *goSymbol) SymbolMatch {
+    return SymbolMatch{ID: sym.id, Name: sym.name, Kind: sym.kind, File: sym.file, Line: sym.line, Signature: sym.signature}
+}
+
+// searchSymbols ищет символы по подстроке ID без учёта регистра; точные
+// совпадения имени идут первыми. kind фильтрует по виду
+func (s *analysisServer) searchSymbols(query, kind string, limit int) []SymbolMatch {
+    query = strings.ToLower(query)
+    s.mu.RLock()
+    matches := []SymbolMatch{}
+    for _, sym := range s.symbols {
+        if kind != "" && sym.kind != kind || !strings.Contains(strings.ToLower(sym.id), query) {
+            continue
+        }
+        matches = append(matches, symbolMatch(sym))
+    }
+    s.mu.RUnlock()
+    exact := func(m SymbolMatch) bool {
+        name := strings.ToLower(m.Name)
+        return name == query || strings.HasSuffix(name, "."+query)
+    }
+    sort.Slice(matches, func(i, j int) bool {
+        if ei, ej := exact(matches[i]), exact(matches[j]); ei != ej {
+            return ei
+        }
+        return matches[i].ID < matches[j].ID
+    })
+    if len(matches) > limit {
+        matches = matches[:limit]
+    }
+    return matches
+}
+
+func (s *analysisServer) handleSymbols(w http.ResponseWriter, r *http.Request) {
+    limit := 100
+    if v := r.URL.Query().Get("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n <= 0 {
+            writeError(w, http.StatusBadRequest, "invalid limit: "+v)
+            return
+        }
+        limit = n
+    }
+    writeJSON(w, http.StatusOK, s.searchSymbols(r.URL.Query().Get("query"), r.URL.Query().Get("kind"), limit))
+}
+
+func (s *analysisServer) handleImpact(w http.ResponseWriter, r *http.Request) {
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        writeError(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    depth := 3
+    if v := r.URL.Query().Get("depth"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            writeError(w, http.StatusBadRequest, "invalid depth: "+v)
+            return
+        }
+        depth = n
+    }
+    s.mu.RLock()
+    report, err := buildImpact(s.projectPath, s.analysis, symbol, depth)
+    s.mu.RUnlock()
+    if err != nil {
+        writeError(w, http.StatusNotFound, err.Error())
+        return
+    }
+    writeJSON(w, http.StatusOK, report)
+}
+
+func (s *analysisServer) handleRefresh(w http.ResponseWriter, r *http.Request) {
+    if err := s.refresh(r.Context()); err != nil {
+        writeError(w, http.StatusServiceUnavailable, "refresh interrupted: "+err.Error())
+        return
+    }
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    writeJSON(w, http.StatusOK, map[string]any{"files": len(s.analysis.Files), "meta": s.analysis.Meta})
+}
+
+// runServe - analyzer serve: HTTP API над результатом анализа
+func runServe(args []string) {
+    fs := newCommandFlags("serve", "[-addr 127.0.0.1:8080] [project_path]")
+    addr := fs.String("addr", "127.0.0.1:8080", "listen address; POST /refresh is unauthenticated, so expose other interfaces only behind a proxy")
+    projectPath := fs.parse(args)
+
+    server := newAnalysisServer(projectPath)
+    srv := &http.Server{
+        Addr:              *addr,
+        Handler:           server.handler(),
+        ReadHeaderTimeout: 10 * time.Second,
+    }
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    go func() {
+        <-ctx.Done()
+        shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        srv.Shutdown(shutdown)
+    }()
+    slog.Info("serving analysis", "project", projectPath, "addr", *addr)
+    if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+        fatal("server failed", "error", err)
+    }
+}
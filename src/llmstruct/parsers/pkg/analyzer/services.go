@@ -0,0 +1,302 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type ServiceEndpoint struct {
+    Protocol     string   `json:"protocol"`
+    Package      string   `json:"package"`
+    Service      string   `json:"service"`
+    Operations   []string `json:"operations"`
+    Contracts    []OperationContract `json:"contracts,omitempty"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function"`
+}
+
+type ServiceEndpoints struct {
+    Servers      []ServiceEndpoint `json:"servers"`
+    Clients      []ServiceEndpoint `json:"clients"`
+}
+
+type ServiceLink struct {
+    From         string   `json:"from"`
+    To           string   `json:"to"`
+    Protocol     string   `json:"protocol"`
+    Service      string   `json:"service"`
+    Via          string   `json:"via"`
+    Operations   []string `json:"operations"`
+}
+
+var (
+    grpcRegisterRe = regexp.MustCompile(`^Register(\w+)Server$`)
+    grpcNewClientRe = regexp.MustCompile(`^New(\w+)Client$`)
+)
+
+// Типы клиентов, генерируемые oapi-codegen и ogen
+var openAPIClientTypes = map[string]bool{
+    "Client": true, "ClientWithResponses": true, "ClientInterface": true, "ClientWithResponsesInterface": true, "Invoker": true,
+}
+
+// openAPIServerInterface возвращает интерфейс сервера сгенерированного
+// пакета: ServerInterface (oapi-codegen) или Handler (ogen)
+func openAPIServerInterface(pkg *types.Package) *types.Interface {
+    scope := pkg.Scope()
+    names := []string{"ServerInterface", "StrictServerInterface"}
+    if scope.Lookup("Invoker") != nil {
+        names = []string{"Handler"}
+    }
+    for _, name := range names {
+        if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+            if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+                return iface
+            }
+        }
+    }
+    return nil
+}
+
+func isOpenAPIPackage(pkg *types.Package) bool {
+    scope := pkg.Scope()
+    return scope.Lookup("ServerInterface") != nil || scope.Lookup("ClientInterface") != nil || scope.Lookup("Invoker") != nil
+}
+
+func interfaceMethods(iface *types.Interface) []string {
+    var methods []string
+    for i := 0; i < iface.NumMethods(); i++ {
+        // mustEmbedUnimplemented... - служебный метод protoc-gen-go-grpc
+        if name := iface.Method(i).Name(); !strings.HasPrefix(name, "mustEmbed") {
+            methods = append(methods, name)
+        }
+    }
+    sort.Strings(methods)
+    return methods
+}
+
+func lookupInterface(pkg *types.Package, name string) *types.Interface {
+    if tn, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+        iface, _ := tn.Type().Underlying().(*types.Interface)
+        return iface
+    }
+    return nil
+}
+
+// openAPIOperation сводит метод клиента oapi-codegen к operationId:
+// GetUserWithBodyWithResponse -> GetUser
+func openAPIOperation(method string) string {
+    method = strings.TrimSuffix(method, "WithResponse")
+    return strings.TrimSuffix(method, "WithBody")
+}
+
+// serviceBuilder собирает серверы и клиенты gRPC/OpenAPI проекта
+type serviceBuilder struct {
+    projectPath string
+    servers     []*ServiceEndpoint
+    clients     map[string]*ServiceEndpoint
+    clientOrder []string
+}
+
+func newServiceBuilder(projectPath string) *serviceBuilder {
+    return &serviceBuilder{
+        projectPath: projectPath,
+        clients:     make(map[string]*ServiceEndpoint),
+    }
+}
+
+func (b *serviceBuilder) client(protocol, pkgPath, service string, pkg *packages.Package, decl *ast.FuncDecl, call *ast.CallExpr) *ServiceEndpoint {
+    key := protocol + "|" + pkgPath + "|" + service
+    if endpoint, ok := b.clients[key]; ok {
+        return endpoint
+    }
+    pos := pkg.Fset.Position(call.Pos())
+    endpoint := &ServiceEndpoint{
+        Protocol:   protocol,
+        Package:    pkgPath,
+        Service:    service,
+        Operations: []string{},
+        File:       relativePath(b.projectPath, pos.Filename),
+        Line:       pos.Line,
+        Function:   funcID(pkg, decl),
+    }
+    b.clients[key] = endpoint
+    b.clientOrder = append(b.clientOrder, key)
+    return endpoint
+}
+
+func (b *serviceBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok {
+                return true
+            }
+            fn := calleeFunc(pkg.TypesInfo, call)
+            if fn == nil || fn.Pkg() == nil {
+                return true
+            }
+            gen := fn.Pkg()
+            sig := fn.Type().(*types.Signature)
+            pos := pkg.Fset.Position(call.Pos())
+
+            if sig.Recv() == nil {
+                // pb.RegisterUserServiceServer(s, impl)
+                if m := grpcRegisterRe.FindStringSubmatch(fn.Name()); m != nil && sig.Params().Len() == 2 && strings.Contains(sig.Params().At(0).Type().String(), "google.golang.org/grpc") {
+                    endpoint := &ServiceEndpoint{
+                        Protocol:   "grpc",
+                        Package:    gen.Path(),
+                        Service:    m[1],
+                        Operations: []string{},
+                        File:       relativePath(b.projectPath, pos.Filename),
+                        Line:       pos.Line,
+                        Function:   funcID(pkg, decl),
+                    }
+                    if iface := lookupInterface(gen, m[1]+"Server"); iface != nil {
+                        endpoint.Operations = interfaceMethods(iface)
+                        endpoint.Contracts = operationContracts(iface)
+                    }
+                    b.servers = append(b.servers, endpoint)
+                    return true
+                }
+                // pb.NewUserServiceClient(conn)
+                if m := grpcNewClientRe.FindStringSubmatch(fn.Name()); m != nil && lookupInterface(gen, m[1]+"Client") != nil {
+                    b.client("grpc", gen.Path(), m[1], pkg, decl, call)
+                    return true
+                }
+                if !isOpenAPIPackage(gen) {
+                    return true
+                }
+                // Регистрация обработчиков: параметр - интерфейс сервера
+                if iface := openAPIServerInterface(gen); iface != nil {
+                    for i := 0; i < sig.Params().Len(); i++ {
+                        if types.Identical(sig.Params().At(i).Type().Underlying(), iface) {
+                            b.servers = append(b.servers, &ServiceEndpoint{
+                                Protocol:   "openapi",
+                                Package:    gen.Path(),
+                                Service:    gen.Name(),
+                                Operations: interfaceMethods(iface),
+                                File:       relativePath(b.projectPath, pos.Filename),
+                                Line:       pos.Line,
+                                Function:   funcID(pkg, decl),
+                            })
+                            return true
+                        }
+                    }
+                }
+                if strings.HasPrefix(fn.Name(), "NewClient") {
+                    b.client("openapi", gen.Path(), gen.Name(), pkg, decl, call)
+                }
+                return true
+            }
+
+            // Вызовы операций через клиент
+            recv := sig.Recv().Type()
+            if ptr, ok := recv.(*types.Pointer); ok {
+                recv = ptr.Elem()
+            }
+            named, ok := recv.(*types.Named)
+            if !ok {
+                return true
+            }
+            typeName := named.Obj().Name()
+            switch {
+            case strings.HasSuffix(typeName, "Client") && gen.Scope().Lookup("New"+typeName) != nil && lookupInterface(gen, typeName) != nil:
+                endpoint := b.client("grpc", gen.Path(), strings.TrimSuffix(typeName, "Client"), pkg, decl, call)
+                endpoint.Operations = appendUnique(endpoint.Operations, fn.Name())
+            case openAPIClientTypes[typeName] && isOpenAPIPackage(gen):
+                endpoint := b.client("openapi", gen.Path(), gen.Name(), pkg, decl, call)
+                endpoint.Operations = appendUnique(endpoint.Operations, openAPIOperation(fn.Name()))
+            }
+            return true
+        })
+    }
+}
+
+func (b *serviceBuilder) build() *ServiceEndpoints {
+    if len(b.servers) == 0 && len(b.clients) == 0 {
+        return nil
+    }
+    result := &ServiceEndpoints{Servers: []ServiceEndpoint{}, Clients: []ServiceEndpoint{}}
+    for _, server := range b.servers {
+        result.Servers = append(result.Servers, *server)
+    }
+    for _, key := range b.clientOrder {
+        client := *b.clients[key]
+        sort.Strings(client.Operations)
+        result.Clients = append(result.Clients, client)
+    }
+    return result
+}
+
+// projectName - имя проекта в межрепозиторном графе
+func projectName(analysis *ProjectAnalysis, projectPath string) string {
+    if analysis.ModuleName != "" {
+        return analysis.ModuleName
+    }
+    return filepath.Base(projectPath)
+}
+
+func intersectStrings(a, b []string) []string {
+    set := make(map[string]bool)
+    for _, s := range b {
+        set[s] = true
+    }
+    result := []string{}
+    for _, s := range a {
+        if set[s] {
+            result = append(result, s)
+        }
+    }
+    return result
+}
+
+// linkServices связывает клиентов одного проекта с серверами другого: gRPC -
+// по общему пакету proto (или имени сервиса), OpenAPI - по operationId
+func linkServices(names []string, projects []*ProjectAnalysis) []ServiceLink {
+    links := []ServiceLink{}
+    for i, from := range projects {
+        if from.Services == nil {
+            continue
+        }
+        for _, client := range from.Services.Clients {
+            for j, to := range projects {
+                if i == j || to.Services == nil {
+                    continue
+                }
+                for _, server := range to.Services.Servers {
+                    if server.Protocol != client.Protocol {
+                        continue
+                    }
+                    link := ServiceLink{From: names[i], To: names[j], Protocol: client.Protocol, Service: server.Service}
+                    switch {
+                    case client.Protocol == "grpc" && client.Package == server.Package && client.Service == server.Service:
+                        link.Via = "shared proto package " + server.Package
+                    case client.Protocol == "grpc" && client.Service == server.Service && filepath.Base(client.Package) == filepath.Base(server.Package):
+                        link.Via = "service name " + server.Service
+                    case client.Protocol == "openapi":
+                        link.Via = "operation ids"
+                    default:
+                        continue
+                    }
+                    link.Operations = intersectStrings(client.Operations, server.Operations)
+                    if client.Protocol == "openapi" && len(link.Operations) == 0 {
+                        continue
+                    }
+                    links = append(links, link)
+                }
+            }
+        }
+    }
+    return links
+}
@@ -0,0 +1,303 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/token"
+    "go/types"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type GlobalVar struct {
+    ID           string   `json:"id"`
+    Type         string   `json:"type"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    IsExported   bool     `json:"is_exported"`
+    Synchronized bool     `json:"synchronized"`
+    Readers      []string `json:"readers"`
+    Writers      []string `json:"writers"`
+}
+
+type RaceCandidate struct {
+    Variable     string   `json:"variable"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Priority     string   `json:"priority"`
+    Contexts     []string `json:"contexts"`
+    Writers      []string `json:"writers"`
+}
+
+type globalAccess struct {
+    obj       types.Object
+    function  string
+    context   string
+    goroutine bool
+    write     bool
+    locked    bool
+}
+
+// sharedStateBuilder собирает обращения к переменным уровня пакета и
+// контексты (обычный вызов или горутина), из которых они происходят
+type sharedStateBuilder struct {
+    projectPath string
+    fset        *token.FileSet
+    globals     []*types.Var
+    accesses    []globalAccess
+    launched    map[*types.Func]string
+}
+
+func newSharedStateBuilder(projectPath string) *sharedStateBuilder {
+    return &sharedStateBuilder{
+        projectPath: projectPath,
+        launched:    make(map[*types.Func]string),
+    }
+}
+
+// isSyncType: sync.Mutex, sync.Map, atomic.Int64 и т.п. синхронизированы сами
+func isSyncType(t types.Type) bool {
+    if ptr, ok := t.(*types.Pointer); ok {
+        t = ptr.Elem()
+    }
+    named, ok := t.(*types.Named)
+    if !ok || named.Obj().Pkg() == nil {
+        return false
+    }
+    path := named.Obj().Pkg().Path()
+    return path == "sync" || path == "sync/atomic"
+}
+
+// usesLocking проверяет, вызывает ли функция Lock/RLock или sync/atomic
+func usesLocking(info *types.Info, body ast.Node) bool {
+    locked := false
+    ast.Inspect(body, func(n ast.Node) bool {
+        call, ok := n.(*ast.CallExpr)
+        if !ok {
+            return !locked
+        }
+        if fn := calleeFunc(info, call); fn != nil && fn.Pkg() != nil {
+            switch fn.Pkg().Path() {
+            case "sync":
+                if fn.Name() == "Lock" || fn.Name() == "RLock" {
+                    locked = true
+                }
+            case "sync/atomic":
+                locked = true
+            }
+        }
+        return !locked
+    })
+    return locked
+}
+
+// writtenIdents возвращает идентификаторы, значения которых изменяются
+// присваиванием, ++/-- или записью в элемент/поле
+func writtenIdents(body ast.Node) map[*ast.Ident]bool {
+    written := make(map[*ast.Ident]bool)
+    mark := func(expr ast.Expr) {
+        if id := rootIdent(expr); id != nil {
+            written[id] = true
+        }
+    }
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch node := n.(type) {
+        case *ast.AssignStmt:
+            for _, lhs := range node.Lhs {
+                mark(lhs)
+            }
+        case *ast.IncDecStmt:
+            mark(node.X)
+        }
+        return true
+    })
+    return written
+}
+
+func (b *sharedStateBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil || pkg.Types == nil {
+        return
+    }
+    info := pkg.TypesInfo
+    b.fset = pkg.Fset
+
+    scope := pkg.Types.Scope()
+    for _, name := range scope.Names() {
+        if v, ok := scope.Lookup(name).(*types.Var); ok && name != "_" {
+            b.globals = append(b.globals, v)
+        }
+    }
+
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            fd, ok := decl.(*ast.FuncDecl)
+            if !ok || fd.Body == nil {
+                continue
+            }
+            function := funcID(pkg, fd)
+            written := writtenIdents(fd.Body)
+            locked := usesLocking(info, fd.Body)
+
+            // Запуски горутин: литералы и именованные функции
+            goLits := make(map[*ast.FuncLit]string)
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                stmt, ok := n.(*ast.GoStmt)
+                if !ok {
+                    return true
+                }
+                p := pkg.Fset.Position(stmt.Pos())
+                context := fmt.Sprintf("goroutine at %s:%d", relativePath(b.projectPath, p.Filename), p.Line)
+                if lit, ok := stmt.Call.Fun.(*ast.FuncLit); ok {
+                    goLits[lit] = context
+                } else if fn := calleeFunc(info, stmt.Call); fn != nil {
+                    b.launched[fn] = context
+                }
+                return true
+            })
+
+            var visit func(root ast.Node, context string, goroutine, locked bool)
+            visit = func(root ast.Node, context string, goroutine, locked bool) {
+                ast.Inspect(root, func(n ast.Node) bool {
+                    if lit, ok := n.(*ast.FuncLit); ok && n != root {
+                        if ctx, ok := goLits[lit]; ok {
+                            visit(lit, ctx, true, usesLocking(info, lit.Body))
+                            return false
+                        }
+                        return true
+                    }
+                    id, ok := n.(*ast.Ident)
+                    if !ok {
+                        return true
+                    }
+                    v, ok := info.Uses[id].(*types.Var)
+                    if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+                        return true
+                    }
+                    b.accesses = append(b.accesses, globalAccess{
+                        obj:       v,
+                        function:  function,
+                        context:   context,
+                        goroutine: goroutine,
+                        write:     written[id],
+                        locked:    locked,
+                    })
+                    return true
+                })
+            }
+            // Обращения из функций, запускаемых через go f(), получают
+            // контекст горутины в raceCandidates, когда известны все запуски
+            visit(fd.Body, function, false, locked)
+        }
+    }
+}
+
+func (b *sharedStateBuilder) globalState() []GlobalVar {
+    byObj := make(map[types.Object]*GlobalVar)
+    var vars []GlobalVar
+    for _, v := range b.globals {
+        p := b.fset.Position(v.Pos())
+        byObj[v] = &GlobalVar{
+            ID:           v.Pkg().Path() + "." + v.Name(),
+            Type:         types.TypeString(v.Type(), nil),
+            File:         relativePath(b.projectPath, p.Filename),
+            Line:         p.Line,
+            IsExported:   v.Exported(),
+            Synchronized: isSyncType(v.Type()),
+            Readers:      []string{},
+            Writers:      []string{},
+        }
+    }
+    for _, a := range b.accesses {
+        g, ok := byObj[a.obj]
+        if !ok {
+            continue
+        }
+        if a.write {
+            g.Writers = appendUnique(g.Writers, a.function)
+        } else {
+            g.Readers = appendUnique(g.Readers, a.function)
+        }
+    }
+    for _, v := range b.globals {
+        vars = append(vars, *byObj[v])
+    }
+    sort.SliceStable(vars, func(i, j int) bool {
+        return vars[i].ID < vars[j].ID
+    })
+    return vars
+}
+
+// raceCandidates отбирает переменные, к которым обращаются из нескольких
+// контекстов, включая горутины, с записью и без видимой синхронизации
+func (b *sharedStateBuilder) raceCandidates() []RaceCandidate {
+    type usage struct {
+        contexts   []string
+        writers    []string
+        goroutines int
+        goWrites   bool
+        unlocked   bool
+    }
+    usages := make(map[types.Object]*usage)
+    functionIDs := make(map[string]*types.Func)
+    for fn := range b.launched {
+        functionIDs[fn.FullName()] = fn
+    }
+
+    for _, a := range b.accesses {
+        u, ok := usages[a.obj]
+        if !ok {
+            u = &usage{}
+            usages[a.obj] = u
+        }
+        context, goroutine := a.context, a.goroutine
+        if fn, ok := functionIDs[a.function]; ok && !goroutine {
+            context, goroutine = b.launched[fn], true
+        }
+        before := len(u.contexts)
+        u.contexts = appendUnique(u.contexts, context)
+        if goroutine && len(u.contexts) > before {
+            u.goroutines++
+        }
+        if a.write {
+            u.writers = appendUnique(u.writers, a.function)
+            if goroutine {
+                u.goWrites = true
+            }
+        }
+        if !a.locked {
+            u.unlocked = true
+        }
+    }
+
+    var candidates []RaceCandidate
+    for _, v := range b.globals {
+        u, ok := usages[v]
+        if !ok || isSyncType(v.Type()) || !u.unlocked {
+            continue
+        }
+        if u.goroutines == 0 || len(u.contexts) < 2 || len(u.writers) == 0 {
+            continue
+        }
+        priority := "medium"
+        if u.goWrites {
+            priority = "high"
+        }
+        p := b.fset.Position(v.Pos())
+        candidates = append(candidates, RaceCandidate{
+            Variable: v.Pkg().Path() + "." + v.Name(),
+            File:     relativePath(b.projectPath, p.Filename),
+            Line:     p.Line,
+            Priority: priority,
+            Contexts: u.contexts,
+            Writers:  u.writers,
+        })
+    }
+    sort.SliceStable(candidates, func(i, j int) bool {
+        if candidates[i].Priority != candidates[j].Priority {
+            return candidates[i].Priority == "high"
+        }
+        return len(candidates[i].Contexts) > len(candidates[j].Contexts)
+    })
+    return candidates
+}
@@ -0,0 +1,305 @@
+package analyzer
+
+import (
+    "bufio"
+    "bytes"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+type ShellScript struct {
+    Path         string   `json:"path"`
+    Interpreter  string   `json:"interpreter"`
+    Usage        string   `json:"usage,omitempty"`
+    Functions    []string `json:"functions"`
+    Binaries     []string `json:"binaries"`
+    EnvVars      []string `json:"env_vars"`
+    LineCount    int      `json:"line_count"`
+}
+
+// Каталоги, которые не обходятся при поиске не-Go файлов
+var skippedDirs = map[string]bool{
+    ".git": true, "vendor": true, "node_modules": true, "testdata": true, "third_party": true,
+}
+
+// Каталоги, где скрипты ищутся и без расширения (по shebang)
+var scriptDirs = map[string]bool{"scripts": true, "hack": true}
+
+var (
+    shebangRe      = regexp.MustCompile(`^#!\s*(?:/usr/bin/env\s+)?(?:\S*/)?(sh|bash|zsh|ksh|dash)\b`)
+    shellFuncRe    = regexp.MustCompile(`^\s*(?:function\s+)?([A-Za-z_][\w-]*)\s*\(\)\s*\{?`)
+    shellFuncKwRe  = regexp.MustCompile(`^\s*function\s+([A-Za-z_][\w-]*)\s*\{?`)
+    shellAssignRe  = regexp.MustCompile(`^\s*(?:export\s+|local\s+|readonly\s+|declare\s+(?:-\w+\s+)*)?([A-Za-z_]\w*)=(.*)`)
+    shellForReadRe = regexp.MustCompile(`^\s*(?:for|read(?:\s+-\w+)*)\s+([A-Za-z_]\w*)`)
+    shellVarRe     = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+    envVarNameRe   = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+    heredocRe      = regexp.MustCompile(`<<-?\s*['"]?(\w+)['"]?`)
+    commandSubRe   = regexp.MustCompile("\\$\\(([^()]*)\\)|`([^`]*)`")
+    singleQuoteRe  = regexp.MustCompile(`'[^']*'`)
+    doubleQuoteRe  = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+    commandSepRe   = regexp.MustCompile(`&&|\|\||[;|&(){}]`)
+    binaryNameRe   = regexp.MustCompile(`^[A-Za-z0-9_./+-]+$`)
+)
+
+// Встроенные команды и ключевые слова оболочки - не внешние программы
+var shellBuiltins = map[string]bool{
+    "if": true, "then": true, "else": true, "elif": true, "fi": true, "for": true, "while": true, "until": true,
+    "do": true, "done": true, "case": true, "esac": true, "in": true, "function": true, "select": true, "!": true,
+    "[": true, "[[": true, "]]": true, "test": true, "true": true, "false": true, ":": true, ".": true,
+    "echo": true, "printf": true, "cd": true, "pwd": true, "export": true, "local": true, "readonly": true,
+    "declare": true, "typeset": true, "set": true, "unset": true, "shift": true, "exit": true, "return": true,
+    "read": true, "source": true, "eval": true, "trap": true, "wait": true, "break": true, "continue": true,
+    "pushd": true, "popd": true, "getopts": true, "alias": true, "type": true, "hash": true, "let": true,
+    "mapfile": true, "readarray": true, "shopt": true, "ulimit": true, "umask": true, "kill": true, "jobs": true,
+}
+
+// Обёртки, запускающие следующую за ними команду
+var shellWrappers = map[string]bool{
+    "sudo": true, "env": true, "exec": true, "command": true, "nohup": true, "time": true, "xargs": true, "builtin": true,
+}
+
+// Специальные переменные оболочки, не приходящие из окружения
+var shellSpecialVars = map[string]bool{
+    "BASH_SOURCE": true, "BASH_REMATCH": true, "LINENO": true, "FUNCNAME": true, "PIPESTATUS": true,
+    "OPTARG": true, "OPTIND": true, "RANDOM": true, "SECONDS": true, "IFS": true, "PWD": true, "OLDPWD": true,
+}
+
+// shellInterpreter определяет скрипт по расширению или shebang
+func shellInterpreter(path string, dirScript bool) string {
+    ext := filepath.Ext(path)
+    if ext != ".sh" && ext != ".bash" && (!dirScript || ext != "") {
+        return ""
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        return ""
+    }
+    defer f.Close()
+    line, _ := bufio.NewReader(f).ReadString('\n')
+    if m := shebangRe.FindStringSubmatch(line); m != nil {
+        return m[1]
+    }
+    if ext == ".bash" {
+        return "bash"
+    }
+    if ext == ".sh" {
+        return "sh"
+    }
+    return ""
+}
+
+// commandWords возвращает имена команд в строке скрипта: первые слова
+// сегментов, разделённых ; && || | и подстановками $(...)
+func commandWords(line string) []string {
+    line = singleQuoteRe.ReplaceAllString(line, "''")
+    segments := []string{}
+    for _, m := range commandSubRe.FindAllStringSubmatch(line, -1) {
+        segments = append(segments, m[1]+m[2])
+    }
+    line = commandSubRe.ReplaceAllString(line, "")
+    line = doubleQuoteRe.ReplaceAllString(line, `""`)
+    segments = append(segments, commandSepRe.Split(line, -1)...)
+
+    var words []string
+    for _, segment := range segments {
+        fields := strings.Fields(segment)
+        for len(fields) > 0 {
+            word := fields[0]
+            // Присваивания перед командой: FOO=1 cmd
+            if strings.Contains(word, "=") && !strings.HasPrefix(word, "=") {
+                fields = fields[1:]
+                continue
+            }
+            if shellWrappers[word] {
+                fields = fields[1:]
+                // Флаги обёртки: sudo -E cmd, xargs -n1 cmd
+                for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+                    fields = fields[1:]
+                }
+                continue
+            }
+            if word == "then" || word == "do" || word == "else" || word == "!" || word == "time" {
+                fields = fields[1:]
+                continue
+            }
+            words = append(words, word)
+            break
+        }
+    }
+    return words
+}
+
+func analyzeShellScript(projectPath, path, interpreter string) (ShellScript, error) {
+    content, err := os.ReadFile(path)
+    if err != nil {
+        return ShellScript{}, err
+    }
+    script := ShellScript{
+        Path:        relativePath(projectPath, path),
+        Interpreter: interpreter,
+        Functions:   []string{},
+        Binaries:    []string{},
+        EnvVars:     []string{},
+    }
+
+    functions := make(map[string]bool)
+    assigned := make(map[string]bool)
+    referenced := make(map[string]bool)
+    var candidates []string
+    var usage []string
+    leading := true
+    heredoc := ""
+    inUsage := false
+    pending := ""
+
+    scanner := bufio.NewScanner(bytes.NewReader(content))
+    scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    for scanner.Scan() {
+        raw := scanner.Text()
+        script.LineCount++
+        trimmed := strings.TrimSpace(raw)
+
+        if heredoc != "" {
+            if trimmed == heredoc {
+                heredoc = ""
+                continue
+            }
+            if inUsage {
+                usage = append(usage, strings.TrimRight(raw, " \t"))
+            }
+            for _, m := range shellVarRe.FindAllStringSubmatch(raw, -1) {
+                referenced[m[1]] = true
+            }
+            continue
+        }
+
+        // Первый блок комментариев - описание и usage скрипта
+        if leading {
+            switch {
+            case script.LineCount == 1 && strings.HasPrefix(trimmed, "#!"):
+                continue
+            case strings.HasPrefix(trimmed, "#"):
+                text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+                if !strings.HasPrefix(text, "shellcheck ") && !strings.HasPrefix(text, "-*-") {
+                    usage = append(usage, text)
+                }
+                continue
+            case trimmed == "" && len(usage) == 0:
+                continue
+            default:
+                leading = false
+            }
+        }
+        if strings.HasPrefix(trimmed, "#") || trimmed == "" {
+            continue
+        }
+        if trimmed == "}" {
+            inUsage = false
+        }
+        // echo "Usage: ..." внутри usage()
+        if inUsage && (strings.HasPrefix(trimmed, "echo ") || strings.HasPrefix(trimmed, "printf ")) {
+            if m := doubleQuoteRe.FindString(trimmed); len(m) >= 2 {
+                usage = append(usage, m[1:len(m)-1])
+            }
+        }
+        if i := strings.Index(trimmed, " #"); i >= 0 && !strings.ContainsAny(trimmed[:i], `'"`) {
+            trimmed = trimmed[:i]
+        }
+        // Продолжение строки
+        if strings.HasSuffix(trimmed, "\\") {
+            pending += strings.TrimSuffix(trimmed, "\\") + " "
+            continue
+        }
+        line := pending + trimmed
+        pending = ""
+
+        if m := shellFuncRe.FindStringSubmatch(line); m != nil {
+            functions[m[1]] = true
+            inUsage = m[1] == "usage" || m[1] == "help"
+            line = line[len(m[0]):]
+        } else if m := shellFuncKwRe.FindStringSubmatch(line); m != nil {
+            functions[m[1]] = true
+            inUsage = m[1] == "usage" || m[1] == "help"
+            line = line[len(m[0]):]
+        }
+        if m := heredocRe.FindStringSubmatch(line); m != nil {
+            heredoc = m[1]
+        }
+
+        for _, m := range shellVarRe.FindAllStringSubmatch(singleQuoteRe.ReplaceAllString(line, ""), -1) {
+            referenced[m[1]] = true
+        }
+        // FOO=${FOO:-default} - переопределяемая через окружение переменная
+        if m := shellAssignRe.FindStringSubmatch(line); m != nil && !strings.Contains(m[2], "$"+m[1]) && !strings.Contains(m[2], "${"+m[1]) {
+            assigned[m[1]] = true
+        }
+        if m := shellForReadRe.FindStringSubmatch(line); m != nil {
+            assigned[m[1]] = true
+        }
+        candidates = append(candidates, commandWords(line)...)
+    }
+    if err := scanner.Err(); err != nil {
+        return script, err
+    }
+
+    script.Usage = strings.TrimSpace(strings.Join(usage, "\n"))
+    for name := range functions {
+        script.Functions = append(script.Functions, name)
+    }
+    sort.Strings(script.Functions)
+    for _, word := range candidates {
+        if shellBuiltins[word] || functions[word] || !binaryNameRe.MatchString(word) || strings.HasPrefix(word, "-") {
+            continue
+        }
+        script.Binaries = appendUnique(script.Binaries, word)
+    }
+    sort.Strings(script.Binaries)
+    for name := range referenced {
+        if envVarNameRe.MatchString(name) && !assigned[name] && !shellSpecialVars[name] {
+            script.EnvVars = append(script.EnvVars, name)
+        }
+    }
+    sort.Strings(script.EnvVars)
+    return script, nil
+}
+
+// extractShellScripts находит shell-скрипты проекта: *.sh/*.bash везде и
+// файлы с shebang оболочки в scripts/ и hack/
+func extractShellScripts(projectPath string) ([]ShellScript, error) {
+    var scripts []ShellScript
+    err := filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if d.IsDir() {
+            if path != projectPath && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if !d.Type().IsRegular() {
+            return nil
+        }
+        dirScript := false
+        for _, part := range strings.Split(filepath.ToSlash(relativePath(projectPath, filepath.Dir(path))), "/") {
+            if scriptDirs[part] {
+                dirScript = true
+            }
+        }
+        interpreter := shellInterpreter(path, dirScript)
+        if interpreter == "" {
+            return nil
+        }
+        script, err := analyzeShellScript(projectPath, path, interpreter)
+        if err != nil {
+            return err
+        }
+        scripts = append(scripts, script)
+        return nil
+    })
+    return scripts, err
+}
@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/build"
+    "go/doc"
+    "go/parser"
+    "go/token"
+    "go/types"
+    "path/filepath"
+    "sort"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// StdlibCall - вызов функции стандартной библиотеки с её сигнатурой и
+// первой фразой документации, чтобы не полагаться на память модели
+type StdlibCall struct {
+    Function     string   `json:"function"`
+    Signature    string   `json:"signature"`
+    Doc          string   `json:"doc,omitempty"`
+    Line         int      `json:"line"`
+}
+
+// stdlibIndex кэширует принадлежность пакетов GOROOT и краткую
+// документацию их функций ("Func", "Type.Method")
+type stdlibIndex struct {
+    goroot map[string]bool
+    docs   map[string]map[string]string
+}
+
+func newStdlibIndex() *stdlibIndex {
+    return &stdlibIndex{goroot: make(map[string]bool), docs: make(map[string]map[string]string)}
+}
+
+func (idx *stdlibIndex) isStdlib(pkgPath string) bool {
+    std, ok := idx.goroot[pkgPath]
+    if !ok {
+        p, err := build.Default.Import(pkgPath, "", build.FindOnly)
+        std = err == nil && p.Goroot
+        idx.goroot[pkgPath] = std
+    }
+    return std
+}
+
+// packageDocs разбирает исходники пакета из GOROOT (только комментарии
+// объявлений) и запоминает синопсис каждой функции и метода
+func (idx *stdlibIndex) packageDocs(pkgPath string) map[string]string {
+    if docs, ok := idx.docs[pkgPath]; ok {
+        return docs
+    }
+    docs := make(map[string]string)
+    idx.docs[pkgPath] = docs
+    p, err := build.Default.Import(pkgPath, "", 0)
+    if err != nil {
+        return docs
+    }
+    fset := token.NewFileSet()
+    var synopsis doc.Package
+    for _, name := range p.GoFiles {
+        file, err := parser.ParseFile(fset, filepath.Join(p.Dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+        if err != nil {
+            continue
+        }
+        for _, decl := range file.Decls {
+            switch d := decl.(type) {
+            case *ast.FuncDecl:
+                if d.Doc == nil {
+                    continue
+                }
+                key := d.Name.Name
+                if d.Recv != nil && len(d.Recv.List) > 0 {
+                    key = receiverTypeName(extractTypeString(d.Recv.List[0].Type)) + "." + key
+                }
+                docs[key] = synopsis.Synopsis(d.Doc.Text())
+            case *ast.GenDecl:
+                // Методы интерфейсов документируются у полей
+                for _, spec := range d.Specs {
+                    ts, ok := spec.(*ast.TypeSpec)
+                    if !ok {
+                        continue
+                    }
+                    iface, ok := ts.Type.(*ast.InterfaceType)
+                    if !ok {
+                        continue
+                    }
+                    for _, m := range iface.Methods.List {
+                        if m.Doc == nil {
+                            continue
+                        }
+                        for _, name := range m.Names {
+                            docs[ts.Name.Name+"."+name.Name] = synopsis.Synopsis(m.Doc.Text())
+                        }
+                    }
+                }
+            }
+        }
+    }
+    return docs
+}
+
+// call описывает вызываемую функцию, если она из стандартной библиотеки
+func (idx *stdlibIndex) call(fn *types.Func) (StdlibCall, bool) {
+    fn = fn.Origin()
+    if fn.Pkg() == nil || !idx.isStdlib(fn.Pkg().Path()) {
+        return StdlibCall{}, false
+    }
+    key := fn.Name()
+    if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+        recv := sig.Recv().Type()
+        if ptr, ok := recv.(*types.Pointer); ok {
+            recv = ptr.Elem()
+        }
+        if named, ok := recv.(*types.Named); ok {
+            key = named.Obj().Name() + "." + key
+        }
+    }
+    qualifier := func(p *types.Package) string { return p.Name() }
+    return StdlibCall{
+        Function:  fn.Pkg().Name() + "." + key,
+        Signature: types.ObjectString(fn, qualifier),
+        Doc:       idx.packageDocs(fn.Pkg().Path())[key],
+    }, true
+}
+
+// annotate добавляет функциям файла вызовы стандартной библиотеки (по
+// одной записи на функцию, строка первого вызова)
+func (idx *stdlibIndex) annotate(pkg *packages.Package, file *ast.File, analysis *FileAnalysis) {
+    calls := make(map[*ast.FuncDecl][]StdlibCall)
+    seen := make(map[*ast.FuncDecl]map[string]bool)
+    inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+        call, ok := n.(*ast.CallExpr)
+        if !ok || decl == nil {
+            return true
+        }
+        fn := calleeFunc(pkg.TypesInfo, call)
+        if fn == nil {
+            return true
+        }
+        record, ok := idx.call(fn)
+        if !ok {
+            return true
+        }
+        if seen[decl] == nil {
+            seen[decl] = make(map[string]bool)
+        }
+        if seen[decl][record.Function] {
+            return true
+        }
+        seen[decl][record.Function] = true
+        record.Line = pkg.Fset.Position(call.Pos()).Line
+        calls[decl] = append(calls[decl], record)
+        return true
+    })
+    for decl, records := range calls {
+        line := pkg.Fset.Position(decl.Pos()).Line
+        for i := range analysis.Functions {
+            if fn := &analysis.Functions[i]; fn.Name == decl.Name.Name && fn.Line == line {
+                sort.SliceStable(records, func(a, b int) bool { return records[a].Line < records[b].Line })
+                fn.StdlibCalls = records
+            }
+        }
+    }
+}
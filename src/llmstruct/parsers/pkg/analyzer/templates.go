@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/scanner"
+    "go/token"
+    "os"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+const (
+    // Функции короче этого числа токенов похожи друг на друга случайно
+    minTemplateTokens = 40
+    // Шаблоном считается форма, повторённая хотя бы столько раз
+    minTemplateInstances = 3
+)
+
+// TemplateInstance - функция по шаблону; Values - значения заполнителей
+// ${1}, ${2}, ... в порядке номеров
+type TemplateInstance struct {
+    Function     string   `json:"function"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Values       []string `json:"values"`
+}
+
+// FunctionTemplate - группа функций одной формы: тексты совпадают с
+// точностью до имён и литералов. Template - текст представителя, где
+// различающиеся имена и литералы заменены на ${n}; одинаково меняющиеся
+// места получают один номер
+type FunctionTemplate struct {
+    Representative string             `json:"representative"`
+    Template       string             `json:"template"`
+    Tokens         int                `json:"tokens"`
+    Placeholders   int                `json:"placeholders"`
+    Instances      []TemplateInstance `json:"instances"`
+}
+
+// shapeToken - токен функции; у имён и литералов запоминается позиция в
+// тексте функции для подстановки заполнителя
+type shapeToken struct {
+    norm   string
+    lit    string
+    offset int
+    slot   bool
+}
+
+type shapedFunc struct {
+    id     string
+    file   string
+    line   int
+    src    string
+    tokens []shapeToken
+}
+
+// functionShape разбивает текст функции на токены: имена и литералы
+// сводятся к $id и $lit, остальное сохраняется как есть
+func functionShape(src string) []shapeToken {
+    fset := token.NewFileSet()
+    file := fset.AddFile("", -1, len(src))
+    var s scanner.Scanner
+    s.Init(file, []byte(src), nil, 0)
+    var toks []shapeToken
+    for {
+        pos, tok, lit := s.Scan()
+        if tok == token.EOF {
+            break
+        }
+        t := shapeToken{norm: tok.String(), offset: file.Offset(pos)}
+        switch {
+        case tok == token.IDENT:
+            t.norm, t.lit, t.slot = "$id", lit, true
+        case tok.IsLiteral():
+            t.norm, t.lit, t.slot = "$lit", lit, true
+        case tok == token.SEMICOLON:
+            t.norm = ";"
+        }
+        toks = append(toks, t)
+    }
+    return toks
+}
+
+// buildTemplate собирает шаблон группы: места, где значения различаются,
+// нумеруются по первому появлению
+func buildTemplate(group []*shapedFunc) FunctionTemplate {
+    rep := group[0]
+    slots := make(map[string]int)
+    var order []int
+    placeholder := make([]int, len(rep.tokens))
+    for i, t := range rep.tokens {
+        if !t.slot {
+            continue
+        }
+        values := make([]string, len(group))
+        differs := false
+        for j, fn := range group {
+            values[j] = fn.tokens[i].lit
+            differs = differs || values[j] != t.lit
+        }
+        if !differs {
+            continue
+        }
+        key := strings.Join(values, "\x00")
+        n, ok := slots[key]
+        if !ok {
+            n = len(slots) + 1
+            slots[key] = n
+            order = append(order, i)
+        }
+        placeholder[i] = n
+    }
+
+    var b strings.Builder
+    last := 0
+    for i, t := range rep.tokens {
+        if placeholder[i] == 0 {
+            continue
+        }
+        b.WriteString(rep.src[last:t.offset])
+        fmt.Fprintf(&b, "${%d}", placeholder[i])
+        last = t.offset + len(t.lit)
+    }
+    b.WriteString(rep.src[last:])
+
+    tmpl := FunctionTemplate{
+        Representative: rep.id,
+        Template:       b.String(),
+        Tokens:         len(rep.tokens),
+        Placeholders:   len(order),
+        Instances:      []TemplateInstance{},
+    }
+    for _, fn := range group {
+        values := make([]string, len(order))
+        for n, i := range order {
+            values[n] = fn.tokens[i].lit
+        }
+        tmpl.Instances = append(tmpl.Instances, TemplateInstance{Function: fn.id, File: fn.file, Line: fn.line, Values: values})
+    }
+    return tmpl
+}
+
+// extractTemplates находит повторяющиеся формы функций проекта (типовые
+// обработчики, конструкторы, обёртки) и описывает каждую шаблоном с
+// вариациями по экземплярам
+func extractTemplates(pkgs []*packages.Package, projectPath string) []FunctionTemplate {
+    groups := make(map[string][]*shapedFunc)
+    for _, pkg := range pkgs {
+        for i, file := range pkg.Syntax {
+            if i >= len(pkg.CompiledGoFiles) {
+                continue
+            }
+            content, err := os.ReadFile(pkg.CompiledGoFiles[i])
+            if err != nil {
+                continue
+            }
+            for _, decl := range file.Decls {
+                fd, ok := decl.(*ast.FuncDecl)
+                if !ok || fd.Body == nil {
+                    continue
+                }
+                start, end := pkg.Fset.Position(fd.Pos()), pkg.Fset.Position(fd.End())
+                if end.Offset > len(content) {
+                    continue
+                }
+                src := string(content[start.Offset:end.Offset])
+                toks := functionShape(src)
+                if len(toks) < minTemplateTokens {
+                    continue
+                }
+                norms := make([]string, len(toks))
+                for j, t := range toks {
+                    norms[j] = t.norm
+                }
+                key := strings.Join(norms, " ")
+                groups[key] = append(groups[key], &shapedFunc{
+                    id:     funcID(pkg, fd),
+                    file:   relativePath(projectPath, start.Filename),
+                    line:   start.Line,
+                    src:    src,
+                    tokens: toks,
+                })
+            }
+        }
+    }
+
+    templates := []FunctionTemplate{}
+    for _, group := range groups {
+        if len(group) < minTemplateInstances {
+            continue
+        }
+        sort.Slice(group, func(i, j int) bool { return group[i].id < group[j].id })
+        templates = append(templates, buildTemplate(group))
+    }
+    sort.Slice(templates, func(i, j int) bool {
+        a, b := templates[i], templates[j]
+        if len(a.Instances) != len(b.Instances) {
+            return len(a.Instances) > len(b.Instances)
+        }
+        return a.Representative < b.Representative
+    })
+    return templates
+}
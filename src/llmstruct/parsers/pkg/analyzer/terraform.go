@@ -0,0 +1,524 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/constant"
+    "go/token"
+    "go/types"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type TerraformAccess struct {
+    Function     string   `json:"function"`
+    Operation    string   `json:"operation"`
+    Method       string   `json:"method"`
+}
+
+type TerraformAttribute struct {
+    Name         string   `json:"name"`
+    Type         string   `json:"type,omitempty"`
+    ElemType     string   `json:"elem_type,omitempty"`
+    Required     bool     `json:"required,omitempty"`
+    Optional     bool     `json:"optional,omitempty"`
+    Computed     bool     `json:"computed,omitempty"`
+    ForceNew     bool     `json:"force_new,omitempty"`
+    Sensitive    bool     `json:"sensitive,omitempty"`
+    Description  string   `json:"description,omitempty"`
+    Line         int      `json:"line"`
+    Attributes   []TerraformAttribute `json:"attributes,omitempty"`
+    HandledBy    []TerraformAccess    `json:"handled_by"`
+}
+
+type TerraformResource struct {
+    Name         string            `json:"name"`
+    Kind         string            `json:"kind"`
+    Constructor  string            `json:"constructor,omitempty"`
+    File         string            `json:"file"`
+    Line         int               `json:"line"`
+    CRUD         map[string]string `json:"crud"`
+    Attributes   []TerraformAttribute `json:"attributes"`
+}
+
+type TerraformProvider struct {
+    Schema       []TerraformAttribute `json:"schema"`
+    Resources    []TerraformResource  `json:"resources"`
+}
+
+// Поля schema.Resource с CRUD-функциями (SDK v1/v2) и их операции
+var terraformCRUDFields = map[string]string{
+    "Create": "create", "CreateContext": "create", "CreateWithoutTimeout": "create",
+    "Read": "read", "ReadContext": "read", "ReadWithoutTimeout": "read",
+    "Update": "update", "UpdateContext": "update", "UpdateWithoutTimeout": "update",
+    "Delete": "delete", "DeleteContext": "delete", "DeleteWithoutTimeout": "delete",
+    "Exists": "exists", "Importer": "import",
+}
+
+// Методы schema.ResourceData, принимающие имя атрибута первым аргументом
+var resourceDataMethods = map[string]bool{
+    "Get": true, "GetOk": true, "GetOkExists": true, "GetChange": true,
+    "Set": true, "HasChange": true, "HasChangeExcept": true,
+}
+
+// isTerraformSchemaType: тип из пакета helper/schema Terraform Plugin SDK
+func isTerraformSchemaType(t types.Type, name string) bool {
+    named := namedStruct(t)
+    if named == nil || named.Obj().Pkg() == nil || named.Obj().Name() != name {
+        return false
+    }
+    path := named.Obj().Pkg().Path()
+    return strings.Contains(path, "hashicorp/terraform-plugin-sdk") && strings.HasSuffix(path, "/helper/schema")
+}
+
+type terraformDecl struct {
+    pkg  *packages.Package
+    decl *ast.FuncDecl
+}
+
+// terraformResourceLit - литерал schema.Resource верхнего уровня
+type terraformResourceLit struct {
+    pkg         *packages.Package
+    lit         *ast.CompositeLit
+    constructor *types.Func
+    decl        *ast.FuncDecl
+}
+
+// terraformBuilder собирает схемы ресурсов провайдера Terraform (SDK v1/v2)
+// и сопоставляет атрибуты с CRUD-функциями, которые их читают и пишут
+type terraformBuilder struct {
+    projectPath string
+    decls       map[*types.Func]terraformDecl
+    resources   []terraformResourceLit
+    nested      map[*ast.CompositeLit]bool
+    names       map[*types.Func][2]string
+    litNames    map[*ast.CompositeLit][2]string
+    provider    []TerraformAttribute
+    accesses    map[*types.Func][]terraformAttrAccess
+}
+
+type terraformAttrAccess struct {
+    attr   string
+    method string
+}
+
+func newTerraformBuilder(projectPath string) *terraformBuilder {
+    return &terraformBuilder{
+        projectPath: projectPath,
+        decls:       make(map[*types.Func]terraformDecl),
+        nested:      make(map[*ast.CompositeLit]bool),
+        names:       make(map[*types.Func][2]string),
+        litNames:    make(map[*ast.CompositeLit][2]string),
+        accesses:    make(map[*types.Func][]terraformAttrAccess),
+    }
+}
+
+// compositeOf возвращает литерал за выражением: &T{...}, T{...} или вызов
+// функции проекта, возвращающей литерал
+func (b *terraformBuilder) compositeOf(info *types.Info, expr ast.Expr) (*ast.CompositeLit, *packages.Package) {
+    switch e := expr.(type) {
+    case *ast.ParenExpr:
+        return b.compositeOf(info, e.X)
+    case *ast.UnaryExpr:
+        if e.Op == token.AND {
+            return b.compositeOf(info, e.X)
+        }
+    case *ast.CompositeLit:
+        return e, nil
+    case *ast.CallExpr:
+        fn := calleeFunc(info, e)
+        d, ok := b.decls[fn]
+        if !ok {
+            return nil, nil
+        }
+        var lit *ast.CompositeLit
+        ast.Inspect(d.decl.Body, func(n ast.Node) bool {
+            if ret, ok := n.(*ast.ReturnStmt); ok && lit == nil && len(ret.Results) == 1 {
+                lit, _ = b.compositeOf(d.pkg.TypesInfo, ret.Results[0])
+            }
+            return lit == nil
+        })
+        if lit != nil {
+            return lit, d.pkg
+        }
+    }
+    return nil, nil
+}
+
+func (b *terraformBuilder) addPackage(pkg *packages.Package) {
+    if pkg.TypesInfo == nil {
+        return
+    }
+    for fn, decl := range funcDecls(pkg) {
+        b.decls[fn] = terraformDecl{pkg: pkg, decl: decl}
+    }
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            switch n := n.(type) {
+            case *ast.CompositeLit:
+                if isTerraformSchemaType(pkg.TypesInfo.TypeOf(n), "Resource") && !b.nested[n] {
+                    res := terraformResourceLit{pkg: pkg, lit: n, decl: decl}
+                    if decl != nil {
+                        res.constructor, _ = pkg.TypesInfo.Defs[decl.Name].(*types.Func)
+                    }
+                    b.resources = append(b.resources, res)
+                    b.markNested(pkg.TypesInfo, n)
+                }
+            case *ast.CallExpr:
+                b.recordAccess(pkg, decl, n)
+            }
+            return true
+        })
+    }
+}
+
+// markNested помечает вложенные schema.Resource (Elem) внутри ресурса,
+// чтобы они не считались самостоятельными ресурсами
+func (b *terraformBuilder) markNested(info *types.Info, lit *ast.CompositeLit) {
+    ast.Inspect(lit, func(n ast.Node) bool {
+        if inner, ok := n.(*ast.CompositeLit); ok && inner != lit && isTerraformSchemaType(info.TypeOf(inner), "Resource") {
+            b.nested[inner] = true
+        }
+        return true
+    })
+}
+
+// recordAccess запоминает обращения d.Get("attr")/d.Set("attr", ...) в функции
+func (b *terraformBuilder) recordAccess(pkg *packages.Package, decl *ast.FuncDecl, call *ast.CallExpr) {
+    if decl == nil {
+        return
+    }
+    fn := calleeFunc(pkg.TypesInfo, call)
+    if fn == nil || !resourceDataMethods[fn.Name()] || len(call.Args) == 0 {
+        return
+    }
+    recv := fn.Type().(*types.Signature).Recv()
+    if recv == nil || !isTerraformSchemaType(recv.Type(), "ResourceData") {
+        return
+    }
+    name, ok := constString(pkg.TypesInfo, call.Args[0])
+    if !ok {
+        return
+    }
+    owner, _ := pkg.TypesInfo.Defs[decl.Name].(*types.Func)
+    if owner == nil {
+        return
+    }
+    b.accesses[owner] = append(b.accesses[owner], terraformAttrAccess{attr: name, method: fn.Name()})
+}
+
+// helpers возвращает функции проекта, которым CRUD-функция передаёт
+// *schema.ResourceData (expandX(d), flattenX(d) и т.п.)
+func (b *terraformBuilder) helpers(fn *types.Func) []*types.Func {
+    d, ok := b.decls[fn]
+    if !ok {
+        return nil
+    }
+    var result []*types.Func
+    ast.Inspect(d.decl.Body, func(n ast.Node) bool {
+        call, ok := n.(*ast.CallExpr)
+        if !ok {
+            return true
+        }
+        callee := calleeFunc(d.pkg.TypesInfo, call)
+        if _, project := b.decls[callee]; !project || callee == fn {
+            return true
+        }
+        for _, arg := range call.Args {
+            if isTerraformSchemaType(d.pkg.TypesInfo.TypeOf(arg), "ResourceData") {
+                result = append(result, callee)
+                break
+            }
+        }
+        return true
+    })
+    return result
+}
+
+func (b *terraformBuilder) funcRef(info *types.Info, expr ast.Expr) *types.Func {
+    switch e := expr.(type) {
+    case *ast.Ident:
+        fn, _ := info.Uses[e].(*types.Func)
+        return fn
+    case *ast.SelectorExpr:
+        fn, _ := info.Uses[e.Sel].(*types.Func)
+        return fn
+    }
+    return nil
+}
+
+// attributes разбирает map[string]*schema.Schema
+func (b *terraformBuilder) attributes(pkg *packages.Package, expr ast.Expr) []TerraformAttribute {
+    attrs := []TerraformAttribute{}
+    lit, owner := b.compositeOf(pkg.TypesInfo, expr)
+    if lit == nil {
+        return attrs
+    }
+    if owner != nil {
+        pkg = owner
+    }
+    info := pkg.TypesInfo
+    for _, elt := range lit.Elts {
+        kv, ok := elt.(*ast.KeyValueExpr)
+        if !ok {
+            continue
+        }
+        name, ok := constString(info, kv.Key)
+        if !ok {
+            continue
+        }
+        attr := TerraformAttribute{Name: name, Line: pkg.Fset.Position(kv.Pos()).Line, HandledBy: []TerraformAccess{}}
+        if schemaLit, schemaPkg := b.compositeOf(info, kv.Value); schemaLit != nil {
+            if schemaPkg == nil {
+                schemaPkg = pkg
+            }
+            b.fillAttribute(schemaPkg, schemaLit, &attr)
+        }
+        attrs = append(attrs, attr)
+    }
+    sort.SliceStable(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+    return attrs
+}
+
+// valueType сводит schema.TypeString к "string"
+func valueType(expr ast.Expr) string {
+    if sel, ok := expr.(*ast.SelectorExpr); ok {
+        return strings.ToLower(strings.TrimPrefix(sel.Sel.Name, "Type"))
+    }
+    return ""
+}
+
+func (b *terraformBuilder) fillAttribute(pkg *packages.Package, lit *ast.CompositeLit, attr *TerraformAttribute) {
+    info := pkg.TypesInfo
+    flag := func(expr ast.Expr) bool {
+        tv, ok := info.Types[expr]
+        return ok && tv.Value != nil && tv.Value.Kind() == constant.Bool && constant.BoolVal(tv.Value)
+    }
+    for _, elt := range lit.Elts {
+        kv, ok := elt.(*ast.KeyValueExpr)
+        if !ok {
+            continue
+        }
+        key, ok := kv.Key.(*ast.Ident)
+        if !ok {
+            continue
+        }
+        switch key.Name {
+        case "Type":
+            attr.Type = valueType(kv.Value)
+        case "Required":
+            attr.Required = flag(kv.Value)
+        case "Optional":
+            attr.Optional = flag(kv.Value)
+        case "Computed":
+            attr.Computed = flag(kv.Value)
+        case "ForceNew":
+            attr.ForceNew = flag(kv.Value)
+        case "Sensitive":
+            attr.Sensitive = flag(kv.Value)
+        case "Description":
+            attr.Description, _ = constString(info, kv.Value)
+        case "Elem":
+            elem, elemPkg := b.compositeOf(info, kv.Value)
+            if elem == nil {
+                continue
+            }
+            if elemPkg == nil {
+                elemPkg = pkg
+            }
+            switch {
+            case isTerraformSchemaType(elemPkg.TypesInfo.TypeOf(elem), "Resource"):
+                for _, field := range elem.Elts {
+                    if fkv, ok := field.(*ast.KeyValueExpr); ok {
+                        if id, ok := fkv.Key.(*ast.Ident); ok && id.Name == "Schema" {
+                            attr.Attributes = b.attributes(elemPkg, fkv.Value)
+                        }
+                    }
+                }
+            case isTerraformSchemaType(elemPkg.TypesInfo.TypeOf(elem), "Schema"):
+                for _, field := range elem.Elts {
+                    if fkv, ok := field.(*ast.KeyValueExpr); ok {
+                        if id, ok := fkv.Key.(*ast.Ident); ok && id.Name == "Type" {
+                            attr.ElemType = valueType(fkv.Value)
+                        }
+                    }
+                }
+            }
+        }
+    }
+}
+
+// collectNames запоминает имена ресурсов из ResourcesMap/DataSourcesMap и
+// схему самого провайдера
+func (b *terraformBuilder) collectNames() {
+    for _, d := range b.decls {
+        info := d.pkg.TypesInfo
+        ast.Inspect(d.decl.Body, func(n ast.Node) bool {
+            lit, ok := n.(*ast.CompositeLit)
+            if !ok || !isTerraformSchemaType(info.TypeOf(lit), "Provider") {
+                return true
+            }
+            for _, elt := range lit.Elts {
+                kv, ok := elt.(*ast.KeyValueExpr)
+                if !ok {
+                    continue
+                }
+                key, _ := kv.Key.(*ast.Ident)
+                if key == nil {
+                    continue
+                }
+                kind := map[string]string{"ResourcesMap": "resource", "DataSourcesMap": "data_source"}[key.Name]
+                if key.Name == "Schema" {
+                    b.provider = b.attributes(d.pkg, kv.Value)
+                    continue
+                }
+                m, ok := kv.Value.(*ast.CompositeLit)
+                if kind == "" || !ok {
+                    continue
+                }
+                for _, entry := range m.Elts {
+                    ekv, ok := entry.(*ast.KeyValueExpr)
+                    if !ok {
+                        continue
+                    }
+                    name, ok := constString(info, ekv.Key)
+                    if !ok {
+                        continue
+                    }
+                    if call, ok := ekv.Value.(*ast.CallExpr); ok {
+                        if fn := calleeFunc(info, call); fn != nil {
+                            b.names[fn] = [2]string{name, kind}
+                        }
+                    } else if resLit, _ := b.compositeOf(info, ekv.Value); resLit != nil {
+                        b.litNames[resLit] = [2]string{name, kind}
+                    }
+                }
+            }
+            return true
+        })
+    }
+}
+
+func (b *terraformBuilder) build() *TerraformProvider {
+    if len(b.resources) == 0 {
+        return nil
+    }
+    b.collectNames()
+    provider := &TerraformProvider{Schema: b.provider, Resources: []TerraformResource{}}
+    if provider.Schema == nil {
+        provider.Schema = []TerraformAttribute{}
+    }
+
+    for _, res := range b.resources {
+        info := res.pkg.TypesInfo
+        pos := res.pkg.Fset.Position(res.lit.Pos())
+        resource := TerraformResource{
+            Kind:       "resource",
+            File:       relativePath(b.projectPath, pos.Filename),
+            Line:       pos.Line,
+            CRUD:       map[string]string{},
+            Attributes: []TerraformAttribute{},
+        }
+        naming, named := b.litNames[res.lit]
+        if res.constructor != nil {
+            resource.Constructor = res.constructor.FullName()
+            resource.Name = res.constructor.Name()
+            if n, ok := b.names[res.constructor]; ok {
+                naming, named = n, true
+            }
+        }
+        if named {
+            resource.Name, resource.Kind = naming[0], naming[1]
+        }
+
+        crud := make(map[*types.Func]string)
+        for _, elt := range res.lit.Elts {
+            kv, ok := elt.(*ast.KeyValueExpr)
+            if !ok {
+                continue
+            }
+            key, _ := kv.Key.(*ast.Ident)
+            if key == nil {
+                continue
+            }
+            if op, ok := terraformCRUDFields[key.Name]; ok {
+                if fn := b.funcRef(info, kv.Value); fn != nil {
+                    resource.CRUD[op] = fn.FullName()
+                    crud[fn] = op
+                }
+            }
+            if key.Name == "Schema" {
+                resource.Attributes = b.attributes(res.pkg, kv.Value)
+            }
+        }
+        // Ресурс без CRUD (Elem вне литерала) - не самостоятельный ресурс
+        if len(resource.CRUD) == 0 && !named {
+            continue
+        }
+
+        index := make(map[string]*TerraformAttribute)
+        indexAttributes(index, "", resource.Attributes)
+        ops := make([]*types.Func, 0, len(crud))
+        for fn := range crud {
+            ops = append(ops, fn)
+        }
+        sort.Slice(ops, func(i, j int) bool { return ops[i].FullName() < ops[j].FullName() })
+        for _, fn := range ops {
+            b.attachAccesses(fn, crud[fn], index, make(map[*types.Func]bool))
+        }
+        provider.Resources = append(provider.Resources, resource)
+    }
+    sort.SliceStable(provider.Resources, func(i, j int) bool {
+        if provider.Resources[i].Kind != provider.Resources[j].Kind {
+            return provider.Resources[i].Kind < provider.Resources[j].Kind
+        }
+        return provider.Resources[i].Name < provider.Resources[j].Name
+    })
+    return provider
+}
+
+// indexAttributes индексирует атрибуты по пути: "disk", "disk.size_gb"
+func indexAttributes(index map[string]*TerraformAttribute, prefix string, attrs []TerraformAttribute) {
+    for i := range attrs {
+        path := prefix + attrs[i].Name
+        index[path] = &attrs[i]
+        indexAttributes(index, path+".", attrs[i].Attributes)
+    }
+}
+
+// attachAccesses приписывает атрибутам обращения функции и её помощников
+func (b *terraformBuilder) attachAccesses(fn *types.Func, op string, index map[string]*TerraformAttribute, seen map[*types.Func]bool) {
+    if seen[fn] {
+        return
+    }
+    seen[fn] = true
+    for _, access := range b.accesses[fn] {
+        entry := TerraformAccess{Function: fn.FullName(), Operation: op, Method: access.method}
+        // "disk.0.size_gb" относится и к "disk", и к вложенному "disk.size_gb"
+        path := ""
+        for _, segment := range strings.Split(access.attr, ".") {
+            if segment == "" || strings.Trim(segment, "0123456789#%") == "" {
+                continue
+            }
+            if path != "" {
+                path += "."
+            }
+            path += segment
+            attr, ok := index[path]
+            if !ok {
+                break
+            }
+            duplicate := false
+            for _, existing := range attr.HandledBy {
+                duplicate = duplicate || existing == entry
+            }
+            if !duplicate {
+                attr.HandledBy = append(attr.HandledBy, entry)
+            }
+        }
+    }
+    for _, helper := range b.helpers(fn) {
+        b.attachAccesses(helper, op, index, seen)
+    }
+}
@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+    "context"
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "math"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+type TestgenTarget struct {
+    ID           string   `json:"id"`
+    Kind         string   `json:"kind"`
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Signature    string   `json:"signature"`
+    Score        float64  `json:"score"`
+    Importance   float64  `json:"importance"`
+    Complexity   int      `json:"complexity"`
+    Callers      int      `json:"callers"`
+    TestedBy     []string `json:"tested_by"`
+    Calls        []string `json:"calls"`
+    Uses         []string `json:"uses"`
+}
+
+// TestPatterns - как уже устроены тесты пакета, чтобы новые тесты
+// следовали тем же приёмам
+type TestPatterns struct {
+    Files        []string `json:"files"`
+    Tests        []string `json:"tests"`
+    TableDriven  int      `json:"table_driven"`
+    Subtests     int      `json:"subtests"`
+    Parallel     int      `json:"parallel"`
+    Assertions   []string `json:"assertions"`
+    Example      string   `json:"example,omitempty"`
+}
+
+type TestgenReport struct {
+    Targets      []TestgenTarget          `json:"targets"`
+    Patterns     map[string]*TestPatterns `json:"patterns"`
+}
+
+// Библиотеки проверок в тестах, определяемые по импортам
+var assertionLibraries = map[string]string{
+    "github.com/stretchr/testify/assert":  "testify/assert",
+    "github.com/stretchr/testify/require": "testify/require",
+    "github.com/google/go-cmp/cmp":        "go-cmp",
+    "gotest.tools/v3/assert":              "gotest.tools",
+    "github.com/onsi/gomega":              "gomega",
+    "github.com/matryer/is":               "is",
+}
+
+// funcSourceComplexity разбирает исходный текст функции и считает её
+// цикломатическую сложность
+func funcSourceComplexity(src string) int {
+    file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, parser.SkipObjectResolution)
+    if err != nil || len(file.Decls) == 0 {
+        return 1
+    }
+    if fd, ok := file.Decls[0].(*ast.FuncDecl); ok {
+        return cyclomaticComplexity(fd.Body)
+    }
+    return 1
+}
+
+// testPatterns сводит тесты пакета: табличные тесты, t.Run, t.Parallel,
+// библиотеки проверок; пример - самый короткий табличный тест (или
+// просто самый короткий тест)
+func testPatterns(tests []*testFunc, src *sourceLines) *TestPatterns {
+    patterns := &TestPatterns{Files: []string{}, Tests: []string{}, Assertions: []string{}}
+    var example *testFunc
+    for _, t := range tests {
+        patterns.Files = appendUnique(patterns.Files, t.file)
+        patterns.Tests = append(patterns.Tests, t.name)
+        if t.kind != "test" {
+            continue
+        }
+        if t.table {
+            patterns.TableDriven++
+        }
+        if t.selects["t.Run"] {
+            patterns.Subtests++
+        }
+        if t.selects["t.Parallel"] {
+            patterns.Parallel++
+        }
+        for _, imp := range t.imports {
+            if lib, ok := assertionLibraries[imp]; ok {
+                patterns.Assertions = appendUnique(patterns.Assertions, lib)
+            }
+        }
+        if example == nil || t.table && !example.table ||
+            t.table == example.table && t.endLine-t.line < example.endLine-example.line {
+            example = t
+        }
+    }
+    if len(patterns.Assertions) == 0 && len(tests) > 0 {
+        patterns.Assertions = append(patterns.Assertions, "testing")
+    }
+    sort.Strings(patterns.Assertions)
+    if example != nil {
+        patterns.Example = src.span(example.file, example.line, example.endLine)
+    }
+    return patterns
+}
+
+// buildTestgenTargets ранжирует функции для генерации тестов по
+// произведению важности (вызывающие, экспорт), сложности и непокрытости
+// (функция не упоминается ни в одном тесте)
+func buildTestgenTargets(projectPath string, analysis *ProjectAnalysis, limit int) *TestgenReport {
+    report := &TestgenReport{Targets: []TestgenTarget{}, Patterns: make(map[string]*TestPatterns)}
+    src := &sourceLines{root: projectPath, files: make(map[string][]string)}
+    tests := collectTestFuncs(projectPath)
+    testsByDir := make(map[string][]*testFunc)
+    for _, t := range tests {
+        testsByDir[t.dir] = append(testsByDir[t.dir], t)
+    }
+
+    for _, sym := range goSymbols(analysis, false) {
+        if sym.kind != "function" && sym.kind != "method" || sym.name == "main" || sym.name == "init" {
+            continue
+        }
+        target := TestgenTarget{
+            ID:         sym.id,
+            Kind:       sym.kind,
+            Package:    strings.TrimSuffix(strings.TrimPrefix(sym.id, "go:"), "."+sym.name),
+            File:       sym.file,
+            Line:       sym.line,
+            Signature:  sym.signature,
+            Complexity: funcSourceComplexity(src.span(sym.file, sym.line, sym.endLine)),
+            TestedBy:   []string{},
+            Calls:      []string{},
+            Uses:       []string{},
+        }
+        if analysis.refs != nil {
+            target.Callers = len(analysis.refs.callers(sym.id))
+            for _, i := range analysis.refs.byFrom[sym.id] {
+                ref := analysis.refs.refs[i]
+                if ref.kind == "call" {
+                    target.Calls = appendUnique(target.Calls, ref.to)
+                } else {
+                    target.Uses = appendUnique(target.Uses, ref.to)
+                }
+            }
+        }
+        for _, t := range tests {
+            if t.references(sym) {
+                target.TestedBy = append(target.TestedBy, t.name)
+            }
+        }
+
+        target.Importance = 1 + math.Log2(1+float64(target.Callers))
+        if sym.exported {
+            target.Importance += 1
+        }
+        uncovered := 1.0
+        if len(target.TestedBy) > 0 {
+            uncovered = 0.2
+        }
+        target.Importance = math.Round(target.Importance*100) / 100
+        target.Score = math.Round(target.Importance*float64(target.Complexity)*uncovered*100) / 100
+        report.Targets = append(report.Targets, target)
+    }
+
+    sort.Slice(report.Targets, func(i, j int) bool {
+        if report.Targets[i].Score != report.Targets[j].Score {
+            return report.Targets[i].Score > report.Targets[j].Score
+        }
+        return report.Targets[i].ID < report.Targets[j].ID
+    })
+    if limit > 0 && len(report.Targets) > limit {
+        report.Targets = report.Targets[:limit]
+    }
+    for _, target := range report.Targets {
+        dir := filepath.ToSlash(filepath.Dir(target.File))
+        if _, ok := report.Patterns[target.Package]; !ok {
+            report.Patterns[target.Package] = testPatterns(testsByDir[dir], src)
+        }
+    }
+    return report
+}
+
+func runTestgenTargets(args []string) {
+    fs := newCommandFlags("testgen-targets", "[flags] [project_path]")
+    limit := fs.Int("limit", 20, "emit at most this many targets (0 = all)")
+    projectPath := fs.parse(args)
+
+    analysis := analyzeProject(context.Background(), projectPath, defaultOptions())
+    fs.write(buildTestgenTargets(projectPath, analysis, *limit))
+}
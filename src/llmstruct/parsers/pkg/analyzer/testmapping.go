@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// TestTarget - символ проекта, который проверяет тест. Evidence: name -
+// имя теста называет символ (TestFoo, TestType_Method), reference - тест
+// упоминает символ, call_graph - символ вызывается из названного
+type TestTarget struct {
+    Symbol       string   `json:"symbol"`
+    Kind         string   `json:"kind"`
+    File         string   `json:"file"`
+    Evidence     []string `json:"evidence"`
+}
+
+// TestMapping - тестовая функция из _test.go и то, что она проверяет
+type TestMapping struct {
+    Test         string       `json:"test"`
+    Kind         string       `json:"kind"`
+    File         string       `json:"file"`
+    Line         int          `json:"line"`
+    Targets      []TestTarget `json:"targets"`
+}
+
+// Префиксы тестовых функций по виду testKind
+var testPrefixes = map[string]string{"test": "Test", "benchmark": "Benchmark", "fuzz": "Fuzz", "example": "Example"}
+
+// namedBy - имя теста называет символ пакета: TestFoo и TestFoo_case -
+// функцию или тип Foo, TestType_Method и TestTypeMethod - метод
+func namedBy(test *testFunc, sym *goSymbol) bool {
+    rest := strings.TrimPrefix(test.name, testPrefixes[test.kind])
+    head, tail, _ := strings.Cut(rest, "_")
+    switch sym.kind {
+    case "function", "struct", "interface":
+        return sym.name == rest || sym.name == head
+    case "method":
+        typeName, method, _ := strings.Cut(sym.name, ".")
+        return head == typeName && tail == method || strings.ReplaceAll(sym.name, ".", "") == rest
+    }
+    return false
+}
+
+// buildTestMapping связывает тесты с функциями и типами проекта: по имени
+// (в каталоге теста), по упоминаниям в теле и по графу вызовов из
+// названных символов
+func buildTestMapping(projectPath string, analysis *ProjectAnalysis) []TestMapping {
+    symbols := goSymbols(analysis, false)
+    byDir := make(map[string][]*goSymbol)
+    var candidates []*goSymbol
+    for _, sym := range symbols {
+        if sym.kind == "variable" || sym.kind == "constant" {
+            continue
+        }
+        candidates = append(candidates, sym)
+        dir := filepath.ToSlash(filepath.Dir(sym.file))
+        byDir[dir] = append(byDir[dir], sym)
+    }
+
+    mapping := []TestMapping{}
+    for _, test := range collectTestFuncs(projectPath) {
+        targets := make(map[string]*TestTarget)
+        evidence := func(sym *goSymbol, kind string) {
+            t := targets[sym.id]
+            if t == nil {
+                t = &TestTarget{Symbol: sym.id, Kind: sym.kind, File: sym.file, Evidence: []string{}}
+                targets[sym.id] = t
+            }
+            t.Evidence = appendUnique(t.Evidence, kind)
+        }
+        var named []*goSymbol
+        for _, sym := range byDir[test.dir] {
+            if namedBy(test, sym) {
+                evidence(sym, "name")
+                named = append(named, sym)
+            }
+        }
+        for _, sym := range candidates {
+            if test.references(sym) {
+                evidence(sym, "reference")
+            }
+        }
+        if analysis.refs != nil {
+            for _, sym := range named {
+                for _, callee := range analysis.refs.callees(sym.id) {
+                    if s := symbols[callee]; s != nil && s.kind != "variable" && s.kind != "constant" {
+                        evidence(s, "call_graph")
+                    }
+                }
+            }
+        }
+        if len(targets) == 0 {
+            continue
+        }
+        m := TestMapping{Test: test.name, Kind: test.kind, File: test.file, Line: test.line, Targets: []TestTarget{}}
+        for _, t := range targets {
+            m.Targets = append(m.Targets, *t)
+        }
+        // Сначала названные символы, затем упомянутые, затем вызываемые
+        sort.Slice(m.Targets, func(i, j int) bool {
+            a, b := m.Targets[i], m.Targets[j]
+            if a.Evidence[0] != b.Evidence[0] {
+                return evidenceRank(a.Evidence[0]) < evidenceRank(b.Evidence[0])
+            }
+            return a.Symbol < b.Symbol
+        })
+        mapping = append(mapping, m)
+    }
+    return mapping
+}
+
+func evidenceRank(kind string) int {
+    switch kind {
+    case "name":
+        return 0
+    case "reference":
+        return 1
+    }
+    return 2
+}
@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "io/fs"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// testFunc - тестовая функция из _test.go. Тестовые файлы не входят в
+// загруженные пакеты, поэтому они разбираются синтаксически, а связь с
+// символами проекта устанавливается по именам
+type testFunc struct {
+    name     string
+    kind     string
+    file     string
+    line     int
+    endLine  int
+    dir      string
+    imports  []string
+    table    bool
+    idents   map[string]bool
+    selects  map[string]bool
+}
+
+// testKind определяет вид функции по имени и сигнатуре
+func testKind(decl *ast.FuncDecl) string {
+    if decl.Recv != nil {
+        return ""
+    }
+    name := decl.Name.Name
+    for prefix, kind := range map[string]string{"Test": "test", "Benchmark": "benchmark", "Fuzz": "fuzz", "Example": "example"} {
+        if !strings.HasPrefix(name, prefix) {
+            continue
+        }
+        // TestMain и Testable не тесты: как у go test, после префикса не
+        // может идти строчная буква; цифра и _ допустимы (Test1)
+        r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+        if name == "TestMain" || unicode.IsLower(r) {
+            return ""
+        }
+        if !testSignature(decl.Type, kind) {
+            return ""
+        }
+        return kind
+    }
+    return ""
+}
+
+// testSignature: Test/Benchmark/Fuzz принимают единственный *testing.T,
+// *testing.B или *testing.F и ничего не возвращают, Example - без
+// параметров. Пакет testing может быть импортирован под другим именем
+func testSignature(ft *ast.FuncType, kind string) bool {
+    if ft.TypeParams != nil && len(ft.TypeParams.List) > 0 || ft.Results != nil && len(ft.Results.List) > 0 {
+        return false
+    }
+    params := 0
+    if ft.Params != nil {
+        params = ft.Params.NumFields()
+    }
+    if kind == "example" {
+        return params == 0
+    }
+    if params != 1 {
+        return false
+    }
+    star, ok := ft.Params.List[0].Type.(*ast.StarExpr)
+    if !ok {
+        return false
+    }
+    sel, ok := star.X.(*ast.SelectorExpr)
+    if !ok {
+        return false
+    }
+    return sel.Sel.Name == map[string]string{"test": "T", "benchmark": "B", "fuzz": "F"}[kind]
+}
+
+// collectTestFuncs разбирает все _test.go проекта
+func collectTestFuncs(projectPath string) []*testFunc {
+    var tests []*testFunc
+    fset := token.NewFileSet()
+    filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if d.IsDir() {
+            if path != projectPath && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if !strings.HasSuffix(path, "_test.go") {
+            return nil
+        }
+        file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+        if err != nil {
+            return nil
+        }
+        rel := relativePath(projectPath, path)
+        var imports []string
+        for _, imp := range file.Imports {
+            if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+                imports = append(imports, p)
+            }
+        }
+        for _, decl := range file.Decls {
+            fd, ok := decl.(*ast.FuncDecl)
+            if !ok || fd.Body == nil {
+                continue
+            }
+            kind := testKind(fd)
+            if kind == "" {
+                continue
+            }
+            test := &testFunc{
+                name:    fd.Name.Name,
+                kind:    kind,
+                file:    rel,
+                line:    fset.Position(fd.Pos()).Line,
+                endLine: fset.Position(fd.End()).Line,
+                dir:     filepath.ToSlash(filepath.Dir(rel)),
+                imports: imports,
+                idents:  make(map[string]bool),
+                selects: make(map[string]bool),
+            }
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                switch x := n.(type) {
+                case *ast.Ident:
+                    test.idents[x.Name] = true
+                case *ast.SelectorExpr:
+                    if id, ok := x.X.(*ast.Ident); ok {
+                        test.selects[id.Name+"."+x.Sel.Name] = true
+                    }
+                    // Методы: x.Method(...) - по имени метода
+                    test.idents[x.Sel.Name] = true
+                case *ast.CompositeLit:
+                    // Табличный тест: []struct{...}{...} или map[string]struct{...}{...}
+                    var elem ast.Expr
+                    switch t := x.Type.(type) {
+                    case *ast.ArrayType:
+                        elem = t.Elt
+                    case *ast.MapType:
+                        elem = t.Value
+                    }
+                    if _, ok := elem.(*ast.StructType); ok {
+                        test.table = true
+                    }
+                }
+                return true
+            })
+            tests = append(tests, test)
+        }
+        return nil
+    })
+    sort.Slice(tests, func(i, j int) bool {
+        if tests[i].file != tests[j].file {
+            return tests[i].file < tests[j].file
+        }
+        return tests[i].line < tests[j].line
+    })
+    return tests
+}
+
+// references сообщает, упоминает ли тест символ sym: в том же каталоге -
+// по имени, в другом - через селектор <пакет>.<имя>; методы - по имени
+// метода в тестах пакета типа
+func (t *testFunc) references(sym *goSymbol) bool {
+    dir := filepath.ToSlash(filepath.Dir(sym.file))
+    name := shortName(sym.name)
+    if t.dir == dir {
+        return t.idents[name]
+    }
+    if sym.kind == "method" {
+        return false
+    }
+    pkgName := filepath.Base(dir)
+    return t.selects[pkgName+"."+name]
+}
+
+// testVariants оставляет из пакетов, загруженных с Tests, по одному
+// варианту каждого пакета: "p [p.test]" с тестовыми файлами вместо p,
+// внешние p_test и пакеты без тестов; сгенерированные p.test (main
+// тестового бинарника) отбрасываются
+func testVariants(pkgs []*packages.Package) []*packages.Package {
+    withTests := make(map[string]bool)
+    for _, pkg := range pkgs {
+        if pkg.ID == pkg.PkgPath+" ["+pkg.PkgPath+".test]" {
+            withTests[pkg.PkgPath] = true
+        }
+    }
+    var out []*packages.Package
+    for _, pkg := range pkgs {
+        if pkg.Name == "main" && strings.HasSuffix(pkg.PkgPath, ".test") || pkg.ID == pkg.PkgPath && withTests[pkg.PkgPath] {
+            continue
+        }
+        out = append(out, pkg)
+    }
+    return out
+}
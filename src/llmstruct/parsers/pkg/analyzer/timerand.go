@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// TimeRandUse - обращение к часам или генератору случайных чисел. Kind:
+// clock (time.Now/Since/Until), sleep, math_rand, crypto_rand; Flag:
+// hard_coded_sleep (константная длительность), weak_random (math/rand в
+// функции, связанной с безопасностью)
+type TimeRandUse struct {
+    Line         int      `json:"line"`
+    Call         string   `json:"call"`
+    Kind         string   `json:"kind"`
+    Flag         string   `json:"flag,omitempty"`
+    Detail       string   `json:"detail,omitempty"`
+}
+
+// FunctionTimeRand - недетерминированные зависимости функции, мешающие
+// тестам
+type FunctionTimeRand struct {
+    Function     string        `json:"function"`
+    File         string        `json:"file"`
+    Line         int           `json:"line"`
+    Uses         []TimeRandUse `json:"uses"`
+}
+
+// Слова в имени функции, типа или пакета, указывающие на токены, ключи и
+// пароли
+var securityWords = []string{"token", "secret", "passw", "nonce", "salt", "session", "auth", "csrf", "otp", "crypt", "apikey", "signature", "credential"}
+
+func isSecurityContext(names ...string) bool {
+    for _, name := range names {
+        lower := strings.ToLower(name)
+        for _, word := range securityWords {
+            if strings.Contains(lower, word) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// timeRandKind классифицирует вызываемую функцию или метод
+func timeRandKind(fn *types.Func) string {
+    if fn.Pkg() == nil {
+        return ""
+    }
+    switch fn.Pkg().Path() {
+    case "time":
+        switch fn.Name() {
+        case "Now", "Since", "Until":
+            return "clock"
+        case "Sleep":
+            return "sleep"
+        }
+    case "math/rand", "math/rand/v2":
+        return "math_rand"
+    case "crypto/rand":
+        return "crypto_rand"
+    }
+    return ""
+}
+
+// extractTimeRand перечисляет по функциям обращения к time.Now, time.Sleep,
+// math/rand и crypto/rand
+func extractTimeRand(pkgs []*packages.Package, projectPath string) []FunctionTimeRand {
+    result := []FunctionTimeRand{}
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        info := pkg.TypesInfo
+        for _, file := range pkg.Syntax {
+            for _, decl := range file.Decls {
+                fd, ok := decl.(*ast.FuncDecl)
+                if !ok || fd.Body == nil {
+                    continue
+                }
+                recv := ""
+                if fd.Recv != nil && len(fd.Recv.List) > 0 {
+                    recv = receiverTypeName(extractTypeString(fd.Recv.List[0].Type))
+                }
+                security := isSecurityContext(fd.Name.Name, recv, pkg.Name)
+                var uses []TimeRandUse
+                ast.Inspect(fd.Body, func(n ast.Node) bool {
+                    call, ok := n.(*ast.CallExpr)
+                    if !ok {
+                        return true
+                    }
+                    fn := calleeFunc(info, call)
+                    if fn == nil {
+                        return true
+                    }
+                    kind := timeRandKind(fn)
+                    if kind == "" {
+                        return true
+                    }
+                    use := TimeRandUse{
+                        Line: pkg.Fset.Position(call.Pos()).Line,
+                        Call: types.ExprString(call.Fun),
+                        Kind: kind,
+                    }
+                    switch {
+                    case kind == "sleep" && len(call.Args) == 1 && info.Types[call.Args[0]].Value != nil:
+                        use.Flag = "hard_coded_sleep"
+                        use.Detail = "sleeps for constant " + types.ExprString(call.Args[0]) + "; inject a clock or make the delay configurable"
+                    case kind == "math_rand" && security:
+                        use.Flag = "weak_random"
+                        use.Detail = "math/rand is predictable; use crypto/rand for security-relevant values"
+                    }
+                    uses = append(uses, use)
+                    return true
+                })
+                if len(uses) == 0 {
+                    continue
+                }
+                pos := pkg.Fset.Position(fd.Pos())
+                result = append(result, FunctionTimeRand{
+                    Function: funcID(pkg, fd),
+                    File:     relativePath(projectPath, pos.Filename),
+                    Line:     pos.Line,
+                    Uses:     uses,
+                })
+            }
+        }
+    }
+    sort.Slice(result, func(i, j int) bool {
+        if result[i].File != result[j].File {
+            return result[i].File < result[j].File
+        }
+        return result[i].Line < result[j].Line
+    })
+    return result
+}
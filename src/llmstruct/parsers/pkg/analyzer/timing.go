@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+    "time"
+)
+
+type StageTiming struct {
+    Stage        string   `json:"stage"`
+    DurationMs   float64  `json:"duration_ms"`
+}
+
+type AnalysisMeta struct {
+    TotalMs      float64       `json:"total_ms"`
+    Timings      []StageTiming `json:"timings"`
+    Cache        *CacheStats   `json:"cache,omitempty"`
+}
+
+// stageTimer суммирует время этапов анализа; этапы, вызываемые для каждого
+// пакета, накапливаются под одним именем
+type stageTimer struct {
+    start     time.Time
+    durations map[string]time.Duration
+    order     []string
+}
+
+func newStageTimer() *stageTimer {
+    return &stageTimer{start: time.Now(), durations: make(map[string]time.Duration)}
+}
+
+func (t *stageTimer) track(stage string, fn func()) {
+    begin := time.Now()
+    fn()
+    if _, ok := t.durations[stage]; !ok {
+        t.order = append(t.order, stage)
+    }
+    t.durations[stage] += time.Since(begin)
+}
+
+func milliseconds(d time.Duration) float64 {
+    return float64(d.Microseconds()) / 1000
+}
+
+func (t *stageTimer) meta() *AnalysisMeta {
+    meta := &AnalysisMeta{TotalMs: milliseconds(time.Since(t.start)), Timings: []StageTiming{}}
+    for _, stage := range t.order {
+        meta.Timings = append(meta.Timings, StageTiming{Stage: stage, DurationMs: milliseconds(t.durations[stage])})
+    }
+    return meta
+}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/token"
+    "regexp"
+    "sort"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// tokenCounter оценивает число токенов текста для бюджета контекста LLM
+type tokenCounter func(text []byte) int
+
+// tokenizers - поддерживаемые оценки; пустое имя - cl100k
+var tokenizers = map[string]tokenCounter{
+    "cl100k": cl100kTokens,
+    "chars":  charTokens,
+}
+
+func tokenizerNames() string {
+    names := make([]string, 0, len(tokenizers))
+    for name := range tokenizers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return strings.Join(names, ", ")
+}
+
+func newTokenCounter(name string) (tokenCounter, error) {
+    if name == "" {
+        name = "cl100k"
+    }
+    count, ok := tokenizers[name]
+    if !ok {
+        return nil, fmt.Errorf("unknown tokenizer %q (supported: %s)", name, tokenizerNames())
+    }
+    return count, nil
+}
+
+// Предразбиение cl100k_base без опережающей проверки \s+(?!\S), которой
+// нет в RE2: пробел перед словом достаётся пробельному куску, а не слову
+var cl100kPieceRe = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// cl100kTokens приближает BPE cl100k_base без словаря: текст режется
+// тем же регулярным выражением, что и в tiktoken, а число токенов куска
+// оценивается по его виду. Для Go-кода ошибка обычно в пределах 10-15%
+func cl100kTokens(text []byte) int {
+    total := 0
+    for _, piece := range cl100kPieceRe.FindAll(text, -1) {
+        total += pieceTokens(piece)
+    }
+    return total
+}
+
+func pieceTokens(piece []byte) int {
+    r, _ := utf8.DecodeRune(piece)
+    last, _ := utf8.DecodeLastRune(piece)
+    switch {
+    case unicode.IsSpace(r) && unicode.IsSpace(last):
+        // Отступы и переводы строк склеиваются в один токен
+        return 1
+    case unicode.IsNumber(r):
+        return 1
+    case !unicode.IsLetter(last):
+        // Частые сочетания знаков (":=", "()", "{\n") - один токен
+        return (len(piece) + 1) / 2
+    }
+    tokens := 0
+    segment, nonASCII := 0, 0
+    flush := func() {
+        if segment > 0 {
+            // Словарь покрывает частые слова и части идентификаторов
+            tokens += 1 + (segment-1)/5
+        }
+        segment = 0
+    }
+    prev := rune(0)
+    for _, c := range string(piece) {
+        switch {
+        case c >= utf8.RuneSelf:
+            // Кириллица и прочие алфавиты: около двух символов на токен
+            flush()
+            nonASCII++
+        case unicode.IsUpper(c) && unicode.IsLower(prev):
+            // Граница camelCase
+            flush()
+            segment = 1
+        case unicode.IsLetter(c):
+            segment++
+        default:
+            // Ведущий пробел или знак входит в токен слова
+        }
+        prev = c
+    }
+    flush()
+    return tokens + (nonASCII+1)/2
+}
+
+// charTokens - грубая оценка "четыре байта на токен"
+func charTokens(text []byte) int {
+    return (len(text) + 3) / 4
+}
+
+// nodeTokens считает токены объявления вместе с его doc-комментарием
+func nodeTokens(fset *token.FileSet, src []byte, doc *ast.CommentGroup, node ast.Node, count tokenCounter) int {
+    start := node.Pos()
+    if doc != nil {
+        start = doc.Pos()
+    }
+    from, to := fset.Position(start).Offset, fset.Position(node.End()).Offset
+    if from < 0 || to > len(src) || from > to {
+        return 0
+    }
+    return count(src[from:to])
+}
+
+// annotateTokens проставляет оценку токенов файлу, функциям, типам и
+// методам интерфейсов; объявления сопоставляются по имени и строке
+func annotateTokens(fset *token.FileSet, file *ast.File, src []byte, analysis *FileAnalysis, count tokenCounter) {
+    analysis.Tokens = count(src)
+    line := func(n ast.Node) int { return fset.Position(n.Pos()).Line }
+    for _, decl := range file.Decls {
+        switch d := decl.(type) {
+        case *ast.FuncDecl:
+            for i := range analysis.Functions {
+                if fn := &analysis.Functions[i]; fn.Name == d.Name.Name && fn.Line == line(d) {
+                    fn.Tokens = nodeTokens(fset, src, d.Doc, d, count)
+                }
+            }
+        case *ast.GenDecl:
+            for _, spec := range d.Specs {
+                s, ok := spec.(*ast.TypeSpec)
+                if !ok {
+                    continue
+                }
+                doc := s.Doc
+                if doc == nil && !d.Lparen.IsValid() {
+                    // Комментарий "type X struct" висит на GenDecl
+                    doc = d.Doc
+                }
+                for _, group := range [][]Struct{analysis.Structs, analysis.Interfaces} {
+                    for i := range group {
+                        st := &group[i]
+                        if st.Name != s.Name.Name || st.Line != line(s) {
+                            continue
+                        }
+                        st.Tokens = nodeTokens(fset, src, doc, s, count)
+                        iface, ok := s.Type.(*ast.InterfaceType)
+                        if !ok || iface.Methods == nil {
+                            continue
+                        }
+                        for _, method := range iface.Methods.List {
+                            for j := range st.Methods {
+                                if m := &st.Methods[j]; len(method.Names) > 0 && m.Line == line(method) {
+                                    m.Tokens = nodeTokens(fset, src, method.Doc, method, count)
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type TxQuery struct {
+    Method       string   `json:"method"`
+    Statement    string   `json:"statement,omitempty"`
+    Line         int      `json:"line"`
+}
+
+type Transaction struct {
+    Function     string    `json:"function"`
+    File         string    `json:"file"`
+    Line         int       `json:"line"`
+    Library      string    `json:"library"`
+    Origin       string    `json:"origin"`
+    Begin        string    `json:"begin,omitempty"`
+    Variable     string    `json:"variable"`
+    Commits      []int     `json:"commits"`
+    Rollbacks    []int     `json:"rollbacks"`
+    Queries      []TxQuery `json:"queries"`
+    PassedTo     []string  `json:"passed_to"`
+}
+
+// Типы транзакций поддерживаемых библиотек и их библиотека
+var txTypes = map[string]string{
+    "*database/sql.Tx":                "database/sql",
+    "*github.com/jmoiron/sqlx.Tx":     "sqlx",
+    "github.com/jackc/pgx/v4.Tx":      "pgx",
+    "github.com/jackc/pgx/v5.Tx":      "pgx",
+    "*gorm.io/gorm.DB":                "gorm",
+}
+
+var txBeginNames = map[string]bool{
+    "Begin": true, "BeginTx": true, "Beginx": true, "BeginTxx": true, "MustBegin": true, "MustBeginTx": true,
+}
+
+var txCallbackNames = map[string]bool{
+    "Transaction": true, "BeginFunc": true, "BeginTxFunc": true,
+}
+
+func txLibrary(t types.Type) (string, bool) {
+    if t == nil {
+        return "", false
+    }
+    lib, ok := txTypes[types.TypeString(t, nil)]
+    return lib, ok
+}
+
+// rootIdent возвращает базовый идентификатор цепочки вызовов
+// tx.Where(...).First(...) -> tx
+func rootIdent(expr ast.Expr) *ast.Ident {
+    for {
+        switch e := expr.(type) {
+        case *ast.Ident:
+            return e
+        case *ast.SelectorExpr:
+            expr = e.X
+        case *ast.CallExpr:
+            expr = e.Fun
+        case *ast.ParenExpr:
+            expr = e.X
+        case *ast.StarExpr:
+            expr = e.X
+        default:
+            return nil
+        }
+    }
+}
+
+func extractTransactions(pkg *packages.Package, projectPath string) []Transaction {
+    if pkg.TypesInfo == nil {
+        return nil
+    }
+    info := pkg.TypesInfo
+
+    var result []Transaction
+    for _, file := range pkg.Syntax {
+        for _, decl := range file.Decls {
+            fd, ok := decl.(*ast.FuncDecl)
+            if !ok || fd.Body == nil {
+                continue
+            }
+
+            txs := make(map[types.Object]*Transaction)
+            var order []types.Object
+            addTx := func(obj types.Object, origin, begin string, pos ast.Node) {
+                if obj == nil || txs[obj] != nil {
+                    return
+                }
+                lib, ok := txLibrary(obj.Type())
+                if !ok {
+                    return
+                }
+                // *gorm.DB в параметрах не обязательно транзакция
+                if lib == "gorm" && origin == "parameter" {
+                    return
+                }
+                p := pkg.Fset.Position(pos.Pos())
+                txs[obj] = &Transaction{
+                    Function:  funcID(pkg, fd),
+                    File:      relativePath(projectPath, p.Filename),
+                    Line:      p.Line,
+                    Library:   lib,
+                    Origin:    origin,
+                    Begin:     begin,
+                    Variable:  obj.Name(),
+                    Commits:   []int{},
+                    Rollbacks: []int{},
+                    Queries:   []TxQuery{},
+                    PassedTo:  []string{},
+                }
+                order = append(order, obj)
+            }
+
+            // Транзакции, полученные параметром функции
+            if fd.Type.Params != nil {
+                for _, field := range fd.Type.Params.List {
+                    for _, name := range field.Names {
+                        addTx(info.Defs[name], "parameter", "", name)
+                    }
+                }
+            }
+
+            // Begin-вызовы и колбэки db.Transaction(func(tx *gorm.DB) error {...})
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                switch node := n.(type) {
+                case *ast.AssignStmt:
+                    if len(node.Rhs) != 1 {
+                        return true
+                    }
+                    call, ok := node.Rhs[0].(*ast.CallExpr)
+                    if !ok {
+                        return true
+                    }
+                    fn := calleeFunc(info, call)
+                    if fn == nil || !txBeginNames[fn.Name()] {
+                        return true
+                    }
+                    if id, ok := node.Lhs[0].(*ast.Ident); ok {
+                        addTx(info.ObjectOf(id), "begin", fn.FullName(), call)
+                    }
+                case *ast.CallExpr:
+                    fn := calleeFunc(info, node)
+                    if fn == nil || !txCallbackNames[fn.Name()] {
+                        return true
+                    }
+                    for _, arg := range node.Args {
+                        lit, ok := arg.(*ast.FuncLit)
+                        if !ok || lit.Type.Params == nil {
+                            continue
+                        }
+                        for _, field := range lit.Type.Params.List {
+                            for _, name := range field.Names {
+                                addTx(info.Defs[name], "callback", fn.FullName(), node)
+                            }
+                        }
+                    }
+                }
+                return true
+            })
+
+            if len(txs) == 0 {
+                continue
+            }
+
+            // Запросы, commit/rollback и передача транзакции дальше
+            ast.Inspect(fd.Body, func(n ast.Node) bool {
+                call, ok := n.(*ast.CallExpr)
+                if !ok {
+                    return true
+                }
+                line := pkg.Fset.Position(call.Pos()).Line
+
+                if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+                    if root := rootIdent(sel.X); root != nil {
+                        if tx := txs[info.Uses[root]]; tx != nil {
+                            switch sel.Sel.Name {
+                            case "Commit":
+                                tx.Commits = append(tx.Commits, line)
+                            case "Rollback":
+                                tx.Rollbacks = append(tx.Rollbacks, line)
+                            default:
+                                query := TxQuery{Method: sel.Sel.Name, Line: line}
+                                for _, arg := range call.Args {
+                                    if stmt, ok := constString(info, arg); ok {
+                                        query.Statement = strings.TrimSpace(stmt)
+                                        break
+                                    }
+                                }
+                                tx.Queries = append(tx.Queries, query)
+                            }
+                            return true
+                        }
+                    }
+                }
+
+                for _, arg := range call.Args {
+                    id, ok := arg.(*ast.Ident)
+                    if !ok {
+                        continue
+                    }
+                    tx := txs[info.Uses[id]]
+                    if tx == nil {
+                        continue
+                    }
+                    if fn := calleeFunc(info, call); fn != nil {
+                        tx.PassedTo = append(tx.PassedTo, fn.FullName())
+                    } else {
+                        tx.PassedTo = append(tx.PassedTo, types.ExprString(call.Fun))
+                    }
+                }
+                return true
+            })
+
+            for _, obj := range order {
+                result = append(result, *txs[obj])
+            }
+        }
+    }
+    return result
+}
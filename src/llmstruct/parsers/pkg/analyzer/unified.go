@@ -0,0 +1,231 @@
+package analyzer
+
+import (
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// Версия единой схемы (schemas/llmstruct-unified-v1.json)
+const unifiedSchemaVersion = "1.0"
+
+// Position - позиция символа; путь относительно корня проекта, у пакетов
+// это каталог без строки
+type Position struct {
+    File         string   `json:"file"`
+    Line         int      `json:"line,omitempty"`
+    EndLine      int      `json:"end_line,omitempty"`
+}
+
+// UnifiedSymbol - языконезависимый символ. Kind берётся из общего словаря
+// (package, file, function, method, type, interface, variable, constant),
+// всё специфичное для языка кладётся в Extensions[<язык>]
+type UnifiedSymbol struct {
+    ID           string   `json:"id"`
+    Kind         string   `json:"kind"`
+    Name         string   `json:"name"`
+    Language     string   `json:"language"`
+    Parent       string   `json:"parent,omitempty"`
+    Exported     bool     `json:"exported"`
+    Doc          string   `json:"doc,omitempty"`
+    Position     Position `json:"position"`
+    Extensions   map[string]map[string]any `json:"extensions,omitempty"`
+}
+
+// UnifiedRelation - связь между символами: contains, member_of, imports,
+// implements. Цель может быть внешней и отсутствовать в списке символов
+type UnifiedRelation struct {
+    Kind         string   `json:"kind"`
+    From         string   `json:"from"`
+    To           string   `json:"to"`
+}
+
+type UnifiedSchema struct {
+    SchemaVersion string            `json:"schema_version"`
+    Languages     []string          `json:"languages"`
+    Symbols       []UnifiedSymbol   `json:"symbols"`
+    Relations     []UnifiedRelation `json:"relations"`
+}
+
+// Идентификаторы: файлы общие для всех языков ("file:<путь>"), остальные
+// символы - "<язык>:<квалифицированное имя>"
+func unifiedFileID(path string) string {
+    return "file:" + filepath.ToSlash(path)
+}
+
+func goSymbolID(pkgPath, name string) string {
+    return "go:" + pkgPath + "." + name
+}
+
+// receiverTypeName сводит receiver к имени типа: *List[T] -> List
+func receiverTypeName(receiver string) string {
+    name := strings.TrimPrefix(receiver, "*")
+    if i := strings.Index(name, "["); i >= 0 {
+        name = name[:i]
+    }
+    return name
+}
+
+// unifiedBuilder переводит анализ файлов в единую схему
+type unifiedBuilder struct {
+    schema   *UnifiedSchema
+    packages map[string]bool
+}
+
+func newUnifiedBuilder() *unifiedBuilder {
+    return &unifiedBuilder{
+        schema: &UnifiedSchema{
+            SchemaVersion: unifiedSchemaVersion,
+            Languages:     []string{"go"},
+            Symbols:       []UnifiedSymbol{},
+            Relations:     []UnifiedRelation{},
+        },
+        packages: make(map[string]bool),
+    }
+}
+
+func (b *unifiedBuilder) symbol(sym UnifiedSymbol) {
+    sym.Language = "go"
+    b.schema.Symbols = append(b.schema.Symbols, sym)
+    if sym.Parent != "" {
+        b.relation("contains", sym.Parent, sym.ID)
+    }
+}
+
+func (b *unifiedBuilder) relation(kind, from, to string) {
+    b.schema.Relations = append(b.schema.Relations, UnifiedRelation{Kind: kind, From: from, To: to})
+}
+
+func (b *unifiedBuilder) addFile(pkg *packages.Package, file *FileAnalysis) {
+    pkgID := "go:" + pkg.PkgPath
+    if !b.packages[pkg.PkgPath] {
+        b.packages[pkg.PkgPath] = true
+        b.symbol(UnifiedSymbol{
+            ID:         pkgID,
+            Kind:       "package",
+            Name:       pkg.Name,
+            Exported:   true,
+            Position:   Position{File: filepath.ToSlash(filepath.Dir(file.Path))},
+            Extensions: map[string]map[string]any{"go": {"import_path": pkg.PkgPath}},
+        })
+    }
+
+    fileID := unifiedFileID(file.Path)
+    b.symbol(UnifiedSymbol{
+        ID:         fileID,
+        Kind:       "file",
+        Name:       filepath.Base(file.Path),
+        Parent:     pkgID,
+        Exported:   true,
+        Position:   Position{File: filepath.ToSlash(file.Path), Line: 1, EndLine: file.LineCount},
+        Extensions: map[string]map[string]any{"go": {"package": file.Package, "test": file.HasTests}},
+    })
+    for _, imp := range file.Imports {
+        b.relation("imports", fileID, "go:"+imp.Path)
+    }
+
+    pos := func(line, endLine int) Position {
+        return Position{File: filepath.ToSlash(file.Path), Line: line, EndLine: endLine}
+    }
+    for _, fn := range file.Functions {
+        ext := map[string]any{"params": fn.Params, "returns": fn.Returns}
+        if !fn.IsMethod {
+            b.symbol(UnifiedSymbol{
+                ID:         goSymbolID(pkg.PkgPath, fn.Name),
+                Kind:       "function",
+                Name:       fn.Name,
+                Parent:     fileID,
+                Exported:   fn.IsExported,
+                Doc:        fn.Docstring,
+                Position:   pos(fn.Line, fn.EndLine),
+                Extensions: map[string]map[string]any{"go": ext},
+            })
+            continue
+        }
+        recv := receiverTypeName(fn.Receiver)
+        ext["receiver"] = fn.Receiver
+        ext["pointer_receiver"] = strings.HasPrefix(fn.Receiver, "*")
+        id := goSymbolID(pkg.PkgPath, recv+"."+fn.Name)
+        b.symbol(UnifiedSymbol{
+            ID:         id,
+            Kind:       "method",
+            Name:       fn.Name,
+            Parent:     fileID,
+            Exported:   fn.IsExported,
+            Doc:        fn.Docstring,
+            Position:   pos(fn.Line, fn.EndLine),
+            Extensions: map[string]map[string]any{"go": ext},
+        })
+        b.relation("member_of", id, goSymbolID(pkg.PkgPath, recv))
+    }
+    for _, st := range file.Structs {
+        b.symbol(UnifiedSymbol{
+            ID:         goSymbolID(pkg.PkgPath, st.Name),
+            Kind:       "type",
+            Name:       st.Name,
+            Parent:     fileID,
+            Exported:   st.IsExported,
+            Doc:        st.Docstring,
+            Position:   pos(st.Line, st.EndLine),
+            Extensions: map[string]map[string]any{"go": {"type_kind": "struct", "fields": st.Fields}},
+        })
+    }
+    for _, iface := range file.Interfaces {
+        methods := make([]string, len(iface.Methods))
+        for i, m := range iface.Methods {
+            methods[i] = m.Name + strings.TrimPrefix(funcSignature(m), "func")
+        }
+        b.symbol(UnifiedSymbol{
+            ID:         goSymbolID(pkg.PkgPath, iface.Name),
+            Kind:       "interface",
+            Name:       iface.Name,
+            Parent:     fileID,
+            Exported:   iface.IsExported,
+            Doc:        iface.Docstring,
+            Position:   pos(iface.Line, iface.EndLine),
+            Extensions: map[string]map[string]any{"go": {"methods": methods, "embeds": iface.Fields}},
+        })
+    }
+    values := append(append([]Variable{}, file.Variables...), file.Constants...)
+    for _, v := range values {
+        kind := "variable"
+        if v.IsConstant {
+            kind = "constant"
+        }
+        sym := UnifiedSymbol{
+            ID:       goSymbolID(pkg.PkgPath, v.Name),
+            Kind:     kind,
+            Name:     v.Name,
+            Parent:   fileID,
+            Exported: v.IsExported,
+            Position: pos(v.Line, 0),
+        }
+        if v.Type != "" || v.Value != "" {
+            ext := make(map[string]any)
+            if v.Type != "" {
+                ext["type"] = v.Type
+            }
+            if v.Value != "" {
+                ext["value"] = v.Value
+            }
+            sym.Extensions = map[string]map[string]any{"go": ext}
+        }
+        b.symbol(sym)
+    }
+}
+
+// build добавляет связи implements и упорядочивает связи
+func (b *unifiedBuilder) build(implementations []TypeImplementations) *UnifiedSchema {
+    for _, impl := range implementations {
+        for _, iface := range impl.Interfaces {
+            iface, _, _ = strings.Cut(iface, " ")
+            b.relation("implements", "go:"+impl.Type, "go:"+iface)
+        }
+    }
+    sort.SliceStable(b.schema.Relations, func(i, j int) bool {
+        return b.schema.Relations[i].Kind < b.schema.Relations[j].Kind
+    })
+    return b.schema
+}
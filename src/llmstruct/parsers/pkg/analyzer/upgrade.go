@@ -0,0 +1,268 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/ast"
+    "go/token"
+    "go/types"
+    "go/version"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// UpgradeIssue - код, требующий внимания при переходе на новую версию Go.
+// Kind: deprecated (API объявлен устаревшим к целевой версии), behavior
+// (поведение меняется между текущей и целевой версией, обычно вместе со
+// строкой go в go.mod), language (изменение семантики языка)
+type UpgradeIssue struct {
+    Package      string   `json:"package"`
+    File         string   `json:"file"`
+    Line         int      `json:"line"`
+    Function     string   `json:"function,omitempty"`
+    API          string   `json:"api"`
+    Kind         string   `json:"kind"`
+    Since        string   `json:"since"`
+    Note         string   `json:"note"`
+    Replacement  string   `json:"replacement,omitempty"`
+}
+
+type UpgradeReport struct {
+    From         string         `json:"from"`
+    To           string         `json:"to"`
+    Issues       []UpgradeIssue `json:"issues"`
+}
+
+// goChange - запись встроенной базы изменений стандартной библиотеки.
+// API - "pkg" (любое использование пакета), "pkg.Name" или
+// "pkg.Type.Method"
+type goChange struct {
+    api         string
+    kind        string
+    since       string
+    note        string
+    replacement string
+}
+
+var goChanges = []goChange{
+    {"io/ioutil", "deprecated", "1.16", "io/ioutil is deprecated; its functions are thin wrappers", "io and os (io.ReadAll, os.ReadFile, os.WriteFile, os.MkdirTemp, os.ReadDir)"},
+    {"crypto/dsa", "deprecated", "1.16", "DSA is a legacy algorithm", "crypto/ecdsa or crypto/ed25519"},
+    {"crypto/x509.IsEncryptedPEMBlock", "deprecated", "1.16", "legacy PEM encryption is insecure by design", ""},
+    {"crypto/x509.DecryptPEMBlock", "deprecated", "1.16", "legacy PEM encryption is insecure by design", ""},
+    {"crypto/x509.EncryptPEMBlock", "deprecated", "1.16", "legacy PEM encryption is insecure by design", ""},
+    {"net/url.ParseQuery", "behavior", "1.17", "semicolons are no longer accepted as query separators; such pairs are rejected", ""},
+    {"net/url.URL.Query", "behavior", "1.17", "semicolons are no longer accepted as query separators; such pairs are dropped", ""},
+    {"strings.Title", "deprecated", "1.18", "does not handle Unicode punctuation and word boundaries properly", "golang.org/x/text/cases"},
+    {"bytes.Title", "deprecated", "1.18", "does not handle Unicode punctuation and word boundaries properly", "golang.org/x/text/cases"},
+    {"net.Error.Temporary", "deprecated", "1.18", "temporary errors are not well-defined", "check specific errors (timeouts via Timeout)"},
+    {"crypto/x509.Certificate.Verify", "behavior", "1.18", "certificates signed with SHA-1 are rejected", ""},
+    {"os/exec.Command", "behavior", "1.19", "programs found relative to the current directory are no longer run; exec.ErrDot is returned", "an absolute or ./-prefixed path"},
+    {"os/exec.LookPath", "behavior", "1.19", "results relative to the current directory return exec.ErrDot", ""},
+    {"crypto/x509.ParseCRL", "deprecated", "1.19", "parses only the legacy CRL format", "x509.ParseRevocationList"},
+    {"crypto/x509.ParseDERCRL", "deprecated", "1.19", "parses only the legacy CRL format", "x509.ParseRevocationList"},
+    {"crypto/x509.Certificate.CreateCRL", "deprecated", "1.19", "creates legacy v1 CRLs", "x509.CreateRevocationList"},
+    {"math/rand.Seed", "deprecated", "1.20", "the global generator is seeded randomly at startup", "rand.New(rand.NewSource(seed)) for reproducible sequences"},
+    {"math/rand.Read", "deprecated", "1.20", "not a cryptographically secure source", "crypto/rand.Read"},
+    {"reflect.SliceHeader", "deprecated", "1.20", "unsafe to use; layout is not guaranteed", "unsafe.Slice or unsafe.SliceData"},
+    {"reflect.StringHeader", "deprecated", "1.20", "unsafe to use; layout is not guaranteed", "unsafe.String or unsafe.StringData"},
+    {"crypto/elliptic.Marshal", "deprecated", "1.21", "low-level elliptic curve operations are deprecated", "crypto/ecdh"},
+    {"crypto/elliptic.Unmarshal", "deprecated", "1.21", "low-level elliptic curve operations are deprecated", "crypto/ecdh"},
+    {"crypto/elliptic.GenerateKey", "deprecated", "1.21", "low-level elliptic curve operations are deprecated", "crypto/ecdh or crypto/ecdsa"},
+    {"net/http.HandleFunc", "behavior", "1.22", "ServeMux patterns accept methods and wildcards; paths with {, } or spaces are interpreted differently (GODEBUG httpmuxgo121)", ""},
+    {"net/http.Handle", "behavior", "1.22", "ServeMux patterns accept methods and wildcards; paths with {, } or spaces are interpreted differently (GODEBUG httpmuxgo121)", ""},
+    {"net/http.ServeMux.HandleFunc", "behavior", "1.22", "ServeMux patterns accept methods and wildcards; paths with {, } or spaces are interpreted differently (GODEBUG httpmuxgo121)", ""},
+    {"net/http.ServeMux.Handle", "behavior", "1.22", "ServeMux patterns accept methods and wildcards; paths with {, } or spaces are interpreted differently (GODEBUG httpmuxgo121)", ""},
+    {"crypto/tls.Config", "behavior", "1.22", "RSA key exchange cipher suites are no longer offered by default and servers require TLS 1.2 (GODEBUG tlsrsakex, tls10server)", ""},
+    {"time.NewTimer", "behavior", "1.23", "timer channels are unbuffered and unstopped timers are collected; Reset/Stop no longer leave stale values (GODEBUG asynctimerchan)", ""},
+    {"time.Timer.Reset", "behavior", "1.23", "after Reset or Stop no stale value is received from the channel (GODEBUG asynctimerchan)", ""},
+    {"time.Timer.Stop", "behavior", "1.23", "after Reset or Stop no stale value is received from the channel (GODEBUG asynctimerchan)", ""},
+    {"time.NewTicker", "behavior", "1.23", "unstopped tickers are collected and channels are unbuffered (GODEBUG asynctimerchan)", ""},
+    {"crypto/x509.ParseCertificate", "behavior", "1.23", "certificates with negative serial numbers are rejected (GODEBUG x509negativeserial)", ""},
+    {"runtime.GOROOT", "deprecated", "1.24", "the GOROOT of the running binary may not exist at run time", "go env GOROOT"},
+    {"crypto/cipher.NewOFB", "deprecated", "1.24", "unauthenticated stream mode", "crypto/cipher.AEAD or NewCTR"},
+    {"crypto/cipher.NewCFBEncrypter", "deprecated", "1.24", "unauthenticated stream mode", "crypto/cipher.AEAD or NewCTR"},
+    {"crypto/cipher.NewCFBDecrypter", "deprecated", "1.24", "unauthenticated stream mode", "crypto/cipher.AEAD or NewCTR"},
+    {"math/rand.Seed", "behavior", "1.24", "Seed has no effect on the global generator (GODEBUG randseednop)", "rand.New(rand.NewSource(seed))"},
+    {"crypto/rsa.GenerateKey", "behavior", "1.24", "keys shorter than 1024 bits are rejected (GODEBUG rsa1024min)", ""},
+}
+
+// goVersion приводит "1.22", "1.22.3" и "go1.22" к виду go/version
+func goVersion(v string) string {
+    v = strings.TrimSpace(v)
+    if !strings.HasPrefix(v, "go") {
+        v = "go" + v
+    }
+    return v
+}
+
+func validGoVersion(v string) error {
+    if !version.IsValid(goVersion(v)) {
+        return fmt.Errorf("invalid Go version %q (expected e.g. 1.23)", v)
+    }
+    return nil
+}
+
+// applies: устаревание важно, если наступило к целевой версии; изменение
+// поведения - только если версия пересекается при обновлении
+func (c goChange) applies(from, to string) bool {
+    since := goVersion(c.since)
+    if version.Compare(since, goVersion(to)) > 0 {
+        return false
+    }
+    return c.kind == "deprecated" || from == "" || version.Compare(since, goVersion(from)) > 0
+}
+
+// changeAPI - ключ объекта в базе изменений: pkg.Name или pkg.Type.Method
+func changeAPI(obj types.Object) string {
+    if obj.Pkg() == nil {
+        return ""
+    }
+    if fn, ok := obj.(*types.Func); ok {
+        if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+            named, ok := deref(recv.Type()).(*types.Named)
+            if !ok {
+                return ""
+            }
+            return obj.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name()
+        }
+    }
+    if obj.Parent() != obj.Pkg().Scope() {
+        return ""
+    }
+    return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// extractUpgradeIssues сопоставляет использование API проектом со
+// встроенной базой изменений между версией из go.mod и целевой
+func extractUpgradeIssues(pkgs []*packages.Package, projectPath, from, to string) *UpgradeReport {
+    report := &UpgradeReport{From: from, To: to, Issues: []UpgradeIssue{}}
+    changes := make(map[string][]goChange)
+    for _, c := range goChanges {
+        if c.applies(from, to) {
+            changes[c.api] = append(changes[c.api], c)
+        }
+    }
+    add := func(pkg *packages.Package, decl *ast.FuncDecl, pos token.Pos, api string, c goChange) {
+        p := pkg.Fset.Position(pos)
+        report.Issues = append(report.Issues, UpgradeIssue{
+            Package:     pkg.PkgPath,
+            File:        relativePath(projectPath, p.Filename),
+            Line:        p.Line,
+            Function:    funcID(pkg, decl),
+            API:         api,
+            Kind:        c.kind,
+            Since:       c.since,
+            Note:        c.note,
+            Replacement: c.replacement,
+        })
+    }
+    loopvar := from != "" && version.Compare(goVersion(from), "go1.22") < 0 && version.Compare(goVersion(to), "go1.22") >= 0
+    for _, pkg := range pkgs {
+        if pkg.TypesInfo == nil {
+            continue
+        }
+        info := pkg.TypesInfo
+        for _, file := range pkg.Syntax {
+            for _, imp := range file.Imports {
+                path := strings.Trim(imp.Path.Value, `"`)
+                for _, c := range changes[path] {
+                    add(pkg, nil, imp.Pos(), path, c)
+                }
+            }
+            // Одно место на API в функции: повторы ничего не добавляют
+            seen := make(map[string]bool)
+            inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+                switch x := n.(type) {
+                case *ast.Ident:
+                    obj := info.Uses[x]
+                    if obj == nil {
+                        return true
+                    }
+                    api := changeAPI(obj)
+                    for _, c := range changes[api] {
+                        key := funcID(pkg, decl) + "|" + api + "|" + c.kind
+                        if !seen[key] {
+                            seen[key] = true
+                            add(pkg, decl, x.Pos(), api, c)
+                        }
+                    }
+                case *ast.ForStmt, *ast.RangeStmt:
+                    if loopvar {
+                        for _, pos := range sharedLoopVars(info, x) {
+                            add(pkg, decl, pos, "for loop variable", goChange{
+                                kind:  "language",
+                                since: "1.22",
+                                note:  "loop variables are per-iteration once go.mod says go 1.22; closures and pointers here no longer share one variable",
+                            })
+                        }
+                    }
+                }
+                return true
+            })
+        }
+    }
+    sort.SliceStable(report.Issues, func(i, j int) bool {
+        if report.Issues[i].File != report.Issues[j].File {
+            return report.Issues[i].File < report.Issues[j].File
+        }
+        return report.Issues[i].Line < report.Issues[j].Line
+    })
+    return report
+}
+
+// sharedLoopVars находит переменные цикла (объявленные через :=), которые
+// захватываются замыканием или чей адрес берётся в теле: только здесь
+// переход к переменным на итерацию меняет поведение
+func sharedLoopVars(info *types.Info, loop ast.Node) []token.Pos {
+    vars := make(map[types.Object]bool)
+    var body *ast.BlockStmt
+    define := func(exprs ...ast.Expr) {
+        for _, e := range exprs {
+            if id, ok := e.(*ast.Ident); ok && info.Defs[id] != nil {
+                vars[info.Defs[id]] = true
+            }
+        }
+    }
+    switch x := loop.(type) {
+    case *ast.ForStmt:
+        if init, ok := x.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+            define(init.Lhs...)
+        }
+        body = x.Body
+    case *ast.RangeStmt:
+        if x.Tok == token.DEFINE {
+            define(x.Key, x.Value)
+        }
+        body = x.Body
+    }
+    if len(vars) == 0 || body == nil {
+        return nil
+    }
+    var found []token.Pos
+    reported := make(map[types.Object]bool)
+    report := func(id *ast.Ident) {
+        if obj := info.Uses[id]; vars[obj] && !reported[obj] {
+            reported[obj] = true
+            found = append(found, id.Pos())
+        }
+    }
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.FuncLit:
+            ast.Inspect(x.Body, func(m ast.Node) bool {
+                if id, ok := m.(*ast.Ident); ok {
+                    report(id)
+                }
+                return true
+            })
+            return false
+        case *ast.UnaryExpr:
+            if id, ok := ast.Unparen(x.X).(*ast.Ident); ok && x.Op == token.AND {
+                report(id)
+            }
+        }
+        return true
+    })
+    return found
+}
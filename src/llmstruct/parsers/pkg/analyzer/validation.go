@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "reflect"
+    "strconv"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type ValidationRule struct {
+    Name         string   `json:"name"`
+    Params       []string `json:"params,omitempty"`
+}
+
+type FieldValidation struct {
+    Struct       string           `json:"struct"`
+    Field        string           `json:"field"`
+    Source       string           `json:"source"`
+    Rules        []ValidationRule `json:"rules"`
+    File         string           `json:"file"`
+    Line         int              `json:"line"`
+}
+
+// Теги валидаторов: go-playground/validator и gin binding
+var validationTagKeys = []string{"validate", "binding"}
+
+// parseValidateTag разбирает тег go-playground/validator:
+// "required,min=3,oneof=a b,email|url"
+func parseValidateTag(tag string) []ValidationRule {
+    rules := []ValidationRule{}
+    for _, part := range strings.Split(tag, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        if strings.Contains(part, "|") {
+            rules = append(rules, ValidationRule{Name: "or", Params: strings.Split(part, "|")})
+            continue
+        }
+        name, param, hasParam := strings.Cut(part, "=")
+        rule := ValidationRule{Name: name}
+        if hasParam {
+            if name == "oneof" {
+                rule.Params = strings.Fields(param)
+            } else {
+                rule.Params = []string{param}
+            }
+        }
+        rules = append(rules, rule)
+    }
+    return rules
+}
+
+// ozzoRule описывает правило ozzo-validation: validation.Required,
+// validation.Length(5, 50), is.Email
+func ozzoRule(info *types.Info, expr ast.Expr) ValidationRule {
+    // Цепочки вида validation.Length(1, 5).Error("...") сводим к базовому правилу
+    if call, ok := expr.(*ast.CallExpr); ok {
+        if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+            if inner, ok := sel.X.(*ast.CallExpr); ok && (sel.Sel.Name == "Error" || sel.Sel.Name == "ErrorObject") {
+                return ozzoRule(info, inner)
+            }
+        }
+        rule := ValidationRule{Name: ozzoRuleName(call.Fun)}
+        for _, arg := range call.Args {
+            value := types.ExprString(arg)
+            if tv, ok := info.Types[arg]; ok && tv.Value != nil {
+                value = tv.Value.ExactString()
+            }
+            rule.Params = append(rule.Params, value)
+        }
+        return rule
+    }
+    return ValidationRule{Name: ozzoRuleName(expr)}
+}
+
+func ozzoRuleName(expr ast.Expr) string {
+    name := types.ExprString(expr)
+    if sel, ok := expr.(*ast.SelectorExpr); ok {
+        name = sel.Sel.Name
+    }
+    return strings.ToLower(name[:1]) + name[1:]
+}
+
+func extractValidations(pkg *packages.Package, projectPath string) []FieldValidation {
+    var result []FieldValidation
+
+    for _, file := range pkg.Syntax {
+        // Теги validate/binding на полях структур
+        ast.Inspect(file, func(n ast.Node) bool {
+            spec, ok := n.(*ast.TypeSpec)
+            if !ok {
+                return true
+            }
+            st, ok := spec.Type.(*ast.StructType)
+            if !ok || st.Fields == nil {
+                return true
+            }
+            for _, field := range st.Fields.List {
+                if field.Tag == nil || len(field.Names) == 0 {
+                    continue
+                }
+                raw, err := strconv.Unquote(field.Tag.Value)
+                if err != nil {
+                    continue
+                }
+                for _, key := range validationTagKeys {
+                    tag, ok := reflect.StructTag(raw).Lookup(key)
+                    if !ok || tag == "" || tag == "-" {
+                        continue
+                    }
+                    pos := pkg.Fset.Position(field.Pos())
+                    for _, name := range field.Names {
+                        result = append(result, FieldValidation{
+                            Struct: pkg.PkgPath + "." + spec.Name.Name,
+                            Field:  name.Name,
+                            Source: key + " tag",
+                            Rules:  parseValidateTag(tag),
+                            File:   relativePath(projectPath, pos.Filename),
+                            Line:   pos.Line,
+                        })
+                    }
+                }
+            }
+            return true
+        })
+
+        if pkg.TypesInfo == nil {
+            continue
+        }
+
+        // ozzo-validation: validation.Field(&s.Name, validation.Required, ...)
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok || len(call.Args) == 0 {
+                return true
+            }
+            fn := calleeFunc(pkg.TypesInfo, call)
+            if fn == nil || fn.Pkg() == nil || !strings.HasPrefix(fn.Pkg().Path(), "github.com/go-ozzo/ozzo-validation") {
+                return true
+            }
+            if fn.Name() != "Field" && fn.Name() != "FieldStruct" {
+                return true
+            }
+
+            target, ok := call.Args[0].(*ast.UnaryExpr)
+            if !ok {
+                return true
+            }
+            sel, ok := target.X.(*ast.SelectorExpr)
+            if !ok {
+                return true
+            }
+            selection, ok := pkg.TypesInfo.Selections[sel]
+            if !ok {
+                return true
+            }
+            owner := namedStruct(selection.Recv())
+            if owner == nil {
+                return true
+            }
+
+            rules := []ValidationRule{}
+            for _, arg := range call.Args[1:] {
+                rules = append(rules, ozzoRule(pkg.TypesInfo, arg))
+            }
+            pos := pkg.Fset.Position(call.Pos())
+            result = append(result, FieldValidation{
+                Struct: typeID(owner),
+                Field:  sel.Sel.Name,
+                Source: "ozzo-validation",
+                Rules:  rules,
+                File:   relativePath(projectPath, pos.Filename),
+                Line:   pos.Line,
+            })
+            return true
+        })
+    }
+    return result
+}
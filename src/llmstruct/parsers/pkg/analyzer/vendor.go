@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+// Режимы -vendor: skip - каталог vendor не анализируется (как ./...),
+// deps-only - вендоренные пакеты только дополняют Dependencies,
+// include - вендоренные пакеты анализируются наравне с пакетами проекта
+const (
+    vendorSkip     = "skip"
+    vendorDepsOnly = "deps-only"
+    vendorInclude  = "include"
+)
+
+func validVendorMode(mode string) error {
+    switch mode {
+    case "", vendorSkip, vendorDepsOnly, vendorInclude:
+        return nil
+    }
+    return fmt.Errorf("unknown vendor mode %q (supported: skip, deps-only, include)", mode)
+}
+
+// hasVendor - у проекта есть vendor/modules.txt, то есть go может
+// загружать зависимости из vendor
+func hasVendor(projectPath string) bool {
+    return fileExists(filepath.Join(projectPath, "vendor", "modules.txt"))
+}
+
+// vendoredPackages - пакеты из vendor/modules.txt: строки без "#" - пути
+// импорта вендоренных пакетов
+func vendoredPackages(projectPath string) ([]string, error) {
+    f, err := os.Open(filepath.Join(projectPath, "vendor", "modules.txt"))
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    var pkgs []string
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        line := strings.TrimSpace(scanner.Text())
+        if line != "" && !strings.HasPrefix(line, "#") {
+            pkgs = append(pkgs, line)
+        }
+    }
+    sort.Strings(pkgs)
+    return pkgs, scanner.Err()
+}
+
+// withVendored добавляет к пакетам проекта загруженные из vendor
+// зависимости: ./... их не включает, а синтаксис и типы у них уже есть
+func withVendored(projectPath string, pkgs []*packages.Package) []*packages.Package {
+    vendor := filepath.Join(projectPath, "vendor")
+    seen := make(map[*packages.Package]bool)
+    for _, pkg := range pkgs {
+        seen[pkg] = true
+    }
+    var vendored []*packages.Package
+    packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+        if !seen[pkg] && len(pkg.CompiledGoFiles) > 0 && hasPathPrefix(pkg.CompiledGoFiles[0], vendor) {
+            vendored = append(vendored, pkg)
+        }
+    })
+    sort.Slice(vendored, func(i, j int) bool { return vendored[i].PkgPath < vendored[j].PkgPath })
+    return append(pkgs, vendored...)
+}
@@ -0,0 +1,140 @@
+package analyzer
+
+import "sort"
+
+type WireChange struct {
+    Schema       string   `json:"schema"`
+    Field        string   `json:"field,omitempty"`
+    GoField      string   `json:"go_field,omitempty"`
+    Change       string   `json:"change"`
+    Before       string   `json:"before,omitempty"`
+    After        string   `json:"after,omitempty"`
+    Breaking     bool     `json:"breaking"`
+}
+
+// wireType - краткое описание типа свойства для сравнения и вывода
+func wireType(s *JSONSchema) string {
+    if s == nil {
+        return ""
+    }
+    switch {
+    case s.Ref != "":
+        return s.Ref
+    case s.Items != nil:
+        return "array<" + wireType(s.Items) + ">"
+    case s.AdditionalProperties != nil:
+        return "map<" + wireType(s.AdditionalProperties) + ">"
+    }
+    t := s.Type
+    if s.Format != "" {
+        t += "(" + s.Format + ")"
+    }
+    if t == "" {
+        t = "any"
+    }
+    return t
+}
+
+func isRequired(s *JSONSchema, name string) bool {
+    for _, r := range s.Required {
+        if r == name {
+            return true
+        }
+    }
+    return false
+}
+
+// diffWireSchemas сравнивает выведенные JSON/DB-схемы двух ревизий.
+// Ломающими считаются удалённые схемы и поля, переименованные json-теги и
+// колонки, смена типа; поле, ставшее необязательным, и смена собственного
+// маршалера - предупреждения
+func diffWireSchemas(base, head []JSONSchema) []WireChange {
+    heads := make(map[string]*JSONSchema, len(head))
+    for i := range head {
+        heads[head[i].ID] = &head[i]
+    }
+    changes := []WireChange{}
+    seen := make(map[string]bool)
+    for i := range base {
+        old := &base[i]
+        seen[old.ID] = true
+        cur := heads[old.ID]
+        if cur == nil {
+            changes = append(changes, WireChange{Schema: old.ID, Change: "schema removed", Breaking: true})
+            continue
+        }
+        if old.CustomMarshaler != cur.CustomMarshaler {
+            changes = append(changes, WireChange{Schema: old.ID, Change: "custom marshaler changed", Before: old.CustomMarshaler, After: cur.CustomMarshaler})
+        }
+        changes = append(changes, diffWireProperties(old, cur)...)
+    }
+    for i := range head {
+        if !seen[head[i].ID] {
+            changes = append(changes, WireChange{Schema: head[i].ID, Change: "schema added"})
+        }
+    }
+    sort.SliceStable(changes, func(i, j int) bool {
+        if changes[i].Breaking != changes[j].Breaking {
+            return changes[i].Breaking
+        }
+        if changes[i].Schema != changes[j].Schema {
+            return changes[i].Schema < changes[j].Schema
+        }
+        return changes[i].Field < changes[j].Field
+    })
+    return changes
+}
+
+func diffWireProperties(old, cur *JSONSchema) []WireChange {
+    var changes []WireChange
+    // Поле Go, сохранившееся под другим json-именем - переименованный тег
+    byGoField := make(map[string]string)
+    for name, prop := range cur.Properties {
+        if prop.GoField != "" {
+            byGoField[prop.GoField] = name
+        }
+    }
+
+    for name, prop := range old.Properties {
+        next, ok := cur.Properties[name]
+        if !ok {
+            if renamed, ok := byGoField[prop.GoField]; ok && prop.GoField != "" && old.Properties[renamed] == nil {
+                changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: prop.GoField, Change: "json tag renamed", Before: name, After: renamed, Breaking: true})
+            } else {
+                changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: prop.GoField, Change: "field removed", Before: wireType(prop), Breaking: true})
+            }
+            continue
+        }
+        if before, after := wireType(prop), wireType(next); before != after {
+            changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: next.GoField, Change: "type changed", Before: before, After: after, Breaking: true})
+        } else if prop.GoType != next.GoType && prop.GoType != "" && next.GoType != "" {
+            changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: next.GoField, Change: "type changed", Before: prop.GoType, After: next.GoType, Breaking: true})
+        }
+        if prop.DBColumn != next.DBColumn {
+            change := WireChange{Schema: old.ID, Field: name, GoField: next.GoField, Change: "db column renamed", Before: prop.DBColumn, After: next.DBColumn, Breaking: true}
+            if prop.DBColumn == "" || next.DBColumn == "" {
+                change.Change = "db column changed"
+            }
+            changes = append(changes, change)
+        }
+        if isRequired(old, name) && !isRequired(cur, name) {
+            changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: next.GoField, Change: "became optional", Before: "required", After: "omitempty"})
+        }
+        if prop.CustomMarshaler != next.CustomMarshaler {
+            changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: next.GoField, Change: "custom marshaler changed", Before: prop.CustomMarshaler, After: next.CustomMarshaler})
+        }
+    }
+
+    renamedTo := make(map[string]bool)
+    for _, change := range changes {
+        if change.Change == "json tag renamed" {
+            renamedTo[change.After] = true
+        }
+    }
+    for name, prop := range cur.Properties {
+        if _, ok := old.Properties[name]; !ok && !renamedTo[name] {
+            changes = append(changes, WireChange{Schema: old.ID, Field: name, GoField: prop.GoField, Change: "field added", After: wireType(prop)})
+        }
+    }
+    return changes
+}
@@ -0,0 +1,312 @@
+package analyzer
+
+import (
+    "go/ast"
+    "go/types"
+    "reflect"
+    "sort"
+    "strings"
+
+    "golang.org/x/tools/go/packages"
+)
+
+type JSONSchema struct {
+    Schema               string                 `json:"$schema,omitempty"`
+    ID                   string                 `json:"$id,omitempty"`
+    Ref                  string                 `json:"$ref,omitempty"`
+    Title                string                 `json:"title,omitempty"`
+    Type                 string                 `json:"type,omitempty"`
+    Format               string                 `json:"format,omitempty"`
+    ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+    Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+    Required             []string               `json:"required,omitempty"`
+    Items                *JSONSchema            `json:"items,omitempty"`
+    AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+    Nullable             bool                   `json:"x-nullable,omitempty"`
+    CustomMarshaler      string                 `json:"x-custom-marshaler,omitempty"`
+    GoType               string                 `json:"x-go-type,omitempty"`
+    GoField              string                 `json:"x-go-field,omitempty"`
+    DBColumn             string                 `json:"x-db-column,omitempty"`
+    Validation           []ValidationRule       `json:"x-validation,omitempty"`
+}
+
+// wireSchemaBuilder выводит JSON-схемы структур, участвующих в encoding/json
+type wireSchemaBuilder struct {
+    project  map[*types.Package]bool
+    roots    map[*types.Named]bool
+    order    []*types.Named
+}
+
+func newWireSchemaBuilder() *wireSchemaBuilder {
+    return &wireSchemaBuilder{
+        project: make(map[*types.Package]bool),
+        roots:   make(map[*types.Named]bool),
+    }
+}
+
+func namedStruct(t types.Type) *types.Named {
+    for {
+        ptr, ok := t.(*types.Pointer)
+        if !ok {
+            break
+        }
+        t = ptr.Elem()
+    }
+    named, ok := t.(*types.Named)
+    if !ok {
+        return nil
+    }
+    if _, ok := named.Underlying().(*types.Struct); !ok {
+        return nil
+    }
+    return named
+}
+
+func (b *wireSchemaBuilder) addRoot(named *types.Named) {
+    if named != nil && !b.roots[named] {
+        b.roots[named] = true
+        b.order = append(b.order, named)
+    }
+}
+
+func hasJSONTags(st *types.Struct) bool {
+    for i := 0; i < st.NumFields(); i++ {
+        if _, ok := reflect.StructTag(st.Tag(i)).Lookup("json"); ok {
+            return true
+        }
+    }
+    return false
+}
+
+func (b *wireSchemaBuilder) addPackage(pkg *packages.Package) {
+    if pkg.Types == nil || pkg.TypesInfo == nil {
+        return
+    }
+    b.project[pkg.Types] = true
+
+    // Структуры с json-тегами
+    scope := pkg.Types.Scope()
+    for _, name := range scope.Names() {
+        tn, ok := scope.Lookup(name).(*types.TypeName)
+        if !ok {
+            continue
+        }
+        if named := namedStruct(tn.Type()); named != nil && hasJSONTags(named.Underlying().(*types.Struct)) {
+            b.addRoot(named)
+        }
+    }
+
+    // Аргументы json.Marshal/Unmarshal и Encoder.Encode/Decoder.Decode
+    for _, file := range pkg.Syntax {
+        inspectCode(file, func(decl *ast.FuncDecl, n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok {
+                return true
+            }
+            fn := calleeFunc(pkg.TypesInfo, call)
+            if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "encoding/json" {
+                return true
+            }
+            switch fn.Name() {
+            case "Marshal", "MarshalIndent", "Encode":
+                if len(call.Args) > 0 {
+                    b.addRoot(namedStruct(pkg.TypesInfo.TypeOf(call.Args[0])))
+                }
+            case "Unmarshal":
+                if len(call.Args) > 1 {
+                    b.addRoot(namedStruct(pkg.TypesInfo.TypeOf(call.Args[1])))
+                }
+            case "Decode":
+                if len(call.Args) > 0 {
+                    b.addRoot(namedStruct(pkg.TypesInfo.TypeOf(call.Args[0])))
+                }
+            }
+            return true
+        })
+    }
+}
+
+func typeID(named *types.Named) string {
+    if named.Obj().Pkg() == nil {
+        return named.Obj().Name()
+    }
+    return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+// customMarshaler сообщает, какой метод переопределяет JSON-представление
+func customMarshaler(t types.Type) string {
+    mset := types.NewMethodSet(types.NewPointer(t))
+    for _, method := range []string{"MarshalJSON", "MarshalText"} {
+        if mset.Lookup(nil, method) != nil {
+            return method
+        }
+    }
+    return ""
+}
+
+// schemaFor строит схему типа; именованные структуры проекта выносятся
+// в отдельные документы через $ref
+func (b *wireSchemaBuilder) schemaFor(t types.Type, visiting map[*types.Named]bool) *JSONSchema {
+    if ptr, ok := t.(*types.Pointer); ok {
+        schema := b.schemaFor(ptr.Elem(), visiting)
+        schema.Nullable = true
+        return schema
+    }
+
+    if named, ok := t.(*types.Named); ok {
+        switch typeID(named) {
+        case "time.Time":
+            return &JSONSchema{Type: "string", Format: "date-time"}
+        case "time.Duration":
+            return &JSONSchema{Type: "integer", GoType: "time.Duration"}
+        case "encoding/json.RawMessage":
+            return &JSONSchema{GoType: "encoding/json.RawMessage"}
+        }
+        if method := customMarshaler(named); method != "" {
+            schema := &JSONSchema{GoType: typeID(named), CustomMarshaler: method}
+            if method == "MarshalText" {
+                schema.Type = "string"
+            }
+            return schema
+        }
+        if _, ok := named.Underlying().(*types.Struct); !ok {
+            // Собственный UnmarshalJSON меняет только разбор входа
+            if types.NewMethodSet(types.NewPointer(named)).Lookup(nil, "UnmarshalJSON") != nil {
+                schema := b.schemaFor(named.Underlying(), visiting)
+                schema.GoType = typeID(named)
+                schema.CustomMarshaler = "UnmarshalJSON"
+                return schema
+            }
+        } else {
+            if named.Obj().Pkg() != nil && b.project[named.Obj().Pkg()] {
+                b.addRoot(named)
+                return &JSONSchema{Ref: typeID(named)}
+            }
+            if visiting[named] {
+                return &JSONSchema{GoType: typeID(named)}
+            }
+            visiting[named] = true
+            defer delete(visiting, named)
+        }
+    }
+
+    switch u := t.Underlying().(type) {
+    case *types.Basic:
+        switch {
+        case u.Info()&types.IsBoolean != 0:
+            return &JSONSchema{Type: "boolean"}
+        case u.Info()&types.IsInteger != 0:
+            return &JSONSchema{Type: "integer"}
+        case u.Info()&types.IsFloat != 0:
+            return &JSONSchema{Type: "number"}
+        case u.Info()&types.IsString != 0:
+            return &JSONSchema{Type: "string"}
+        }
+    case *types.Slice:
+        if basic, ok := u.Elem().(*types.Basic); ok && basic.Kind() == types.Byte {
+            return &JSONSchema{Type: "string", ContentEncoding: "base64"}
+        }
+        return &JSONSchema{Type: "array", Items: b.schemaFor(u.Elem(), visiting)}
+    case *types.Array:
+        return &JSONSchema{Type: "array", Items: b.schemaFor(u.Elem(), visiting)}
+    case *types.Map:
+        return &JSONSchema{Type: "object", AdditionalProperties: b.schemaFor(u.Elem(), visiting)}
+    case *types.Struct:
+        schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+        b.addFields(schema, u, visiting)
+        return schema
+    }
+    // interface{}, функции, каналы: формат не выводится статически
+    return &JSONSchema{}
+}
+
+// addFields добавляет поля структуры по правилам encoding/json: имя из
+// тега, "-" пропускается, omitempty не обязателен, встроенные без имени
+// раскрываются
+func (b *wireSchemaBuilder) addFields(schema *JSONSchema, st *types.Struct, visiting map[*types.Named]bool) {
+    for i := 0; i < st.NumFields(); i++ {
+        field := st.Field(i)
+        tag, hasTag := reflect.StructTag(st.Tag(i)).Lookup("json")
+        if tag == "-" {
+            continue
+        }
+        parts := strings.Split(tag, ",")
+        name := parts[0]
+
+        if field.Embedded() && name == "" {
+            if embedded := namedStruct(field.Type()); embedded != nil && customMarshaler(embedded) == "" {
+                b.addFields(schema, embedded.Underlying().(*types.Struct), visiting)
+                continue
+            }
+        }
+        if !field.Exported() {
+            continue
+        }
+        if !hasTag || name == "" {
+            name = field.Name()
+        }
+
+        prop := b.schemaFor(field.Type(), visiting)
+        omitEmpty := false
+        for _, opt := range parts[1:] {
+            switch opt {
+            case "omitempty", "omitzero":
+                omitEmpty = true
+            case "string":
+                prop = &JSONSchema{Type: "string", Format: prop.Type}
+            }
+        }
+        prop.GoField = field.Name()
+        prop.DBColumn = dbColumn(reflect.StructTag(st.Tag(i)))
+        for _, key := range validationTagKeys {
+            if rules, ok := reflect.StructTag(st.Tag(i)).Lookup(key); ok && rules != "" && rules != "-" {
+                prop.Validation = append(prop.Validation, parseValidateTag(rules)...)
+            }
+        }
+
+        schema.Properties[name] = prop
+        if !omitEmpty {
+            schema.Required = append(schema.Required, name)
+        }
+    }
+}
+
+// dbColumn возвращает имя колонки/поля хранилища из тегов db (sqlx),
+// gorm:"column:...", bun и bson
+func dbColumn(tag reflect.StructTag) string {
+    if v, ok := tag.Lookup("gorm"); ok {
+        for _, part := range strings.Split(v, ";") {
+            if column, ok := strings.CutPrefix(strings.TrimSpace(part), "column:"); ok {
+                return column
+            }
+        }
+    }
+    for _, key := range []string{"db", "bun", "bson"} {
+        if v, ok := tag.Lookup(key); ok {
+            if name, _, _ := strings.Cut(v, ","); name != "" && name != "-" {
+                return name
+            }
+        }
+    }
+    return ""
+}
+
+func (b *wireSchemaBuilder) build() []JSONSchema {
+    var schemas []JSONSchema
+    // Очередь растёт по мере обнаружения вложенных структур
+    for i := 0; i < len(b.order); i++ {
+        named := b.order[i]
+        schema := b.schemaFor(named.Underlying(), map[*types.Named]bool{named: true})
+        schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+        schema.ID = typeID(named)
+        schema.Title = named.Obj().Name()
+        if method := customMarshaler(named); method != "" {
+            schema.CustomMarshaler = method
+        }
+        schemas = append(schemas, *schema)
+    }
+    sort.SliceStable(schemas, func(i, j int) bool {
+        return schemas[i].ID < schemas[j].ID
+    })
+    return schemas
+}
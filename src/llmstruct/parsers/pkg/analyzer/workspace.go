@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+    "fmt"
+    "go/token"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "golang.org/x/mod/modfile"
+    "golang.org/x/tools/go/packages"
+)
+
+// WorkspaceModule - модуль проекта из go.work или найденный по вложенным
+// go.mod: путь, каталог относительно проекта ("." - корень), версия Go из
+// его go.mod и файлы анализа, которые ему принадлежат
+type WorkspaceModule struct {
+    Path         string   `json:"path"`
+    Dir          string   `json:"dir"`
+    GoVersion    string   `json:"go_version"`
+    Files        []string `json:"files"`
+}
+
+// readGoWork читает go.work в корне проекта; модули без go.mod
+// пропускаются, как это делает go
+func readGoWork(projectPath string) (goVersion string, modules []WorkspaceModule, err error) {
+    path := filepath.Join(projectPath, "go.work")
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return "", nil, err
+    }
+    work, err := modfile.ParseWork(path, data, nil)
+    if err != nil {
+        return "", nil, err
+    }
+    if work.Go != nil {
+        goVersion = work.Go.Version
+    }
+    for _, use := range work.Use {
+        dir := filepath.ToSlash(filepath.Clean(use.Path))
+        if filepath.IsAbs(use.Path) {
+            rel, err := filepath.Rel(projectPath, use.Path)
+            if err != nil {
+                continue
+            }
+            dir = filepath.ToSlash(rel)
+        }
+        mod := parseGoMod(filepath.Join(projectPath, dir, "go.mod"))
+        if mod == nil || mod.Module == "" {
+            continue
+        }
+        modules = append(modules, WorkspaceModule{Path: mod.Module, Dir: dir, GoVersion: mod.Go, Files: []string{}})
+    }
+    return goVersion, modules, nil
+}
+
+// discoverModules находит все go.mod проекта без go.work. Результат
+// пустой, если модуль один: тогда хватает обычной загрузки ./...
+func discoverModules(projectPath string) []WorkspaceModule {
+    var modules []WorkspaceModule
+    filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return nil
+        }
+        if d.IsDir() {
+            if path != projectPath && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if d.Name() != "go.mod" {
+            return nil
+        }
+        mod := parseGoMod(path)
+        if mod == nil || mod.Module == "" {
+            return nil
+        }
+        modules = append(modules, WorkspaceModule{
+            Path:      mod.Module,
+            Dir:       relativePath(projectPath, filepath.Dir(path)),
+            GoVersion: mod.Go,
+            Files:     []string{},
+        })
+        return nil
+    })
+    if len(modules) < 2 && (len(modules) == 0 || modules[0].Dir == ".") {
+        return nil
+    }
+    sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+    return modules
+}
+
+// loadModules загружает пакеты каждого модуля из его каталога: без
+// go.work шаблон ./... не переходит границу вложенного go.mod. Ошибка
+// одного модуля не мешает загрузке остальных
+func loadModules(cfg *packages.Config, projectPath string, modules []WorkspaceModule) ([]*packages.Package, error) {
+    var pkgs []*packages.Package
+    var firstErr error
+    // Общий FileSet: позиции пакетов всех модулей разрешаются через
+    // pkg.Fset любого из них
+    shared := *cfg
+    if shared.Fset == nil {
+        shared.Fset = token.NewFileSet()
+    }
+    for _, m := range modules {
+        modCfg := shared
+        modCfg.Dir = filepath.Join(projectPath, filepath.FromSlash(m.Dir))
+        loaded, err := packages.Load(&modCfg, "./...")
+        if err != nil && firstErr == nil {
+            firstErr = fmt.Errorf("%s: %v", m.Path, err)
+        }
+        pkgs = append(pkgs, loaded...)
+    }
+    return pkgs, firstErr
+}
+
+// modulePatterns - шаблоны загрузки пакетов всех модулей: "./..." из
+// корня рабочего пространства в go.work не работает
+func modulePatterns(modules []WorkspaceModule) []string {
+    patterns := make([]string, 0, len(modules))
+    for _, m := range modules {
+        if m.Dir == "." {
+            patterns = append(patterns, "./...")
+        } else {
+            patterns = append(patterns, "./"+m.Dir+"/...")
+        }
+    }
+    return patterns
+}
+
+// moduleForDir - модуль, которому принадлежит каталог проекта (через
+// "/"): вложенный модуль важнее внешнего
+func (a *ProjectAnalysis) moduleForDir(dir string) *WorkspaceModule {
+    var best *WorkspaceModule
+    for i := range a.Modules {
+        m := &a.Modules[i]
+        if (m.Dir == "." || hasPathPrefix(dir, m.Dir)) && (best == nil || best.Dir == "." || len(m.Dir) > len(best.Dir)) {
+            best = m
+        }
+    }
+    return best
+}
+
+// inProject - пакет принадлежит одному из модулей рабочего пространства
+func (a *ProjectAnalysis) inProject(pkgPath string) bool {
+    for _, m := range a.Modules {
+        if hasPathPrefix(pkgPath, m.Path) {
+            return true
+        }
+    }
+    return false
+}
+
+// addModuleFile относит файл анализа к его модулю
+func (a *ProjectAnalysis) addModuleFile(path string) {
+    if m := a.moduleForDir(filepath.ToSlash(filepath.Dir(path))); m != nil {
+        m.Files = append(m.Files, path)
+    }
+}
+
+// moduleRelDir - каталог относительно корня модуля
+func moduleRelDir(m *WorkspaceModule, dir string) string {
+    if m.Dir == "." {
+        return dir
+    }
+    return strings.TrimPrefix(strings.TrimPrefix(dir, m.Dir), "/")
+}
@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+    "context"
+    "testing"
+)
+
+func TestNestedModulesShareFileSet(t *testing.T) {
+    project := writeTestProject(t, map[string]string{
+        "a/go.mod": "module example.com/a\n\ngo 1.21\n",
+        "a/jobs.go": `package a
+
+var Jobs = make(chan int)
+
+func Produce() { Jobs <- 1 }
+`,
+        "b/go.mod": "module example.com/b\n\ngo 1.21\n",
+        "b/results.go": `package b
+
+var Results = make(chan string)
+
+func Consume() string { return <-Results }
+`,
+    })
+    analysis := analyzeProject(context.Background(), project, testOptions(t))
+
+    if len(analysis.Modules) != 2 {
+        t.Fatalf("modules = %+v, want a and b", analysis.Modules)
+    }
+    if analysis.ChannelGraph == nil {
+        t.Fatal("channel graph is empty")
+    }
+    want := map[string]string{"example.com/a.Jobs": "a/jobs.go", "example.com/b.Results": "b/results.go"}
+    for _, ch := range analysis.ChannelGraph.Channels {
+        file, ok := want[ch.ID]
+        if !ok {
+            continue
+        }
+        delete(want, ch.ID)
+        if ch.File != file || ch.Line != 3 {
+            t.Errorf("%s at %s:%d, want %s:3", ch.ID, ch.File, ch.Line, file)
+        }
+    }
+    for id := range want {
+        t.Errorf("channel %s missing", id)
+    }
+    for _, op := range analysis.ChannelGraph.Ops {
+        if op.File == "" || op.Line == 0 {
+            t.Errorf("op %+v has no position", op)
+        }
+    }
+}
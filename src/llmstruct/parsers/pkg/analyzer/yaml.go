@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "strings"
+)
+
+// yamlNode - значение JSON с сохранённым порядком ключей объекта
+type yamlNode struct {
+    object   bool
+    array    bool
+    keys     []string
+    items    []*yamlNode
+    scalar   any
+}
+
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+    tok, err := dec.Token()
+    if err != nil {
+        return nil, err
+    }
+    delim, ok := tok.(json.Delim)
+    if !ok {
+        return &yamlNode{scalar: tok}, nil
+    }
+    node := &yamlNode{object: delim == '{', array: delim == '['}
+    for dec.More() {
+        if node.object {
+            key, err := dec.Token()
+            if err != nil {
+                return nil, err
+            }
+            node.keys = append(node.keys, key.(string))
+        }
+        item, err := decodeYAMLNode(dec)
+        if err != nil {
+            return nil, err
+        }
+        node.items = append(node.items, item)
+    }
+    // Закрывающая скобка
+    if _, err := dec.Token(); err != nil {
+        return nil, err
+    }
+    return node, nil
+}
+
+var (
+    yamlPlain    = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./()\[\]{}*<>,+=@:#~ -]*$`)
+    yamlReserved = map[string]bool{
+        "true": true, "false": true, "null": true, "yes": true, "no": true,
+        "on": true, "off": true, "y": true, "n": true, "~": true,
+    }
+)
+
+// yamlString выбирает самую читаемую запись строки: без кавычек, блоком
+// для многострочного текста или в двойных кавычках JSON, которые YAML
+// понимает так же
+func yamlString(s, indent string) string {
+    // ": " и " #" начинают отображение и комментарий
+    if yamlPlain.MatchString(s) && !yamlReserved[strings.ToLower(s)] && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, ":") && !strings.Contains(s, ": ") && !strings.Contains(s, " #") {
+        return s
+    }
+    // Отступ блока определяется по первой непустой строке
+    if strings.Contains(s, "\n") && strings.TrimSpace(s) != "" && !strings.ContainsAny(s, "\r\t") && !strings.HasPrefix(strings.TrimLeft(s, "\n"), " ") && !strings.HasSuffix(s, "\n\n") && printable(s) {
+        header := "|-"
+        if strings.HasSuffix(s, "\n") {
+            header, s = "|", strings.TrimSuffix(s, "\n")
+        }
+        var b strings.Builder
+        b.WriteString(header)
+        for _, line := range strings.Split(s, "\n") {
+            b.WriteString("\n")
+            if line != "" {
+                b.WriteString(indent + line)
+            }
+        }
+        return b.String()
+    }
+    quoted, _ := json.Marshal(s)
+    return string(quoted)
+}
+
+func printable(s string) bool {
+    for _, r := range s {
+        if r < 0x20 && r != '\n' {
+            return false
+        }
+    }
+    return true
+}
+
+func yamlScalar(v any, indent string) string {
+    switch x := v.(type) {
+    case nil:
+        return "null"
+    case string:
+        return yamlString(x, indent)
+    case json.Number:
+        return x.String()
+    case bool:
+        return fmt.Sprint(x)
+    }
+    return fmt.Sprint(v)
+}
+
+// inline возвращает запись узла, помещающуюся на строке ключа: скаляр
+// или пустая коллекция
+func (n *yamlNode) inline(indent string) (string, bool) {
+    switch {
+    case n.object && len(n.items) == 0:
+        return "{}", true
+    case n.array && len(n.items) == 0:
+        return "[]", true
+    case !n.object && !n.array:
+        return yamlScalar(n.scalar, indent), true
+    }
+    return "", false
+}
+
+// write выводит коллекцию блоками; first - префикс первой строки ("- "
+// для объекта внутри списка)
+func (n *yamlNode) write(b *bytes.Buffer, indent, first string) {
+    for i, item := range n.items {
+        prefix := indent
+        if i == 0 && first != "" {
+            prefix = first
+        }
+        if n.object {
+            b.WriteString(prefix + yamlString(n.keys[i], indent) + ":")
+            if s, ok := item.inline(indent + "  "); ok {
+                b.WriteString(" " + s + "\n")
+                continue
+            }
+            b.WriteString("\n")
+            if item.array {
+                // Элементы списка на уровне ключа - привычная запись
+                item.write(b, indent, "")
+            } else {
+                item.write(b, indent+"  ", "")
+            }
+            continue
+        }
+        if s, ok := item.inline(indent + "  "); ok {
+            b.WriteString(prefix + "- " + s + "\n")
+            continue
+        }
+        item.write(b, indent+"  ", prefix+"- ")
+    }
+}
+
+// encodeYAML переводит JSON в YAML блочного стиля с тем же порядком
+// ключей: построчные изменения удобно читать в диффах
+func encodeYAML(data []byte) ([]byte, error) {
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.UseNumber()
+    root, err := decodeYAMLNode(dec)
+    if err != nil {
+        return nil, err
+    }
+    var b bytes.Buffer
+    b.WriteString("---\n")
+    if s, ok := root.inline(""); ok {
+        b.WriteString(s + "\n")
+    } else {
+        root.write(&b, "", "")
+    }
+    return b.Bytes(), nil
+}
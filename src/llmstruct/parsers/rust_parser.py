@@ -0,0 +1,454 @@
+"""
+Rust parser (tree-sitter, syntax level)
+
+Emits functions, structs/enums, traits, impls and use declarations
+into the unified schema (schemas/llmstruct-unified-v1.json).
+"""
+
+import os
+import re
+from typing import Dict, Any, List, Optional
+
+UNIFIED_SCHEMA_VERSION = "1.0"
+
+
+def _text(node) -> str:
+    return node.text.decode("utf-8", errors="replace") if node is not None else ""
+
+
+def _squash(text: str) -> str:
+    """Collapse whitespace in a signature fragment"""
+    return re.sub(r"\s+", " ", text).strip()
+
+
+def find_crate_root(filepath: str, root_dir: str) -> str:
+    """Nearest directory with Cargo.toml between the file and the project root"""
+    root_dir = os.path.abspath(root_dir)
+    current = os.path.dirname(os.path.abspath(filepath))
+    while True:
+        if os.path.isfile(os.path.join(current, "Cargo.toml")):
+            return current
+        if current == root_dir or os.path.dirname(current) == current:
+            return root_dir
+        current = os.path.dirname(current)
+
+
+def crate_name(crate_root: str) -> str:
+    """Package name from Cargo.toml ([package] name), '-' normalized to '_'"""
+    name = os.path.basename(crate_root)
+    try:
+        with open(os.path.join(crate_root, "Cargo.toml"), "r", encoding="utf-8") as f:
+            in_package = False
+            for line in f:
+                line = line.strip()
+                if line.startswith("["):
+                    in_package = line == "[package]"
+                elif in_package and line.startswith("name"):
+                    name = line.split("=", 1)[1].strip().strip('"')
+                    break
+    except OSError:
+        pass
+    return name.replace("-", "_")
+
+
+def module_path(filepath: str, crate_root: str, crate: str) -> List[str]:
+    """Module path of a file: src/lib.rs -> [crate], src/net/mod.rs -> [crate, net]"""
+    rel = os.path.relpath(os.path.abspath(filepath), crate_root).replace(os.sep, "/")
+    parts = rel[:-len(".rs")].split("/")
+    if parts and parts[0] == "src":
+        parts = parts[1:]
+    # Binaries, examples and tests are separate crate roots
+    elif parts and parts[0] in ("examples", "tests", "benches"):
+        parts = parts[1:]
+    if parts and parts[-1] in ("lib", "main", "mod"):
+        parts = parts[:-1]
+    return [crate] + [p for p in parts if p]
+
+
+class RustParser:
+    """Syntax-level Rust parser producing unified schema symbols and relations"""
+
+    def __init__(self):
+        try:
+            import tree_sitter
+            import tree_sitter_rust
+        except ImportError as e:
+            raise ImportError(
+                "Rust support requires tree-sitter: pip install 'llmstruct[rust]'"
+            ) from e
+        self.parser = tree_sitter.Parser(tree_sitter.Language(tree_sitter_rust.language()))
+
+    def parse_project(self, root_dir: str, files: List[str]) -> Dict[str, Any]:
+        """Build a unified schema document for the given .rs files"""
+        schema = {
+            "schema_version": UNIFIED_SCHEMA_VERSION,
+            "languages": ["rust"],
+            "symbols": [],
+            "relations": [],
+            "errors": [],
+        }
+        seen = set()
+        for filepath in sorted(files):
+            result = self.parse_file(filepath, root_dir)
+            if "error" in result:
+                schema["errors"].append(f"{result['path']}: {result['error']}")
+                continue
+            for symbol in result["symbols"]:
+                # Модуль из mod.rs и из родительского файла совпадают
+                if symbol["id"] in seen:
+                    continue
+                seen.add(symbol["id"])
+                schema["symbols"].append(symbol)
+            schema["relations"].extend(result["relations"])
+        schema["relations"].sort(key=lambda r: r["kind"])
+        return schema
+
+    def parse_file(self, filepath: str, root_dir: str) -> Dict[str, Any]:
+        """Parse one file into unified symbols and relations"""
+        rel_path = os.path.relpath(filepath, root_dir).replace(os.sep, "/")
+        try:
+            with open(filepath, "rb") as f:
+                source = f.read()
+        except OSError as e:
+            return {"path": rel_path, "error": str(e)}
+        tree = self.parser.parse(source)
+
+        crate_root = find_crate_root(filepath, root_dir)
+        crate = crate_name(crate_root)
+        walker = _FileWalker(rel_path, crate, module_path(filepath, crate_root, crate))
+        walker.walk(tree.root_node, source.count(b"\n") + 1)
+        if tree.root_node.has_error:
+            walker.file_symbol["extensions"]["rust"]["syntax_errors"] = True
+        return {"path": rel_path, "symbols": walker.symbols, "relations": walker.relations}
+
+
+class _FileWalker:
+    """Walks a single file's syntax tree"""
+
+    def __init__(self, rel_path: str, crate: str, module: List[str]):
+        self.rel_path = rel_path
+        self.crate = crate
+        self.module = module
+        self.symbols: List[Dict[str, Any]] = []
+        self.relations: List[Dict[str, str]] = []
+        self.uses: Dict[str, str] = {}
+        self.file_symbol: Dict[str, Any] = {}
+
+    def symbol_id(self, module: List[str], *names: str) -> str:
+        return "rust:" + "::".join(module + list(names))
+
+    def position(self, node=None, line: int = 0, end_line: int = 0) -> Dict[str, Any]:
+        pos = {"file": self.rel_path}
+        if node is not None:
+            line, end_line = node.start_point[0] + 1, node.end_point[0] + 1
+        if line:
+            pos["line"] = line
+        if end_line:
+            pos["end_line"] = end_line
+        return pos
+
+    def add(self, symbol: Dict[str, Any], parent: Optional[str]) -> Dict[str, Any]:
+        symbol["language"] = "rust"
+        if not symbol.get("doc"):
+            symbol.pop("doc", None)
+        if parent:
+            symbol["parent"] = parent
+            self.relations.append({"kind": "contains", "from": parent, "to": symbol["id"]})
+        self.symbols.append(symbol)
+        return symbol
+
+    def relation(self, kind: str, source: str, target: str) -> None:
+        self.relations.append({"kind": kind, "from": source, "to": target})
+
+    def walk(self, root, line_count: int) -> None:
+        module_id = self.symbol_id(self.module)
+        self.add({
+            "id": module_id,
+            "kind": "module",
+            "name": self.module[-1],
+            "exported": True,
+            "position": {"file": os.path.dirname(self.rel_path)},
+            "extensions": {"rust": {"path": "::".join(self.module)}},
+        }, None)
+        file_id = "file:" + self.rel_path
+        self.file_symbol = self.add({
+            "id": file_id,
+            "kind": "file",
+            "name": os.path.basename(self.rel_path),
+            "exported": True,
+            "position": self.position(line=1, end_line=line_count),
+            "extensions": {"rust": {"module": "::".join(self.module)}},
+        }, module_id)
+
+        # Сначала use: по ним разрешаются имена трейтов в impl
+        for node in root.named_children:
+            if node.type == "use_declaration":
+                self.use(node, file_id)
+        self.items(root, self.module, file_id)
+
+    def items(self, container, module: List[str], file_id: str) -> None:
+        for node in container.named_children:
+            if node.type == "function_item":
+                self.function(node, module, file_id)
+            elif node.type in ("struct_item", "enum_item", "union_item", "type_item"):
+                self.type_item(node, module, file_id)
+            elif node.type == "trait_item":
+                self.trait(node, module, file_id)
+            elif node.type == "impl_item":
+                self.impl(node, module, file_id)
+            elif node.type == "mod_item":
+                self.mod(node, module, file_id)
+
+    # --- use -------------------------------------------------------------
+
+    def resolve_path(self, path: str, module: List[str]) -> str:
+        """crate::/self::/super:: -> absolute module path"""
+        segments = path.split("::")
+        if segments[0] == "crate":
+            segments = [self.crate] + segments[1:]
+        elif segments[0] in ("self", "super"):
+            base = list(module)
+            while segments and segments[0] in ("self", "super"):
+                if segments[0] == "super" and len(base) > 1:
+                    base.pop()
+                segments = segments[1:]
+            segments = base + segments
+        return "::".join(segments)
+
+    def use_paths(self, node, prefix: str) -> List[tuple]:
+        """Flatten a use tree into (full path, local name) pairs"""
+        kind = node.type
+        if kind in ("identifier", "scoped_identifier", "crate", "self", "super"):
+            path = _text(node)
+            full = f"{prefix}::{path}" if prefix else path
+            if path == "self":
+                full = prefix
+            return [(full, full.split("::")[-1])]
+        if kind == "use_as_clause":
+            path = _text(node.child_by_field_name("path"))
+            full = f"{prefix}::{path}" if prefix else path
+            return [(full, _text(node.child_by_field_name("alias")))]
+        if kind == "use_wildcard":
+            path = _text(node).rstrip("*").rstrip(":")
+            full = f"{prefix}::{path}" if prefix and path else (prefix or path)
+            return [(full + "::*", "*")]
+        if kind == "scoped_use_list":
+            path = _text(node.child_by_field_name("path"))
+            full = f"{prefix}::{path}" if prefix and path else (prefix or path)
+            return self.use_paths(node.child_by_field_name("list"), full)
+        if kind == "use_list":
+            pairs = []
+            for child in node.named_children:
+                pairs.extend(self.use_paths(child, prefix))
+            return pairs
+        return []
+
+    def use(self, node, file_id: str) -> None:
+        argument = node.child_by_field_name("argument")
+        if argument is None:
+            return
+        for full, local in self.use_paths(argument, ""):
+            resolved = self.resolve_path(full, self.module)
+            if local != "*":
+                self.uses[local] = resolved
+            self.relation("imports", file_id, "rust:" + resolved)
+
+    def resolve_type(self, name: str, module: List[str]) -> str:
+        """Resolve a type/trait name as written to a symbol ID"""
+        name = re.sub(r"<.*", "", _squash(name)).lstrip("&").strip()
+        name = re.sub(r"^(dyn|impl|mut)\s+", "", name)
+        head, _, rest = name.partition("::")
+        if head in self.uses:
+            return "rust:" + self.uses[head] + (f"::{rest}" if rest else "")
+        if head in ("crate", "self", "super"):
+            return "rust:" + self.resolve_path(name, module)
+        if rest:
+            return "rust:" + name
+        return self.symbol_id(module, name)
+
+    # --- items -----------------------------------------------------------
+
+    def doc(self, node) -> str:
+        """Outer doc comments (///, /** */) directly above the item, skipping attributes"""
+        lines = []
+        sibling = node.prev_named_sibling
+        while sibling is not None and sibling.type in ("line_comment", "block_comment", "attribute_item"):
+            text = _text(sibling)
+            if sibling.type == "line_comment" and text.startswith("///") and not text.startswith("////"):
+                lines.insert(0, text[3:].strip())
+            elif sibling.type == "block_comment" and text.startswith("/**"):
+                lines.insert(0, text[3:-2].strip())
+            elif sibling.type != "attribute_item":
+                break
+            sibling = sibling.prev_named_sibling
+        return "\n".join(lines)
+
+    def attributes(self, node) -> List[str]:
+        attrs = []
+        sibling = node.prev_named_sibling
+        while sibling is not None and sibling.type in ("line_comment", "block_comment", "attribute_item"):
+            if sibling.type == "attribute_item":
+                attrs.insert(0, _squash(_text(sibling)))
+            sibling = sibling.prev_named_sibling
+        return attrs
+
+    def is_pub(self, node) -> bool:
+        for child in node.named_children:
+            if child.type == "visibility_modifier":
+                return _text(child) == "pub"
+        return False
+
+    def visibility(self, node) -> str:
+        for child in node.named_children:
+            if child.type == "visibility_modifier":
+                return _squash(_text(child))
+        return ""
+
+    def function_ext(self, node) -> Dict[str, Any]:
+        params = node.child_by_field_name("parameters")
+        ext = {
+            "params": [_squash(_text(p)) for p in params.named_children if p.type != "attribute_item"] if params else [],
+            "returns": _squash(_text(node.child_by_field_name("return_type"))),
+        }
+        generics = node.child_by_field_name("type_parameters")
+        if generics is not None:
+            ext["generics"] = _squash(_text(generics))
+        for child in node.children:
+            if child.type == "function_modifiers":
+                ext["modifiers"] = _text(child).split()
+        if params is not None:
+            for p in params.named_children:
+                if p.type == "self_parameter":
+                    ext["self"] = _squash(_text(p))
+        visibility = self.visibility(node)
+        if visibility:
+            ext["visibility"] = visibility
+        attrs = self.attributes(node)
+        if attrs:
+            ext["attributes"] = attrs
+        return ext
+
+    def function(self, node, module: List[str], file_id: str) -> None:
+        name = _text(node.child_by_field_name("name"))
+        self.add({
+            "id": self.symbol_id(module, name),
+            "kind": "function",
+            "name": name,
+            "exported": self.is_pub(node),
+            "doc": self.doc(node),
+            "position": self.position(node),
+            "extensions": {"rust": self.function_ext(node)},
+        }, file_id)
+
+    def type_item(self, node, module: List[str], file_id: str) -> None:
+        name = _text(node.child_by_field_name("name"))
+        ext = {"type_kind": node.type[:-len("_item")]}
+        body = node.child_by_field_name("body")
+        if body is not None and body.type in ("field_declaration_list", "ordered_field_declaration_list"):
+            ext["fields"] = [_squash(_text(f)) for f in body.named_children if f.type != "attribute_item"]
+        elif body is not None and body.type == "enum_variant_list":
+            ext["variants"] = [_text(v.child_by_field_name("name")) for v in body.named_children if v.type == "enum_variant"]
+        elif node.type == "type_item":
+            ext["alias_of"] = _squash(_text(node.child_by_field_name("type")))
+        generics = node.child_by_field_name("type_parameters")
+        if generics is not None:
+            ext["generics"] = _squash(_text(generics))
+        derives = [a for a in self.attributes(node) if a.startswith("#[derive")]
+        if derives:
+            ext["derives"] = [d.strip() for a in derives for d in a[len("#[derive("):-2].split(",") if d.strip()]
+        self.add({
+            "id": self.symbol_id(module, name),
+            "kind": "type",
+            "name": name,
+            "exported": self.is_pub(node),
+            "doc": self.doc(node),
+            "position": self.position(node),
+            "extensions": {"rust": ext},
+        }, file_id)
+
+    def trait(self, node, module: List[str], file_id: str) -> None:
+        name = _text(node.child_by_field_name("name"))
+        trait_id = self.symbol_id(module, name)
+        exported = self.is_pub(node)
+        body = node.child_by_field_name("body")
+        methods = []
+        if body is not None:
+            methods = [m for m in body.named_children if m.type in ("function_signature_item", "function_item")]
+        ext = {"methods": [_text(m.child_by_field_name("name")) for m in methods]}
+        bounds = node.child_by_field_name("bounds")
+        if bounds is not None:
+            ext["supertraits"] = [self.resolve_type(_text(b), module)[len("rust:"):] for b in bounds.named_children]
+        self.add({
+            "id": trait_id,
+            "kind": "interface",
+            "name": name,
+            "exported": exported,
+            "doc": self.doc(node),
+            "position": self.position(node),
+            "extensions": {"rust": ext},
+        }, file_id)
+        for method in methods:
+            method_name = _text(method.child_by_field_name("name"))
+            method_ext = self.function_ext(method)
+            method_ext["default"] = method.type == "function_item"
+            method_id = self.symbol_id(module, name, method_name)
+            self.add({
+                "id": method_id,
+                "kind": "method",
+                "name": method_name,
+                "exported": exported,
+                "doc": self.doc(method),
+                "position": self.position(method),
+                "extensions": {"rust": method_ext},
+            }, file_id)
+            self.relation("member_of", method_id, trait_id)
+
+    def impl(self, node, module: List[str], file_id: str) -> None:
+        type_node = node.child_by_field_name("type")
+        type_id = self.resolve_type(_text(type_node), module)
+        trait_node = node.child_by_field_name("trait")
+        trait_id = self.resolve_type(_text(trait_node), module) if trait_node is not None else ""
+        if trait_id:
+            self.relation("implements", type_id, trait_id)
+
+        body = node.child_by_field_name("body")
+        if body is None:
+            return
+        for method in body.named_children:
+            if method.type != "function_item":
+                continue
+            method_name = _text(method.child_by_field_name("name"))
+            ext = self.function_ext(method)
+            ext["impl_for"] = _squash(_text(type_node))
+            if trait_id:
+                ext["impl_trait"] = trait_id[len("rust:"):]
+            method_id = f"{type_id}::{method_name}"
+            self.add({
+                "id": method_id,
+                "kind": "method",
+                "name": method_name,
+                # Методы trait impl видимы вместе с трейтом
+                "exported": self.is_pub(method) or bool(trait_id),
+                "doc": self.doc(method),
+                "position": self.position(method),
+                "extensions": {"rust": ext},
+            }, file_id)
+            self.relation("member_of", method_id, type_id)
+
+    def mod(self, node, module: List[str], file_id: str) -> None:
+        name = _text(node.child_by_field_name("name"))
+        child_module = module + [name]
+        module_id = self.symbol_id(child_module)
+        body = node.child_by_field_name("body")
+        self.add({
+            "id": module_id,
+            "kind": "module",
+            "name": name,
+            "exported": self.is_pub(node),
+            "doc": self.doc(node),
+            "position": self.position(node),
+            "extensions": {"rust": {"path": "::".join(child_module), "inline": body is not None}},
+        }, self.symbol_id(module))
+        # mod foo; - содержимое в foo.rs / foo/mod.rs
+        if body is not None:
+            self.items(body, child_module, file_id)
@@ -17,12 +17,14 @@ try:
     from .python_parser import analyze_module as analyze_python
     from .go_analyzer import GoAnalyzer
     from .javascript_parser import JavaScriptParser
+    from .rust_parser import RustParser
     from .converter_config import Language, ConverterConfig, LanguageDetector
 except ImportError:
     # Fallback for standalone execution
     from python_parser import analyze_module as analyze_python
     from go_analyzer import GoAnalyzer
     from javascript_parser import JavaScriptParser
+    from rust_parser import RustParser
     from converter_config import Language, ConverterConfig, LanguageDetector
 
 logging.basicConfig(level=logging.INFO)
@@ -36,6 +38,8 @@ class UniversalConverter:
         self.config = config or ConverterConfig()
         self.go_analyzer = GoAnalyzer()
         self.js_parser = JavaScriptParser()
+        # tree-sitter - необязательная зависимость, парсер создаётся по требованию
+        self.rust_parser = None
         
     def detect_language(self, file_path: str) -> Language:
         """Detect programming language from file extension"""
@@ -107,12 +111,12 @@ class UniversalConverter:
         
         return self._build_project_structure("python", project_path, modules, toc)
     
-    def convert_go_project(self, project_path: str) -> Dict[str, Any]:
+    def convert_go_project(self, project_path: str, extra_args: List[str] = None) -> Dict[str, Any]:
         """Convert Go project to llmstruct format"""
         logger.info("Converting Go project...")
         
         try:
-            analysis = self.go_analyzer.analyze_project(project_path)
+            analysis = self.go_analyzer.analyze_project(project_path, extra_args)
             
             # Use the existing convert_to_llmstruct_format from go_converter
             from .go_converter import convert_to_llmstruct_format
@@ -159,6 +163,63 @@ class UniversalConverter:
         
         return self._build_project_structure("javascript", project_path, modules, toc)
     
+    def convert_rust_project(self, project_path: str) -> Dict[str, Any]:
+        """Convert Rust project to llmstruct format (modules + unified schema)"""
+        logger.info("Converting Rust project...")
+        
+        if self.rust_parser is None:
+            self.rust_parser = RustParser()
+        files = self.get_project_files(project_path, Language.RUST)
+        unified = self.rust_parser.parse_project(project_path, files)
+        for error in unified["errors"]:
+            logger.warning(f"Failed to analyze {error}")
+        
+        # Модули llmstruct собираются из символов единой схемы
+        modules = {}
+        for symbol in unified["symbols"]:
+            path = symbol["position"]["file"]
+            if symbol["kind"] == "file":
+                modules[path] = {
+                    "module_id": symbol["extensions"]["rust"]["module"],
+                    "path": path,
+                    "language": "rust",
+                    "category": "test" if "/tests/" in f"/{path}" else "core",
+                    "line_count": symbol["position"].get("end_line", 0),
+                    "functions": [],
+                    "classes": [],
+                }
+            elif symbol["kind"] in ("function", "method") and path in modules:
+                modules[path]["functions"].append({
+                    "name": symbol["name"],
+                    "docstring": symbol.get("doc", ""),
+                    "line_range": [symbol["position"]["line"], symbol["position"]["end_line"]] if self.config.include_ranges else None,
+                    "parameters": symbol["extensions"]["rust"]["params"],
+                    "returns": symbol["extensions"]["rust"]["returns"],
+                    "is_exported": symbol["exported"],
+                    "is_method": symbol["kind"] == "method",
+                })
+            elif symbol["kind"] in ("type", "interface") and path in modules:
+                modules[path]["classes"].append({
+                    "name": symbol["name"],
+                    "docstring": symbol.get("doc", ""),
+                    "line_range": [symbol["position"]["line"], symbol["position"]["end_line"]] if self.config.include_ranges else None,
+                    "kind": symbol["extensions"]["rust"].get("type_kind", "trait"),
+                    "is_exported": symbol["exported"],
+                })
+        
+        toc = [{
+            "module_id": module["module_id"],
+            "path": module["path"],
+            "category": module["category"],
+            "functions": len(module["functions"]),
+            "classes": len(module["classes"]),
+            "summary": "",
+        } for module in modules.values()]
+        
+        result = self._build_project_structure("rust", project_path, list(modules.values()), toc)
+        result["unified"] = unified
+        return result
+    
     def convert_project(self, project_path: str, language: Language = None) -> Dict[str, Any]:
         """Convert any project to llmstruct format"""
         project_path = os.path.abspath(project_path)
@@ -183,6 +244,8 @@ class UniversalConverter:
             return self.convert_go_project(project_path)
         elif language == Language.JAVASCRIPT:
             return self.convert_javascript_project(project_path)
+        elif language == Language.RUST:
+            return self.convert_rust_project(project_path)
         else:
             raise NotImplementedError(f"Language {language.value} not yet supported")
     
@@ -203,9 +266,12 @@ class UniversalConverter:
                 if language == Language.PYTHON:
                     result = self.convert_python_project(project_path)
                 elif language == Language.GO:
-                    result = self.convert_go_project(project_path)
+                    # Единая схема нужна для объединения с другими языками
+                    result = self.convert_go_project(project_path, ["-unified"])
                 elif language == Language.JAVASCRIPT:
                     result = self.convert_javascript_project(project_path)
+                elif language == Language.RUST:
+                    result = self.convert_rust_project(project_path)
                 else:
                     logger.warning(f"Skipping unsupported language: {language.value}")
                     continue
@@ -272,8 +338,17 @@ class UniversalConverter:
         all_modules = []
         all_toc = []
         combined_stats = {"modules_count": 0, "functions_count": 0, "classes_count": 0, "total_lines": 0}
+        unified = {"schema_version": "1.0", "languages": [], "symbols": [], "relations": []}
         
         for language, result in language_results.items():
+            # Единая схема объединяется без преобразований: файлы общие (file:<путь>),
+            # идентификаторы остальных символов содержат язык
+            if result.get("unified"):
+                unified["languages"].append(language)
+                unified["symbols"].extend(result["unified"].get("symbols", []))
+                unified["relations"].extend(result["unified"].get("relations", []))
+            
+
             # Prefix module IDs with language
             for module in result.get("modules", []):
                 module["module_id"] = f"{language}.{module['module_id']}"
@@ -306,6 +381,7 @@ class UniversalConverter:
             },
             "toc": all_toc,
             "modules": all_modules,
+            "unified": unified,
         }
 
 
@@ -1,6 +1,6 @@
 import pytest
 
-from llmstruct.parsers.go_converter import convert_to_llmstruct_format
+from llmstruct.parsers.go_converter import PROJECT_SECTIONS, convert_to_llmstruct_format
 
 
 @pytest.mark.unit
@@ -33,4 +33,55 @@ def test_go_converter_adds_tags():
     assert set(func_tags) == {"function", "public"}
 
     class_tags = module["classes"][0]["tags"]
-    assert set(class_tags) == {"class", "public"} 
\ No newline at end of file
+    assert set(class_tags) == {"class", "public"} 
+
+@pytest.mark.unit
+def test_go_converter_keeps_structured_fields():
+    fields = [
+        {"name": "ID", "type": "int", "tags": {"json": "id"}, "docstring": "Primary key"},
+        {"name": "Base", "type": "Base", "embedded": True},
+    ]
+    analysis = {
+        "files": [
+            {
+                "path": "model.go",
+                "package": "model",
+                "functions": [],
+                "structs": [
+                    {"name": "User", "line": 3, "fields": fields, "methods": []},
+                ],
+                "interfaces": [],
+                "imports": [],
+            }
+        ],
+        "all_packages": ["model"],
+    }
+
+    result = convert_to_llmstruct_format(analysis)
+    user = result["modules"][0]["classes"][0]
+
+    # Fields stay structured objects, not "Name Type" strings
+    assert user["fields"] == fields
+    assert user["fields"][0]["tags"] == {"json": "id"}
+    assert user["fields"][1]["embedded"] is True
+
+
+@pytest.mark.unit
+def test_go_converter_passes_project_sections_through():
+    analysis = {
+        "files": [],
+        "all_packages": [],
+        "feature_flags": [{"key": "new-ui", "provider": "openfeature"}],
+        "routes": [{"method": "GET", "path": "/health"}],
+        "coverage": {},
+    }
+
+    result = convert_to_llmstruct_format(analysis)
+
+    assert result["feature_flags"] == analysis["feature_flags"]
+    assert result["routes"] == analysis["routes"]
+    # Empty sections are not copied
+    assert "coverage" not in result
+    for section in PROJECT_SECTIONS:
+        if section not in ("feature_flags", "routes"):
+            assert section not in result
@@ -0,0 +1,150 @@
+import importlib
+import pytest
+
+tree_sitter_spec = importlib.util.find_spec("tree_sitter")
+tree_sitter_rust_spec = importlib.util.find_spec("tree_sitter_rust")
+
+pytestmark = [
+    pytest.mark.unit,
+    pytest.mark.skipif(
+        tree_sitter_spec is None or tree_sitter_rust_spec is None,
+        reason="tree-sitter not installed",
+    ),
+]
+
+LIB_RS = """
+use crate::shapes::Shape;
+use std::fmt::{self, Display};
+
+pub mod shapes;
+
+/// Adds two numbers.
+pub fn add(a: i32, b: i32) -> i32 {
+    a + b
+}
+
+fn helper() {}
+
+#[derive(Debug, Clone)]
+pub struct Point {
+    pub x: i32,
+    y: i32,
+}
+
+pub trait Area {
+    fn area(&self) -> f64;
+    fn name(&self) -> String {
+        String::new()
+    }
+}
+
+impl Area for Point {
+    fn area(&self) -> f64 {
+        0.0
+    }
+}
+
+impl Shape for Point {}
+
+impl Point {
+    pub fn new() -> Self {
+        Point { x: 0, y: 0 }
+    }
+}
+"""
+
+SHAPES_RS = """
+pub trait Shape {}
+"""
+
+
+@pytest.fixture
+def crate_dir(tmp_path):
+    """Crate demo-crate with src/lib.rs and src/shapes/mod.rs"""
+    (tmp_path / "Cargo.toml").write_text('[package]\nname = "demo-crate"\nversion = "0.1.0"\n')
+    (tmp_path / "src" / "shapes").mkdir(parents=True)
+    (tmp_path / "src" / "lib.rs").write_text(LIB_RS)
+    (tmp_path / "src" / "shapes" / "mod.rs").write_text(SHAPES_RS)
+    return tmp_path
+
+
+def parse(crate_dir):
+    from llmstruct.parsers.rust_parser import RustParser
+
+    files = [str(crate_dir / "src" / "lib.rs"), str(crate_dir / "src" / "shapes" / "mod.rs")]
+    return RustParser().parse_project(str(crate_dir), files)
+
+
+def test_rust_parser_functions_and_structs(crate_dir):
+    unified = parse(crate_dir)
+    assert unified["errors"] == []
+    symbols = {s["id"]: s for s in unified["symbols"]}
+
+    # mod shapes; and shapes/mod.rs yield a single module symbol
+    assert [s["id"] for s in unified["symbols"]].count("rust:demo_crate::shapes") == 1
+    assert symbols["file:src/lib.rs"]["extensions"]["rust"]["module"] == "demo_crate"
+    assert symbols["file:src/shapes/mod.rs"]["extensions"]["rust"]["module"] == "demo_crate::shapes"
+
+    add = symbols["rust:demo_crate::add"]
+    assert add["kind"] == "function"
+    assert add["exported"] is True
+    assert add["doc"] == "Adds two numbers."
+    assert add["parent"] == "file:src/lib.rs"
+    assert add["extensions"]["rust"]["params"] == ["a: i32", "b: i32"]
+    assert add["extensions"]["rust"]["returns"] == "i32"
+    assert symbols["rust:demo_crate::helper"]["exported"] is False
+
+    point = symbols["rust:demo_crate::Point"]
+    assert point["kind"] == "type"
+    assert point["extensions"]["rust"]["type_kind"] == "struct"
+    assert point["extensions"]["rust"]["fields"] == ["pub x: i32", "y: i32"]
+    assert point["extensions"]["rust"]["derives"] == ["Debug", "Clone"]
+
+
+def test_rust_parser_traits_impls_and_uses(crate_dir):
+    unified = parse(crate_dir)
+    symbols = {s["id"]: s for s in unified["symbols"]}
+    relations = {(r["kind"], r["from"], r["to"]) for r in unified["relations"]}
+
+    area = symbols["rust:demo_crate::Area"]
+    assert area["kind"] == "interface"
+    assert area["extensions"]["rust"]["methods"] == ["area", "name"]
+    assert symbols["rust:demo_crate::Area::area"]["extensions"]["rust"]["default"] is False
+    assert symbols["rust:demo_crate::Area::name"]["extensions"]["rust"]["default"] is True
+    assert ("member_of", "rust:demo_crate::Area::area", "rust:demo_crate::Area") in relations
+
+    # Trait impl methods are exported with the trait
+    impl_area = symbols["rust:demo_crate::Point::area"]
+    assert impl_area["kind"] == "method"
+    assert impl_area["exported"] is True
+    assert impl_area["extensions"]["rust"]["impl_trait"] == "demo_crate::Area"
+    assert symbols["rust:demo_crate::Point::new"]["exported"] is True
+    assert ("member_of", "rust:demo_crate::Point::new", "rust:demo_crate::Point") in relations
+    assert ("implements", "rust:demo_crate::Point", "rust:demo_crate::Area") in relations
+
+    # Trait names imported with use resolve to their module path
+    assert ("implements", "rust:demo_crate::Point", "rust:demo_crate::shapes::Shape") in relations
+    assert ("imports", "file:src/lib.rs", "rust:demo_crate::shapes::Shape") in relations
+    assert ("imports", "file:src/lib.rs", "rust:std::fmt::Display") in relations
+
+
+def test_convert_rust_project(crate_dir):
+    from llmstruct.parsers.universal_converter import UniversalConverter
+
+    result = UniversalConverter().convert_rust_project(str(crate_dir))
+
+    assert result["metadata"]["language"] == "rust"
+    assert "unified" in result
+    modules = {m["path"]: m for m in result["modules"]}
+    assert set(modules) == {"src/lib.rs", "src/shapes/mod.rs"}
+
+    lib = modules["src/lib.rs"]
+    assert lib["module_id"] == "demo_crate"
+    functions = {f["name"]: f for f in lib["functions"] if not f["is_method"]}
+    assert set(functions) == {"add", "helper"}
+    assert functions["add"]["parameters"] == ["a: i32", "b: i32"]
+    assert functions["add"]["is_exported"] is True
+    classes = {c["name"]: c["kind"] for c in lib["classes"]}
+    assert classes == {"Point": "struct", "Area": "trait"}
+
+    assert {t["path"]: t["classes"] for t in result["toc"]}["src/shapes/mod.rs"] == 1